@@ -0,0 +1,160 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog caches metadata of tables, which the source and the destination look up on open,
+// so connectors of the same database don't query the system views for every table again.
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
+)
+
+// DefaultTTL is the time metadata of shared catalogs is cached for.
+const DefaultTTL = 5 * time.Minute
+
+// Catalog is a concurrency-safe cache of metadata of tables by their names.
+// The cached [columntypes.TableInfo] is shared by all callers, so it must not be modified.
+type Catalog struct {
+	// ttl - time metadata is cached for, it's cached until invalidated if it's zero.
+	ttl time.Duration
+	// load - loads metadata of the table from the database.
+	load func(ctx context.Context, querier columntypes.Querier, table string) (columntypes.TableInfo, error)
+	now  func() time.Time
+
+	mu     sync.Mutex
+	tables map[string]entry
+	// generations - numbers of invalidations by table names, metadata loaded before an invalidation isn't cached.
+	generations map[string]int
+}
+
+// entry is cached metadata of a table.
+type entry struct {
+	info     columntypes.TableInfo
+	loadedAt time.Time
+}
+
+// New creates an empty catalog, which caches metadata for the ttl.
+func New(ttl time.Duration) *Catalog {
+	return &Catalog{
+		ttl:         ttl,
+		load:        columntypes.GetTableInfo,
+		now:         time.Now,
+		tables:      make(map[string]entry),
+		generations: make(map[string]int),
+	}
+}
+
+var (
+	sharedMu sync.Mutex
+	// shared - catalogs of the process by their keys.
+	shared = make(map[string]*Catalog)
+)
+
+// Shared returns the catalog of the process shared by connectors with the same key, see [Key].
+func Shared(key string) *Catalog {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	c, ok := shared[key]
+	if !ok {
+		c = New(DefaultTTL)
+		shared[key] = c
+	}
+
+	return c
+}
+
+// Key returns the key of the catalog of connections of the auth config with the default schema,
+// which resolve the same table names. It's a hash, so the secrets of the auth config aren't kept in it.
+func Key(auth config.AuthConfig, defaultSchema string) string {
+	// fmt prints the options sorted by their names.
+	sum := sha256.Sum256(fmt.Appendf(nil, "%q %q %q %q %q %q %q %q %q %v %q",
+		auth.Mechanism, auth.Host, auth.DSN, auth.Username, auth.Password, auth.Token,
+		auth.ClientCertFilePath, auth.ClientKeyFilePath, auth.DatabaseName, auth.Options, defaultSchema))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// TableInfo returns the cached metadata of the table, it's loaded from the database if it isn't cached
+// or has expired.
+func (c *Catalog) TableInfo(ctx context.Context, querier columntypes.Querier, table string) (
+	columntypes.TableInfo, error,
+) {
+	name := strings.ToUpper(table)
+
+	c.mu.Lock()
+	e, ok := c.tables[name]
+	generation := c.generations[name]
+	c.mu.Unlock()
+
+	if ok && (c.ttl == 0 || c.now().Sub(e.loadedAt) < c.ttl) {
+		return e.info, nil
+	}
+
+	return c.fetch(ctx, querier, name, generation)
+}
+
+// Refresh loads metadata of the table from the database and caches it, regardless of the cached one.
+func (c *Catalog) Refresh(ctx context.Context, querier columntypes.Querier, table string) (
+	columntypes.TableInfo, error,
+) {
+	name := strings.ToUpper(table)
+
+	return c.fetch(ctx, querier, name, c.invalidate(name))
+}
+
+// Invalidate removes the cached metadata of the table, so it's loaded again on the next lookup.
+// It's called after the table is altered.
+func (c *Catalog) Invalidate(table string) {
+	c.invalidate(strings.ToUpper(table))
+}
+
+// invalidate removes the cached metadata of the table and returns the new generation of the table.
+func (c *Catalog) invalidate(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tables, name)
+	c.generations[name]++
+
+	return c.generations[name]
+}
+
+// fetch loads metadata of the table and caches it, unless the table was invalidated since the generation.
+func (c *Catalog) fetch(ctx context.Context, querier columntypes.Querier, name string, generation int) (
+	columntypes.TableInfo, error,
+) {
+	info, err := c.load(ctx, querier, name)
+	if err != nil {
+		return columntypes.TableInfo{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[name] == generation {
+		c.tables[name] = entry{info: info, loadedAt: c.now()}
+	}
+
+	return info, nil
+}
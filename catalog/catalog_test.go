@@ -0,0 +1,149 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
+)
+
+// fakeLoader loads metadata with the number of the load as the schema name.
+type fakeLoader struct {
+	loads int
+	err   error
+}
+
+func (l *fakeLoader) load(_ context.Context, _ columntypes.Querier, table string) (columntypes.TableInfo, error) {
+	if l.err != nil {
+		return columntypes.TableInfo{}, l.err
+	}
+
+	l.loads++
+
+	return columntypes.TableInfo{Schema: string(rune('0' + l.loads)), Name: table}, nil
+}
+
+func newTestCatalog(ttl time.Duration, loader *fakeLoader, now *time.Time) *Catalog {
+	c := New(ttl)
+	c.load = loader.load
+	c.now = func() time.Time { return *now }
+
+	return c
+}
+
+func TestCatalog_TableInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	loader := &fakeLoader{}
+	c := newTestCatalog(time.Minute, loader, &now)
+
+	info, err := c.TableInfo(ctx, nil, "clients")
+	if err != nil {
+		t.Fatalf("table info error = %v", err)
+	}
+
+	if info.Name != "CLIENTS" || info.Schema != "1" {
+		t.Errorf("table info = %+v, want the first load of CLIENTS", info)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if info, _ = c.TableInfo(ctx, nil, "CLIENTS"); info.Schema != "1" {
+		t.Errorf("schema = %s, want the cached metadata", info.Schema)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if info, _ = c.TableInfo(ctx, nil, "CLIENTS"); info.Schema != "2" {
+		t.Errorf("schema = %s, want the metadata loaded after the ttl", info.Schema)
+	}
+
+	c.Invalidate("clients")
+
+	if info, _ = c.TableInfo(ctx, nil, "CLIENTS"); info.Schema != "3" {
+		t.Errorf("schema = %s, want the metadata loaded after the invalidation", info.Schema)
+	}
+
+	if info, _ = c.Refresh(ctx, nil, "CLIENTS"); info.Schema != "4" {
+		t.Errorf("schema = %s, want the refreshed metadata", info.Schema)
+	}
+
+	if info, _ = c.TableInfo(ctx, nil, "CLIENTS"); info.Schema != "4" {
+		t.Errorf("schema = %s, want the refreshed metadata cached", info.Schema)
+	}
+}
+
+func TestCatalog_TableInfo_Error(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	loader := &fakeLoader{err: errors.New("table CLIENTS doesn't exist")}
+	c := newTestCatalog(0, loader, &now)
+
+	if _, err := c.TableInfo(context.Background(), nil, "CLIENTS"); !errors.Is(err, loader.err) {
+		t.Errorf("error = %v, want %v", err, loader.err)
+	}
+
+	if len(c.tables) != 0 {
+		t.Error("failed lookup is cached")
+	}
+}
+
+func TestCatalog_fetch_Invalidated(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := newTestCatalog(0, &fakeLoader{}, &now)
+
+	// the table is invalidated while its metadata is loaded.
+	generation := c.generations["CLIENTS"]
+	c.Invalidate("CLIENTS")
+
+	if _, err := c.fetch(context.Background(), nil, "CLIENTS", generation); err != nil {
+		t.Fatalf("fetch error = %v", err)
+	}
+
+	if _, ok := c.tables["CLIENTS"]; ok {
+		t.Error("metadata loaded before the invalidation is cached")
+	}
+}
+
+func TestShared(t *testing.T) {
+	t.Parallel()
+
+	auth := config.AuthConfig{Mechanism: config.BasicAuthType, Host: "hana:30015", Username: "user", Password: "secret"}
+
+	if Shared(Key(auth, "SALES")) != Shared(Key(auth, "SALES")) {
+		t.Error("catalogs of the same key are different")
+	}
+
+	if Shared(Key(auth, "SALES")) == Shared(Key(auth, "HR")) {
+		t.Error("catalogs of different default schemas are the same")
+	}
+
+	other := auth
+	other.Username = "other"
+
+	if Key(auth, "SALES") == Key(other, "SALES") {
+		t.Error("keys of different users are the same")
+	}
+}
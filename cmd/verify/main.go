@@ -0,0 +1,148 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command verify compares the source table of a pipeline with its target table by their row counts
+// and the checksums of their rows, and prints the drift between them as JSON.
+// It exits with the code 1 if the tables differ, and 2 if the comparison fails.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/verify"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	exitOK     = 0
+	exitDrift  = 1
+	exitFailed = 2
+)
+
+// errRequiredFlags occurs when the databases or the table aren't set.
+var errRequiredFlags = errors.New("source-dsn, target-dsn and table are required")
+
+func main() {
+	os.Exit(verifyTables())
+}
+
+// verifyTables compares the tables of the flags and returns the exit code.
+func verifyTables() int {
+	var (
+		sourceDSN   = flag.String("source-dsn", os.Getenv("SAPHANA_SOURCE_DSN"), "DSN of the source database")
+		targetDSN   = flag.String("target-dsn", os.Getenv("SAPHANA_TARGET_DSN"), "DSN of the target database")
+		table       = flag.String("table", "", "name of the source table")
+		targetTable = flag.String("target-table", "", "name of the target table, the source one by default")
+		keys        = flag.String("keys", "", "comma separated key columns, the primary key by default")
+		columns     = flag.String("columns", "", "comma separated compared columns, all comparable ones by default")
+		maxSamples  = flag.Int("samples", 10, "max number of keys of drifted rows in the report")
+	)
+
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := run(ctx, *sourceDSN, *targetDSN, verify.Params{
+		SourceTable: *table,
+		TargetTable: *targetTable,
+		Keys:        split(*keys),
+		Columns:     split(*columns),
+		MaxSamples:  *maxSamples,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitFailed
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitFailed
+	}
+
+	if report.HasDrift() {
+		return exitDrift
+	}
+
+	return exitOK
+}
+
+// run connects to both databases and compares the tables.
+func run(ctx context.Context, sourceDSN, targetDSN string, params verify.Params) (verify.Report, error) {
+	if sourceDSN == "" || targetDSN == "" || params.SourceTable == "" {
+		return verify.Report{}, errRequiredFlags
+	}
+
+	source, err := connect(sourceDSN)
+	if err != nil {
+		return verify.Report{}, fmt.Errorf("connect to source db: %w", err)
+	}
+	defer source.Close()
+
+	target, err := connect(targetDSN)
+	if err != nil {
+		return verify.Report{}, fmt.Errorf("connect to target db: %w", err)
+	}
+	defer target.Close()
+
+	params.Source, params.Target = source, target
+
+	report, err := verify.Run(ctx, params)
+	if err != nil {
+		return verify.Report{}, fmt.Errorf("verify: %w", err)
+	}
+
+	return report, nil
+}
+
+// connect connects to the database of the DSN.
+func connect(dsn string) (*sqlx.DB, error) {
+	auth := config.AuthConfig{Mechanism: config.DSNAuthType, DSN: dsn}
+
+	db, err := helper.ConnectToDB(auth)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the caller wraps it
+	}
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("ping db: %w", helper.RedactError(err, auth))
+	}
+
+	return db, nil
+}
+
+// split returns the comma separated values, it's nil if the list is empty.
+func split(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	return strings.Split(list, ",")
+}
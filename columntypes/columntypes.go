@@ -22,9 +22,12 @@ import (
 	"math"
 	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/SAP/go-hdb/driver"
 	"github.com/conduitio/conduit-commons/opencdc"
@@ -50,9 +53,27 @@ const (
 	// sap hana binary types.
 	varbinaryType = "VARBINARY"
 
+	// sap hana large object types, which can't be compared.
+	blobType    = "BLOB"
+	textType    = "TEXT"
+	bintextType = "BINTEXT"
+
+	// generatedAlwaysPrefix prefix of the generation type of columns generated always.
+	generatedAlwaysPrefix = "ALWAYS"
+
 	// sap hana decimal type.
 	smallDecimalType = "SMALLDECIMAL"
 	decimalType      = "DECIMAL"
+
+	// sap hana integer types.
+	tinyintType  = "TINYINT"
+	smallintType = "SMALLINT"
+	integerType  = "INTEGER"
+	bigintType   = "BIGINT"
+
+	// sap hana floating point types.
+	realType   = "REAL"
+	doubleType = "DOUBLE"
 )
 
 const (
@@ -61,11 +82,14 @@ const (
 		  COLUMN_NAME, 
 		  DATA_TYPE_NAME,
 		  LENGTH,
-		  SCALE
+		  SCALE,
+		  IS_NULLABLE,
+		  GENERATION_TYPE
 		FROM 
 		  TABLE_COLUMNS 
 		WHERE 
 		  TABLE_NAME = $1
+		  AND SCHEMA_NAME = $2
 `
 	queryGetPrimaryKeys = `
 		SELECT 
@@ -74,9 +98,39 @@ const (
 		  CONSTRAINTS 
 		WHERE 
 		  TABLE_NAME = $1 
+		  AND SCHEMA_NAME = $2
 		  AND IS_PRIMARY_KEY = 'TRUE'
 `
-	queryIfTableExist = `SELECT count(*) AS count FROM TABLES WHERE TABLE_NAME = $1`
+	// not null unique indexes go first, unique indexes are keys only if all their columns are not null.
+	queryGetUniqueIndexColumns = `
+		SELECT 
+		  INDEX_NAME, 
+		  COLUMN_NAME 
+		FROM 
+		  INDEX_COLUMNS 
+		WHERE 
+		  TABLE_NAME = $1 
+		  AND SCHEMA_NAME = $2
+		  AND CONSTRAINT IN ('NOT NULL UNIQUE', 'UNIQUE')
+		ORDER BY 
+		  CONSTRAINT, INDEX_NAME, POSITION
+`
+	queryGetTable = `SELECT SCHEMA_NAME, TABLE_NAME FROM TABLES WHERE TABLE_NAME = $1 AND SCHEMA_NAME = CURRENT_SCHEMA`
+	// synonyms of the current schema take precedence over public ones.
+	queryGetSynonymTable = `
+		SELECT 
+		  OBJECT_SCHEMA, 
+		  OBJECT_NAME 
+		FROM 
+		  SYNONYMS 
+		WHERE 
+		  SYNONYM_NAME = $1 
+		  AND SCHEMA_NAME IN (CURRENT_SCHEMA, 'PUBLIC') 
+		  AND OBJECT_TYPE = 'TABLE'
+		ORDER BY 
+		  CASE SCHEMA_NAME WHEN 'PUBLIC' THEN 1 ELSE 0 END
+		LIMIT 1
+`
 )
 
 // column types where length is required parameter.
@@ -84,37 +138,188 @@ var typesWithLength = []string{varcharType, nvarcharType, varbinaryType, alphanu
 
 // TableInfo - information about colum types, primary keys from table.
 type TableInfo struct {
+	// Schema - schema name of the table, or of the base table if the name is a synonym.
+	Schema string
+	// Name - table name, or the base table name if the name is a synonym.
+	Name string
 	// ColumnTypes - column name with column type.
 	ColumnTypes map[string]string
 	// PrimaryKeys - primary keys column names.
 	PrimaryKeys []string
+	// UniqueKeys - column names of the first unique index.
+	UniqueKeys []string
 	// ColumnLengths - column name with length.
 	ColumnLengths map[string]int
 	// ColumnScales - column name with scale.
 	ColumnScales map[string]*int
+	// NotNullColumns - names of columns which don't accept null values.
+	NotNullColumns map[string]bool
+	// GeneratedColumns - names of generated and identity columns with their generation type,
+	// for example: ALWAYS AS IDENTITY, BY DEFAULT AS IDENTITY, ALWAYS AS.
+	GeneratedColumns map[string]string
+	// QuoteIdentifiers - column names of the query parts are quoted.
+	QuoteIdentifiers bool
+}
+
+// QualifiedName returns the quoted name of the table with its schema, for example: "SALES"."ORDERS".
+func (t TableInfo) QualifiedName() string {
+	return fmt.Sprintf(`"%s"."%s"`, t.Schema, t.Name)
+}
+
+// IsGeneratedAlways returns true if the column values are always generated by the database,
+// so they can't be inserted or updated.
+func (t TableInfo) IsGeneratedAlways(column string) bool {
+	return strings.HasPrefix(t.GeneratedColumns[column], generatedAlwaysPrefix)
+}
+
+// SchemaChange - names of columns added, dropped or changed their type.
+type SchemaChange struct {
+	Added   []string `json:"added,omitempty"`
+	Dropped []string `json:"dropped,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// IsEmpty returns true if no columns were changed.
+func (s SchemaChange) IsEmpty() bool {
+	return len(s.Added) == 0 && len(s.Dropped) == 0 && len(s.Changed) == 0
+}
+
+// CompareColumns returns columns which were added, dropped or changed their type in the current column types.
+func CompareColumns(previous, current map[string]string) SchemaChange {
+	var change SchemaChange
+
+	for column, columnType := range current {
+		previousType, ok := previous[column]
+
+		switch {
+		case !ok:
+			change.Added = append(change.Added, column)
+		case previousType != columnType:
+			change.Changed = append(change.Changed, column)
+		}
+	}
+
+	for column := range previous {
+		if _, ok := current[column]; !ok {
+			change.Dropped = append(change.Dropped, column)
+		}
+	}
+
+	sort.Strings(change.Added)
+	sort.Strings(change.Dropped)
+	sort.Strings(change.Changed)
+
+	return change
+}
+
+// IsComparable returns true if values of the column type can be compared with the comparison operators.
+// Large objects and vectors can't be compared.
+func IsComparable(columnType string) bool {
+	switch columnType {
+	case blobType, clobType, nclobType, textType, bintextType, realVectorType:
+		return false
+	default:
+		return true
+	}
+}
+
+// IsTimeType returns true if values of the column type are read as time values: DATE, TIME, SECONDDATE, TIMESTAMP.
+func IsTimeType(columnType string) bool {
+	switch columnType {
+	case dateType, timeType, secondDateType, timestampType:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuoteIdentifier returns the identifier in double quotes, double quotes inside it are doubled.
+// Quoted identifiers can be reserved words, e.g. ORDER, or contain special characters, e.g. /BIC/AZSALES00.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Identifier returns the identifier quoted if quote is true or it isn't a plain identifier,
+// and as it is otherwise.
+func Identifier(name string, quote bool) string {
+	if !quote && IsPlainIdentifier(name) {
+		return name
+	}
+
+	return QuoteIdentifier(name)
+}
+
+// IsPlainIdentifier returns true if the identifier is valid without quotes and means the same as quoted:
+// it starts with a letter or an underscore, has only upper case letters, digits, underscores, # and $.
+// SAP namespace names, e.g. /BIC/AZSALES00, aren't plain identifiers.
+func IsPlainIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLower(r):
+			return false
+		case unicode.IsLetter(r), r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '#' || r == '$'):
+		default:
+			return false
+		}
+	}
+
+	return name != ""
 }
 
 // GetColumnQueryPart prepare query part about creation column for tracking table.
 // For example: NAME VARCHAR(40), AGE INT, ADDRESS VARCHAR(120).
 func (t TableInfo) GetColumnQueryPart() string {
 	var columns []string
-	for key, val := range t.ColumnTypes {
-		cl := fmt.Sprintf("%s %s", key, val)
-		// add length value
-		if isTypeWithRequiredLength(val) {
-			cl = fmt.Sprintf("%s(%d)", cl, t.ColumnLengths[key])
-		}
-		// add length and scale, only for decimal type
-		if val == decimalType && t.ColumnScales[key] != nil {
-			cl = fmt.Sprintf("%s(%d,%d)", cl, t.ColumnLengths[key], *t.ColumnScales[key])
-		}
-
-		columns = append(columns, cl)
+	for key := range t.ColumnTypes {
+		columns = append(columns, t.columnDefinition(key))
 	}
 
 	return strings.Join(columns, ",")
 }
 
+// GetColumnsQueryPart prepare query part about the given columns, for altering the tracking table.
+func (t TableInfo) GetColumnsQueryPart(columns []string) string {
+	definitions := make([]string, len(columns))
+	for i, key := range columns {
+		definitions[i] = t.columnDefinition(key)
+	}
+
+	return strings.Join(definitions, ",")
+}
+
+// ColumnDefinitions returns the types of the columns with their lengths, precisions and scales,
+// for example: VARCHAR(40), DECIMAL(10,2).
+func (t TableInfo) ColumnDefinitions() map[string]string {
+	definitions := make(map[string]string, len(t.ColumnTypes))
+	for key := range t.ColumnTypes {
+		definitions[key] = t.columnType(key)
+	}
+
+	return definitions
+}
+
+// columnDefinition returns the column name with its type, for example: NAME VARCHAR(40).
+func (t TableInfo) columnDefinition(key string) string {
+	return fmt.Sprintf("%s %s", Identifier(key, t.QuoteIdentifiers), t.columnType(key))
+}
+
+// columnType returns the column type with its length, or precision and scale, for example: VARCHAR(40).
+func (t TableInfo) columnType(key string) string {
+	val := t.ColumnTypes[key]
+
+	// add length value
+	if isTypeWithRequiredLength(val) {
+		return fmt.Sprintf("%s(%d)", val, t.ColumnLengths[key])
+	}
+	// add length and scale, only for decimal type
+	if val == decimalType && t.ColumnScales[key] != nil {
+		return fmt.Sprintf("%s(%d,%d)", val, t.ColumnLengths[key], *t.ColumnScales[key])
+	}
+
+	return val
+}
+
 func isTypeWithRequiredLength(elem string) bool {
 	for _, val := range typesWithLength {
 		if val == elem {
@@ -130,6 +335,65 @@ var layouts = []string{
 	time.RFC3339, time.RFC3339Nano, time.Layout, time.ANSIC, time.UnixDate, time.RubyDate,
 	time.RFC822, time.RFC822Z, time.RFC850, time.RFC1123, time.RFC1123Z, time.RFC3339, time.RFC3339,
 	time.RFC3339Nano, time.Kitchen, time.Stamp, time.StampMilli, time.StampMicro, time.StampNano,
+	sapHanaTimestampLayout, time.DateTime, time.DateOnly,
+}
+
+const (
+	// sapHanaTimestampLayout is the default sap hana string representation of timestamps.
+	sapHanaTimestampLayout = "2006-01-02 15:04:05.9999999"
+	// timestampLayout is RFC3339 with all 7 fractional digits of the TIMESTAMP type.
+	timestampLayout = "2006-01-02T15:04:05.0000000Z07:00"
+
+	// timestampPrecision is the precision of the TIMESTAMP type.
+	timestampPrecision = 100 * time.Nanosecond
+	// secondDatePrecision is the precision of the SECONDDATE type.
+	secondDatePrecision = time.Second
+)
+
+// TruncateTime truncates the time to the precision of the column type.
+func TruncateTime(t time.Time, columnType string) time.Time {
+	switch columnType {
+	case timestampType:
+		return t.Truncate(timestampPrecision)
+	case secondDateType:
+		return t.Truncate(secondDatePrecision)
+	default:
+		return t
+	}
+}
+
+// TimeFormat defines how time values are represented in source records.
+type TimeFormat string
+
+const (
+	// TimeFormatRFC3339 formats time values as RFC3339 strings.
+	TimeFormatRFC3339 TimeFormat = "rfc3339"
+	// TimeFormatUnixMillis formats DATE, SECONDDATE and TIMESTAMP values as epoch milliseconds.
+	TimeFormatUnixMillis TimeFormat = "unixMillis"
+	// TimeFormatDate formats DATE values as dates without time, e.g. 2018-01-01.
+	TimeFormatDate TimeFormat = "date"
+)
+
+// TransformOptions holds options of the TransformRow function.
+type TransformOptions struct {
+	// TimeFormat defines how time values are represented.
+	TimeFormat TimeFormat
+	// Masking defines which column values are masked.
+	Masking Masking
+	// BooleanColumns are integer columns of logical booleans, their values are represented as true or false.
+	BooleanColumns []string
+}
+
+// FormatTime formats the time according to the precision of the column type.
+// TIMESTAMP values always keep their 7 fractional digits, other types are formatted as RFC3339.
+func FormatTime(t time.Time, columnType string) string {
+	t = TruncateTime(t, columnType)
+
+	if columnType == timestampType {
+		return t.Format(timestampLayout)
+	}
+
+	return t.Format(time.RFC3339)
 }
 
 // Querier is a database querier interface needed for the GetColumnTypes function.
@@ -137,41 +401,65 @@ type Querier interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 }
 
-// GetTableInfo returns a map containing all table's columns and their database types
-// and returns primary columns names.
-//
-//nolint:funlen,nolintlint
-func GetTableInfo(ctx context.Context, querier Querier, tableName string) (TableInfo, error) {
-	var primaryKeys []string
+// ResolveTable returns the schema and the name of the table.
+// If there's no such table in the current schema, the name is resolved as a synonym
+// of the current schema or a public one.
+func ResolveTable(ctx context.Context, querier Querier, tableName string) (string, string, error) {
+	for _, query := range []string{queryGetTable, queryGetSynonymTable} {
+		schema, table, err := queryTableName(ctx, querier, query, tableName)
+		if err != nil {
+			return "", "", err
+		}
 
-	// check if table exist.
-	rows, err := querier.QueryContext(ctx, queryIfTableExist, tableName)
-	if err != nil {
-		return TableInfo{}, fmt.Errorf("execute query exist table: %w", err)
+		if table != "" {
+			return schema, table, nil
+		}
 	}
 
-	defer rows.Close() //nolint:staticcheck,nolintlint
+	return "", "", fmt.Errorf("%w: %s", ErrTableNotFound, tableName)
+}
 
-	for rows.Next() {
-		var count int
-		scanErr := rows.Scan(&count)
-		if scanErr != nil {
-			return TableInfo{}, fmt.Errorf("scan: %w", scanErr)
-		}
+// queryTableName returns the schema and the table name from the first row of the query result.
+func queryTableName(ctx context.Context, querier Querier, query, tableName string) (string, string, error) {
+	rows, err := querier.QueryContext(ctx, query, tableName)
+	if err != nil {
+		return "", "", fmt.Errorf("query table name: %w", err)
+	}
+	defer rows.Close()
+
+	var schema, table string
 
-		if count == 0 {
-			return TableInfo{}, fmt.Errorf("table %s doesn't exist", tableName)
+	if rows.Next() {
+		if er := rows.Scan(&schema, &table); er != nil {
+			return "", "", fmt.Errorf("scan: %w", er)
 		}
 	}
 	if rows.Err() != nil {
-		return TableInfo{}, fmt.Errorf("iterate rows error: %w", rows.Err())
+		return "", "", fmt.Errorf("iterate rows error: %w", rows.Err())
+	}
+
+	return schema, table, nil
+}
+
+// GetTableInfo returns a map containing all table's columns and their database types
+// and returns primary columns names. Synonyms are followed to their base tables.
+//
+//nolint:funlen,nolintlint
+func GetTableInfo(ctx context.Context, querier Querier, tableName string) (TableInfo, error) {
+	var primaryKeys []string
+
+	schema, baseTable, err := ResolveTable(ctx, querier, strings.ToUpper(tableName))
+	if err != nil {
+		return TableInfo{}, fmt.Errorf("resolve table: %w", err)
 	}
 
 	columnTypes := make(map[string]string)
 	columnLengths := make(map[string]int)
 	columnScales := make(map[string]*int)
+	notNullColumns := make(map[string]bool)
+	generatedColumns := make(map[string]string)
 
-	rows, err = querier.QueryContext(ctx, querySchemaColumnTypes, strings.ToUpper(tableName))
+	rows, err := querier.QueryContext(ctx, querySchemaColumnTypes, baseTable, schema)
 	if err != nil {
 		return TableInfo{}, fmt.Errorf("query get column types: %w", err)
 	}
@@ -179,24 +467,29 @@ func GetTableInfo(ctx context.Context, querier Querier, tableName string) (Table
 
 	for rows.Next() {
 		var (
-			columnName, dataType string
-			length               int
-			scale                *int
+			columnName, dataType, isNullable string
+			length                           int
+			scale                            *int
+			generationType                   *string
 		)
 
-		if er := rows.Scan(&columnName, &dataType, &length, &scale); er != nil {
+		if er := rows.Scan(&columnName, &dataType, &length, &scale, &isNullable, &generationType); er != nil {
 			return TableInfo{}, fmt.Errorf("scan rows: %w", er)
 		}
 
 		columnTypes[columnName] = dataType
 		columnLengths[columnName] = length
 		columnScales[columnName] = scale
+		notNullColumns[columnName] = isNullable == "FALSE"
+		if generationType != nil && *generationType != "" {
+			generatedColumns[columnName] = *generationType
+		}
 	}
 	if rows.Err() != nil {
 		return TableInfo{}, fmt.Errorf("iterate rows error: %w", rows.Err())
 	}
 
-	rows, err = querier.QueryContext(ctx, queryGetPrimaryKeys, strings.ToUpper(tableName))
+	rows, err = querier.QueryContext(ctx, queryGetPrimaryKeys, baseTable, schema)
 	if err != nil {
 		return TableInfo{}, fmt.Errorf("query get column types: %w", err)
 	}
@@ -215,19 +508,85 @@ func GetTableInfo(ctx context.Context, querier Querier, tableName string) (Table
 		return TableInfo{}, fmt.Errorf("iterate rows error: %w", rows.Err())
 	}
 
+	uniqueKeys, err := getUniqueKeys(ctx, querier, schema, baseTable, notNullColumns)
+	if err != nil {
+		return TableInfo{}, fmt.Errorf("get unique keys: %w", err)
+	}
+
 	return TableInfo{
-		ColumnTypes:   columnTypes,
-		PrimaryKeys:   primaryKeys,
-		ColumnLengths: columnLengths,
-		ColumnScales:  columnScales,
+		Schema:           schema,
+		Name:             baseTable,
+		ColumnTypes:      columnTypes,
+		PrimaryKeys:      primaryKeys,
+		UniqueKeys:       uniqueKeys,
+		ColumnLengths:    columnLengths,
+		ColumnScales:     columnScales,
+		NotNullColumns:   notNullColumns,
+		GeneratedColumns: generatedColumns,
 	}, nil
 }
 
+// getUniqueKeys returns column names of the first unique index of the table without nullable columns.
+// Rows with nulls in a unique index aren't unique, so such an index doesn't identify them.
+func getUniqueKeys(
+	ctx context.Context, querier Querier, schema, tableName string, notNullColumns map[string]bool,
+) ([]string, error) {
+	rows, err := querier.QueryContext(ctx, queryGetUniqueIndexColumns, tableName, schema)
+	if err != nil {
+		return nil, fmt.Errorf("query get unique index columns: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		index    string
+		columns  []string
+		nullable bool
+	)
+
+	for rows.Next() {
+		var indexName, columnName string
+
+		if er := rows.Scan(&indexName, &columnName); er != nil {
+			return nil, fmt.Errorf("scan rows: %w", er)
+		}
+
+		// only columns of a single index form the key.
+		if indexName != index {
+			if len(columns) > 0 && !nullable {
+				return columns, nil
+			}
+
+			index, columns, nullable = indexName, nil, false
+		}
+
+		columns = append(columns, columnName)
+		nullable = nullable || !notNullColumns[columnName]
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate rows error: %w", rows.Err())
+	}
+
+	if nullable {
+		return nil, nil
+	}
+
+	return columns, nil
+}
+
+// ConvertOptions holds options of the ConvertStructuredData function.
+type ConvertOptions struct {
+	// ThousandsSeparator separates groups of thousands in strings written into decimal and float columns,
+	// e.g. 1,234.56 with the "," separator. If it's ".", the decimal separator is ",", e.g. 1.234,56.
+	ThousandsSeparator string
+}
+
 // ConvertStructuredData converts a sdk.StructureData values to a proper database types.
+// Values of column types with converters are converted by them, see [RegisterConverter].
 func ConvertStructuredData(
 	_ context.Context,
 	columnTypes map[string]string,
 	data opencdc.StructuredData,
+	opts ConvertOptions,
 ) (opencdc.StructuredData, error) {
 	result := make(opencdc.StructuredData, len(data))
 
@@ -238,6 +597,40 @@ func ConvertStructuredData(
 			continue
 		}
 
+		columnType := columnTypes[strings.ToUpper(key)]
+
+		if strValue, ok := value.(string); ok && opts.ThousandsSeparator != "" && isFractionalType(columnType) {
+			normalized, err := normalizeNumber(strValue, opts.ThousandsSeparator)
+			if err != nil {
+				return nil, fmt.Errorf("convert %q: %w", key, err)
+			}
+
+			value = normalized
+		}
+
+		if converter, ok := converterOf(columnType); ok && converter.Write != nil {
+			converted, err := converter.Write(value, columnType)
+			if err != nil {
+				return nil, fmt.Errorf("convert %q: %w", key, err)
+			}
+
+			result[key] = converted
+
+			continue
+		}
+
+		// numbers decoded with json.Decoder.UseNumber keep their precision.
+		if num, ok := value.(json.Number); ok {
+			numValue, err := convertNumber(num, columnType)
+			if err != nil {
+				return nil, fmt.Errorf("convert number %q: %w", key, err)
+			}
+
+			result[key] = numValue
+
+			continue
+		}
+
 		// sap hana doesn't have json type or similar.
 		// string types can replace it.
 		if reflect.TypeOf(value).Kind() == reflect.Map {
@@ -251,71 +644,124 @@ func ConvertStructuredData(
 			continue
 		}
 
-		// Converting value to time if it is string.
-		switch columnTypes[strings.ToUpper(key)] {
-		case dateType, timeType, secondDateType, timestampType:
-			_, ok := value.(time.Time)
-			if ok {
-				result[key] = value
+		result[key] = value
+	}
+
+	return result, nil
+}
 
+// FitColumnLengths checks string and binary values against the column lengths.
+// Values that exceed a column length are truncated if truncate is true, otherwise an error is returned.
+func FitColumnLengths(
+	data opencdc.StructuredData,
+	columnTypes map[string]string,
+	columnLengths map[string]int,
+	truncate bool,
+) (opencdc.StructuredData, error) {
+	for key, value := range data {
+		columnName := strings.ToUpper(key)
+		if !isTypeWithRequiredLength(columnTypes[columnName]) {
+			continue
+		}
+
+		maxLength := columnLengths[columnName]
+
+		switch v := value.(type) {
+		case string:
+			length := utf8.RuneCountInString(v)
+			if length <= maxLength {
 				continue
 			}
 
-			valueStr, ok := value.(string)
-			if !ok {
-				return nil, ErrValueIsNotAString
+			if !truncate {
+				return nil, valueExceedsColumnLengthErr(key, length, maxLength)
 			}
 
-			timeValue, err := parseToTime(valueStr)
-			if err != nil {
-				return nil, fmt.Errorf("convert value to time.Time: %w", err)
+			data[key] = string([]rune(v)[:maxLength])
+		case []byte:
+			if len(v) <= maxLength {
+				continue
 			}
 
-			result[key] = timeValue
-		case decimalType, smallDecimalType:
-			decValue, err := convertToDecimal(value)
-			if err != nil {
-				return nil, fmt.Errorf("convert to decimal: %w", err)
+			if !truncate {
+				return nil, valueExceedsColumnLengthErr(key, len(v), maxLength)
 			}
 
-			result[key] = decValue
-		default:
-			result[key] = value
+			data[key] = v[:maxLength]
 		}
 	}
 
-	return result, nil
+	return data, nil
 }
 
 // TransformRow converts row map values to appropriate Go types, based on the columnTypes.
-func TransformRow(_ context.Context, row map[string]any, columnTypes map[string]string) (map[string]any, error) {
+// Values of column types with converters are converted by them, see [RegisterConverter].
+func TransformRow(
+	_ context.Context,
+	row map[string]any,
+	columnTypes map[string]string,
+	opts TransformOptions,
+) (map[string]any, error) {
 	result := make(map[string]any, len(row))
 
 	for key, value := range row {
-		if value == nil {
+		converter, ok := converterOf(columnTypes[key])
+		if value == nil || !ok || converter.Read == nil {
 			result[key] = value
 
 			continue
 		}
 
-		switch columnTypes[key] {
-		// Convert to string.
-		case clobType, varcharType, nclobType, nvarcharType, alphanumType, shortTextType:
-			valueBytes, ok := value.([]byte)
-			if !ok {
-				return nil, convertValueToBytesErr(key)
-			}
+		converted, err := converter.Read(value, columnTypes[key], opts)
+		if err != nil {
+			return nil, fmt.Errorf("convert %q: %w", key, err)
+		}
 
-			result[key] = string(valueBytes)
+		result[key] = converted
+	}
 
-		default:
-			result[key] = value
+	for _, column := range opts.BooleanColumns {
+		if value, ok := result[column]; ok {
+			result[column] = toBool(value)
 		}
 	}
 
+	opts.Masking.apply(result)
+
 	return result, nil
 }
 
+// toBool converts an integer value of a logical boolean column to true if it isn't zero,
+// values of other types are kept as they are.
+func toBool(value any) any {
+	switch v := value.(type) {
+	case int64:
+		return v != 0
+	case int32:
+		return v != 0
+	case int16:
+		return v != 0
+	case uint8:
+		return v != 0
+	case int:
+		return v != 0
+	default:
+		return value
+	}
+}
+
+// transformTime represents the time value of the column type in the time format.
+func transformTime(t time.Time, columnType string, format TimeFormat) any {
+	switch {
+	case format == TimeFormatUnixMillis && columnType != timeType:
+		return TruncateTime(t, columnType).UnixMilli()
+	case format == TimeFormatDate && columnType == dateType:
+		return t.Format(time.DateOnly)
+	default:
+		return FormatTime(t, columnType)
+	}
+}
+
 func parseToTime(val string) (time.Time, error) {
 	for _, l := range layouts {
 		timeValue, err := time.Parse(l, val)
@@ -329,66 +775,191 @@ func parseToTime(val string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("%s - %w", val, ErrInvalidTimeLayout)
 }
 
-// convertToDecimal - convert variable to special Sap HANA decimal type.
-func convertToDecimal(val any) (*driver.Decimal, error) {
-	switch reflect.TypeOf(val).Kind() { //nolint:exhaustive,nolintlint
-	case reflect.Float64, reflect.Float32:
-		return convertStrToDecimal(fmt.Sprintf("%g", val))
-	case reflect.String:
-		strVal := fmt.Sprintf("%s", val)
-		if strings.Contains(strVal, ".") { // usual case, for example 110.45
-			return convertStrToDecimal(strVal)
-		}
-		if strings.Contains(strVal, "/") { // sap hana case, for example  11045/100
-			parts := strings.Split(strVal, "/")
-			if len(parts) != 2 { //nolint:mnd,nolintlint
-				return nil, ErrInvalidDecimalStringPresentation
-			}
+// convertNumber converts json.Number to the Go type of the column type without losing precision.
+func convertNumber(num json.Number, columnType string) (any, error) {
+	switch columnType {
+	case decimalType, smallDecimalType:
+		rat, ok := new(big.Rat).SetString(num.String())
+		if !ok {
+			return nil, ErrCannotConvertValueToDecimal
+		}
 
-			a, err := strconv.ParseInt(parts[0], 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("parse to int64: %w", err)
-			}
+		return (*driver.Decimal)(rat), nil
+	case tinyintType, smallintType, integerType, bigintType:
+		intValue, err := num.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("parse to int64: %w", err)
+		}
 
-			b, err := strconv.ParseInt(parts[1], 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("parse to int64: %w", err)
-			}
+		return intValue, nil
+	case realType, doubleType:
+		floatValue, err := num.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("parse to float64: %w", err)
+		}
 
-			return (*driver.Decimal)(big.NewRat(a, b)), nil
+		return floatValue, nil
+	case varcharType, nvarcharType, clobType, nclobType, alphanumType, shortTextType:
+		return num.String(), nil
+	default:
+		if intValue, err := num.Int64(); err == nil {
+			return intValue, nil
 		}
-	case reflect.Int64, reflect.Int32:
-		intVal, ok := val.(int64)
-		if !ok {
-			return nil, ErrCannotConvertToInt
+
+		floatValue, err := num.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("parse to float64: %w", err)
+		}
+
+		return floatValue, nil
+	}
+}
+
+// formatDecimal formats the decimal with as many fractional digits as it has, e.g. 0.000001 or 15000000000.
+// Fractions that have no finite decimal representation are formatted as a/b.
+func formatDecimal(rat *big.Rat) string {
+	denominator := new(big.Int).Set(rat.Denom())
+
+	var digits int
+	for _, factor := range []int64{2, 5} {
+		var count int
+
+		f := big.NewInt(factor)
+		for new(big.Int).Mod(denominator, f).Sign() == 0 {
+			denominator.Quo(denominator, f)
+			count++
+		}
+
+		digits = max(digits, count)
+	}
+
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		return rat.RatString()
+	}
+
+	return rat.FloatString(digits)
+}
+
+// isFractionalType returns true if the column type is a decimal or a float type.
+func isFractionalType(columnType string) bool {
+	switch columnType {
+	case decimalType, smallDecimalType, realType, doubleType:
+		return true
+	default:
+		return false
+	}
+}
+
+// thousandsGroupLength is the number of digits in groups of thousands.
+const thousandsGroupLength = 3
+
+// normalizeNumber removes thousands separators from the integer part of the number, e.g. 1,234.56 becomes 1234.56.
+// If the thousands separator is ".", the decimal separator "," is replaced with ".", e.g. 1.234,56 becomes 1234.56.
+// Groups of thousands must have 3 digits, so 1,23 isn't taken for 123.
+func normalizeNumber(value, thousandsSeparator string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	decimalSeparator := "."
+	if thousandsSeparator == "." {
+		decimalSeparator = ","
+	}
+
+	// the integer part ends at the decimal separator or the exponent.
+	end := strings.IndexAny(value, decimalSeparator+"eE")
+	if end == -1 {
+		end = len(value)
+	}
+
+	integer, fraction := value[:end], value[end:]
+
+	if strings.Contains(fraction, thousandsSeparator) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, value)
+	}
+
+	fraction = strings.Replace(fraction, decimalSeparator, ".", 1)
+
+	sign := ""
+	if strings.HasPrefix(integer, "-") || strings.HasPrefix(integer, "+") {
+		sign, integer = integer[:1], integer[1:]
+	}
+
+	groups := strings.Split(integer, thousandsSeparator)
+	if len(groups) > 1 {
+		if len(groups[0]) == 0 || len(groups[0]) > thousandsGroupLength {
+			return "", fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, value)
+		}
+
+		for _, group := range groups[1:] {
+			if len(group) != thousandsGroupLength {
+				return "", fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, value)
+			}
 		}
+	}
+
+	return sign + strings.Join(groups, "") + fraction, nil
+}
 
-		return (*driver.Decimal)(big.NewRat(intVal, 1)), nil
+// convertToDecimal - convert variable to special Sap HANA decimal type.
+// Floats are converted by their shortest representation, so 0.1 stays 0.1 instead of its binary approximation.
+// Strings can be decimals, e.g. 110.45, in the scientific notation, e.g. 1.5e10, or sap hana fractions, e.g. 11045/100.
+func convertToDecimal(val any) (*driver.Decimal, error) {
+	switch v := val.(type) {
+	case *driver.Decimal:
+		return v, nil
+	case *big.Rat:
+		return (*driver.Decimal)(v), nil
+	case float64:
+		return floatToDecimal(v, 64)
+	case float32:
+		return floatToDecimal(float64(v), 32)
+	case string:
+		return convertStrToDecimal(strings.TrimSpace(v))
+	}
+
+	rv := reflect.ValueOf(val)
+
+	switch rv.Kind() { //nolint:exhaustive // other kinds can't be converted
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return (*driver.Decimal)(new(big.Rat).SetInt64(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return (*driver.Decimal)(new(big.Rat).SetUint64(rv.Uint())), nil
 	default:
 		return nil, ErrCannotConvertValueToDecimal
 	}
+}
+
+// floatToDecimal converts the float of the bit size by its shortest representation.
+func floatToDecimal(f float64, bitSize int) (*driver.Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, ErrCannotConvertValueToDecimal
+	}
 
-	return nil, ErrCannotConvertValueToDecimal
+	return convertStrToDecimal(strconv.FormatFloat(f, 'g', -1, bitSize))
 }
 
+// convertStrToDecimal parses decimals, numbers in the scientific notation and sap hana fractions.
 func convertStrToDecimal(strVal string) (*driver.Decimal, error) {
-	parts := strings.Split(strVal, ".")
-	if len(parts) == 1 { //nolint:mnd,nolintlint
-		i, err := strconv.ParseInt(parts[0], 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("parse to int64: %w", err)
+	// fractions are parsed by parts, as big.Rat treats numbers with leading zeros in them as octal.
+	if numerator, denominator, ok := strings.Cut(strVal, "/"); ok {
+		a, okA := new(big.Int).SetString(numerator, 10)
+		b, okB := new(big.Int).SetString(denominator, 10)
+
+		if !okA || !okB || b.Sign() == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, strVal)
 		}
 
-		return (*driver.Decimal)(big.NewRat(i, 1)), nil
+		return (*driver.Decimal)(new(big.Rat).SetFrac(a, b)), nil
+	}
+
+	// base prefixes, like 0x, aren't decimals.
+	if strings.ContainsAny(strVal, "xXoObBpP_") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, strVal)
 	}
-	if len(parts) == 2 { //nolint:mnd,nolintlint
-		ft, err := strconv.ParseInt(strings.Join(parts, ""), 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("parse to int64: %w", err)
-		}
 
-		return (*driver.Decimal)(big.NewRat(ft, int64(math.Pow(10, float64(len(parts[1])))))), nil //nolint:mnd,nolintlint
+	rat, ok := new(big.Rat).SetString(strVal)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDecimalStringPresentation, strVal)
 	}
 
-	return nil, ErrInvalidDecimalStringPresentation
+	return (*driver.Decimal)(rat), nil
 }
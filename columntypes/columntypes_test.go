@@ -0,0 +1,628 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestFitColumnLengths(t *testing.T) {
+	t.Parallel()
+
+	columnTypes := map[string]string{"NAME": nvarcharType, "DATA": varbinaryType, "AGE": "INTEGER"}
+	columnLengths := map[string]int{"NAME": 3, "DATA": 2, "AGE": 10}
+
+	t.Run("success, fits", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		got, err := FitColumnLengths(opencdc.StructuredData{"name": "ab", "AGE": 25}, columnTypes, columnLengths, false)
+		is.NoErr(err)
+		is.Equal(got, opencdc.StructuredData{"name": "ab", "AGE": 25})
+	})
+
+	t.Run("success, truncate", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		got, err := FitColumnLengths(opencdc.StructuredData{"NAME": "äbcd", "DATA": []byte{1, 2, 3}},
+			columnTypes, columnLengths, true)
+		is.NoErr(err)
+		is.Equal(got, opencdc.StructuredData{"NAME": "äbc", "DATA": []byte{1, 2}})
+	})
+
+	t.Run("fail, overflow", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		_, err := FitColumnLengths(opencdc.StructuredData{"NAME": "abcd"}, columnTypes, columnLengths, false)
+		is.True(errors.Is(err, ErrValueExceedsColumnLength))
+	})
+}
+
+func TestConvertStructuredData_JSONNumber(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"ID": bigintType, "AMOUNT": decimalType, "NAME": nvarcharType}
+
+	got, err := ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{
+		"id":     json.Number("9223372036854775807"),
+		"AMOUNT": json.Number("12345678901234567.123456789"),
+		"NAME":   json.Number("42"),
+	}, ConvertOptions{})
+	is.NoErr(err)
+
+	is.Equal(got["id"], int64(9223372036854775807))
+	is.Equal((*big.Rat)(got["AMOUNT"].(*driver.Decimal)).FloatString(9), "12345678901234567.123456789")
+	is.Equal(got["NAME"], "42")
+}
+
+func TestFormatTime(t *testing.T) {
+	t.Parallel()
+
+	value := time.Date(2023, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	tests := []struct {
+		columnType string
+		want       string
+	}{
+		{columnType: timestampType, want: "2023-01-02T03:04:05.1234567Z"},
+		{columnType: secondDateType, want: "2023-01-02T03:04:05Z"},
+		{columnType: dateType, want: "2023-01-02T03:04:05Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.columnType, func(t *testing.T) {
+			t.Parallel()
+
+			if got := FormatTime(value, tt.columnType); got != tt.want {
+				t.Errorf("FormatTime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformRow_TimeFormat(t *testing.T) {
+	t.Parallel()
+
+	value := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	columnTypes := map[string]string{"CL_DATE": dateType}
+
+	tests := []struct {
+		format TimeFormat
+		want   any
+	}{
+		{format: TimeFormatRFC3339, want: "2018-01-01T00:00:00Z"},
+		{format: TimeFormatUnixMillis, want: int64(1514764800000)},
+		{format: TimeFormatDate, want: "2018-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			is := is.New(t)
+
+			got, err := TransformRow(context.Background(), map[string]any{"CL_DATE": value}, columnTypes,
+				TransformOptions{TimeFormat: tt.format})
+			is.NoErr(err)
+			is.Equal(got["CL_DATE"], tt.want)
+		})
+	}
+}
+
+func TestCompareColumns(t *testing.T) {
+	t.Parallel()
+
+	previous := map[string]string{"ID": "INTEGER", "NAME": "VARCHAR", "FAX": "VARCHAR", "AGE": "INTEGER"}
+	current := map[string]string{"ID": "INTEGER", "NAME": "NVARCHAR", "PHONE": "VARCHAR", "AGE": "INTEGER"}
+
+	want := SchemaChange{
+		Added:   []string{"PHONE"},
+		Dropped: []string{"FAX"},
+		Changed: []string{"NAME"},
+	}
+
+	got := CompareColumns(previous, current)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareColumns() = %v, want %v", got, want)
+	}
+
+	if !CompareColumns(current, current).IsEmpty() {
+		t.Errorf("CompareColumns() of the same columns isn't empty")
+	}
+}
+
+func TestTableInfo_GetColumnsQueryPart(t *testing.T) {
+	t.Parallel()
+
+	scale := 2
+
+	tableInfo := TableInfo{
+		ColumnTypes:   map[string]string{"ID": "INTEGER", "PHONE": "VARCHAR", "PRICE": "DECIMAL"},
+		ColumnLengths: map[string]int{"ID": 10, "PHONE": 18, "PRICE": 10},
+		ColumnScales:  map[string]*int{"PRICE": &scale},
+	}
+
+	want := "PHONE VARCHAR(18),PRICE DECIMAL(10,2)"
+
+	if got := tableInfo.GetColumnsQueryPart([]string{"PHONE", "PRICE"}); got != want {
+		t.Errorf("GetColumnsQueryPart() = %q, want %q", got, want)
+	}
+
+	tableInfo.QuoteIdentifiers = true
+
+	want = `"PHONE" VARCHAR(18),"PRICE" DECIMAL(10,2)`
+
+	if got := tableInfo.GetColumnsQueryPart([]string{"PHONE", "PRICE"}); got != want {
+		t.Errorf("GetColumnsQueryPart() of quoted identifiers = %q, want %q", got, want)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		quote bool
+		want  string
+	}{
+		{name: "ORDERS", want: "ORDERS"},
+		{name: "ORDERS", quote: true, want: `"ORDERS"`},
+		{name: "/BIC/AZSALES00", want: `"/BIC/AZSALES00"`},
+		{name: "orderId", want: `"orderId"`},
+		{name: "1ST", want: `"1ST"`},
+		{name: "AMOUNT$2", want: "AMOUNT$2"},
+	}
+
+	for _, tt := range tests {
+		if got := Identifier(tt.name, tt.quote); got != tt.want {
+			t.Errorf("Identifier(%q, %t) = %s, want %s", tt.name, tt.quote, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"ORDER":          `"ORDER"`,
+		"/BIC/AZSALES00": `"/BIC/AZSALES00"`,
+		`A"B`:            `"A""B"`,
+	}
+
+	for name, want := range tests {
+		if got := QuoteIdentifier(name); got != want {
+			t.Errorf("QuoteIdentifier(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestTableInfo_ColumnDefinitions(t *testing.T) {
+	t.Parallel()
+
+	scale := 2
+
+	tableInfo := TableInfo{
+		ColumnTypes:   map[string]string{"ID": "INTEGER", "PHONE": "NVARCHAR", "PRICE": "DECIMAL", "RATE": "DECIMAL"},
+		ColumnLengths: map[string]int{"ID": 10, "PHONE": 18, "PRICE": 10, "RATE": 34},
+		ColumnScales:  map[string]*int{"PRICE": &scale},
+	}
+
+	want := map[string]string{"ID": "INTEGER", "PHONE": "NVARCHAR(18)", "PRICE": "DECIMAL(10,2)", "RATE": "DECIMAL"}
+
+	if got := tableInfo.ColumnDefinitions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ColumnDefinitions() = %v, want %v", got, want)
+	}
+}
+
+func TestTransformRow_Masking(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"EMAIL": varcharType, "PHONE": varcharType, "SSN": varcharType, "FAX": varcharType}
+	row := map[string]any{
+		"EMAIL": []byte("user@example.com"),
+		"PHONE": []byte("555-0100"),
+		"SSN":   []byte("078-05-1120"),
+		"FAX":   nil,
+	}
+
+	got, err := TransformRow(context.Background(), row, columnTypes, TransformOptions{
+		Masking: Masking{
+			Columns: map[string]MaskingStrategy{
+				"EMAIL": MaskingHash,
+				"PHONE": MaskingNull,
+				"SSN":   MaskingFixed,
+				"FAX":   MaskingFixed,
+			},
+			FixedValue: "****",
+		},
+	})
+	is.NoErr(err)
+
+	// sha256 of user@example.com.
+	is.Equal(got["EMAIL"], "b4c9a289323b21a01c3e940f150eb9b8c542587f1abfd8f0e1cc1ffc5e475514")
+	is.Equal(got["PHONE"], nil)
+	is.Equal(got["SSN"], "****")
+	is.Equal(got["FAX"], nil)
+}
+
+func TestTransformRow_BooleanColumns(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"ACTIVE": tinyintType, "DELETED": tinyintType, "LEVEL": tinyintType, "FLAG": tinyintType}
+	row := map[string]any{"ACTIVE": int64(1), "DELETED": int64(0), "LEVEL": int64(3), "FLAG": nil}
+
+	got, err := TransformRow(context.Background(), row, columnTypes, TransformOptions{
+		BooleanColumns: []string{"ACTIVE", "DELETED", "FLAG", "MISSING"},
+	})
+	is.NoErr(err)
+
+	is.Equal(got, map[string]any{"ACTIVE": true, "DELETED": false, "LEVEL": int64(3), "FLAG": nil})
+}
+
+func TestConvertStructuredData_Boolean(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"ACTIVE": tinyintType, "DELETED": tinyintType, "LEVEL": tinyintType}
+
+	got, err := ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{
+		"ACTIVE":  true,
+		"deleted": false,
+		"LEVEL":   json.Number("3"),
+	}, ConvertOptions{})
+	is.NoErr(err)
+
+	is.Equal(got["ACTIVE"], int64(1))
+	is.Equal(got["deleted"], int64(0))
+	is.Equal(got["LEVEL"], int64(3))
+}
+
+func TestConvertToDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   any
+		want    string
+		wantErr error
+	}{
+		{name: "decimal string", value: "110.45", want: "2209/20"},
+		{name: "integer string", value: "42", want: "42"},
+		{name: "scientific notation", value: "1.5e10", want: "15000000000"},
+		{name: "negative exponent", value: "-1.2E-5", want: "-3/250000"},
+		{name: "sap hana fraction", value: "11045/100", want: "2209/20"},
+		{name: "leading zeros fraction", value: "010/0100", want: "1/10"},
+		{name: "big decimal", value: "123456789012345678901234.5", want: "246913578024691357802469/2"},
+		{name: "float64", value: 0.1, want: "1/10"},
+		{name: "float32", value: float32(0.1), want: "1/10"},
+		{name: "int", value: 7, want: "7"},
+		{name: "uint64", value: uint64(18446744073709551615), want: "18446744073709551615"},
+		{name: "hex string", value: "0x10", wantErr: ErrInvalidDecimalStringPresentation},
+		{name: "invalid string", value: "1.2.3", wantErr: ErrInvalidDecimalStringPresentation},
+		{name: "zero denominator", value: "1/0", wantErr: ErrInvalidDecimalStringPresentation},
+		{name: "nan", value: math.NaN(), wantErr: ErrCannotConvertValueToDecimal},
+		{name: "bool", value: true, wantErr: ErrCannotConvertValueToDecimal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			is := is.New(t)
+
+			got, err := convertToDecimal(tt.value)
+			if tt.wantErr != nil {
+				is.True(errors.Is(err, tt.wantErr))
+
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal((*big.Rat)(got).RatString(), tt.want)
+		})
+	}
+}
+
+func TestConvertStructuredData_Float(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"RATIO": doubleType, "SCORE": realType, "AMOUNT": smallDecimalType}
+
+	got, err := ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{
+		"RATIO":  "1.5e10",
+		"SCORE":  big.NewRat(1, 4),
+		"AMOUNT": "-2.5E-3",
+	}, ConvertOptions{})
+	is.NoErr(err)
+
+	is.Equal(got["RATIO"], 1.5e10)
+	is.Equal(got["SCORE"], 0.25)
+	is.Equal((*big.Rat)(got["AMOUNT"].(*driver.Decimal)).RatString(), "-1/400")
+
+	_, err = ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{"RATIO": "1,5"},
+		ConvertOptions{})
+	is.True(err != nil)
+}
+
+func TestTransformRow_SmallDecimal(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"SMALL": smallDecimalType, "TINY": smallDecimalType, "AMOUNT": decimalType}
+	row := map[string]any{
+		"SMALL":  big.NewRat(15000000000, 1),
+		"TINY":   big.NewRat(-1, 1000000),
+		"AMOUNT": big.NewRat(11045, 100),
+	}
+
+	got, err := TransformRow(context.Background(), row, columnTypes, TransformOptions{})
+	is.NoErr(err)
+
+	is.Equal(got["SMALL"], "15000000000")
+	is.Equal(got["TINY"], "-0.000001")
+	// DECIMAL values keep the type of the driver.
+	is.Equal(got["AMOUNT"], big.NewRat(11045, 100))
+}
+
+func TestFormatDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value *big.Rat
+		want  string
+	}{
+		{value: big.NewRat(2209, 20), want: "110.45"},
+		{value: big.NewRat(3, 8), want: "0.375"},
+		{value: big.NewRat(42, 1), want: "42"},
+		{value: big.NewRat(1, 3), want: "1/3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			if got := formatDecimal(tt.value); got != tt.want {
+				t.Errorf("formatDecimal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value     string
+		separator string
+		want      string
+		wantErr   bool
+	}{
+		{value: "1,234.56", separator: ",", want: "1234.56"},
+		{value: "-1,234,567", separator: ",", want: "-1234567"},
+		{value: "1234.56", separator: ",", want: "1234.56"},
+		{value: "1,234.5E+3", separator: ",", want: "1234.5E+3"},
+		{value: "1.234,56", separator: ".", want: "1234.56"},
+		{value: "1 234 567.5", separator: " ", want: "1234567.5"},
+		{value: "1'234.5", separator: "'", want: "1234.5"},
+		{value: "1,23", separator: ",", wantErr: true},
+		{value: "1234,567", separator: ",", wantErr: true},
+		{value: ",123", separator: ",", wantErr: true},
+		{value: "1.234,5", separator: ",", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			is := is.New(t)
+
+			got, err := normalizeNumber(tt.value, tt.separator)
+			if tt.wantErr {
+				is.True(errors.Is(err, ErrInvalidDecimalStringPresentation))
+
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestConvertStructuredData_ThousandsSeparator(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	columnTypes := map[string]string{"AMOUNT": decimalType, "RATIO": doubleType, "NAME": nvarcharType}
+
+	got, err := ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{
+		"AMOUNT": "1,234.56",
+		"RATIO":  "1,200.5e-2",
+		"NAME":   "1,234",
+	}, ConvertOptions{ThousandsSeparator: ","})
+	is.NoErr(err)
+
+	is.Equal((*big.Rat)(got["AMOUNT"].(*driver.Decimal)).FloatString(2), "1234.56")
+	is.Equal(got["RATIO"], 12.005)
+	is.Equal(got["NAME"], "1,234")
+}
+
+func TestGetUniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	// indexColumns returns the database with pairs of index and column names in the order of the query,
+	// not null unique indexes first.
+	indexColumns := func(names ...string) *fakedb.DB {
+		var rows [][]sqldriver.Value
+		for i := 0; i < len(names); i += 2 {
+			rows = append(rows, []sqldriver.Value{names[i], names[i+1]})
+		}
+
+		return fakedb.New(func(string, []any) fakedb.Result {
+			return fakedb.Result{Columns: []string{"INDEX_NAME", "COLUMN_NAME"}, Rows: rows}
+		})
+	}
+
+	tests := []struct {
+		name    string
+		db      *fakedb.DB
+		notNull map[string]bool
+		want    []string
+	}{
+		{
+			name:    "columns of the first index",
+			db:      indexColumns("UX_CODE", "REGION", "UX_CODE", "CODE", "UX_EMAIL", "EMAIL"),
+			notNull: map[string]bool{"REGION": true, "CODE": true, "EMAIL": true},
+			want:    []string{"REGION", "CODE"},
+		},
+		{
+			name:    "index with a nullable column is skipped",
+			db:      indexColumns("UX_CODE", "REGION", "UX_CODE", "CODE", "UX_EMAIL", "EMAIL"),
+			notNull: map[string]bool{"REGION": true, "EMAIL": true},
+			want:    []string{"EMAIL"},
+		},
+		{
+			name:    "only nullable indexes",
+			db:      indexColumns("UX_EMAIL", "EMAIL"),
+			notNull: map[string]bool{},
+		},
+		{
+			name: "no unique indexes",
+			db:   indexColumns(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			is := is.New(t)
+
+			got, err := getUniqueKeys(context.Background(), tt.db.Open(), "SALES", "CLIENTS", tt.notNull)
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestResolveTable(t *testing.T) {
+	t.Parallel()
+
+	errQuery := errors.New("connection refused")
+
+	// tableName returns the result of the query of the table name with the schema and the table of the rows.
+	tableName := func(rows ...[]sqldriver.Value) fakedb.Result {
+		return fakedb.Result{Columns: []string{"SCHEMA_NAME", "TABLE_NAME"}, Rows: rows}
+	}
+
+	tests := []struct {
+		name       string
+		table      fakedb.Result
+		synonym    fakedb.Result
+		wantSchema string
+		wantTable  string
+		wantErr    error
+	}{
+		{
+			name:       "table",
+			table:      tableName([]sqldriver.Value{"SALES", "ORDERS"}),
+			wantSchema: "SALES",
+			wantTable:  "ORDERS",
+		},
+		{
+			// synonyms are ordered by the query, the synonym of the current schema goes first.
+			name:       "synonym of the schema over the public one",
+			table:      tableName(),
+			synonym:    tableName([]sqldriver.Value{"ARCHIVE", "ORDERS_2024"}, []sqldriver.Value{"SHARED", "ORDERS"}),
+			wantSchema: "ARCHIVE",
+			wantTable:  "ORDERS_2024",
+		},
+		{
+			name:       "public synonym",
+			table:      tableName(),
+			synonym:    tableName([]sqldriver.Value{"SHARED", "ORDERS"}),
+			wantSchema: "SHARED",
+			wantTable:  "ORDERS",
+		},
+		{
+			name:    "neither table nor synonym",
+			table:   tableName(),
+			synonym: tableName(),
+			wantErr: ErrTableNotFound,
+		},
+		{
+			name:    "failed query",
+			table:   fakedb.Result{Err: errQuery},
+			wantErr: errQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, args []any) fakedb.Result {
+				if !reflect.DeepEqual(args, []any{"ORDERS"}) {
+					return fakedb.Result{Err: fmt.Errorf("unexpected args %v", args)}
+				}
+
+				if query == queryGetSynonymTable {
+					return tt.synonym
+				}
+
+				return tt.table
+			})
+
+			schema, table, err := ResolveTable(context.Background(), db.Open(), "ORDERS")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveTable() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if schema != tt.wantSchema || table != tt.wantTable {
+				t.Errorf("ResolveTable() = %s.%s, want %s.%s", schema, table, tt.wantSchema, tt.wantTable)
+			}
+		})
+	}
+}
@@ -26,9 +26,12 @@ var (
 	ErrInvalidDecimalStringPresentation = errors.New("invalid decimal string presentation")
 	ErrCannotConvertToInt               = errors.New("cannot convert value to int type")
 	ErrInvalidTimeLayout                = errors.New("invalid time layout")
+	ErrValueExceedsColumnLength         = errors.New("value exceeds column length")
+	ErrInvalidVector                    = errors.New("invalid vector")
+	ErrTableNotFound                    = errors.New("table doesn't exist")
 )
 
-// convertValueToBytesErr returns the formatted ErrCannotConvertValueToBytes error.
-func convertValueToBytesErr(name string) error {
-	return fmt.Errorf("%w: %q", ErrCannotConvertValueToBytes, name)
+// valueExceedsColumnLengthErr returns the formatted ErrValueExceedsColumnLength error.
+func valueExceedsColumnLengthErr(name string, length, maxLength int) error {
+	return fmt.Errorf("%w: %q has length %d, max length is %d", ErrValueExceedsColumnLength, name, length, maxLength)
 }
@@ -0,0 +1,92 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// MaskingStrategy defines how values of a column are masked in source records.
+type MaskingStrategy string
+
+const (
+	// MaskingNull replaces values with null.
+	MaskingNull MaskingStrategy = "null"
+	// MaskingHash replaces values with the hex encoded SHA-256 hash, or HMAC-SHA256 if the salt is set.
+	MaskingHash MaskingStrategy = "hash"
+	// MaskingFixed replaces values with the fixed value.
+	MaskingFixed MaskingStrategy = "fixed"
+)
+
+// IsValid checks the strategy is known.
+func (s MaskingStrategy) IsValid() bool {
+	switch s {
+	case MaskingNull, MaskingHash, MaskingFixed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Masking holds options of masking column values.
+type Masking struct {
+	// Columns - masking strategies by column names.
+	Columns map[string]MaskingStrategy
+	// FixedValue - value of the columns masked with the fixed strategy.
+	FixedValue string
+	// HashSalt - key of HMAC for the columns masked with the hash strategy.
+	HashSalt string
+}
+
+// apply masks values of the row, null values stay null.
+func (m Masking) apply(row map[string]any) {
+	for column, strategy := range m.Columns {
+		value, ok := row[column]
+		if !ok || value == nil {
+			continue
+		}
+
+		switch strategy {
+		case MaskingNull:
+			row[column] = nil
+		case MaskingHash:
+			row[column] = m.hash(value)
+		case MaskingFixed:
+			row[column] = m.FixedValue
+		}
+	}
+}
+
+// hash returns the hex encoded hash of the value.
+func (m Masking) hash(value any) string {
+	data, ok := value.([]byte)
+	if !ok {
+		data = []byte(fmt.Sprint(value))
+	}
+
+	if m.HashSalt == "" {
+		sum := sha256.Sum256(data)
+
+		return hex.EncodeToString(sum[:])
+	}
+
+	mac := hmac.New(sha256.New, []byte(m.HashSalt))
+	mac.Write(data) //nolint:errcheck // the hash never returns an error
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,209 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadFunc converts a value of the column type read from the database into the value of the record.
+type ReadFunc func(value any, columnType string, opts TransformOptions) (any, error)
+
+// WriteFunc converts a value of the record into the value written into the column of the type.
+type WriteFunc func(value any, columnType string) (any, error)
+
+// Converter converts non-nil values of a column type, values are kept as they are if its function is nil.
+type Converter struct {
+	Read  ReadFunc
+	Write WriteFunc
+}
+
+// registry holds converters by column types.
+var registry = struct {
+	sync.RWMutex
+	converters map[string]Converter
+}{
+	converters: builtinConverters(),
+}
+
+// RegisterConverter registers the converter of the column type, e.g. ST_GEOMETRY or a user-defined type,
+// replacing the existing one. It's meant to be called before the connector starts, e.g. in an init function.
+func RegisterConverter(columnType string, converter Converter) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.converters[strings.ToUpper(columnType)] = converter
+}
+
+// converterOf returns the converter of the column type, ok is false if there is no such converter.
+func converterOf(columnType string) (Converter, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	converter, ok := registry.converters[columnType]
+
+	return converter, ok
+}
+
+// builtinConverters returns converters of the column types supported by the connector.
+func builtinConverters() map[string]Converter {
+	converters := make(map[string]Converter)
+
+	for _, columnType := range []string{clobType, varcharType, nclobType, nvarcharType, alphanumType, shortTextType} {
+		converters[columnType] = Converter{Read: readString}
+	}
+
+	for _, columnType := range []string{dateType, timeType, secondDateType, timestampType} {
+		converters[columnType] = Converter{Read: readTime, Write: writeTime}
+	}
+
+	converters[decimalType] = Converter{Write: writeDecimal}
+	converters[smallDecimalType] = Converter{Read: readSmallDecimal, Write: writeDecimal}
+
+	for _, columnType := range []string{realType, doubleType} {
+		converters[columnType] = Converter{Write: writeFloat}
+	}
+
+	for _, columnType := range []string{tinyintType, smallintType, integerType, bigintType} {
+		converters[columnType] = Converter{Write: writeInteger}
+	}
+
+	converters[realVectorType] = Converter{Read: readVector, Write: writeVector}
+
+	return converters
+}
+
+// readString converts bytes of string types to a string.
+func readString(value any, _ string, _ TransformOptions) (any, error) {
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, ErrCannotConvertValueToBytes
+	}
+
+	return string(valueBytes), nil
+}
+
+// readTime formats time with the precision of the column type.
+func readTime(value any, columnType string, opts TransformOptions) (any, error) {
+	timeValue, ok := value.(time.Time)
+	if !ok {
+		return value, nil
+	}
+
+	return transformTime(timeValue, columnType, opts.TimeFormat), nil
+}
+
+// writeTime parses strings into time and truncates time to the precision of the column type.
+func writeTime(value any, columnType string) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return TruncateTime(v, columnType), nil
+	case json.Number:
+		return convertNumber(v, columnType)
+	case string:
+		timeValue, err := parseToTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("convert value to time.Time: %w", err)
+		}
+
+		return TruncateTime(timeValue, columnType), nil
+	default:
+		return nil, ErrValueIsNotAString
+	}
+}
+
+// writeDecimal converts numbers and their string representations to the decimal type of the driver.
+func writeDecimal(value any, columnType string) (any, error) {
+	// numbers decoded with json.Decoder.UseNumber keep their precision.
+	if num, ok := value.(json.Number); ok {
+		return convertNumber(num, columnType)
+	}
+
+	decValue, err := convertToDecimal(value)
+	if err != nil {
+		return nil, fmt.Errorf("convert to decimal: %w", err)
+	}
+
+	return decValue, nil
+}
+
+// readSmallDecimal formats the floating point decimal as a decimal string, e.g. 0.000001 or 15000000000,
+// instead of the fraction of the driver.
+func readSmallDecimal(value any, _ string, _ TransformOptions) (any, error) {
+	rat, ok := value.(*big.Rat)
+	if !ok {
+		return value, nil
+	}
+
+	return formatDecimal(rat), nil
+}
+
+// writeFloat parses numbers and strings, including the scientific notation like 1.5e10, and converts decimals.
+func writeFloat(value any, columnType string) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return convertNumber(v, columnType)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse float: %w", err)
+		}
+
+		return f, nil
+	case *big.Rat:
+		f, _ := v.Float64()
+
+		return f, nil
+	default:
+		return value, nil
+	}
+}
+
+// writeInteger converts booleans to 1 and 0, so integer columns can hold logical booleans of other systems.
+func writeInteger(value any, columnType string) (any, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+
+		return int64(0), nil
+	case json.Number:
+		return convertNumber(v, columnType)
+	default:
+		return value, nil
+	}
+}
+
+// readVector converts the vector to a float array.
+func readVector(value any, _ string, _ TransformOptions) (any, error) {
+	vector, err := parseVector(value)
+	if err != nil {
+		return nil, fmt.Errorf("parse vector: %w", err)
+	}
+
+	return vector, nil
+}
+
+// writeVector converts the float array to the TO_REAL_VECTOR function call.
+func writeVector(value any, _ string) (any, error) {
+	return convertVector(value)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestRegisterConverter(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	// the test type isn't used by other tests, so it doesn't affect them.
+	RegisterConverter("test_geometry", Converter{
+		Read: func(value any, _ string, _ TransformOptions) (any, error) {
+			return fmt.Sprintf("WKT %s", value), nil
+		},
+		Write: func(value any, _ string) (any, error) {
+			if _, ok := value.(string); !ok {
+				return nil, ErrValueIsNotAString
+			}
+
+			return fmt.Sprintf("ST_GEOMFROMTEXT(%s)", value), nil
+		},
+	})
+
+	columnTypes := map[string]string{"SHAPE": "TEST_GEOMETRY", "NAME": nvarcharType}
+
+	row, err := TransformRow(context.Background(), map[string]any{
+		"SHAPE": "POINT(1 2)",
+		"NAME":  []byte("home"),
+	}, columnTypes, TransformOptions{})
+	is.NoErr(err)
+	is.Equal(row, map[string]any{"SHAPE": "WKT POINT(1 2)", "NAME": "home"})
+
+	data, err := ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{
+		"shape": "POINT(1 2)",
+		"NAME":  "home",
+	}, ConvertOptions{})
+	is.NoErr(err)
+	is.Equal(data, opencdc.StructuredData{"shape": "ST_GEOMFROMTEXT(POINT(1 2))", "NAME": "home"})
+
+	_, err = ConvertStructuredData(context.Background(), columnTypes, opencdc.StructuredData{"SHAPE": 42},
+		ConvertOptions{})
+	is.True(errors.Is(err, ErrValueIsNotAString))
+}
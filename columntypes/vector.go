@@ -0,0 +1,165 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// sap hana vector type of the vector engine.
+const realVectorType = "REAL_VECTOR"
+
+// vectorDimensionSize is the size of the dimension number of the binary vector representation.
+const vectorDimensionSize = 4
+
+// parseVector converts the REAL_VECTOR column value to a float array.
+// The database returns vectors either in the binary fvecs format, which is a little-endian 4-byte dimension
+// followed by little-endian 4-byte floats, or in the text format, for example [0.1,0.2].
+func parseVector(value any) ([]float32, error) {
+	switch v := value.(type) {
+	case []float32:
+		return v, nil
+	case string:
+		return parseVectorText(v)
+	case []byte:
+		if len(v) > 0 && v[0] == '[' {
+			return parseVectorText(string(v))
+		}
+
+		return parseVectorBinary(v)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrInvalidVector, value)
+	}
+}
+
+// parseVectorBinary parses the binary fvecs format.
+func parseVectorBinary(data []byte) ([]float32, error) {
+	if len(data) < vectorDimensionSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidVector, len(data))
+	}
+
+	dimension := int(binary.LittleEndian.Uint32(data))
+	data = data[vectorDimensionSize:]
+
+	if len(data) != dimension*vectorDimensionSize {
+		return nil, fmt.Errorf("%w: dimension %d, %d bytes of values", ErrInvalidVector, dimension, len(data))
+	}
+
+	vector := make([]float32, dimension)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*vectorDimensionSize:]))
+	}
+
+	return vector, nil
+}
+
+// parseVectorText parses the text format, for example [0.1,0.2].
+func parseVectorText(text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidVector, text)
+	}
+
+	text = strings.TrimSpace(text[1 : len(text)-1])
+	if text == "" {
+		return []float32{}, nil
+	}
+
+	parts := strings.Split(text, ",")
+	vector := make([]float32, len(parts))
+
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidVector, err)
+		}
+
+		vector[i] = float32(value)
+	}
+
+	return vector, nil
+}
+
+// convertVector converts a float array of the payload to the TO_REAL_VECTOR function call.
+// Text values in the vector text format are accepted as they are.
+func convertVector(value any) (sqlbuilder.Builder, error) {
+	var text string
+
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []float32:
+		text = formatVector(len(v), func(i int) float64 { return float64(v[i]) })
+	case []float64:
+		text = formatVector(len(v), func(i int) float64 { return v[i] })
+	case []any:
+		vector := make([]float64, len(v))
+
+		for i, elem := range v {
+			number, err := vectorElement(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			vector[i] = number
+		}
+
+		text = formatVector(len(vector), func(i int) float64 { return vector[i] })
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrInvalidVector, value)
+	}
+
+	return sqlbuilder.Buildf("TO_REAL_VECTOR(%v)", text), nil
+}
+
+// vectorElement converts an element of a decoded JSON array to a number.
+func vectorElement(elem any) (float64, error) {
+	switch v := elem.(type) {
+	case json.Number:
+		number, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrInvalidVector, err)
+		}
+
+		return number, nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("%w: element %T", ErrInvalidVector, elem)
+	}
+}
+
+// formatVector formats the vector in the text format.
+func formatVector(length int, elem func(i int) float64) string {
+	parts := make([]string, length)
+	for i := range parts {
+		parts[i] = strconv.FormatFloat(elem(i), 'g', -1, 32)
+	}
+
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntypes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/matryer/is"
+)
+
+func TestTransformRow_Vector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value any
+		want  []float32
+	}{
+		{
+			name:  "binary",
+			value: []byte{2, 0, 0, 0, 0, 0, 0x80, 0x3f, 0, 0, 0, 0xc0},
+			want:  []float32{1, -2},
+		},
+		{
+			name:  "text",
+			value: []byte("[0.5, 1.5]"),
+			want:  []float32{0.5, 1.5},
+		},
+		{
+			name:  "empty",
+			value: "[]",
+			want:  []float32{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			is := is.New(t)
+
+			got, err := TransformRow(context.Background(),
+				map[string]any{"EMBEDDING": tt.value}, map[string]string{"EMBEDDING": realVectorType}, TransformOptions{})
+			is.NoErr(err)
+			is.Equal(got["EMBEDDING"], tt.want)
+		})
+	}
+
+	t.Run("invalid binary", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		_, err := TransformRow(context.Background(),
+			map[string]any{"EMBEDDING": []byte{3, 0, 0, 0, 0}}, map[string]string{"EMBEDDING": realVectorType},
+			TransformOptions{})
+		is.True(errors.Is(err, ErrInvalidVector))
+	})
+}
+
+func TestConvertStructuredData_Vector(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	got, err := ConvertStructuredData(context.Background(), map[string]string{"EMBEDDING": realVectorType},
+		opencdc.StructuredData{"embedding": []any{json.Number("0.25"), float64(1), json.Number("-3")}}, ConvertOptions{})
+	is.NoErr(err)
+
+	sb := sqlbuilder.NewInsertBuilder()
+	sb.InsertInto("DOCS")
+	sb.Cols("embedding")
+	sb.Values(got["embedding"])
+
+	query, args := sb.Build()
+	is.Equal(query, "INSERT INTO DOCS (embedding) VALUES (TO_REAL_VECTOR(?))")
+	is.Equal(args, []any{"[0.25,1,-3]"})
+
+	_, err = ConvertStructuredData(context.Background(), map[string]string{"EMBEDDING": realVectorType},
+		opencdc.StructuredData{"embedding": []any{"a"}}, ConvertOptions{})
+	is.True(errors.Is(err, ErrInvalidVector))
+}
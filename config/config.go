@@ -16,6 +16,8 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 )
 
 const (
@@ -33,7 +35,18 @@ const (
 // shared between source and destination SAP HANA connector.
 type Config struct {
 	// Table is a name of the table that the connector should write to or read from.
-	Table string `json:"table" validate:"required"`
+	Table string `json:"table"`
+	// DefaultSchema is a schema, which is set on every connection of the pool, so unqualified table names
+	// resolve in it instead of the default schema of the user. It takes precedence over the defaultSchema option.
+	DefaultSchema string `json:"defaultSchema"`
+	// QuoteIdentifiers quotes column names in the generated SQL: the tracking table DDL, the triggers and the queries
+	// of the source, and the statements of the destination. It's needed for columns named by reserved words,
+	// e.g. ORDER, or containing special characters, e.g. /BIC/ZAMOUNT.
+	QuoteIdentifiers bool `json:"quoteIdentifiers" default:"false"`
+	// KeepaliveInterval is the interval of pinging the database in the background, so idle connections aren't
+	// dropped by firewalls. Idle connections are checked before reuse and replaced if they are broken.
+	// Zero disables it.
+	KeepaliveInterval time.Duration `json:"keepaliveInterval" default:"0"`
 
 	Auth AuthConfig
 }
@@ -56,6 +69,11 @@ type AuthConfig struct {
 	ClientCertFilePath string `json:"clientCertFilePath"`
 	// ClientKeyFilePath path to file, parameter for X509 auth.
 	ClientKeyFilePath string `json:"clientKeyFilePath"`
+	// DatabaseName is a name of the tenant database of a multitenant system, the host is the system database
+	// then. It overrides the databaseName of the DSN.
+	DatabaseName string `json:"databaseName"`
+	// Options are go-hdb DSN options for Basic, JWT and X509 auth, e.g. timeout, TLSServerName, defaultSchema.
+	Options map[string]string `json:"options"`
 }
 
 // Validate auth config parameters.
@@ -66,6 +84,10 @@ func (a *AuthConfig) Validate() error {
 			return requiredAuthParam(DSNAuthType, "DSN")
 		}
 
+		if len(a.Options) > 0 {
+			return ErrOptionsWithDSN
+		}
+
 		return nil
 	case BasicAuthType:
 		if a.Host == "" {
@@ -106,6 +128,40 @@ func (a *AuthConfig) Validate() error {
 	}
 }
 
+// WithHost returns a copy of the auth config connecting to the host, e.g. a secondary system.
+// The DSN isn't included into the error, because it contains the password.
+func (a AuthConfig) WithHost(host string) (AuthConfig, error) {
+	if a.Mechanism != DSNAuthType {
+		a.Host = host
+
+		return a, nil
+	}
+
+	dsn, err := url.Parse(a.DSN)
+	if err != nil {
+		return AuthConfig{}, ErrInvalidDSN
+	}
+
+	dsn.Host = host
+	a.DSN = dsn.String()
+
+	return a, nil
+}
+
+// HostAddress returns the host and port the auth config connects to, it's empty if the DSN is invalid.
+func (a AuthConfig) HostAddress() string {
+	if a.Mechanism != DSNAuthType {
+		return a.Host
+	}
+
+	dsn, err := url.Parse(a.DSN)
+	if err != nil {
+		return ""
+	}
+
+	return dsn.Host
+}
+
 func requiredAuthParam(auth, param string) error {
 	return fmt.Errorf("%s is required for %s auth", param, auth)
 }
@@ -18,5 +18,13 @@ import (
 	"errors"
 )
 
-// ErrInvalidAuthMechanism occurs when there's invalid mechanism config value.
-var ErrInvalidAuthMechanism = errors.New("invalid auth mechanism")
+var (
+	// ErrInvalidAuthMechanism occurs when there's invalid mechanism config value.
+	ErrInvalidAuthMechanism = errors.New("invalid auth mechanism")
+	// ErrTableRequired occurs when there's no table config value.
+	ErrTableRequired = errors.New("table is required")
+	// ErrOptionsWithDSN occurs when there are auth options for the DSN auth, which has them in the DSN.
+	ErrOptionsWithDSN = errors.New("auth options can't be used with DSN auth, add them to the DSN")
+	// ErrInvalidDSN occurs when the DSN can't be parsed to replace its host.
+	ErrInvalidDSN = errors.New("invalid DSN")
+)
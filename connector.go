@@ -24,6 +24,11 @@ import (
 
 var Connector = sdk.Connector{
 	NewSpecification: Specification,
-	NewSource:        source.New,
+	NewSource:        newSource,
 	NewDestination:   destination.New,
 }
+
+// newSource initialises a new source of the connector version.
+func newSource() sdk.Source {
+	return source.NewWithVersion(version)
+}
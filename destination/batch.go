@@ -0,0 +1,163 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// groupKind returns the kind of the write of the record, records of the same kind can be written as one group.
+// It's empty for records, which are always written one by one.
+func groupKind(record opencdc.Record) string {
+	switch record.Operation {
+	case opencdc.OperationCreate, opencdc.OperationSnapshot:
+		return "insert"
+	case opencdc.OperationUpdate:
+		return "update"
+	case opencdc.OperationDelete:
+		return "delete"
+	default:
+		return ""
+	}
+}
+
+// groupRecords splits the records with the indexes into groups of consecutive records with the same kind
+// of the write and the same table.
+func groupRecords(records []opencdc.Record, indexes []int) [][]int {
+	var groups [][]int
+
+	for i, idx := range indexes {
+		if i > 0 {
+			prev, last := records[indexes[i-1]], records[idx]
+			kind := groupKind(last)
+
+			if kind != "" && kind == groupKind(prev) && last.Metadata[metadataTable] == prev.Metadata[metadataTable] {
+				groups[len(groups)-1] = append(groups[len(groups)-1], idx)
+
+				continue
+			}
+		}
+
+		groups = append(groups, []int{idx})
+	}
+
+	return groups
+}
+
+// writeGroup writes the group of records with the indexes and returns the index of the failed record.
+// If the writer supports it, the group is written at once. Otherwise, or if the group fails,
+// its records are written one by one, so the failed record is found. Within the transaction of the batch
+// statements of the failed group can be already applied, so the group fails and the transaction is rolled back.
+func (d *Destination) writeGroup(ctx context.Context, records []opencdc.Record, group []int) (int, error) {
+	batchWriter, ok := d.writer.(BatchWriter)
+	if !ok || len(group) == 1 {
+		return d.writeEach(ctx, records, group)
+	}
+
+	batch, err := d.dropDuplicates(ctx, records, group)
+	if err != nil {
+		return group[0], err
+	}
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	err = d.retry.do(ctx, d.recordTable(batch[0]), func() error {
+		return writeBatch(ctx, batchWriter, batch)
+	})
+	if err != nil {
+		if d.writesInTx() {
+			return group[0], err
+		}
+
+		sdk.Logger(ctx).Debug().Err(err).Int("records", len(batch)).Msg("write group of records one by one")
+
+		return d.writeEach(ctx, records, group)
+	}
+
+	if d.dedup != nil {
+		for _, record := range batch {
+			if err = d.dedup.written(ctx, record); err != nil {
+				return group[0], fmt.Errorf("save record hash: %w", err)
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// writeEach writes records with the indexes one by one and returns the index of the failed record.
+func (d *Destination) writeEach(ctx context.Context, records []opencdc.Record, indexes []int) (int, error) {
+	for _, idx := range indexes {
+		if err := d.route(ctx, records[idx]); err != nil {
+			return idx, err
+		}
+	}
+
+	return 0, nil
+}
+
+// dropDuplicates returns records with the indexes, which weren't written within the dedup window.
+func (d *Destination) dropDuplicates(
+	ctx context.Context,
+	records []opencdc.Record,
+	indexes []int,
+) ([]opencdc.Record, error) {
+	batch := make([]opencdc.Record, 0, len(indexes))
+
+	for _, idx := range indexes {
+		if d.dedup != nil {
+			duplicate, err := d.dedup.isDuplicate(ctx, records[idx])
+			if err != nil {
+				return nil, fmt.Errorf("check duplicate: %w", err)
+			}
+
+			if duplicate {
+				sdk.Logger(ctx).Debug().Str("position", string(records[idx].Position)).Msg("drop duplicate record")
+
+				continue
+			}
+		}
+
+		batch = append(batch, records[idx])
+	}
+
+	return batch, nil
+}
+
+// writeBatch writes records of the same kind and table at once.
+func writeBatch(ctx context.Context, batchWriter BatchWriter, batch []opencdc.Record) error {
+	var err error
+
+	switch groupKind(batch[0]) {
+	case "insert":
+		err = batchWriter.InsertBatch(ctx, batch)
+	case "update":
+		err = batchWriter.UpdateBatch(ctx, batch)
+	case "delete":
+		err = batchWriter.DeleteBatch(ctx, batch)
+	}
+
+	if err != nil {
+		return fmt.Errorf("write %s batch: %w", groupKind(batch[0]), err)
+	}
+
+	return nil
+}
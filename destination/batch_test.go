@@ -0,0 +1,153 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/destination/mock"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+	"go.uber.org/mock/gomock"
+)
+
+// batchWriter is a writer supporting groups of records.
+type batchWriter struct {
+	*mock.MockWriter
+	*mock.MockBatchWriter
+}
+
+func TestGroupRecords(t *testing.T) {
+	t.Parallel()
+
+	orders := opencdc.Metadata{metadataTable: "ORDERS"}
+
+	records := []opencdc.Record{
+		{Operation: opencdc.OperationSnapshot},
+		{Operation: opencdc.OperationCreate},
+		{Operation: opencdc.OperationCreate, Metadata: orders},
+		{Operation: opencdc.OperationUpdate, Metadata: orders},
+		{Operation: opencdc.OperationUpdate, Metadata: orders},
+		{Operation: opencdc.OperationDelete},
+		{Operation: opencdc.OperationDelete},
+	}
+
+	got := groupRecords(records, []int{0, 1, 2, 3, 4, 5, 6})
+
+	want := [][]int{{0, 1}, {2}, {3, 4}, {5, 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupRecords() = %v, want %v", got, want)
+	}
+}
+
+func TestDestination_Write_Batch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		records := []opencdc.Record{
+			{Operation: opencdc.OperationCreate, Key: opencdc.StructuredData{"ID": 1}},
+			{Operation: opencdc.OperationCreate, Key: opencdc.StructuredData{"ID": 2}},
+			{Operation: opencdc.OperationDelete, Key: opencdc.StructuredData{"ID": 3}},
+		}
+
+		w := batchWriter{MockWriter: mock.NewMockWriter(ctrl), MockBatchWriter: mock.NewMockBatchWriter(ctrl)}
+		w.MockBatchWriter.EXPECT().InsertBatch(ctx, records[:2]).Return(nil)
+		w.MockWriter.EXPECT().Delete(ctx, records[2]).Return(nil)
+
+		d := Destination{
+			writer: w,
+		}
+
+		c, err := d.Write(ctx, records)
+		is.NoErr(err)
+
+		is.Equal(c, 3)
+	})
+
+	t.Run("failed group is written record by record", func(t *testing.T) {
+		t.Parallel()
+
+		is := is.New(t)
+
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		records := []opencdc.Record{
+			{Operation: opencdc.OperationUpdate, Key: opencdc.StructuredData{"ID": 1}},
+			{Operation: opencdc.OperationUpdate, Key: opencdc.StructuredData{"ID": 2}},
+			{Operation: opencdc.OperationUpdate, Key: opencdc.StructuredData{"ID": 3}},
+		}
+
+		w := batchWriter{MockWriter: mock.NewMockWriter(ctrl), MockBatchWriter: mock.NewMockBatchWriter(ctrl)}
+		w.MockBatchWriter.EXPECT().UpdateBatch(ctx, records).Return(errors.New("unique constraint violated"))
+		w.MockWriter.EXPECT().Update(ctx, records[0]).Return(nil)
+		w.MockWriter.EXPECT().Update(ctx, records[1]).Return(errors.New("unique constraint violated"))
+
+		d := Destination{
+			writer: w,
+		}
+
+		c, err := d.Write(ctx, records)
+		is.True(err != nil)
+
+		is.Equal(c, 1)
+	})
+}
+
+func TestDestination_Write_Batch_Transaction(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	records := []opencdc.Record{
+		{Operation: opencdc.OperationUpdate, Key: opencdc.StructuredData{"ID": 1}},
+		{Operation: opencdc.OperationUpdate, Key: opencdc.StructuredData{"ID": 2}},
+	}
+
+	// statements of the failed group can be applied in the transaction, so it isn't written record by record.
+	w := struct {
+		*mock.MockWriter
+		*mock.MockBatchWriter
+		*mock.MockTxWriter
+	}{mock.NewMockWriter(ctrl), mock.NewMockBatchWriter(ctrl), mock.NewMockTxWriter(ctrl)}
+	gomock.InOrder(
+		w.MockTxWriter.EXPECT().Begin(ctx).Return(nil),
+		w.MockBatchWriter.EXPECT().UpdateBatch(ctx, records).Return(errors.New("unique constraint violated")),
+		w.MockTxWriter.EXPECT().Rollback(ctx).Return(nil),
+	)
+
+	d := Destination{
+		writer: w,
+		config: Config{Autocommit: false},
+	}
+
+	c, err := d.Write(ctx, records)
+	is.True(err != nil)
+
+	is.Equal(c, 0)
+}
@@ -15,10 +15,184 @@
 package destination
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
 )
 
+const (
+	// lengthOverflowTruncate value of the OnLengthOverflow parameter to truncate values.
+	lengthOverflowTruncate = "truncate"
+)
+
+const (
+	// skipGeneratedAlways value of the SkipGeneratedColumns parameter to skip columns generated always.
+	skipGeneratedAlways = "always"
+	// skipGeneratedAll value of the SkipGeneratedColumns parameter to skip all generated columns.
+	skipGeneratedAll = "all"
+)
+
+const (
+	// writeModeSCD2 value of the WriteMode parameter to keep rows as SCD Type 2 versions.
+	writeModeSCD2 = "scd2"
+	// writeModeCollection value of the WriteMode parameter to write payloads into a document store collection.
+	writeModeCollection = "collection"
+	// writeModeProcedure value of the WriteMode parameter to pass records to a stored procedure.
+	writeModeProcedure = "procedure"
+	// writeModeJSON value of the WriteMode parameter to write payloads into a JSON column.
+	writeModeJSON = "json"
+)
+
+// thousandsSeparators are valid values of the ThousandsSeparator parameter.
+var thousandsSeparators = []string{",", ".", " ", "'"}
+
+// isolationLevels maps values of the WriteIsolationLevel parameter to isolation levels of transactions.
+var isolationLevels = map[string]sql.IsolationLevel{
+	"readCommitted":  sql.LevelReadCommitted,
+	"repeatableRead": sql.LevelRepeatableRead,
+	"serializable":   sql.LevelSerializable,
+}
+
+const (
+	// unknownFieldsIgnore value of the UnknownFields parameter to drop unknown fields.
+	unknownFieldsIgnore = "ignore"
+)
+
 // Config holds configurable values specific to destination.
 type Config struct {
 	config.Config
+
+	// OnLengthOverflow defines what to do with string and binary values longer than the column length.
+	// Valid values: error, truncate.
+	OnLengthOverflow string `json:"onLengthOverflow" default:"error" validate:"inclusion=error|truncate"`
+	// Defaults is a map of column names to SQL literals or expressions, e.g. CURRENT_TIMESTAMP,
+	// used on insert when the payload doesn't contain a not null column.
+	Defaults map[string]string `json:"defaults"`
+	// SkipGeneratedColumns defines which generated and identity columns are excluded from inserts and updates.
+	// Valid values: always - columns generated always, all - also identity columns generated by default, none.
+	SkipGeneratedColumns string `json:"skipGeneratedColumns" default:"always" validate:"inclusion=always|all|none"`
+	// UnknownFields defines what to do with payload fields which don't exist in the table.
+	// Valid values: error, ignore.
+	UnknownFields string `json:"unknownFields" default:"error" validate:"inclusion=error|ignore"`
+	// Writers is the number of records written in parallel. Records with the same key are written
+	// by the same writer in their order.
+	Writers int `json:"writers" default:"1" validate:"gt=0"`
+	// ExcludeFields is a list of payload fields dropped before writing, e.g. technical fields without
+	// a column in the table. Fields of flattened nested objects are named by their flattened names.
+	ExcludeFields []string `json:"excludeFields"`
+	// TruncateOnSnapshot truncates the table before the first snapshot record written to it,
+	// so the table matches the source after a fresh snapshot. A snapshot resumed after a restart
+	// doesn't truncate the table again. It requires autocommit.
+	TruncateOnSnapshot bool `json:"truncateOnSnapshot" default:"false"`
+	// DryRun validates records against types, lengths and nullability of the table columns and logs
+	// the problems instead of writing records. Hooks aren't executed and tables aren't truncated.
+	DryRun bool `json:"dryRun" default:"false"`
+	// OnConflict defines what happens to inserts of rows with existing unique keys.
+	// Valid values: error, update - the existing row is updated, ignore - the record is skipped.
+	OnConflict string `json:"onConflict" default:"error" validate:"inclusion=error|update|ignore"`
+	// WriteIsolationLevel is the isolation level of the write transactions, the database default is used if it's empty.
+	// Valid values: readCommitted, repeatableRead, serializable.
+	WriteIsolationLevel string `json:"writeIsolationLevel" validate:"inclusion=readCommitted|repeatableRead|serializable"`
+	// Autocommit commits every write on its own. If it's false, all records of a batch are written
+	// in one transaction committed after the batch, and a failed record rolls back the whole batch.
+	Autocommit bool `json:"autocommit" default:"true"`
+	// WriteTimeout is the maximum time of writing a batch of records, the batch fails with a retryable error
+	// after it, and its connection is replaced by a new one. Zero disables it.
+	WriteTimeout time.Duration `json:"writeTimeout" default:"0"`
+
+	Audit AuditConfig `json:"audit"`
+
+	Hooks HooksConfig `json:"hooks"`
+
+	Dedup DedupConfig `json:"dedup"`
+
+	Retry RetryConfig `json:"retry"`
+
+	Flatten FlattenConfig `json:"flatten"`
+
+	// WriteMode defines how changes are written. Valid values: standard - rows are inserted, updated and deleted,
+	// scd2 - every change is a new version of the row, updates and deletes close the current version,
+	// collection - payloads are written as JSON documents into the document store collection named by table,
+	// procedure - every record is passed to the stored procedure,
+	// json - payloads are written as JSON documents into the JSON column of the table, along with the key columns.
+	WriteMode string `json:"writeMode" default:"standard" validate:"inclusion=standard|scd2|collection|procedure|json"`
+
+	// JSONColumn is a name of the NCLOB or JSON column the payload is written into in the json write mode.
+	JSONColumn string `json:"jsonColumn" default:"PAYLOAD"`
+
+	SCD2 SCD2Config `json:"scd2"`
+
+	Procedure ProcedureConfig `json:"procedure"`
+
+	// VersionColumn is a name of the column with the row version. Updates change only rows with an older
+	// version, so stale out-of-order records are skipped.
+	VersionColumn string `json:"versionColumn"`
+
+	// ThousandsSeparator separates groups of thousands in strings written into decimal and float columns,
+	// e.g. 1,234.56 with the "," separator. If it's ".", the decimal separator is ",", e.g. 1.234,56.
+	// Valid values: ",", ".", " ", "'".
+	ThousandsSeparator string `json:"thousandsSeparator"`
+}
+
+// SCD2Config holds names of the version columns of the SCD Type 2 write mode.
+type SCD2Config struct {
+	// ValidFromColumn is a name of the column with the start of the version validity period.
+	ValidFromColumn string `json:"validFromColumn" default:"VALID_FROM"`
+	// ValidToColumn is a name of the column with the end of the version validity period, it's null for
+	// the current version.
+	ValidToColumn string `json:"validToColumn" default:"VALID_TO"`
+	// CurrentColumn is a name of the boolean column flagging the current version.
+	CurrentColumn string `json:"currentColumn" default:"IS_CURRENT"`
+}
+
+// ProcedureConfig holds configurable values of the procedure write mode.
+type ProcedureConfig struct {
+	// Name is a name of the stored procedure called for every record.
+	Name string `json:"name"`
+	// Parameters is a list of record fields passed to the procedure parameters in their order.
+	// Fields are taken from the payload and the key, $operation passes the record operation.
+	Parameters []string `json:"parameters"`
+}
+
+// DedupConfig holds configurable values of dropping replayed records.
+type DedupConfig struct {
+	// Window is the period within which records with the same table, operation, key and payload as a written
+	// record are dropped. Zero disables the deduplication.
+	Window time.Duration `json:"window" default:"0"`
+	// Table is a name of the table keeping hashes of written records, so they survive restarts.
+	// It's created if it doesn't exist. Hashes are kept in memory if it's empty.
+	Table string `json:"table"`
+}
+
+// RetryConfig holds configurable values of retrying writes failed by lock contention.
+type RetryConfig struct {
+	// Max is the maximum number of retries of a write failed by a lock wait timeout or a deadlock,
+	// zero disables retries.
+	Max int `json:"max" default:"3" validate:"gt=-1"`
+	// Backoff is the delay before the first retry, it's doubled for every next retry and randomized.
+	Backoff time.Duration `json:"backoff" default:"1s"`
+}
+
+// FlattenConfig holds configurable values of flattening nested objects.
+type FlattenConfig struct {
+	// Separator joins names of nested object fields, e.g. address.city is written into the ADDRESS_CITY
+	// column with the _ separator. Nested objects are written as JSON strings if it's empty.
+	Separator string `json:"separator"`
+}
+
+// HooksConfig holds SQL statements executed by the connector, statements are separated by semicolons.
+type HooksConfig struct {
+	// Open is executed once when the connector opens, before writing any records.
+	Open string `json:"open"`
+	// Teardown is executed when the connector stops, after all records are written.
+	Teardown string `json:"teardown"`
+}
+
+// AuditConfig holds names of the columns populated by the writer with the current UTC timestamp.
+type AuditConfig struct {
+	// CreatedAtColumn is a name of the column set on insert, it's kept on update.
+	CreatedAtColumn string `json:"createdAtColumn"`
+	// UpdatedAtColumn is a name of the column set on insert and update.
+	UpdatedAtColumn string `json:"updatedAtColumn"`
 }
@@ -0,0 +1,281 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// metadataTable is the metadata field with the name of the table the record is written to.
+	metadataTable = "saphana.table"
+
+	queryDedupTableExists = `SELECT COUNT(*) FROM TABLES WHERE TABLE_NAME = ? AND SCHEMA_NAME = CURRENT_SCHEMA`
+	queryCreateDedupTable = `CREATE COLUMN TABLE %s (HASH VARCHAR(64) PRIMARY KEY, WRITTEN_AT TIMESTAMP NOT NULL)`
+	queryDedupSeen        = `SELECT COUNT(*) FROM %s WHERE HASH = ? AND WRITTEN_AT > ?`
+	queryDedupAdd         = `UPSERT %s (HASH, WRITTEN_AT) VALUES (?, ?) WITH PRIMARY KEY`
+	queryDedupPrune       = `DELETE FROM %s WHERE WRITTEN_AT <= ?`
+)
+
+// dedupStore keeps hashes of written records with the time they were written.
+type dedupStore interface {
+	// writtenAfter checks whether the record with the hash was written after the time.
+	writtenAfter(ctx context.Context, hash string, after time.Time) (bool, error)
+	// add saves the hash of the written record.
+	add(ctx context.Context, hash string, writtenAt time.Time) error
+	// prune removes hashes written before the time.
+	prune(ctx context.Context, before time.Time) error
+}
+
+// deduplicator drops records with the same table, operation, key and payload as a record written within the window,
+// which protects tables without constraints against replays of at-least-once delivery.
+type deduplicator struct {
+	store  dedupStore
+	window time.Duration
+
+	// prunedAt time of the last removal of expired hashes.
+	prunedAt atomic.Int64
+	// dropped number of dropped duplicates.
+	dropped atomic.Int64
+
+	// pendingMu guards pending.
+	pendingMu sync.Mutex
+	// pending hashes of records written in the open write transaction with the time they were written,
+	// they're saved when it commits. It's nil if there's no transaction.
+	pending map[string]time.Time
+}
+
+// newDeduplicator creates a deduplicator keeping hashes in the table, or in memory if the table is empty.
+// The table is created if it doesn't exist.
+func newDeduplicator(ctx context.Context, db *sqlx.DB, table string, window time.Duration) (*deduplicator, error) {
+	d := &deduplicator{window: window}
+	d.prunedAt.Store(time.Now().UnixNano())
+
+	if table == "" {
+		d.store = &memoryDedupStore{hashes: make(map[string]time.Time)}
+
+		return d, nil
+	}
+
+	var count int
+	if err := db.GetContext(ctx, &count, queryDedupTableExists, table); err != nil {
+		return nil, fmt.Errorf("check dedup table: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(queryCreateDedupTable, table)); err != nil {
+			return nil, fmt.Errorf("create dedup table: %w", err)
+		}
+	}
+
+	d.store = &tableDedupStore{db: db, table: table}
+
+	return d, nil
+}
+
+// isDuplicate checks whether the same record was written within the window.
+func (d *deduplicator) isDuplicate(ctx context.Context, record opencdc.Record) (bool, error) {
+	now := time.Now()
+
+	// expired hashes are removed once per window.
+	if prunedAt := d.prunedAt.Load(); now.Sub(time.Unix(0, prunedAt)) >= d.window &&
+		d.prunedAt.CompareAndSwap(prunedAt, now.UnixNano()) {
+		if err := d.store.prune(ctx, now.Add(-d.window)); err != nil {
+			return false, fmt.Errorf("prune: %w", err)
+		}
+	}
+
+	hash := recordHash(record)
+
+	d.pendingMu.Lock()
+	_, duplicate := d.pending[hash]
+	d.pendingMu.Unlock()
+
+	if !duplicate {
+		var err error
+
+		duplicate, err = d.store.writtenAfter(ctx, hash, now.Add(-d.window))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if duplicate {
+		d.dropped.Add(1)
+	}
+
+	return duplicate, nil
+}
+
+// written saves the hash of the written record, or keeps it until the commit of the open transaction.
+func (d *deduplicator) written(ctx context.Context, record opencdc.Record) error {
+	hash, writtenAt := recordHash(record), time.Now()
+
+	d.pendingMu.Lock()
+	if d.pending != nil {
+		d.pending[hash] = writtenAt
+		d.pendingMu.Unlock()
+
+		return nil
+	}
+	d.pendingMu.Unlock()
+
+	return d.store.add(ctx, hash, writtenAt)
+}
+
+// begin keeps hashes of records written in the transaction until it commits,
+// so records of a rolled back transaction aren't dropped as duplicates when they're redelivered.
+func (d *deduplicator) begin() {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	d.pending = make(map[string]time.Time)
+}
+
+// commit saves hashes of records written in the committed transaction.
+func (d *deduplicator) commit(ctx context.Context) error {
+	d.pendingMu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.pendingMu.Unlock()
+
+	for hash, writtenAt := range pending {
+		if err := d.store.add(ctx, hash, writtenAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback drops hashes of records written in the rolled back transaction.
+func (d *deduplicator) rollback() {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	d.pending = nil
+}
+
+// close logs the number of dropped duplicates.
+func (d *deduplicator) close(ctx context.Context) {
+	if dropped := d.dropped.Load(); dropped > 0 {
+		sdk.Logger(ctx).Info().Int64("duplicates", dropped).Msg("duplicate records were dropped")
+	}
+}
+
+// recordHash returns the SHA-256 hash of the table, the operation, the key and the payload of the record.
+func recordHash(record opencdc.Record) string {
+	h := sha256.New()
+
+	for _, part := range [][]byte{
+		[]byte(record.Metadata[metadataTable]),
+		[]byte(record.Operation.String()),
+		dataBytes(record.Key),
+		dataBytes(record.Payload.After),
+	} {
+		// the length separates parts, so different parts can't give the same input.
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// dataBytes returns bytes of the data, it's nil for nil data.
+func dataBytes(data opencdc.Data) []byte {
+	if data == nil {
+		return nil
+	}
+
+	return data.Bytes()
+}
+
+// memoryDedupStore keeps hashes in memory, they are lost when the connector stops.
+type memoryDedupStore struct {
+	mu     sync.Mutex
+	hashes map[string]time.Time
+}
+
+func (s *memoryDedupStore) writtenAfter(_ context.Context, hash string, after time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writtenAt, ok := s.hashes[hash]
+
+	return ok && writtenAt.After(after), nil
+}
+
+func (s *memoryDedupStore) add(_ context.Context, hash string, writtenAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hashes[hash] = writtenAt
+
+	return nil
+}
+
+func (s *memoryDedupStore) prune(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, writtenAt := range s.hashes {
+		if !writtenAt.After(before) {
+			delete(s.hashes, hash)
+		}
+	}
+
+	return nil
+}
+
+// tableDedupStore keeps hashes in a table, so they survive restarts of the connector.
+type tableDedupStore struct {
+	db    *sqlx.DB
+	table string
+}
+
+func (s *tableDedupStore) writtenAfter(ctx context.Context, hash string, after time.Time) (bool, error) {
+	var count int
+	if err := s.db.GetContext(ctx, &count, fmt.Sprintf(queryDedupSeen, s.table), hash, after.UTC()); err != nil {
+		return false, fmt.Errorf("select hash: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *tableDedupStore) add(ctx context.Context, hash string, writtenAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(queryDedupAdd, s.table), hash, writtenAt.UTC()); err != nil {
+		return fmt.Errorf("upsert hash: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tableDedupStore) prune(ctx context.Context, before time.Time) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(queryDedupPrune, s.table), before.UTC()); err != nil {
+		return fmt.Errorf("delete expired hashes: %w", err)
+	}
+
+	return nil
+}
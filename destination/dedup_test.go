@@ -0,0 +1,105 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestDeduplicator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	d, err := newDeduplicator(ctx, nil, "", time.Hour)
+	if err != nil {
+		t.Fatalf("new deduplicator: %v", err)
+	}
+
+	record := opencdc.Record{
+		Operation: opencdc.OperationCreate,
+		Key:       opencdc.StructuredData{"ID": 1},
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"ID": 1, "NAME": "Bob"}},
+	}
+
+	changed := record
+	changed.Payload.After = opencdc.StructuredData{"ID": 1, "NAME": "Alice"}
+
+	otherTable := record
+	otherTable.Metadata = opencdc.Metadata{metadataTable: "ORDERS"}
+
+	if err = d.written(ctx, record); err != nil {
+		t.Fatalf("written: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		record opencdc.Record
+		want   bool
+	}{
+		{name: "replayed", record: record, want: true},
+		{name: "changed payload", record: changed, want: false},
+		{name: "other table", record: otherTable, want: false},
+	} {
+		duplicate, er := d.isDuplicate(ctx, tt.record)
+		if er != nil {
+			t.Fatalf("%s: is duplicate: %v", tt.name, er)
+		}
+
+		if duplicate != tt.want {
+			t.Errorf("%s: isDuplicate() = %t, want %t", tt.name, duplicate, tt.want)
+		}
+	}
+
+	if dropped := d.dropped.Load(); dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+}
+
+func TestMemoryDedupStore_Window(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &memoryDedupStore{hashes: make(map[string]time.Time)}
+	now := time.Now()
+
+	if err := store.add(ctx, "old", now.Add(-2*time.Minute)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if err := store.add(ctx, "new", now); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if written, _ := store.writtenAfter(ctx, "old", now.Add(-time.Minute)); written {
+		t.Error("hash written before the window is a duplicate")
+	}
+
+	if err := store.prune(ctx, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	if _, ok := store.hashes["old"]; ok {
+		t.Error("expired hash isn't pruned")
+	}
+
+	if written, _ := store.writtenAfter(ctx, "new", now.Add(-time.Minute)); !written {
+		t.Error("hash written within the window isn't a duplicate")
+	}
+}
@@ -16,13 +16,21 @@ package destination
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
 
+	"github.com/conduitio-labs/conduit-connector-sap-hana/catalog"
+	hanaconfig "github.com/conduitio-labs/conduit-connector-sap-hana/config"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/destination/writer"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
 	"github.com/conduitio/conduit-commons/config"
 	"github.com/conduitio/conduit-commons/opencdc"
 	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
 )
 
 // Destination SAP HANA Connector persists records to a sap hana database.
@@ -31,6 +39,12 @@ type Destination struct {
 
 	writer Writer
 	config Config
+	// db is used by the teardown hooks, the writer closes it.
+	db *sqlx.DB
+	// dedup drops records written within the dedup window, it's nil if the deduplication is disabled.
+	dedup *deduplicator
+	// retry retries writes failed by lock contention.
+	retry lockRetrier
 }
 
 // New creates new instance of the Destination.
@@ -49,30 +63,118 @@ func (d *Destination) Configure(ctx context.Context, cfg config.Config) error {
 		return fmt.Errorf("parse config: %w", err)
 	}
 
+	switch {
+	case d.config.WriteMode == writeModeProcedure:
+		if d.config.Procedure.Name == "" {
+			return ErrProcedureRequired
+		}
+	case d.config.Table == "":
+		return hanaconfig.ErrTableRequired
+	}
+
+	if d.config.DryRun && (d.config.WriteMode == writeModeCollection || d.config.WriteMode == writeModeProcedure) {
+		return ErrDryRunWriteMode
+	}
+
+	if !d.config.Autocommit && (d.config.Writers > 1 ||
+		d.config.WriteMode == writeModeCollection || d.config.WriteMode == writeModeProcedure) {
+		return ErrManualCommit
+	}
+
+	// truncate commits the open transaction.
+	if !d.config.Autocommit && d.config.TruncateOnSnapshot {
+		return ErrTruncateManualCommit
+	}
+
+	if d.config.ThousandsSeparator != "" && !slices.Contains(thousandsSeparators, d.config.ThousandsSeparator) {
+		return fmt.Errorf("%w: %q", ErrInvalidThousandsSeparator, d.config.ThousandsSeparator)
+	}
+
 	if err := d.config.Auth.Validate(); err != nil {
 		return fmt.Errorf("validate auth config: %w", err)
 	}
 
+	// Column names are uppercase for Sap Hana database.
+	defaults := make(map[string]string, len(d.config.Defaults))
+	for column, value := range d.config.Defaults {
+		defaults[strings.ToUpper(column)] = value
+	}
+
+	d.config.Defaults = defaults
+	d.config.Audit.CreatedAtColumn = strings.ToUpper(d.config.Audit.CreatedAtColumn)
+	d.config.Audit.UpdatedAtColumn = strings.ToUpper(d.config.Audit.UpdatedAtColumn)
+	d.config.SCD2.ValidFromColumn = strings.ToUpper(d.config.SCD2.ValidFromColumn)
+	d.config.SCD2.ValidToColumn = strings.ToUpper(d.config.SCD2.ValidToColumn)
+	d.config.SCD2.CurrentColumn = strings.ToUpper(d.config.SCD2.CurrentColumn)
+	d.config.VersionColumn = strings.ToUpper(d.config.VersionColumn)
+	d.config.Dedup.Table = strings.ToUpper(d.config.Dedup.Table)
+
 	return nil
 }
 
 // Open makes sure everything is prepared to receive records.
 func (d *Destination) Open(ctx context.Context) error {
-	db, err := helper.ConnectToDB(d.config.Auth)
+	db, err := helper.ConnectToDB(d.config.Auth, helper.WithDefaultSchema(d.config.DefaultSchema),
+		helper.WithKeepalive(d.config.KeepaliveInterval))
 	if err != nil {
 		return fmt.Errorf("connect to db: %w", err)
 	}
 
 	if err = db.Ping(); err != nil {
+		db.Close() //nolint:errcheck // the ping error is more relevant
+
+		return fmt.Errorf("ping db: %w", helper.RedactError(err, d.config.Auth))
+	}
+
+	d.db = db
+	d.retry.max = d.config.Retry.Max
+	d.retry.backoff = d.config.Retry.Backoff
+
+	if d.config.DryRun {
+		return d.openDryRun(ctx, db)
+	}
+
+	if err = d.pinTemporaryTable(ctx, db); err != nil {
+		return err
+	}
+
+	if err = runHooks(ctx, db, d.config.Hooks.Open); err != nil {
+		return fmt.Errorf("run open hooks: %w", err)
+	}
+
+	// the hooks can create or alter the table.
+	if len(d.config.Hooks.Open) > 0 {
+		d.catalog().Invalidate(d.config.Table)
+	}
+
+	if d.config.Dedup.Window > 0 {
+		d.dedup, err = newDeduplicator(ctx, db, d.config.Dedup.Table, d.config.Dedup.Window)
 		if err != nil {
-			return fmt.Errorf("ping db: %w", err)
+			return fmt.Errorf("new deduplicator: %w", err)
 		}
 	}
 
-	d.writer, err = writer.New(ctx, writer.Params{
-		DB:    db,
-		Table: d.config.Table,
-	})
+	// collections don't have columns, so options of tables don't apply to them.
+	if d.config.WriteMode == writeModeCollection {
+		d.writer = writer.NewCollection(writer.CollectionParams{
+			DB:         db,
+			Collection: d.config.Table,
+		})
+
+		return nil
+	}
+
+	if d.config.WriteMode == writeModeProcedure {
+		d.writer = writer.NewProcedure(writer.ProcedureParams{
+			DB:         db,
+			Procedure:  d.config.Procedure.Name,
+			Parameters: d.config.Procedure.Parameters,
+		})
+
+		return nil
+	}
+
+	d.writer, err = writer.New(ctx, d.writerParams(db))
 	if err != nil {
 		return fmt.Errorf("new writer: %w", err)
 	}
@@ -80,31 +182,338 @@ func (d *Destination) Open(ctx context.Context) error {
 	return nil
 }
 
+// openDryRun creates the writer which validates records instead of writing them.
+// Statements changing the database, like hooks, aren't executed.
+func (d *Destination) openDryRun(ctx context.Context, db *sqlx.DB) error {
+	sdk.Logger(ctx).Info().Msg("dry run, records are validated but not written")
+
+	// the writer never truncates tables in the dry run.
+	params := d.writerParams(db)
+	params.TruncateOnSnapshot = false
+
+	w, err := writer.New(ctx, params)
+	if err != nil {
+		return fmt.Errorf("new writer: %w", err)
+	}
+
+	d.writer = writer.NewDryRun(w)
+
+	return nil
+}
+
+// writerParams returns params of the table writer.
+func (d *Destination) writerParams(db *sqlx.DB) writer.Params {
+	return writer.Params{
+		DB:                       db,
+		Table:                    d.config.Table,
+		TruncateOnLengthOverflow: d.config.OnLengthOverflow == lengthOverflowTruncate,
+		Defaults:                 d.config.Defaults,
+		SkipGeneratedAlways: d.config.SkipGeneratedColumns == skipGeneratedAlways ||
+			d.config.SkipGeneratedColumns == skipGeneratedAll,
+		SkipGeneratedByDefault: d.config.SkipGeneratedColumns == skipGeneratedAll,
+		IgnoreUnknownFields:    d.config.UnknownFields == unknownFieldsIgnore,
+		CreatedAtColumn:        d.config.Audit.CreatedAtColumn,
+		UpdatedAtColumn:        d.config.Audit.UpdatedAtColumn,
+		TruncateOnSnapshot:     d.config.TruncateOnSnapshot,
+		SCD2:                   d.config.WriteMode == writeModeSCD2,
+		ValidFromColumn:        d.config.SCD2.ValidFromColumn,
+		ValidToColumn:          d.config.SCD2.ValidToColumn,
+		CurrentColumn:          d.config.SCD2.CurrentColumn,
+		VersionColumn:          d.config.VersionColumn,
+		FlattenSeparator:       d.config.Flatten.Separator,
+		ExcludeFields:          d.config.ExcludeFields,
+		JSONColumn:             d.jsonColumn(),
+		OnConflict:             d.config.OnConflict,
+		QuoteIdentifiers:       d.config.QuoteIdentifiers,
+		IsolationLevel:         isolationLevels[d.config.WriteIsolationLevel],
+		ThousandsSeparator:     d.config.ThousandsSeparator,
+		Catalog:                d.catalog(),
+	}
+}
+
+// catalog returns the cache of the table metadata shared with other connectors of the database.
+func (d *Destination) catalog() *catalog.Catalog {
+	return catalog.Shared(catalog.Key(d.config.Auth, d.config.DefaultSchema))
+}
+
+// pinTemporaryTable keeps the writer and the hooks on a single session, if the table is a global temporary table,
+// as its rows are visible only in the session which has written them.
+func (d *Destination) pinTemporaryTable(ctx context.Context, db *sqlx.DB) error {
+	// collections and procedures don't write into the table.
+	if d.config.WriteMode == writeModeCollection || d.config.WriteMode == writeModeProcedure {
+		return nil
+	}
+
+	temporary, err := isTemporaryTable(ctx, db, d.config.Table)
+	if err != nil {
+		return fmt.Errorf("check temporary table: %w", err)
+	}
+
+	if !temporary {
+		return nil
+	}
+
+	// the write transaction would hold the only connection, which other statements wait for.
+	if !d.config.Autocommit {
+		return ErrTemporaryTableManualCommit
+	}
+
+	pinSession(db)
+
+	sdk.Logger(ctx).Info().Str("table", d.config.Table).Msg("table is temporary, writing in a single session")
+
+	return nil
+}
+
+// jsonColumn returns the column the payload is written into, it's empty unless the json write mode is set.
+func (d *Destination) jsonColumn() string {
+	if d.config.WriteMode != writeModeJSON {
+		return ""
+	}
+
+	return d.config.JSONColumn
+}
+
 // Write writes a record into a Destination.
+// Consecutive records of the same operation and table are written as one group, if the writer supports it.
+// If the records aren't written within the write timeout, the write fails with a retryable error.
 func (d *Destination) Write(ctx context.Context, records []opencdc.Record) (int, error) {
+	if d.config.WriteTimeout <= 0 {
+		return d.write(ctx, records)
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, d.config.WriteTimeout)
+	defer cancel()
+
+	n, err := d.write(writeCtx, records)
+
+	// the driver discards the connection of the canceled statement, so the next write gets a new one.
+	if err != nil && ctx.Err() == nil && errors.Is(writeCtx.Err(), context.DeadlineExceeded) {
+		return n, fmt.Errorf("%w: %w: %w", writer.ErrRetryable, ErrWriteTimeout, err)
+	}
+
+	return n, err
+}
+
+// write writes records in parallel, in one transaction, or one group after another, according to the config.
+func (d *Destination) write(ctx context.Context, records []opencdc.Record) (int, error) {
+	if d.config.Writers > 1 {
+		return d.writeParallel(ctx, records)
+	}
+
+	if d.writesInTx() {
+		return d.writeInTx(ctx, d.writer.(TxWriter), records)
+	}
+
+	return d.writeSequential(ctx, records)
+}
+
+// writesInTx checks whether records of a batch are written in one transaction.
+func (d *Destination) writesInTx() bool {
+	_, ok := d.writer.(TxWriter)
+
+	return ok && !d.config.Autocommit && d.config.Writers <= 1
+}
+
+// writeInTx writes records in one transaction. If a write fails, the transaction is rolled back,
+// so none of the records are written.
+func (d *Destination) writeInTx(ctx context.Context, txWriter TxWriter, records []opencdc.Record) (int, error) {
+	if err := txWriter.Begin(ctx); err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+
+	if d.dedup != nil {
+		d.dedup.begin()
+	}
+
+	if _, err := d.writeSequential(ctx, records); err != nil {
+		if er := txWriter.Rollback(ctx); er != nil {
+			sdk.Logger(ctx).Error().Err(er).Msg("failed to rollback the write transaction")
+		}
+
+		if d.dedup != nil {
+			d.dedup.rollback()
+		}
+
+		return 0, err
+	}
+
+	if err := txWriter.Commit(ctx); err != nil {
+		if d.dedup != nil {
+			d.dedup.rollback()
+		}
+
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	// the records are committed, so they aren't redelivered because of hashes which aren't saved.
+	if d.dedup != nil {
+		if err := d.dedup.commit(ctx); err != nil {
+			sdk.Logger(ctx).Warn().Err(err).Msg("failed to save hashes of written records")
+		}
+	}
+
+	return len(records), nil
+}
+
+// writeSequential writes groups of records one after another.
+func (d *Destination) writeSequential(ctx context.Context, records []opencdc.Record) (int, error) {
+	indexes := make([]int, len(records))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	for _, group := range groupRecords(records, indexes) {
+		if idx, err := d.writeGroup(ctx, records, group); err != nil {
+			return idx, err
+		}
+	}
+
+	return len(records), nil
+}
+
+// writeParallel partitions records by their keys and writes partitions in parallel,
+// so records with the same key are written in their order.
+// If a write fails, the index of the first record which isn't written by any partition is returned,
+// with the error of the failed write, though records after it from other partitions can be already written.
+func (d *Destination) writeParallel(ctx context.Context, records []opencdc.Record) (int, error) {
+	partitions := make([][]int, d.config.Writers)
 	for i, record := range records {
-		err := sdk.Util.Destination.Route(ctx, record,
+		p := partition(record.Key, len(partitions))
+		partitions[p] = append(partitions[p], i)
+	}
+
+	// other writers stop as soon as one of them fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failed   = len(records)
+		writeErr error
+	)
+
+	// stop records the first index the partition hasn't written. The error of the first failure is kept,
+	// since writes of other partitions fail only because they are cancelled after it.
+	stop := func(idx int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		failed = min(failed, idx)
+		if writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	for _, indexes := range partitions {
+		if len(indexes) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(indexes []int) {
+			defer wg.Done()
+
+			for _, group := range groupRecords(records, indexes) {
+				// records of the group aren't written, so they can't be reported as written.
+				if ctx.Err() != nil {
+					stop(group[0], ctx.Err())
+
+					return
+				}
+
+				if idx, err := d.writeGroup(ctx, records, group); err != nil {
+					stop(idx, err)
+					cancel()
+
+					return
+				}
+			}
+		}(indexes)
+	}
+
+	wg.Wait()
+
+	if writeErr != nil {
+		return failed, writeErr
+	}
+
+	return len(records), nil
+}
+
+// route writes the record according to its operation.
+func (d *Destination) route(ctx context.Context, record opencdc.Record) error {
+	if d.dedup != nil {
+		duplicate, err := d.dedup.isDuplicate(ctx, record)
+		if err != nil {
+			return fmt.Errorf("check duplicate: %w", err)
+		}
+
+		if duplicate {
+			sdk.Logger(ctx).Debug().Str("position", string(record.Position)).Msg("drop duplicate record")
+
+			return nil
+		}
+	}
+
+	err := d.retry.do(ctx, d.recordTable(record), func() error {
+		return sdk.Util.Destination.Route(ctx, record,
 			d.writer.Insert,
 			d.writer.Update,
 			d.writer.Delete,
 			d.writer.Insert,
 		)
-		if err != nil {
-			return i, fmt.Errorf("route %s: %w", record.Operation.String(), err)
+	})
+	if err != nil {
+		return fmt.Errorf("route %s: %w", record.Operation.String(), err)
+	}
+
+	if d.dedup != nil {
+		if err = d.dedup.written(ctx, record); err != nil {
+			return fmt.Errorf("save record hash: %w", err)
 		}
 	}
 
-	return len(records), nil
+	return nil
+}
+
+// partition returns the index of the partition of the record key.
+func partition(key opencdc.Data, partitions int) int {
+	if key == nil {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write(key.Bytes()) //nolint:errcheck // the hash never returns an error
+
+	return int(h.Sum32() % uint32(partitions)) //nolint:gosec // the number of partitions is positive
 }
 
 // Teardown gracefully closes connections.
 func (d *Destination) Teardown(ctx context.Context) error {
+	var errs []error
+
+	// the writer is closed even if the hooks fail.
+	if d.db != nil && !d.config.DryRun {
+		if err := runHooks(ctx, d.db, d.config.Hooks.Teardown); err != nil {
+			errs = append(errs, fmt.Errorf("run teardown hooks: %w", err))
+		}
+	}
+
+	if d.dedup != nil {
+		d.dedup.close(ctx)
+	}
+
+	d.retry.report(ctx)
+
 	if d.writer != nil {
 		err := d.writer.Close(ctx)
 		if err != nil {
-			return fmt.Errorf("destination teardown : %w", err)
+			errs = append(errs, fmt.Errorf("destination teardown : %w", err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
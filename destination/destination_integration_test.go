@@ -51,6 +51,12 @@ const (
 			dec_5 DECIMAL,
 			dec_6 DECIMAL
 		)
+`
+	queryCreateTemporaryTable = `
+		CREATE GLOBAL TEMPORARY TABLE %s(
+			id INT NOT NULL PRIMARY KEY,
+			cl_varchar VARCHAR(40)
+		) ON COMMIT PRESERVE ROWS
 `
 	queryDropTable = `
   		 DROP TABLE %s;
@@ -619,6 +625,75 @@ func TestIntegrationDestination_Decimal_Transformation(t *testing.T) {
 	}
 }
 
+func TestIntegrationDestination_Write_TemporaryTable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tableName := randomIdentifier(t)
+
+	cfg, err := prepareConfigMap(tableName)
+	if err != nil {
+		t.Log(err)
+		t.Skip()
+	}
+
+	db, err := sqlx.Open(driverName, cfg[dsnKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = db.ExecContext(ctx, fmt.Sprintf(queryCreateTemporaryTable, tableName)); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := &Destination{}
+
+	t.Cleanup(func() {
+		// the table can't be dropped while the session of the destination uses it.
+		if err = dest.Teardown(ctx); err != nil {
+			t.Error(err)
+		}
+
+		if _, err = db.ExecContext(ctx, fmt.Sprintf(queryDropTable, tableName)); err != nil {
+			t.Error(err)
+		}
+
+		db.Close()
+	})
+
+	if err = dest.Configure(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = dest.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]opencdc.Record, 0, 2)
+	for id := 1; id <= 2; id++ {
+		records = append(records, opencdc.Record{
+			Payload:   opencdc.Change{After: opencdc.StructuredData{"id": id, "cl_varchar": "staged"}},
+			Operation: opencdc.OperationSnapshot,
+			Key:       opencdc.StructuredData{"id": id},
+		})
+	}
+
+	if _, err = dest.Write(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+
+	// rows of the temporary table are visible only in the session of the destination.
+	var count int
+	if err = dest.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
+
 func prepareConfigMap(table string) (map[string]string, error) {
 	dsn := os.Getenv("SAP_HANA_DSN")
 
@@ -8,19 +8,66 @@ import (
 )
 
 const (
+	ConfigAuditCreatedAtColumn   = "audit.createdAtColumn"
+	ConfigAuditUpdatedAtColumn   = "audit.updatedAtColumn"
 	ConfigAuthClientCertFilePath = "auth.clientCertFilePath"
 	ConfigAuthClientKeyFilePath  = "auth.clientKeyFilePath"
+	ConfigAuthDatabaseName       = "auth.databaseName"
 	ConfigAuthDsn                = "auth.dsn"
 	ConfigAuthHost               = "auth.host"
 	ConfigAuthMechanism          = "auth.mechanism"
+	ConfigAuthOptions            = "auth.options.*"
 	ConfigAuthPassword           = "auth.password"
 	ConfigAuthToken              = "auth.token"
 	ConfigAuthUsername           = "auth.username"
+	ConfigAutocommit             = "autocommit"
+	ConfigDedupTable             = "dedup.table"
+	ConfigDedupWindow            = "dedup.window"
+	ConfigDefaultSchema          = "defaultSchema"
+	ConfigDefaults               = "defaults.*"
+	ConfigDryRun                 = "dryRun"
+	ConfigExcludeFields          = "excludeFields"
+	ConfigFlattenSeparator       = "flatten.separator"
+	ConfigHooksOpen              = "hooks.open"
+	ConfigHooksTeardown          = "hooks.teardown"
+	ConfigJsonColumn             = "jsonColumn"
+	ConfigKeepaliveInterval      = "keepaliveInterval"
+	ConfigOnConflict             = "onConflict"
+	ConfigOnLengthOverflow       = "onLengthOverflow"
+	ConfigProcedureName          = "procedure.name"
+	ConfigProcedureParameters    = "procedure.parameters"
+	ConfigQuoteIdentifiers       = "quoteIdentifiers"
+	ConfigRetryBackoff           = "retry.backoff"
+	ConfigRetryMax               = "retry.max"
+	ConfigScd2CurrentColumn      = "scd2.currentColumn"
+	ConfigScd2ValidFromColumn    = "scd2.validFromColumn"
+	ConfigScd2ValidToColumn      = "scd2.validToColumn"
+	ConfigSkipGeneratedColumns   = "skipGeneratedColumns"
 	ConfigTable                  = "table"
+	ConfigThousandsSeparator     = "thousandsSeparator"
+	ConfigTruncateOnSnapshot     = "truncateOnSnapshot"
+	ConfigUnknownFields          = "unknownFields"
+	ConfigVersionColumn          = "versionColumn"
+	ConfigWriteIsolationLevel    = "writeIsolationLevel"
+	ConfigWriteMode              = "writeMode"
+	ConfigWriteTimeout           = "writeTimeout"
+	ConfigWriters                = "writers"
 )
 
 func (Config) Parameters() map[string]config.Parameter {
 	return map[string]config.Parameter{
+		ConfigAuditCreatedAtColumn: {
+			Default:     "",
+			Description: "CreatedAtColumn is a name of the column set on insert, it's kept on update.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigAuditUpdatedAtColumn: {
+			Default:     "",
+			Description: "UpdatedAtColumn is a name of the column set on insert and update.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigAuthClientCertFilePath: {
 			Default:     "",
 			Description: "ClientCertFilePath path to file, parameter for X509 auth.",
@@ -33,6 +80,12 @@ func (Config) Parameters() map[string]config.Parameter {
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
+		ConfigAuthDatabaseName: {
+			Default:     "",
+			Description: "DatabaseName is a name of the tenant database of a multitenant system, the host is the system database\nthen. It overrides the databaseName of the DSN.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigAuthDsn: {
 			Default:     "",
 			Description: "DSN connection to SAP HANA database.",
@@ -53,6 +106,12 @@ func (Config) Parameters() map[string]config.Parameter {
 				config.ValidationInclusion{List: []string{"DSN", "Basic", "JWT", "X509"}},
 			},
 		},
+		ConfigAuthOptions: {
+			Default:     "",
+			Description: "Options are go-hdb DSN options for Basic, JWT and X509 auth, e.g. timeout, TLSServerName, defaultSchema.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigAuthPassword: {
 			Default:     "",
 			Description: "Password parameter for basic auth.",
@@ -71,12 +130,212 @@ func (Config) Parameters() map[string]config.Parameter {
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
+		ConfigAutocommit: {
+			Default:     "true",
+			Description: "Autocommit commits every write on its own. If it's false, all records of a batch are written\nin one transaction committed after the batch, and a failed record rolls back the whole batch.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigDedupTable: {
+			Default:     "",
+			Description: "Table is a name of the table keeping hashes of written records, so they survive restarts.\nIt's created if it doesn't exist. Hashes are kept in memory if it's empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigDedupWindow: {
+			Default:     "0",
+			Description: "Window is the period within which records with the same table, operation, key and payload as a written\nrecord are dropped. Zero disables the deduplication.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigDefaultSchema: {
+			Default:     "",
+			Description: "DefaultSchema is a schema, which is set on every connection of the pool, so unqualified table names\nresolve in it instead of the default schema of the user. It takes precedence over the defaultSchema option.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigDefaults: {
+			Default:     "",
+			Description: "Defaults is a map of column names to SQL literals or expressions, e.g. CURRENT_TIMESTAMP,\nused on insert when the payload doesn't contain a not null column.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigDryRun: {
+			Default:     "false",
+			Description: "DryRun validates records against types, lengths and nullability of the table columns and logs\nthe problems instead of writing records. Hooks aren't executed and tables aren't truncated.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigExcludeFields: {
+			Default:     "",
+			Description: "ExcludeFields is a list of payload fields dropped before writing, e.g. technical fields without\na column in the table. Fields of flattened nested objects are named by their flattened names.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigFlattenSeparator: {
+			Default:     "",
+			Description: "Separator joins names of nested object fields, e.g. address.city is written into the ADDRESS_CITY\ncolumn with the _ separator. Nested objects are written as JSON strings if it's empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHooksOpen: {
+			Default:     "",
+			Description: "Open is executed once when the connector opens, before writing any records.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHooksTeardown: {
+			Default:     "",
+			Description: "Teardown is executed when the connector stops, after all records are written.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigJsonColumn: {
+			Default:     "PAYLOAD",
+			Description: "JSONColumn is a name of the NCLOB or JSON column the payload is written into in the json write mode.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigKeepaliveInterval: {
+			Default:     "0",
+			Description: "KeepaliveInterval is the interval of pinging the database in the background, so idle connections aren't\ndropped by firewalls. Idle connections are checked before reuse and replaced if they are broken.\nZero disables it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigOnConflict: {
+			Default:     "error",
+			Description: "OnConflict defines what happens to inserts of rows with existing unique keys.\nValid values: error, update - the existing row is updated, ignore - the record is skipped.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"error", "update", "ignore"}},
+			},
+		},
+		ConfigOnLengthOverflow: {
+			Default:     "error",
+			Description: "OnLengthOverflow defines what to do with string and binary values longer than the column length.\nValid values: error, truncate.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"error", "truncate"}},
+			},
+		},
+		ConfigProcedureName: {
+			Default:     "",
+			Description: "Name is a name of the stored procedure called for every record.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigProcedureParameters: {
+			Default:     "",
+			Description: "Parameters is a list of record fields passed to the procedure parameters in their order.\nFields are taken from the payload and the key, $operation passes the record operation.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigQuoteIdentifiers: {
+			Default:     "false",
+			Description: "QuoteIdentifiers quotes column names in the generated SQL: the tracking table DDL, the triggers and the queries\nof the source, and the statements of the destination. It's needed for columns named by reserved words,\ne.g. ORDER, or containing special characters, e.g. /BIC/ZAMOUNT.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigRetryBackoff: {
+			Default:     "1s",
+			Description: "Backoff is the delay before the first retry, it's doubled for every next retry and randomized.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigRetryMax: {
+			Default:     "3",
+			Description: "Max is the maximum number of retries of a write failed by a lock wait timeout or a deadlock,\nzero disables retries.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		ConfigScd2CurrentColumn: {
+			Default:     "IS_CURRENT",
+			Description: "CurrentColumn is a name of the boolean column flagging the current version.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigScd2ValidFromColumn: {
+			Default:     "VALID_FROM",
+			Description: "ValidFromColumn is a name of the column with the start of the version validity period.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigScd2ValidToColumn: {
+			Default:     "VALID_TO",
+			Description: "ValidToColumn is a name of the column with the end of the version validity period, it's null for\nthe current version.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSkipGeneratedColumns: {
+			Default:     "always",
+			Description: "SkipGeneratedColumns defines which generated and identity columns are excluded from inserts and updates.\nValid values: always - columns generated always, all - also identity columns generated by default, none.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"always", "all", "none"}},
+			},
+		},
 		ConfigTable: {
 			Default:     "",
 			Description: "Table is a name of the table that the connector should write to or read from.",
 			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigThousandsSeparator: {
+			Default:     "",
+			Description: "ThousandsSeparator separates groups of thousands in strings written into decimal and float columns,\ne.g. 1,234.56 with the \",\" separator. If it's \".\", the decimal separator is \",\", e.g. 1.234,56.\nValid values: \",\", \".\", \" \", \"'\".",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigTruncateOnSnapshot: {
+			Default:     "false",
+			Description: "TruncateOnSnapshot truncates the table before the first snapshot record written to it,\nso the table matches the source after a fresh snapshot. A snapshot resumed after a restart\ndoesn't truncate the table again. It requires autocommit.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigUnknownFields: {
+			Default:     "error",
+			Description: "UnknownFields defines what to do with payload fields which don't exist in the table.\nValid values: error, ignore.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"error", "ignore"}},
+			},
+		},
+		ConfigVersionColumn: {
+			Default:     "",
+			Description: "VersionColumn is a name of the column with the row version. Updates change only rows with an older\nversion, so stale out-of-order records are skipped.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigWriteIsolationLevel: {
+			Default:     "",
+			Description: "WriteIsolationLevel is the isolation level of the write transactions, the database default is used if it's empty.\nValid values: readCommitted, repeatableRead, serializable.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"readCommitted", "repeatableRead", "serializable"}},
+			},
+		},
+		ConfigWriteMode: {
+			Default:     "standard",
+			Description: "WriteMode defines how changes are written. Valid values: standard - rows are inserted, updated and deleted,\nscd2 - every change is a new version of the row, updates and deletes close the current version,\ncollection - payloads are written as JSON documents into the document store collection named by table,\nprocedure - every record is passed to the stored procedure,\njson - payloads are written as JSON documents into the JSON column of the table, along with the key columns.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"standard", "scd2", "collection", "procedure", "json"}},
+			},
+		},
+		ConfigWriteTimeout: {
+			Default:     "0",
+			Description: "WriteTimeout is the maximum time of writing a batch of records, the batch fails with a retryable error\nafter it, and its connection is replaced by a new one. Zero disables it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigWriters: {
+			Default:     "1",
+			Description: "Writers is the number of records written in parallel. Records with the same key are written\nby the same writer in their order.",
+			Type:        config.ParameterTypeInt,
 			Validations: []config.Validation{
-				config.ValidationRequired{},
+				config.ValidationGreaterThan{V: 0},
 			},
 		},
 	}
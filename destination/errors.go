@@ -0,0 +1,38 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"errors"
+)
+
+var (
+	// ErrProcedureRequired occurs when the procedure write mode is set without the procedure name.
+	ErrProcedureRequired = errors.New("procedure name is required for the procedure write mode")
+	// ErrDryRunWriteMode occurs when the dry run is set for a write mode without table columns.
+	ErrDryRunWriteMode = errors.New("dry run is supported only by the standard, scd2 and json write modes")
+	// ErrManualCommit occurs when the autocommit is disabled for parallel writers or a write mode
+	// without transactions.
+	ErrManualCommit = errors.New("disabled autocommit is supported only by a single writer " +
+		"of the standard, scd2 and json write modes")
+	// ErrInvalidThousandsSeparator occurs when the thousands separator isn't one of the supported ones.
+	ErrInvalidThousandsSeparator = errors.New(`thousands separator must be one of ",", ".", " " or "'"`)
+	// ErrTruncateManualCommit occurs when the autocommit is disabled for truncating tables on snapshot.
+	ErrTruncateManualCommit = errors.New("truncateOnSnapshot requires autocommit")
+	// ErrTemporaryTableManualCommit occurs when the autocommit is disabled for a temporary table.
+	ErrTemporaryTableManualCommit = errors.New("temporary tables require autocommit")
+	// ErrWriteTimeout occurs when a batch of records isn't written within the write timeout.
+	ErrWriteTimeout = errors.New("write timeout exceeded")
+)
@@ -0,0 +1,75 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// runHooks executes the SQL statements in their order, it stops at the first failed statement.
+func runHooks(ctx context.Context, db *sqlx.DB, sql string) error {
+	for i, statement := range splitStatements(sql) {
+		sdk.Logger(ctx).Debug().Str("statement", statement).Msg("run sql hook")
+
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// splitStatements splits the SQL into statements by semicolons outside quoted literals and identifiers.
+// Empty statements are skipped.
+func splitStatements(sql string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			// doubled quotes inside a literal close and reopen it, which gives the same result.
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';':
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				statements = append(statements, statement)
+			}
+
+			current.Reset()
+
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
@@ -0,0 +1,56 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "empty",
+			sql:  " ; ",
+			want: nil,
+		},
+		{
+			name: "several statements",
+			sql:  "TRUNCATE TABLE STAGING; ALTER TABLE USERS DISABLE CONSTRAINT FK_ORDERS;",
+			want: []string{"TRUNCATE TABLE STAGING", "ALTER TABLE USERS DISABLE CONSTRAINT FK_ORDERS"},
+		},
+		{
+			name: "semicolons in quotes",
+			sql:  `INSERT INTO "LOG;S" VALUES ('it''s; done'); DELETE FROM T`,
+			want: []string{`INSERT INTO "LOG;S" VALUES ('it''s; done')`, "DELETE FROM T"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := splitStatements(tt.sql); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,3 +27,19 @@ type Writer interface {
 	Update(ctx context.Context, record opencdc.Record) error
 	Close(ctx context.Context) error
 }
+
+// BatchWriter is implemented by writers, which write consecutive records of the same operation and table
+// as one group. A failed group is written again record by record, so it must be written entirely or not at all.
+type BatchWriter interface {
+	DeleteBatch(ctx context.Context, records []opencdc.Record) error
+	InsertBatch(ctx context.Context, records []opencdc.Record) error
+	UpdateBatch(ctx context.Context, records []opencdc.Record) error
+}
+
+// TxWriter is implemented by writers, which can write records of a batch in one transaction.
+// Writes between Begin and Commit or Rollback are executed in the transaction.
+type TxWriter interface {
+	Begin(ctx context.Context) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
@@ -96,3 +96,135 @@ func (mr *MockWriterMockRecorder) Update(ctx, record any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWriter)(nil).Update), ctx, record)
 }
+
+// MockBatchWriter is a mock of BatchWriter interface.
+type MockBatchWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchWriterMockRecorder is the mock recorder for MockBatchWriter.
+type MockBatchWriterMockRecorder struct {
+	mock *MockBatchWriter
+}
+
+// NewMockBatchWriter creates a new mock instance.
+func NewMockBatchWriter(ctrl *gomock.Controller) *MockBatchWriter {
+	mock := &MockBatchWriter{ctrl: ctrl}
+	mock.recorder = &MockBatchWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchWriter) EXPECT() *MockBatchWriterMockRecorder {
+	return m.recorder
+}
+
+// DeleteBatch mocks base method.
+func (m *MockBatchWriter) DeleteBatch(ctx context.Context, records []opencdc.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatch", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBatch indicates an expected call of DeleteBatch.
+func (mr *MockBatchWriterMockRecorder) DeleteBatch(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockBatchWriter)(nil).DeleteBatch), ctx, records)
+}
+
+// InsertBatch mocks base method.
+func (m *MockBatchWriter) InsertBatch(ctx context.Context, records []opencdc.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBatch", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBatch indicates an expected call of InsertBatch.
+func (mr *MockBatchWriterMockRecorder) InsertBatch(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBatch", reflect.TypeOf((*MockBatchWriter)(nil).InsertBatch), ctx, records)
+}
+
+// UpdateBatch mocks base method.
+func (m *MockBatchWriter) UpdateBatch(ctx context.Context, records []opencdc.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBatch", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBatch indicates an expected call of UpdateBatch.
+func (mr *MockBatchWriterMockRecorder) UpdateBatch(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBatch", reflect.TypeOf((*MockBatchWriter)(nil).UpdateBatch), ctx, records)
+}
+
+// MockTxWriter is a mock of TxWriter interface.
+type MockTxWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockTxWriterMockRecorder is the mock recorder for MockTxWriter.
+type MockTxWriterMockRecorder struct {
+	mock *MockTxWriter
+}
+
+// NewMockTxWriter creates a new mock instance.
+func NewMockTxWriter(ctrl *gomock.Controller) *MockTxWriter {
+	mock := &MockTxWriter{ctrl: ctrl}
+	mock.recorder = &MockTxWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxWriter) EXPECT() *MockTxWriterMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockTxWriter) Begin(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockTxWriterMockRecorder) Begin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockTxWriter)(nil).Begin), ctx)
+}
+
+// Commit mocks base method.
+func (m *MockTxWriter) Commit(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockTxWriterMockRecorder) Commit(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockTxWriter)(nil).Commit), ctx)
+}
+
+// Rollback mocks base method.
+func (m *MockTxWriter) Rollback(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockTxWriterMockRecorder) Rollback(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTxWriter)(nil).Rollback), ctx)
+}
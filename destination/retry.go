@@ -0,0 +1,138 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/destination/writer"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jpillora/backoff"
+)
+
+// maxRetryDelay is the maximum delay between retries of a write failed by lock contention.
+const maxRetryDelay = 30 * time.Second
+
+// lockRetrier retries writes failed by lock contention, e.g. while batch jobs lock the table briefly,
+// with a randomized exponential backoff. It counts the contention per table, which is reported on teardown.
+type lockRetrier struct {
+	// max is the maximum number of retries of a write, zero disables retries.
+	max int
+	// backoff is the delay before the first retry.
+	backoff time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*contention
+}
+
+// contention holds counters of the lock contention of a table.
+type contention struct {
+	// retries is the number of retried writes.
+	retries int
+	// exhausted is the number of writes which failed after all retries.
+	exhausted int
+	// waited is the total delay before retries.
+	waited time.Duration
+}
+
+// do calls the write of the table, and calls it again while it fails by lock contention,
+// until the retries are exhausted.
+func (r *lockRetrier) do(ctx context.Context, table string, write func() error) error {
+	err := write()
+	if r.max == 0 || !writer.IsLockContention(err) {
+		return err
+	}
+
+	b := &backoff.Backoff{
+		Min:    r.backoff,
+		Max:    maxRetryDelay,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for attempt := 1; attempt <= r.max && writer.IsLockContention(err); attempt++ {
+		delay := b.Duration()
+
+		sdk.Logger(ctx).Warn().Err(err).Str("table", table).Dur("delay", delay).
+			Msgf("write failed by lock contention, retry %d of %d", attempt, r.max)
+
+		r.count(table, func(c *contention) {
+			c.retries++
+			c.waited += delay
+		})
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for retry: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+
+		err = write()
+	}
+
+	if writer.IsLockContention(err) {
+		r.count(table, func(c *contention) { c.exhausted++ })
+	}
+
+	return err
+}
+
+// count updates the counters of the table.
+func (r *lockRetrier) count(table string, update func(c *contention)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stats == nil {
+		r.stats = make(map[string]*contention)
+	}
+
+	if r.stats[table] == nil {
+		r.stats[table] = &contention{}
+	}
+
+	update(r.stats[table])
+}
+
+// report logs the lock contention of tables, which had any.
+func (r *lockRetrier) report(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, table := range slices.Sorted(maps.Keys(r.stats)) {
+		c := r.stats[table]
+
+		sdk.Logger(ctx).Info().
+			Str("table", table).
+			Int("retries", c.retries).
+			Int("exhausted", c.exhausted).
+			Dur("waited", c.waited).
+			Msg("lock contention")
+	}
+}
+
+// recordTable returns the name of the table the record is written to.
+func (d *Destination) recordTable(record opencdc.Record) string {
+	if table, ok := record.Metadata[metadataTable]; ok {
+		return table
+	}
+
+	return d.config.Table
+}
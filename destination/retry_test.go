@@ -0,0 +1,102 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// lockWaitTimeout is a fake sap hana lock wait timeout error.
+type lockWaitTimeout struct {
+	driver.DBError
+}
+
+func (lockWaitTimeout) Error() string { return "lock wait timeout exceeded" }
+func (lockWaitTimeout) Code() int     { return 131 }
+
+func TestLockRetrier_do(t *testing.T) {
+	t.Parallel()
+
+	errOther := errors.New("other error")
+
+	tests := []struct {
+		name      string
+		failures  []error
+		wantErr   error
+		wantCalls int
+		want      contention
+	}{
+		{
+			name:      "succeeds after lock contention",
+			failures:  []error{lockWaitTimeout{}, fmt.Errorf("exec: %w", lockWaitTimeout{})},
+			wantCalls: 3,
+			want:      contention{retries: 2},
+		},
+		{
+			name:      "retries are exhausted",
+			failures:  []error{lockWaitTimeout{}, lockWaitTimeout{}, lockWaitTimeout{}},
+			wantErr:   lockWaitTimeout{},
+			wantCalls: 3,
+			want:      contention{retries: 2, exhausted: 1},
+		},
+		{
+			name:      "other errors aren't retried",
+			failures:  []error{errOther},
+			wantErr:   errOther,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &lockRetrier{max: 2, backoff: time.Millisecond}
+
+			calls := 0
+			err := r.do(context.Background(), "ORDERS", func() error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+
+			var got contention
+			if c := r.stats["ORDERS"]; c != nil {
+				got = contention{retries: c.retries, exhausted: c.exhausted}
+			}
+
+			if got != tt.want {
+				t.Errorf("got contention %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queryIsTemporaryTable counts global temporary tables of the current schema and the base tables of synonyms
+// with the name, it's zero if the table doesn't exist yet, e.g. it's created by the open hooks.
+const queryIsTemporaryTable = `
+	SELECT 
+	  COUNT(*) 
+	FROM 
+	  TABLES 
+	WHERE 
+	  IS_TEMPORARY = 'TRUE' 
+	  AND (
+	    (SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ?) 
+	    OR (SCHEMA_NAME, TABLE_NAME) IN (
+	      SELECT OBJECT_SCHEMA, OBJECT_NAME FROM SYNONYMS 
+	      WHERE SYNONYM_NAME = ? AND SCHEMA_NAME IN (CURRENT_SCHEMA, 'PUBLIC')
+	    )
+	  )
+`
+
+// isTemporaryTable returns true if the table, or the base table of the synonym, is a global temporary table.
+func isTemporaryTable(ctx context.Context, db *sqlx.DB, table string) (bool, error) {
+	table = strings.ToUpper(table)
+
+	var count int
+	if err := db.QueryRowContext(ctx, queryIsTemporaryTable, table, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("query temporary tables: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// pinSession limits the db to a single connection, which is never closed by the pool, so all statements,
+// including the hooks, are executed in one session and see the rows of temporary tables written by each other.
+func pinSession(db *sqlx.DB) {
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+}
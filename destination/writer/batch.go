@@ -0,0 +1,254 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// maxBatchKeys limits the number of key values of a batched delete.
+const maxBatchKeys = 1000
+
+// statement is a query with arguments of one or several rows.
+// The database executes a statement with arguments of several rows as a bulk operation.
+type statement struct {
+	query string
+	args  []any
+}
+
+// appendStatement adds arguments of the row to the last statement if it has the same query,
+// so consecutive rows with the same columns are executed by one bulk statement.
+func appendStatement(statements []statement, query string, args []any) []statement {
+	if n := len(statements); n > 0 && len(args) > 0 && statements[n-1].query == query {
+		statements[n-1].args = append(statements[n-1].args, args...)
+
+		return statements
+	}
+
+	return append(statements, statement{query: query, args: args})
+}
+
+// InsertBatch inserts records of the same table.
+// Consecutive rows with the same columns are inserted by one bulk statement, all statements are executed
+// in one transaction, so either all records are written or none of them.
+func (w *Writer) InsertBatch(ctx context.Context, records []opencdc.Record) error {
+	tableName := w.getTableName(records[0].Metadata)
+
+	if err := w.trackSnapshot(ctx, tableName, records...); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		var statements []statement
+
+		for _, record := range records {
+			query, args, err := w.insertQuery(ctx, tableName, meta, record)
+			if err != nil {
+				return err
+			}
+
+			statements = appendStatement(statements, query, args)
+		}
+
+		return w.execStatements(ctx, statements)
+	})
+
+	return classify(err)
+}
+
+// UpdateBatch updates records of the same table.
+// Consecutive updates of the same columns are executed by one bulk statement in one transaction.
+// Records are updated one by one in the scd2 mode and with the version column,
+// as they need results of every update.
+func (w *Writer) UpdateBatch(ctx context.Context, records []opencdc.Record) error {
+	if w.scd2 || w.versionColumn != "" {
+		return w.each(ctx, records, w.Update)
+	}
+
+	tableName := w.getTableName(records[0].Metadata)
+
+	if err := w.trackSnapshot(ctx, tableName, records...); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		var statements []statement
+
+		for _, record := range records {
+			payload, keys, err := w.updatePayload(ctx, meta, record)
+			if err != nil {
+				return err
+			}
+
+			query, args := w.buildUpdateQuery(tableName, keys, payload, nil)
+
+			statements = appendStatement(statements, query, args)
+		}
+
+		return w.execStatements(ctx, statements)
+	})
+
+	return classify(err)
+}
+
+// DeleteBatch deletes records of the same table.
+// Records with a single key column are deleted by statements with the IN lists of keys, records with
+// composite keys are deleted by statements with OR-chains of the key column conditions.
+// Records are deleted one by one in the scd2 mode and if the records have different key columns.
+func (w *Writer) DeleteBatch(ctx context.Context, records []opencdc.Record) error {
+	if w.scd2 {
+		return w.each(ctx, records, w.Delete)
+	}
+
+	tableName := w.getTableName(records[0].Metadata)
+
+	if err := w.trackSnapshot(ctx, tableName, records...); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		columns, values, err := w.batchKeys(ctx, meta, records)
+		if err != nil {
+			return err
+		}
+
+		if columns == nil {
+			for _, record := range records {
+				if err = w.delete(ctx, tableName, meta, record); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		return w.execStatements(ctx, buildBatchDeleteStatements(w.identifier(tableName), w.identifiers(columns), values))
+	})
+
+	return classify(err)
+}
+
+// buildBatchDeleteStatements returns statements deleting rows with the key values of the columns.
+// Every statement has at most maxBatchKeys key values.
+func buildBatchDeleteStatements(table string, columns []string, values [][]any) []statement {
+	var statements []statement
+
+	for chunk := range slices.Chunk(values, max(1, maxBatchKeys/len(columns))) {
+		db := sqlbuilder.NewDeleteBuilder()
+		db.DeleteFrom(table)
+
+		if len(columns) == 1 {
+			keys := make([]any, len(chunk))
+			for i, row := range chunk {
+				keys[i] = row[0]
+			}
+
+			db.Where(db.In(columns[0], keys...))
+		} else {
+			conditions := make([]string, len(chunk))
+			for i, row := range chunk {
+				equals := make([]string, len(columns))
+				for j, column := range columns {
+					equals[j] = db.Equal(column, row[j])
+				}
+
+				conditions[i] = db.And(equals...)
+			}
+
+			db.Where(db.Or(conditions...))
+		}
+
+		query, args := db.Build()
+
+		statements = append(statements, statement{query: query, args: args})
+	}
+
+	return statements
+}
+
+// batchKeys returns the sorted key columns and key values of the records in the order of the columns,
+// if all of them have the same key columns. The columns are nil otherwise.
+func (w *Writer) batchKeys(
+	ctx context.Context,
+	meta *tableMeta,
+	records []opencdc.Record,
+) ([]string, [][]any, error) {
+	var (
+		columns []string
+		values  = make([][]any, 0, len(records))
+	)
+
+	for _, record := range records {
+		keys, err := w.recordKeys(ctx, meta, record)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		recordColumns := sortedKeys(keys)
+		if columns != nil && !slices.Equal(columns, recordColumns) {
+			return nil, nil, nil
+		}
+
+		columns = recordColumns
+
+		row := make([]any, len(columns))
+		for i, column := range columns {
+			row[i] = keys[column]
+		}
+
+		values = append(values, row)
+	}
+
+	return columns, values, nil
+}
+
+// each writes the records one by one.
+func (w *Writer) each(
+	ctx context.Context,
+	records []opencdc.Record,
+	write func(context.Context, opencdc.Record) error,
+) error {
+	for _, record := range records {
+		if err := write(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execStatements executes the statements in one transaction.
+func (w *Writer) execStatements(ctx context.Context, statements []statement) error {
+	return w.inTx(ctx, func(tx *sql.Tx) error {
+		for _, s := range statements {
+			if _, err := tx.ExecContext(ctx, s.query, s.args...); err != nil {
+				return fmt.Errorf("exec batch: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// isSnapshot checks whether the record is a snapshot record.
+func isSnapshot(record opencdc.Record) bool {
+	return record.Operation == opencdc.OperationSnapshot
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendStatement(t *testing.T) {
+	t.Parallel()
+
+	var statements []statement
+
+	statements = appendStatement(statements, "INSERT INTO T (ID) VALUES (?)", []any{1})
+	statements = appendStatement(statements, "INSERT INTO T (ID) VALUES (?)", []any{2})
+	statements = appendStatement(statements, "INSERT INTO T (ID, NAME) VALUES (?, ?)", []any{3, "c"})
+	statements = appendStatement(statements, "INSERT INTO T (ID) VALUES (?)", []any{4})
+	statements = appendStatement(statements, "INSERT INTO T (ID) VALUES (DEFAULT)", nil)
+	statements = appendStatement(statements, "INSERT INTO T (ID) VALUES (DEFAULT)", nil)
+
+	want := []statement{
+		{query: "INSERT INTO T (ID) VALUES (?)", args: []any{1, 2}},
+		{query: "INSERT INTO T (ID, NAME) VALUES (?, ?)", args: []any{3, "c"}},
+		{query: "INSERT INTO T (ID) VALUES (?)", args: []any{4}},
+		{query: "INSERT INTO T (ID) VALUES (DEFAULT)"},
+		{query: "INSERT INTO T (ID) VALUES (DEFAULT)"},
+	}
+
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("appendStatement() = %v, want %v", statements, want)
+	}
+}
+
+func TestBuildBatchDeleteStatements(t *testing.T) {
+	t.Parallel()
+
+	single := buildBatchDeleteStatements("T", []string{"ID"}, [][]any{{1}, {2}})
+
+	want := []statement{{query: "DELETE FROM T WHERE ID IN (?, ?)", args: []any{1, 2}}}
+
+	if !reflect.DeepEqual(single, want) {
+		t.Errorf("single column key statements = %v, want %v", single, want)
+	}
+
+	composite := buildBatchDeleteStatements("T", []string{"ID", "REGION"}, [][]any{{1, "EU"}, {2, "US"}})
+
+	want = []statement{{
+		query: "DELETE FROM T WHERE ((ID = ? AND REGION = ?) OR (ID = ? AND REGION = ?))",
+		args:  []any{1, "EU", 2, "US"},
+	}}
+
+	if !reflect.DeepEqual(composite, want) {
+		t.Errorf("composite key statements = %v, want %v", composite, want)
+	}
+
+	values := make([][]any, maxBatchKeys)
+	for i := range values {
+		values[i] = []any{i, "EU"}
+	}
+
+	if got := buildBatchDeleteStatements("T", []string{"ID", "REGION"}, values); len(got) != 2 {
+		t.Errorf("statements of %d composite keys = %d, want 2", len(values), len(got))
+	}
+}
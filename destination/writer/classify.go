@@ -0,0 +1,108 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// sap hana error codes of failures the writer classifies.
+const (
+	errCodeInternalRollback      = 129
+	errCodeLockWaitTimeout       = 131
+	errCodeDeadlock              = 133
+	errCodeResourceBusy          = 146
+	errCodeInvalidTableName      = 259
+	errCodeInconsistentDatatype  = 266
+	errCodeValueTooLarge         = 274
+	errCodeNotNullViolation      = 287
+	errCodeUniqueViolation       = 301
+	errCodeInvalidNumber         = 339
+	errCodeForeignKeyViolation   = 461
+	errCodeForeignKeyRestriction = 462
+)
+
+// errorClasses maps sap hana error codes to the classes of the writer errors.
+var errorClasses = map[int]error{
+	errCodeInternalRollback:      ErrRetryable,
+	errCodeLockWaitTimeout:       ErrRetryable,
+	errCodeDeadlock:              ErrRetryable,
+	errCodeResourceBusy:          ErrRetryable,
+	errCodeInvalidTableName:      ErrSchemaMismatch,
+	errCodeInvalidColumnName:     ErrSchemaMismatch,
+	errCodeInconsistentDatatype:  ErrSchemaMismatch,
+	errCodeValueTooLarge:         ErrSchemaMismatch,
+	errCodeInvalidNumber:         ErrSchemaMismatch,
+	errCodeNotNullViolation:      ErrConstraintViolation,
+	errCodeUniqueViolation:       ErrConstraintViolation,
+	errCodeForeignKeyViolation:   ErrConstraintViolation,
+	errCodeForeignKeyRestriction: ErrConstraintViolation,
+}
+
+// classify wraps the write error with its class, so callers can tell transient failures from permanent ones
+// with errors.Is. Errors of unknown classes are returned as they are.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if class := errorClass(err); class != nil && !errors.Is(err, class) {
+		return fmt.Errorf("%w: %w", class, err)
+	}
+
+	return err
+}
+
+// IsLockContention checks whether the write failed by a lock wait timeout or a deadlock, such writes are rolled back
+// and succeed when the locks are released.
+func IsLockContention(err error) bool {
+	var dbErr driver.DBError
+	if errors.As(err, &dbErr) {
+		return dbErr.Code() == errCodeLockWaitTimeout || dbErr.Code() == errCodeDeadlock
+	}
+
+	return false
+}
+
+// isUniqueViolation checks whether the write failed, because a row with the same unique key already exists.
+func isUniqueViolation(err error) bool {
+	var dbErr driver.DBError
+
+	return errors.As(err, &dbErr) && dbErr.Code() == errCodeUniqueViolation
+}
+
+// errorClass returns the class of the error or nil if it's unknown.
+func errorClass(err error) error {
+	var dbErr driver.DBError
+	if errors.As(err, &dbErr) {
+		return errorClasses[dbErr.Code()]
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, sqldriver.ErrBadConn), errors.As(err, &netErr):
+		return ErrRetryable
+	case errors.Is(err, ErrUnknownField):
+		return ErrSchemaMismatch
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "lock wait timeout", err: dbError{code: errCodeLockWaitTimeout}, want: ErrRetryable},
+		{name: "deadlock", err: fmt.Errorf("exec: %w", dbError{code: errCodeDeadlock}), want: ErrRetryable},
+		{name: "bad connection", err: fmt.Errorf("exec: %w", sqldriver.ErrBadConn), want: ErrRetryable},
+		{name: "invalid column name", err: dbError{code: errCodeInvalidColumnName}, want: ErrSchemaMismatch},
+		{name: "unknown field", err: fmt.Errorf("check: %w", ErrUnknownField), want: ErrSchemaMismatch},
+		{name: "unique violation", err: dbError{code: errCodeUniqueViolation}, want: ErrConstraintViolation},
+		{name: "not null violation", err: dbError{code: errCodeNotNullViolation}, want: ErrConstraintViolation},
+		{name: "unknown code", err: dbError{code: 1}, want: nil},
+		{name: "other error", err: ErrNoKey, want: nil},
+	}
+
+	classes := []error{ErrRetryable, ErrSchemaMismatch, ErrConstraintViolation}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := classify(tt.err)
+
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classify() = %v, the original error isn't wrapped", got)
+			}
+
+			for _, class := range classes {
+				if errors.Is(got, class) != (class == tt.want) {
+					t.Errorf("classify() = %v, want class %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if classify(nil) != nil {
+		t.Error("classify(nil) isn't nil")
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	if !isUniqueViolation(fmt.Errorf("exec insert: %w", dbError{code: errCodeUniqueViolation})) {
+		t.Error("unique violation isn't detected")
+	}
+
+	if isUniqueViolation(dbError{code: errCodeNotNullViolation}) {
+		t.Error("not null violation is detected as a unique violation")
+	}
+}
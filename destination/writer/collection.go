@@ -0,0 +1,201 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	queryInsertDocument = `INSERT INTO %s VALUES (?)`
+	queryDeleteDocument = `DELETE FROM %s WHERE %s`
+)
+
+// CollectionWriter writes whole record payloads as JSON documents into a collection of the HANA Document Store.
+type CollectionWriter struct {
+	db         *sqlx.DB
+	collection string
+}
+
+// CollectionParams is an incoming params for the NewCollection function.
+type CollectionParams struct {
+	DB         *sqlx.DB
+	Collection string
+}
+
+// NewCollection creates new instance of the CollectionWriter.
+func NewCollection(params CollectionParams) *CollectionWriter {
+	return &CollectionWriter{
+		db:         params.DB,
+		collection: params.Collection,
+	}
+}
+
+// Close closes the underlying db connection.
+func (w *CollectionWriter) Close(context.Context) error {
+	if err := w.db.Close(); err != nil {
+		return fmt.Errorf("close db: %w", err)
+	}
+
+	return nil
+}
+
+// Insert inserts the payload as a new document.
+func (w *CollectionWriter) Insert(ctx context.Context, record opencdc.Record) error {
+	document, err := w.document(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.db.ExecContext(ctx, fmt.Sprintf(queryInsertDocument, w.collectionName(record.Metadata)), document)
+	if err != nil {
+		return classify(fmt.Errorf("exec insert: %w", err))
+	}
+
+	return nil
+}
+
+// Update replaces documents matching the key with the payload in a transaction.
+func (w *CollectionWriter) Update(ctx context.Context, record opencdc.Record) error {
+	document, err := w.document(record)
+	if err != nil {
+		return err
+	}
+
+	where, args, err := w.keyCondition(record.Key)
+	if err != nil {
+		return err
+	}
+
+	collection := w.collectionName(record.Metadata)
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(queryDeleteDocument, collection, where), args...); err != nil {
+		return classify(fmt.Errorf("exec delete: %w", err))
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(queryInsertDocument, collection), document); err != nil {
+		return classify(fmt.Errorf("exec insert: %w", err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes documents matching the key.
+func (w *CollectionWriter) Delete(ctx context.Context, record opencdc.Record) error {
+	where, args, err := w.keyCondition(record.Key)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(queryDeleteDocument, w.collectionName(record.Metadata), where)
+
+	if _, err = w.db.ExecContext(ctx, query, args...); err != nil {
+		return classify(fmt.Errorf("exec delete: %w", err))
+	}
+
+	return nil
+}
+
+// collectionName returns either the records metadata value for table or the configured collection.
+func (w *CollectionWriter) collectionName(metadata map[string]string) string {
+	if collection, ok := metadata[metadataTable]; ok {
+		return collection
+	}
+
+	return w.collection
+}
+
+// document returns the payload as a JSON document.
+func (w *CollectionWriter) document(record opencdc.Record) (string, error) {
+	if record.Payload.After == nil || len(record.Payload.After.Bytes()) == 0 {
+		return "", ErrNoPayload
+	}
+
+	document := record.Payload.After.Bytes()
+	if !json.Valid(document) {
+		return "", ErrInvalidDocument
+	}
+
+	return string(document), nil
+}
+
+// keyCondition returns the condition matching document fields with the key fields.
+// Field names are quoted, because document fields are case-sensitive.
+func (w *CollectionWriter) keyCondition(key opencdc.Data) (string, []any, error) {
+	if key == nil || len(key.Bytes()) == 0 {
+		return "", nil, ErrNoKey
+	}
+
+	fields := make(map[string]any)
+
+	// use json.Number to keep precision of big integers.
+	decoder := json.NewDecoder(bytes.NewReader(key.Bytes()))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&fields); err != nil {
+		return "", nil, fmt.Errorf("unmarshal key: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return "", nil, ErrNoKey
+	}
+
+	conditions := make([]string, 0, len(fields))
+	args := make([]any, 0, len(fields))
+
+	for _, field := range sortedKeys(fields) {
+		conditions = append(conditions, fmt.Sprintf(`"%s" = ?`, strings.ReplaceAll(field, `"`, `""`)))
+		args = append(args, documentValue(fields[field]))
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// documentValue converts JSON numbers to integers or floats, so they are compared with document numbers
+// and passed to procedures as numbers.
+func documentValue(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if v, err := number.Int64(); err == nil {
+		return v
+	}
+
+	if v, err := number.Float64(); err == nil {
+		return v
+	}
+
+	return number.String()
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestCollectionWriter_keyCondition(t *testing.T) {
+	t.Parallel()
+
+	w := &CollectionWriter{}
+
+	where, args, err := w.keyCondition(opencdc.RawData(`{"userId":9007199254740993,"region":"EU"}`))
+	if err != nil {
+		t.Fatalf("key condition: %v", err)
+	}
+
+	if want := `"region" = ? AND "userId" = ?`; where != want {
+		t.Errorf("where = %s, want %s", where, want)
+	}
+
+	if want := []any{"EU", int64(9007199254740993)}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	if _, _, err = w.keyCondition(nil); !errors.Is(err, ErrNoKey) {
+		t.Errorf("error = %v, want %v", err, ErrNoKey)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// DryRunWriter validates records against the tables instead of writing them.
+// Every record which wouldn't fit its table is logged with the list of its problems,
+// and the summary of the validation is logged when the writer is closed.
+type DryRunWriter struct {
+	writer *Writer
+
+	// mu guards records and invalid.
+	mu sync.Mutex
+	// records number of validated records by table names.
+	records map[string]int
+	// invalid number of records with problems by table names.
+	invalid map[string]int
+}
+
+// NewDryRun creates new instance of the DryRunWriter, which validates records with the column metadata
+// and options of the writer.
+func NewDryRun(writer *Writer) *DryRunWriter {
+	return &DryRunWriter{
+		writer:  writer,
+		records: make(map[string]int),
+		invalid: make(map[string]int),
+	}
+}
+
+// Close logs the summary of the validation and closes the writer.
+func (w *DryRunWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	for _, table := range sortedKeys(w.records) {
+		sdk.Logger(ctx).Info().
+			Str("table", table).
+			Int("records", w.records[table]).
+			Int("invalid", w.invalid[table]).
+			Msg("dry run report")
+	}
+	w.mu.Unlock()
+
+	return w.writer.Close(ctx)
+}
+
+// Insert validates the payload of the record as an inserted row.
+func (w *DryRunWriter) Insert(ctx context.Context, record opencdc.Record) error {
+	return w.validate(ctx, record, true, false)
+}
+
+// Update validates the key and the payload of the record as an updated row.
+func (w *DryRunWriter) Update(ctx context.Context, record opencdc.Record) error {
+	// scd2 inserts the new version on update.
+	return w.validate(ctx, record, w.writer.scd2, true)
+}
+
+// Delete validates the key of the record.
+func (w *DryRunWriter) Delete(ctx context.Context, record opencdc.Record) error {
+	tableName := w.writer.getTableName(record.Metadata)
+
+	meta, err := w.writer.tableMeta(ctx, tableName)
+	if err != nil {
+		w.report(ctx, tableName, record, []string{err.Error()})
+
+		return nil
+	}
+
+	w.report(ctx, tableName, record, w.keyProblems(ctx, meta, record))
+
+	return nil
+}
+
+// validate checks the record against the table, insert defines whether the payload is a whole new row,
+// and key defines whether the record is written by its key.
+func (w *DryRunWriter) validate(ctx context.Context, record opencdc.Record, insert, key bool) error {
+	tableName := w.writer.getTableName(record.Metadata)
+
+	// a table which doesn't exist is a problem of the record too.
+	meta, err := w.writer.tableMeta(ctx, tableName)
+	if err != nil {
+		w.report(ctx, tableName, record, []string{err.Error()})
+
+		return nil
+	}
+
+	problems := w.payloadProblems(ctx, meta, record, insert)
+
+	if key {
+		problems = append(problems, w.keyProblems(ctx, meta, record)...)
+	}
+
+	w.report(ctx, tableName, record, problems)
+
+	return nil
+}
+
+// payloadProblems returns problems of the payload fields, which would fail the write.
+func (w *DryRunWriter) payloadProblems(
+	ctx context.Context,
+	meta *tableMeta,
+	record opencdc.Record,
+	insert bool,
+) []string {
+	payload, err := w.writer.recordPayload(record)
+	if err != nil && !errors.Is(err, ErrNoPayload) {
+		return []string{fmt.Sprintf("payload: %s", err)}
+	}
+
+	if len(payload) == 0 {
+		return []string{ErrNoPayload.Error()}
+	}
+
+	meta.removeSkippedColumns(payload)
+
+	var problems []string
+
+	present := make(map[string]bool, len(payload))
+
+	for _, field := range sortedKeys(payload) {
+		column := strings.ToUpper(field)
+		present[column] = true
+
+		if _, ok := meta.columnTypes[column]; !ok {
+			if !w.writer.ignoreUnknownFields {
+				problems = append(problems, fmt.Sprintf("field %s: %s", field, ErrUnknownField))
+			}
+
+			continue
+		}
+
+		if payload[field] == nil {
+			if meta.notNullColumns[column] {
+				problems = append(problems, fmt.Sprintf("field %s: null value of not null column", field))
+			}
+
+			continue
+		}
+
+		problems = append(problems, w.fieldProblems(ctx, meta, field, payload[field], w.writer.truncate)...)
+	}
+
+	if insert {
+		problems = append(problems, w.missingColumns(meta, present)...)
+	}
+
+	return problems
+}
+
+// fieldProblems returns problems of converting the field value to the column type and fitting its length.
+func (w *DryRunWriter) fieldProblems(
+	ctx context.Context, meta *tableMeta, field string, value any, truncate bool,
+) []string {
+	converted, err := columntypes.ConvertStructuredData(ctx, meta.columnTypes, opencdc.StructuredData{field: value},
+		w.writer.convertOpts)
+	if err != nil {
+		return []string{fmt.Sprintf("field %s: %s", field, err)}
+	}
+
+	if _, err = columntypes.FitColumnLengths(converted, meta.columnTypes, meta.columnLengths, truncate); err != nil {
+		return []string{fmt.Sprintf("field %s: %s", field, err)}
+	}
+
+	return nil
+}
+
+// missingColumns returns problems of not null columns, which aren't set by the payload or by the writer.
+// Columns with a default value of the table are reported as well, as defaults of the table aren't known.
+func (w *DryRunWriter) missingColumns(meta *tableMeta, present map[string]bool) []string {
+	setByWriter := map[string]bool{
+		w.writer.createdAtColumn: true,
+		w.writer.updatedAtColumn: true,
+	}
+
+	if w.writer.scd2 {
+		setByWriter[w.writer.validFromColumn] = true
+		setByWriter[w.writer.currentColumn] = true
+	}
+
+	var problems []string
+
+	for _, column := range sortedKeys(meta.notNullColumns) {
+		_, generated := meta.skipColumns[column]
+		_, hasDefault := w.writer.defaults[column]
+
+		if !meta.notNullColumns[column] || present[column] || generated || hasDefault || setByWriter[column] {
+			continue
+		}
+
+		problems = append(problems, fmt.Sprintf("column %s: missing value of not null column", column))
+	}
+
+	return problems
+}
+
+// keyProblems returns problems of the key fields.
+func (w *DryRunWriter) keyProblems(ctx context.Context, meta *tableMeta, record opencdc.Record) []string {
+	keys, err := w.writer.structurizeData(record.Key)
+	if err != nil {
+		return []string{fmt.Sprintf("key: %s", err)}
+	}
+
+	if len(keys) == 0 {
+		return []string{ErrNoKey.Error()}
+	}
+
+	var problems []string
+
+	for _, field := range sortedKeys(keys) {
+		if _, ok := meta.columnTypes[strings.ToUpper(field)]; !ok {
+			problems = append(problems, fmt.Sprintf("key %s: %s", field, ErrUnknownField))
+
+			continue
+		}
+
+		problems = append(problems, w.fieldProblems(ctx, meta, field, keys[field], false)...)
+	}
+
+	return problems
+}
+
+// report counts the record and logs its problems.
+func (w *DryRunWriter) report(ctx context.Context, table string, record opencdc.Record, problems []string) {
+	w.mu.Lock()
+	w.records[table]++
+	if len(problems) > 0 {
+		w.invalid[table]++
+	}
+	w.mu.Unlock()
+
+	if len(problems) == 0 {
+		return
+	}
+
+	sdk.Logger(ctx).Warn().
+		Str("table", table).
+		Str("operation", record.Operation.String()).
+		Str("position", string(record.Position)).
+		Strs("problems", problems).
+		Msg("record doesn't fit the table")
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func newTestDryRun() *DryRunWriter {
+	meta := &tableMeta{
+		columnTypes: map[string]string{
+			"ID": "INTEGER", "NAME": "NVARCHAR", "CODE": "NVARCHAR", "CREATED_AT": "TIMESTAMP", "SEQ": "BIGINT",
+		},
+		columnLengths:  map[string]int{"NAME": 5, "CODE": 3},
+		notNullColumns: map[string]bool{"ID": true, "NAME": true, "CODE": true, "CREATED_AT": true, "SEQ": true},
+		skipColumns:    map[string]bool{"SEQ": true},
+	}
+
+	return NewDryRun(&Writer{
+		table:           "CLIENTS",
+		defaults:        map[string]string{"CODE": "'N/A'"},
+		createdAtColumn: "CREATED_AT",
+		tables:          map[string]*tableMeta{"CLIENTS": meta},
+		excludedFields:  map[string]bool{"offset": true},
+	})
+}
+
+func TestDryRunWriter_PayloadProblems(t *testing.T) {
+	t.Parallel()
+
+	w := newTestDryRun()
+
+	tests := []struct {
+		name    string
+		payload string
+		insert  bool
+		want    []string
+	}{
+		{
+			name:    "valid",
+			payload: `{"ID":1,"name":"Bob"}`,
+			insert:  true,
+		},
+		{
+			name:    "unknown field, too long value",
+			payload: `{"ID":1,"name":"Robert","age":30}`,
+			insert:  true,
+			want: []string{
+				"field age: unknown field",
+				`field name: value exceeds column length: "name" has length 6, max length is 5`,
+			},
+		},
+		{
+			name:    "excluded field",
+			payload: `{"ID":1,"name":"Bob","offset":42}`,
+			insert:  true,
+		},
+		{
+			name:    "missing not null column",
+			payload: `{"ID":1}`,
+			insert:  true,
+			want:    []string{"column NAME: missing value of not null column"},
+		},
+		{
+			name:    "null value",
+			payload: `{"ID":1,"name":null}`,
+			insert:  false,
+			want:    []string{"field name: null value of not null column"},
+		},
+		{
+			name:    "partial update",
+			payload: `{"name":"Bob"}`,
+			insert:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			record := opencdc.Record{Payload: opencdc.Change{After: opencdc.RawData(tt.payload)}}
+
+			got := w.payloadProblems(context.Background(), w.writer.tables["CLIENTS"], record, tt.insert)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payloadProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunWriter_Delete(t *testing.T) {
+	t.Parallel()
+
+	w := newTestDryRun()
+	ctx := context.Background()
+
+	for _, key := range []string{`{"ID":1}`, `{"UUID":"a"}`, ``} {
+		if err := w.Delete(ctx, opencdc.Record{Operation: opencdc.OperationDelete, Key: opencdc.RawData(key)}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	}
+
+	if w.records["CLIENTS"] != 3 || w.invalid["CLIENTS"] != 2 {
+		t.Errorf("records = %d, invalid = %d, want 3 and 2", w.records["CLIENTS"], w.invalid["CLIENTS"])
+	}
+}
@@ -23,4 +23,20 @@ var (
 	ErrNoPayload = errors.New("no payload")
 	// ErrNoKey occurs when there is no value for key.
 	ErrNoKey = errors.New("no key")
+	// ErrUnknownField occurs when the payload contains a field which doesn't exist in the table.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrNoVersionColumn occurs when the table doesn't have a column of the SCD Type 2 versions.
+	ErrNoVersionColumn = errors.New("no version column")
+	// ErrInvalidDocument occurs when the payload isn't a valid JSON document.
+	ErrInvalidDocument = errors.New("payload isn't a valid json document")
+
+	// ErrRetryable wraps failures which are transient, so the write can succeed if it's retried,
+	// e.g. a lock wait timeout, a deadlock or a lost connection.
+	ErrRetryable = errors.New("retryable error")
+	// ErrSchemaMismatch wraps failures caused by records which don't match the table,
+	// e.g. an unknown table or column, or a value of a wrong type.
+	ErrSchemaMismatch = errors.New("schema mismatch")
+	// ErrConstraintViolation wraps failures caused by records which violate a table constraint,
+	// e.g. a unique, not null or foreign key constraint.
+	ErrConstraintViolation = errors.New("constraint violation")
 )
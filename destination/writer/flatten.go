@@ -0,0 +1,43 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+// flatten replaces nested objects of the data with their fields, names of the fields are joined
+// with the names of the objects by the separator, e.g. address.city becomes address_city.
+// Arrays aren't flattened.
+func flatten(data map[string]any, separator string) map[string]any {
+	flat := make(map[string]any, len(data))
+
+	flattenInto(flat, "", data, separator)
+
+	return flat
+}
+
+// flattenInto adds fields of the object to the flat map with the prefix.
+func flattenInto(flat map[string]any, prefix string, object map[string]any, separator string) {
+	for key, value := range object {
+		if prefix != "" {
+			key = prefix + separator + key
+		}
+
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenInto(flat, key, nested, separator)
+
+			continue
+		}
+
+		flat[key] = value
+	}
+}
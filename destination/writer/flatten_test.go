@@ -0,0 +1,48 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestWriter_structurizeData_flatten(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{flattenSeparator: "_"}
+
+	data, err := w.structurizeData(opencdc.RawData(
+		`{"id":1,"address":{"city":"Kyiv","geo":{"lat":50.45}},"tags":["a"],"empty":{}}`,
+	))
+	if err != nil {
+		t.Fatalf("structurize data: %v", err)
+	}
+
+	want := opencdc.StructuredData{
+		"id":              json.Number("1"),
+		"address_city":    "Kyiv",
+		"address_geo_lat": json.Number("50.45"),
+		"tags":            []any{"a"},
+		"empty":           map[string]any{},
+	}
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
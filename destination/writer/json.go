@@ -0,0 +1,45 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// jsonPayload returns the key fields of the record and the payload as a JSON document in the JSON column,
+// so rows can be found by the key and the document can be queried with JSON functions.
+func (w *Writer) jsonPayload(record opencdc.Record, payload opencdc.StructuredData) (opencdc.StructuredData, error) {
+	document, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	keys, err := w.structurizeData(record.Key)
+	if err != nil {
+		return nil, fmt.Errorf("structurize key: %w", err)
+	}
+
+	data := make(opencdc.StructuredData, len(keys)+1)
+	for key, value := range keys {
+		data[key] = value
+	}
+
+	data[w.jsonColumn] = string(document)
+
+	return data, nil
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestWriter_recordPayload_jsonColumn(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{jsonColumn: "DOC", excludedFields: map[string]bool{"offset": true}}
+
+	payload, err := w.recordPayload(opencdc.Record{
+		Key: opencdc.RawData(`{"ID":9007199254740993}`),
+		Payload: opencdc.Change{
+			After: opencdc.RawData(`{"ID":9007199254740993,"name":"Bob","tags":["a"],"offset":42}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("record payload: %v", err)
+	}
+
+	want := opencdc.StructuredData{
+		"ID":  json.Number("9007199254740993"),
+		"DOC": `{"ID":9007199254740993,"name":"Bob","tags":["a"]}`,
+	}
+
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+}
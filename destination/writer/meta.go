@@ -0,0 +1,133 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// errCodeInvalidColumnName is the sap hana error code of a statement referencing an unknown column.
+const errCodeInvalidColumnName = 260
+
+// tableMeta is column metadata of a table the writer writes to.
+type tableMeta struct {
+	columnTypes map[string]string
+	// columnLengths column lengths from table.
+	columnLengths map[string]int
+	// notNullColumns names of not null columns from table.
+	notNullColumns map[string]bool
+	// skipColumns generated columns excluded from inserts and updates.
+	skipColumns map[string]bool
+}
+
+// tableMeta returns the cached column metadata of the table, it's loaded from the database on the first call.
+func (w *Writer) tableMeta(ctx context.Context, table string) (*tableMeta, error) {
+	w.tablesMu.Lock()
+	defer w.tablesMu.Unlock()
+
+	if meta, ok := w.tables[table]; ok {
+		return meta, nil
+	}
+
+	tableInfo, err := w.catalog.TableInfo(ctx, w.db, table)
+	if err != nil {
+		return nil, fmt.Errorf("get table info: %w", err)
+	}
+
+	meta := w.newTableMeta(tableInfo)
+
+	w.tables[table] = meta
+
+	return meta, nil
+}
+
+// newTableMeta returns the column metadata of the table with the generated columns skipped by the writer.
+func (w *Writer) newTableMeta(tableInfo columntypes.TableInfo) *tableMeta {
+	meta := &tableMeta{
+		columnTypes:    tableInfo.ColumnTypes,
+		columnLengths:  tableInfo.ColumnLengths,
+		notNullColumns: tableInfo.NotNullColumns,
+		skipColumns:    make(map[string]bool),
+	}
+
+	for column := range tableInfo.GeneratedColumns {
+		if tableInfo.IsGeneratedAlways(column) {
+			meta.skipColumns[column] = w.skipGeneratedAlways
+		} else {
+			meta.skipColumns[column] = w.skipGeneratedByDefault
+		}
+	}
+
+	return meta
+}
+
+// invalidate removes the cached column metadata of the table, so it's loaded again on the next write.
+func (w *Writer) invalidate(table string) {
+	w.tablesMu.Lock()
+	defer w.tablesMu.Unlock()
+
+	delete(w.tables, table)
+	w.catalog.Invalidate(table)
+}
+
+// refreshOnInvalidColumn runs the write with the column metadata of the table.
+// If the database rejects the statement because of an unknown column, the table was altered after
+// its metadata had been loaded, so the metadata is loaded again and the write is retried once.
+func (w *Writer) refreshOnInvalidColumn(ctx context.Context, table string, write func(*tableMeta) error) error {
+	meta, err := w.tableMeta(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	err = write(meta)
+	if !isInvalidColumnError(err) {
+		return err
+	}
+
+	sdk.Logger(ctx).Info().Str("table", table).Msg("table columns changed, reload column metadata")
+
+	w.invalidate(table)
+
+	meta, err = w.tableMeta(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	return write(meta)
+}
+
+// isInvalidColumnError checks whether the error is the sap hana invalid column name error.
+func isInvalidColumnError(err error) bool {
+	var dbErr driver.DBError
+
+	return errors.As(err, &dbErr) && dbErr.Code() == errCodeInvalidColumnName
+}
+
+// removeSkippedColumns removes generated columns, which must not be written, from the payload.
+func (m *tableMeta) removeSkippedColumns(payload opencdc.StructuredData) {
+	for key := range payload {
+		if m.skipColumns[strings.ToUpper(key)] {
+			delete(payload, key)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/catalog"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// dbError is a driver.DBError with the given code.
+type dbError struct {
+	driver.DBError
+	code int
+}
+
+func (e dbError) Error() string { return fmt.Sprintf("sql error %d", e.code) }
+func (e dbError) Code() int     { return e.code }
+
+func TestIsInvalidColumnError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "other error", err: errors.New("connection refused"), want: false},
+		{name: "other database error", err: dbError{code: 259}, want: false},
+		{name: "invalid column name", err: dbError{code: errCodeInvalidColumnName}, want: true},
+		{name: "wrapped", err: fmt.Errorf("exec: %w", dbError{code: errCodeInvalidColumnName}), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isInvalidColumnError(tt.err); got != tt.want {
+				t.Errorf("isInvalidColumnError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter_RefreshOnInvalidColumn(t *testing.T) {
+	t.Parallel()
+
+	stale := &tableMeta{columnTypes: map[string]string{"ID": "INTEGER"}}
+
+	w := &Writer{
+		tables:  map[string]*tableMeta{"CLIENTS": stale, "ORDERS": {}},
+		catalog: catalog.New(catalog.DefaultTTL),
+	}
+
+	err := w.refreshOnInvalidColumn(context.Background(), "CLIENTS", func(meta *tableMeta) error {
+		if meta != stale {
+			t.Fatal("cached metadata isn't used")
+		}
+
+		return fmt.Errorf("exec: %w", dbError{code: 259})
+	})
+	if err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	if w.tables["CLIENTS"] != stale || w.tables["ORDERS"] == nil {
+		t.Error("metadata is invalidated on an unrelated error")
+	}
+
+	w.invalidate("CLIENTS")
+
+	if _, ok := w.tables["CLIENTS"]; ok {
+		t.Error("metadata of the table isn't invalidated")
+	}
+
+	if w.tables["ORDERS"] == nil {
+		t.Error("metadata of another table is invalidated")
+	}
+}
+
+func TestTableMeta_RemoveSkippedColumns(t *testing.T) {
+	t.Parallel()
+
+	meta := &tableMeta{skipColumns: map[string]bool{"TOTAL": true, "SEQ": false}}
+
+	payload := opencdc.StructuredData{"id": 1, "total": 10, "seq": 2}
+	meta.removeSkippedColumns(payload)
+
+	if _, ok := payload["total"]; ok {
+		t.Error("generated always column isn't removed")
+	}
+
+	if _, ok := payload["seq"]; !ok {
+		t.Error("not skipped generated column is removed")
+	}
+}
+
+func TestWriter_newTableMeta_SkipGenerated(t *testing.T) {
+	t.Parallel()
+
+	tableInfo := columntypes.TableInfo{
+		ColumnTypes:      map[string]string{"ID": "INTEGER", "TOTAL": "DECIMAL", "NAME": "NVARCHAR"},
+		GeneratedColumns: map[string]string{"ID": "BY DEFAULT AS IDENTITY", "TOTAL": "ALWAYS AS (PRICE * QTY)"},
+	}
+
+	tests := []struct {
+		name      string
+		always    bool
+		byDefault bool
+		wantKept  []string
+	}{
+		{
+			name:     "always",
+			always:   true,
+			wantKept: []string{"id", "name"},
+		},
+		{
+			name:      "all",
+			always:    true,
+			byDefault: true,
+			wantKept:  []string{"name"},
+		},
+		{
+			name:     "none",
+			wantKept: []string{"id", "name", "total"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := &Writer{skipGeneratedAlways: tt.always, skipGeneratedByDefault: tt.byDefault}
+
+			payload := opencdc.StructuredData{"id": 1, "total": 10, "name": "alice"}
+			w.newTableMeta(tableInfo).removeSkippedColumns(payload)
+
+			if kept := sortedKeys(payload); !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept columns = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
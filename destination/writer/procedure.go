@@ -0,0 +1,155 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	queryCallProcedure = `CALL %s(%s)`
+
+	// ParameterOperation is the procedure parameter passed the record operation instead of a field value.
+	ParameterOperation = "$operation"
+)
+
+// ProcedureWriter passes every record to a stored procedure instead of writing it with DML.
+type ProcedureWriter struct {
+	db *sqlx.DB
+	// query is the call of the procedure with a placeholder per parameter.
+	query string
+	// parameters are names of the record fields passed to the procedure in their order.
+	parameters []string
+}
+
+// ProcedureParams is an incoming params for the NewProcedure function.
+type ProcedureParams struct {
+	DB         *sqlx.DB
+	Procedure  string
+	Parameters []string
+}
+
+// NewProcedure creates new instance of the ProcedureWriter.
+func NewProcedure(params ProcedureParams) *ProcedureWriter {
+	placeholders := make([]string, len(params.Parameters))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	return &ProcedureWriter{
+		db:         params.DB,
+		query:      fmt.Sprintf(queryCallProcedure, params.Procedure, strings.Join(placeholders, ", ")),
+		parameters: params.Parameters,
+	}
+}
+
+// Close closes the underlying db connection.
+func (w *ProcedureWriter) Close(context.Context) error {
+	if err := w.db.Close(); err != nil {
+		return fmt.Errorf("close db: %w", err)
+	}
+
+	return nil
+}
+
+// Insert calls the procedure with fields of the record.
+func (w *ProcedureWriter) Insert(ctx context.Context, record opencdc.Record) error {
+	return w.call(ctx, record)
+}
+
+// Update calls the procedure with fields of the record.
+func (w *ProcedureWriter) Update(ctx context.Context, record opencdc.Record) error {
+	return w.call(ctx, record)
+}
+
+// Delete calls the procedure with fields of the record.
+func (w *ProcedureWriter) Delete(ctx context.Context, record opencdc.Record) error {
+	return w.call(ctx, record)
+}
+
+// call executes the procedure with the record fields mapped to its parameters.
+// Parameters without a field get null.
+func (w *ProcedureWriter) call(ctx context.Context, record opencdc.Record) error {
+	fields, err := recordFields(record)
+	if err != nil {
+		return err
+	}
+
+	args := make([]any, len(w.parameters))
+
+	for i, parameter := range w.parameters {
+		if parameter == ParameterOperation {
+			args[i] = record.Operation.String()
+
+			continue
+		}
+
+		args[i], err = procedureValue(fields[parameter])
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", parameter, err)
+		}
+	}
+
+	if _, err = w.db.ExecContext(ctx, w.query, args...); err != nil {
+		return classify(fmt.Errorf("exec call: %w", err))
+	}
+
+	return nil
+}
+
+// recordFields merges fields of the record, the payload after takes precedence over the key,
+// and the key takes precedence over the payload before.
+func recordFields(record opencdc.Record) (map[string]any, error) {
+	fields := make(map[string]any)
+
+	for _, data := range []opencdc.Data{record.Payload.Before, record.Key, record.Payload.After} {
+		if data == nil || len(data.Bytes()) == 0 {
+			continue
+		}
+
+		// use json.Number to keep precision of big integers and decimals.
+		decoder := json.NewDecoder(bytes.NewReader(data.Bytes()))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("unmarshal record data: %w", err)
+		}
+	}
+
+	return fields, nil
+}
+
+// procedureValue converts the field value to a procedure argument.
+// Objects and arrays are passed as JSON strings.
+func procedureValue(value any) (any, error) {
+	switch value.(type) {
+	case map[string]any, []any:
+		bs, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: %w", err)
+		}
+
+		return string(bs), nil
+	default:
+		return documentValue(value), nil
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestNewProcedure(t *testing.T) {
+	t.Parallel()
+
+	w := NewProcedure(ProcedureParams{Procedure: "UPSERT_CLIENT", Parameters: []string{"ID", "name", "$operation"}})
+
+	if want := "CALL UPSERT_CLIENT(?, ?, ?)"; w.query != want {
+		t.Errorf("query = %s, want %s", w.query, want)
+	}
+}
+
+func TestRecordFields(t *testing.T) {
+	t.Parallel()
+
+	fields, err := recordFields(opencdc.Record{
+		Key: opencdc.RawData(`{"ID":9007199254740993}`),
+		Payload: opencdc.Change{
+			Before: opencdc.RawData(`{"ID":1,"name":"old","tags":["a"]}`),
+			After:  opencdc.RawData(`{"name":"new","address":{"city":"Kyiv"}}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("record fields: %v", err)
+	}
+
+	args := make([]any, 0, 4)
+
+	for _, field := range []string{"ID", "name", "address", "tags"} {
+		value, er := procedureValue(fields[field])
+		if er != nil {
+			t.Fatalf("procedure value: %v", er)
+		}
+
+		args = append(args, value)
+	}
+
+	want := []any{int64(9007199254740993), "new", `{"city":"Kyiv"}`, `["a"]`}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
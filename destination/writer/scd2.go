@@ -0,0 +1,116 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// In the SCD Type 2 mode every change of a key is a new version of the row.
+// The current version has the current flag set and no end of the validity period.
+
+// openVersion adds the columns of the new current version to the payload.
+func (w *Writer) openVersion(payload opencdc.StructuredData, now time.Time) {
+	for _, column := range []string{w.validFromColumn, w.validToColumn, w.currentColumn} {
+		removeColumn(payload, column)
+	}
+
+	payload[w.validFromColumn] = now
+	payload[w.validToColumn] = nil
+	payload[w.currentColumn] = sqlbuilder.Raw("TRUE")
+}
+
+// updateVersion closes the current version of the key and inserts the new one in a transaction,
+// so the key always has a single current version. The new version is a complete row,
+// it's built like an insert, with the defaults and the creation time.
+// A key without a current version gets its first version.
+func (w *Writer) updateVersion(ctx context.Context, tableName string, meta *tableMeta, record opencdc.Record) error {
+	keys, err := w.recordKeys(ctx, meta, record)
+	if err != nil {
+		return err
+	}
+
+	payload, err := w.insertPayload(ctx, meta, record)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	// key columns can be missing in the payload of the update.
+	for key, value := range keys {
+		removeColumn(payload, strings.ToUpper(key))
+		payload[key] = value
+	}
+
+	w.openVersion(payload, now)
+
+	return w.inTx(ctx, func(tx *sql.Tx) error {
+		query, args := w.buildCloseVersionQuery(tableName, keys, now)
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("exec close version: %w", err)
+		}
+
+		columns, values := w.extractColumnsAndValues(payload)
+
+		query, args = w.buildInsertQuery(tableName, columns, values)
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("exec insert version: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// closeVersion ends the validity period of the current version of the key.
+func (w *Writer) closeVersion(ctx context.Context, tableName string, keys map[string]any) error {
+	query, args := w.buildCloseVersionQuery(tableName, keys, time.Now().UTC())
+
+	if err := w.exec(ctx, query, args); err != nil {
+		return fmt.Errorf("exec close version: %w", err)
+	}
+
+	return nil
+}
+
+// buildCloseVersionQuery generates an SQL UPDATE statement query, which closes the current version of the key.
+func (w *Writer) buildCloseVersionQuery(table string, keys map[string]any, now time.Time) (string, []any) {
+	up := sqlbuilder.NewUpdateBuilder()
+
+	up.Update(w.identifier(table))
+	up.Set(
+		up.Assign(w.identifier(w.validToColumn), now),
+		up.Assign(w.identifier(w.currentColumn), sqlbuilder.Raw("FALSE")),
+	)
+
+	for _, key := range sortedKeys(keys) {
+		up.Where(
+			up.Equal(w.identifier(key), keys[key]),
+		)
+	}
+
+	up.Where(up.Equal(w.identifier(w.currentColumn), sqlbuilder.Raw("TRUE")))
+
+	return up.Build()
+}
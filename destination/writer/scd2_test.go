@@ -0,0 +1,204 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// newSCD2Writer returns the writer keeping versions of rows, with the audit columns and a default.
+func newSCD2Writer(db *fakedb.DB) *Writer {
+	sqlDB := db.Open()
+
+	return &Writer{
+		db:              sqlDB,
+		stmts:           newStmtCache(sqlDB, maxCachedStatements),
+		scd2:            true,
+		validFromColumn: "VALID_FROM",
+		validToColumn:   "VALID_TO",
+		currentColumn:   "IS_CURRENT",
+		createdAtColumn: "CREATED_AT",
+		updatedAtColumn: "UPDATED_AT",
+		defaults:        map[string]string{"STATUS": "'active'"},
+	}
+}
+
+// scd2Meta returns the metadata of the versioned table.
+func scd2Meta() *tableMeta {
+	return &tableMeta{
+		columnTypes:    map[string]string{"ID": "INTEGER", "NAME": "NVARCHAR", "STATUS": "NVARCHAR"},
+		columnLengths:  map[string]int{"NAME": 10, "STATUS": 10},
+		notNullColumns: map[string]bool{"STATUS": true},
+	}
+}
+
+// scd2Record returns the record of the key with the name in the payload.
+func scd2Record(operation opencdc.Operation) opencdc.Record {
+	return opencdc.Record{
+		Operation: operation,
+		Key:       opencdc.StructuredData{"id": 1},
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"name": "alice"}},
+	}
+}
+
+func TestWriter_buildCloseVersionQuery(t *testing.T) {
+	t.Parallel()
+
+	w := newSCD2Writer(fakedb.New(nil))
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	query, args := w.buildCloseVersionQuery("USERS", map[string]any{"id": 1, "region": "eu"}, now)
+
+	want := "UPDATE USERS SET VALID_TO = ?, IS_CURRENT = FALSE WHERE id = ? AND region = ? AND IS_CURRENT = TRUE"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{now, 1, "eu"}) {
+		t.Errorf("args = %v, want [%v 1 eu]", args, now)
+	}
+}
+
+func TestWriter_openVersion(t *testing.T) {
+	t.Parallel()
+
+	w := newSCD2Writer(fakedb.New(nil))
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// the version columns of the payload are replaced regardless of the case.
+	payload := opencdc.StructuredData{"id": 1, "valid_from": "2000-01-01", "is_current": false}
+	w.openVersion(payload, now)
+
+	want := opencdc.StructuredData{
+		"id":         1,
+		"VALID_FROM": now,
+		"VALID_TO":   nil,
+		"IS_CURRENT": sqlbuilder.Raw("TRUE"),
+	}
+
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+}
+
+func TestWriter_insert_SCD2(t *testing.T) {
+	t.Parallel()
+
+	db := fakedb.New(nil)
+	w := newSCD2Writer(db)
+
+	err := w.insert(context.Background(), "USERS", scd2Meta(), scd2Record(opencdc.OperationCreate))
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	want := []string{
+		"INSERT INTO USERS (CREATED_AT, IS_CURRENT, STATUS, UPDATED_AT, VALID_FROM, VALID_TO, name) " +
+			"VALUES (CURRENT_UTCTIMESTAMP, TRUE, 'active', CURRENT_UTCTIMESTAMP, ?, ?, ?)",
+	}
+
+	if got := db.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("queries = %q, want %q", got, want)
+	}
+}
+
+func TestWriter_update_SCD2(t *testing.T) {
+	t.Parallel()
+
+	db := fakedb.New(nil)
+	w := newSCD2Writer(db)
+
+	err := w.update(context.Background(), "USERS", scd2Meta(), scd2Record(opencdc.OperationUpdate))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	statements := db.Statements()
+	if len(statements) != 4 {
+		t.Fatalf("statements = %q, want begin, close, insert and commit", db.Queries())
+	}
+
+	if statements[0].Query != fakedb.Begin || statements[3].Query != fakedb.Commit {
+		t.Errorf("statements = %q, want them in a transaction", db.Queries())
+	}
+
+	closeVersion, insertVersion := statements[1], statements[2]
+
+	if !strings.HasPrefix(closeVersion.Query, "UPDATE USERS SET VALID_TO = ?, IS_CURRENT = FALSE") {
+		t.Errorf("close version query = %q", closeVersion.Query)
+	}
+
+	// the new version is a complete row with the creation time, the defaults and the key.
+	wantInsert := "INSERT INTO USERS (CREATED_AT, IS_CURRENT, STATUS, UPDATED_AT, VALID_FROM, VALID_TO, id, name) " +
+		"VALUES (CURRENT_UTCTIMESTAMP, TRUE, 'active', CURRENT_UTCTIMESTAMP, ?, ?, ?, ?)"
+	if insertVersion.Query != wantInsert {
+		t.Errorf("insert version query = %q, want %q", insertVersion.Query, wantInsert)
+	}
+
+	// the closed version ends when the new one starts.
+	if !reflect.DeepEqual(closeVersion.Args[0], insertVersion.Args[0]) {
+		t.Errorf("valid to = %v, valid from = %v, want equal", closeVersion.Args[0], insertVersion.Args[0])
+	}
+}
+
+func TestWriter_update_SCD2_NoCurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	// the key has no current version, closing it affects no rows.
+	db := fakedb.New(func(query string, _ []any) fakedb.Result {
+		if strings.HasPrefix(query, "UPDATE") {
+			return fakedb.Result{RowsAffected: 0}
+		}
+
+		return fakedb.Result{RowsAffected: 1}
+	})
+	w := newSCD2Writer(db)
+
+	err := w.update(context.Background(), "USERS", scd2Meta(), scd2Record(opencdc.OperationUpdate))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	queries := db.Queries()
+	if len(queries) != 4 || !strings.HasPrefix(queries[2], "INSERT INTO USERS") || queries[3] != fakedb.Commit {
+		t.Errorf("queries = %q, want the first version inserted and committed", queries)
+	}
+}
+
+func TestWriter_delete_SCD2(t *testing.T) {
+	t.Parallel()
+
+	db := fakedb.New(nil)
+	w := newSCD2Writer(db)
+
+	err := w.delete(context.Background(), "USERS", scd2Meta(), scd2Record(opencdc.OperationDelete))
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// the row isn't deleted, its current version is closed.
+	queries := db.Queries()
+	if len(queries) != 1 || !strings.HasPrefix(queries[0], "UPDATE USERS SET VALID_TO = ?, IS_CURRENT = FALSE") {
+		t.Errorf("queries = %q, want the current version closed", queries)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// cachedStmt is a prepared statement of the cache.
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+	// users number of executions of the statement in progress.
+	users int
+	// evicted defines whether the statement is closed when its last execution ends.
+	evicted bool
+}
+
+// stmtCache keeps the prepared statements of the recently executed queries. When it's full,
+// the least recently used statement is evicted, it's closed once its executions end.
+type stmtCache struct {
+	db  *sqlx.DB
+	max int
+
+	mu sync.Mutex
+	// entries elements of the recency list by the queries. The query includes the table and
+	// the sorted column set, so it's a unique cache key.
+	entries map[string]*list.Element
+	// recency statements from the most recently used one to the least recently used one.
+	recency *list.List
+}
+
+// newStmtCache creates the cache of at most max prepared statements.
+func newStmtCache(db *sqlx.DB, maxStatements int) *stmtCache {
+	return &stmtCache{
+		db:      db,
+		max:     maxStatements,
+		entries: make(map[string]*list.Element),
+		recency: list.New(),
+	}
+}
+
+// acquire returns the prepared statement of the query, it's prepared if it isn't cached.
+// The statement must be released after its execution.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[query]; ok {
+		c.recency.MoveToFront(element)
+
+		entry := element.Value.(*cachedStmt) //nolint:forcetypeassert // the list keeps only cached statements
+		entry.users++
+
+		return entry, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("prepare: %w", err)
+	}
+
+	if c.recency.Len() >= c.max {
+		c.evict(ctx, c.recency.Back())
+	}
+
+	entry := &cachedStmt{query: query, stmt: stmt, users: 1}
+	c.entries[query] = c.recency.PushFront(entry)
+
+	return entry, nil
+}
+
+// release ends the execution of the statement, the evicted statement is closed after its last execution.
+func (c *stmtCache) release(ctx context.Context, entry *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.users--
+
+	if entry.evicted && entry.users == 0 {
+		closeEvicted(ctx, entry)
+	}
+}
+
+// evict removes the statement from the cache, it's closed if it isn't executed.
+func (c *stmtCache) evict(ctx context.Context, element *list.Element) {
+	entry := c.recency.Remove(element).(*cachedStmt) //nolint:forcetypeassert // the list keeps only cached statements
+	delete(c.entries, entry.query)
+
+	entry.evicted = true
+
+	if entry.users == 0 {
+		closeEvicted(ctx, entry)
+	}
+}
+
+// close closes and forgets all cached statements, it returns the errors of all failed closes.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+
+	for element := c.recency.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cachedStmt) //nolint:forcetypeassert // the list keeps only cached statements
+
+		if err := entry.stmt.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close statement: %w", err))
+		}
+	}
+
+	c.entries = make(map[string]*list.Element)
+	c.recency.Init()
+
+	return errors.Join(errs...)
+}
+
+// len returns the number of cached statements.
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.recency.Len()
+}
+
+// closeEvicted closes the evicted statement, a failure is only logged, as the write doesn't depend on it.
+func closeEvicted(ctx context.Context, entry *cachedStmt) {
+	if err := entry.stmt.Close(); err != nil {
+		sdk.Logger(ctx).Warn().Err(err).Msg("close evicted statement")
+	}
+}
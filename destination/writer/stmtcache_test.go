@@ -0,0 +1,141 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+)
+
+func TestStmtCache_acquire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := fakedb.New(nil)
+	cache := newStmtCache(db.Open(), 2)
+
+	for _, query := range []string{"Q1", "Q2", "Q1"} {
+		entry, err := cache.acquire(ctx, query)
+		if err != nil {
+			t.Fatalf("acquire %s: %v", query, err)
+		}
+
+		cache.release(ctx, entry)
+	}
+
+	if db.Prepared() != 2 {
+		t.Errorf("prepared = %d, want the cached statement reused", db.Prepared())
+	}
+
+	// Q2 is the least recently used statement.
+	entry, err := cache.acquire(ctx, "Q3")
+	if err != nil {
+		t.Fatalf("acquire Q3: %v", err)
+	}
+
+	cache.release(ctx, entry)
+
+	if cache.len() != 2 || db.Closed() != 1 {
+		t.Fatalf("cached = %d, closed = %d, want one statement evicted", cache.len(), db.Closed())
+	}
+
+	if _, ok := cache.entries["Q2"]; ok {
+		t.Error("the least recently used statement isn't evicted")
+	}
+
+	// new statements are still prepared when the cache is full.
+	entry, err = cache.acquire(ctx, "Q4")
+	if err != nil {
+		t.Fatalf("acquire Q4: %v", err)
+	}
+
+	cache.release(ctx, entry)
+
+	if db.Prepared() != 4 {
+		t.Errorf("prepared = %d, want a statement prepared for every new query", db.Prepared())
+	}
+}
+
+func TestStmtCache_EvictInUse(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := fakedb.New(nil)
+	cache := newStmtCache(db.Open(), 1)
+
+	inUse, err := cache.acquire(ctx, "Q1")
+	if err != nil {
+		t.Fatalf("acquire Q1: %v", err)
+	}
+
+	entry, err := cache.acquire(ctx, "Q2")
+	if err != nil {
+		t.Fatalf("acquire Q2: %v", err)
+	}
+
+	cache.release(ctx, entry)
+
+	if db.Closed() != 0 {
+		t.Fatal("the evicted statement is closed while it's executed")
+	}
+
+	if _, err = inUse.stmt.ExecContext(ctx); err != nil {
+		t.Fatalf("exec the evicted statement: %v", err)
+	}
+
+	cache.release(ctx, inUse)
+
+	if db.Closed() != 1 {
+		t.Errorf("closed = %d, want the evicted statement closed after its execution", db.Closed())
+	}
+}
+
+func TestWriter_Close(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := fakedb.New(nil)
+	sqlDB := db.Open()
+
+	w := &Writer{db: sqlDB, stmts: newStmtCache(sqlDB, maxCachedStatements)}
+
+	for i := range 2 {
+		entry, err := w.stmts.acquire(ctx, fmt.Sprintf("Q%d", i))
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+
+		w.stmts.release(ctx, entry)
+	}
+
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if db.Closed() != 2 {
+		t.Errorf("closed = %d, want all statements closed", db.Closed())
+	}
+
+	if sqlDB.PingContext(ctx) == nil {
+		t.Error("db isn't closed")
+	}
+
+	if w.stmts.len() != 0 {
+		t.Errorf("cached = %d, want none after close", w.stmts.len())
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+const (
+	// truncatesTable keeps markers of tables truncated before the snapshot, a marker is removed when the snapshot
+	// ends, so the snapshot resumed after a restart doesn't truncate the table again.
+	truncatesTable = "CONDUIT_TRUNCATES"
+
+	queryTruncatesTableExists = `SELECT COUNT(*) FROM TABLES WHERE TABLE_NAME = ? AND SCHEMA_NAME = CURRENT_SCHEMA`
+	queryCreateTruncatesTable = `CREATE COLUMN TABLE %s (DESTINATION NVARCHAR(256) NOT NULL,
+		TABLE_NAME NVARCHAR(256) NOT NULL, TRUNCATED_AT TIMESTAMP NOT NULL, PRIMARY KEY (DESTINATION, TABLE_NAME))`
+	queryTruncateMarked = `SELECT COUNT(*) FROM %s WHERE DESTINATION = ? AND TABLE_NAME = ?`
+	queryMarkTruncate   = `UPSERT %s (DESTINATION, TABLE_NAME, TRUNCATED_AT) VALUES (?, ?, CURRENT_UTCTIMESTAMP)
+		WITH PRIMARY KEY`
+	queryUnmarkTruncate = `DELETE FROM %s WHERE DESTINATION = ? AND TABLE_NAME = ?`
+
+	// errCodeDuplicateTableName - the table was created concurrently by another connector.
+	errCodeDuplicateTableName = 288
+)
+
+// createTruncatesTable creates the table of the truncate markers, if it doesn't exist.
+func (w *Writer) createTruncatesTable(ctx context.Context) error {
+	var count int
+	if err := w.db.GetContext(ctx, &count, queryTruncatesTableExists, truncatesTable); err != nil {
+		return fmt.Errorf("check table exists: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err := w.db.ExecContext(ctx, fmt.Sprintf(queryCreateTruncatesTable, truncatesTable))
+	if err != nil && !isAlreadyExists(err) {
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	return nil
+}
+
+// trackSnapshot truncates the table before its first snapshot record. The first record of another operation
+// following the snapshot records ends the snapshot, so the next snapshot truncates the table again.
+func (w *Writer) trackSnapshot(ctx context.Context, tableName string, records ...opencdc.Record) error {
+	if !w.truncateOnSnapshot {
+		return nil
+	}
+
+	if slices.ContainsFunc(records, isSnapshot) {
+		if err := w.truncateOnce(ctx, tableName); err != nil {
+			return fmt.Errorf("truncate table: %w", err)
+		}
+	}
+
+	if !isSnapshot(records[len(records)-1]) {
+		if err := w.endSnapshot(ctx, tableName); err != nil {
+			return fmt.Errorf("end snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// truncateOnce truncates the table, if it wasn't truncated for the current snapshot yet. The marker of the truncated
+// table is kept in the database, so the snapshot resumed after a restart doesn't remove the rows it has written.
+// Parallel writers wait until the table is truncated, so their snapshot records aren't removed.
+func (w *Writer) truncateOnce(ctx context.Context, tableName string) error {
+	w.truncatedMu.Lock()
+	defer w.truncatedMu.Unlock()
+
+	if w.truncated[tableName] {
+		return nil
+	}
+
+	var count int
+
+	err := w.db.GetContext(ctx, &count, fmt.Sprintf(queryTruncateMarked, truncatesTable), w.destination, tableName)
+	if err != nil {
+		return fmt.Errorf("check truncate marker: %w", err)
+	}
+
+	if count > 0 {
+		sdk.Logger(ctx).Info().Str("table", tableName).Msg("table was truncated before the resumed snapshot")
+	} else {
+		sdk.Logger(ctx).Info().Str("table", tableName).Msg("truncate table before snapshot")
+
+		if _, err = w.db.ExecContext(ctx, fmt.Sprintf(queryTruncate, w.identifier(tableName))); err != nil {
+			return fmt.Errorf("exec truncate: %w", err)
+		}
+
+		_, err = w.db.ExecContext(ctx, fmt.Sprintf(queryMarkTruncate, truncatesTable), w.destination, tableName)
+		if err != nil {
+			return fmt.Errorf("mark truncate: %w", err)
+		}
+	}
+
+	w.truncated[tableName] = true
+	delete(w.snapshotEnded, tableName)
+
+	return nil
+}
+
+// endSnapshot removes the truncate marker of the table once per snapshot.
+func (w *Writer) endSnapshot(ctx context.Context, tableName string) error {
+	w.truncatedMu.Lock()
+	defer w.truncatedMu.Unlock()
+
+	if w.snapshotEnded[tableName] {
+		return nil
+	}
+
+	_, err := w.db.ExecContext(ctx, fmt.Sprintf(queryUnmarkTruncate, truncatesTable), w.destination, tableName)
+	if err != nil {
+		return fmt.Errorf("unmark truncate: %w", err)
+	}
+
+	w.snapshotEnded[tableName] = true
+	delete(w.truncated, tableName)
+
+	return nil
+}
+
+// isAlreadyExists checks whether the error is caused by a table created concurrently by another connector.
+func isAlreadyExists(err error) bool {
+	var dbErr driver.DBError
+
+	return errors.As(err, &dbErr) && dbErr.Code() == errCodeDuplicateTableName
+}
@@ -0,0 +1,143 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// markersDB returns the fake database keeping the truncate markers, like the truncates table.
+func markersDB() *fakedb.DB {
+	var (
+		mu      sync.Mutex
+		markers = make(map[string]bool)
+	)
+
+	return fakedb.New(func(query string, args []any) fakedb.Result {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(query, "SELECT COUNT(*) FROM "+truncatesTable):
+			if markers[args[1].(string)] {
+				return fakedb.Value(int64(1))
+			}
+
+			return fakedb.Value(int64(0))
+		case strings.HasPrefix(query, "UPSERT "+truncatesTable):
+			markers[args[1].(string)] = true
+		case strings.HasPrefix(query, "DELETE FROM "+truncatesTable):
+			delete(markers, args[1].(string))
+		}
+
+		return fakedb.Result{}
+	})
+}
+
+// newTruncateWriter returns the writer truncating tables on snapshot, as it's created after a restart.
+func newTruncateWriter(db *fakedb.DB) *Writer {
+	return &Writer{
+		db:                 db.Open(),
+		truncateOnSnapshot: true,
+		destination:        "destination",
+		truncated:          make(map[string]bool),
+		snapshotEnded:      make(map[string]bool),
+	}
+}
+
+// truncates returns the number of truncate statements.
+func truncates(db *fakedb.DB) int {
+	var count int
+
+	for _, query := range db.Queries() {
+		if strings.HasPrefix(query, "TRUNCATE TABLE") {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestWriter_trackSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := markersDB()
+	snapshot := opencdc.Record{Operation: opencdc.OperationSnapshot}
+	create := opencdc.Record{Operation: opencdc.OperationCreate}
+
+	w := newTruncateWriter(db)
+
+	for range 2 {
+		if err := w.trackSnapshot(ctx, "CLIENTS", snapshot); err != nil {
+			t.Fatalf("track snapshot: %v", err)
+		}
+	}
+
+	if got := truncates(db); got != 1 {
+		t.Fatalf("truncates = %d, want the table truncated once", got)
+	}
+
+	// the snapshot is resumed after a restart.
+	w = newTruncateWriter(db)
+
+	if err := w.trackSnapshot(ctx, "CLIENTS", snapshot); err != nil {
+		t.Fatalf("track snapshot: %v", err)
+	}
+
+	if got := truncates(db); got != 1 {
+		t.Fatalf("truncates = %d, the resumed snapshot must not truncate the table again", got)
+	}
+
+	// the snapshot ends with the first change.
+	if err := w.trackSnapshot(ctx, "CLIENTS", snapshot, create); err != nil {
+		t.Fatalf("track snapshot: %v", err)
+	}
+
+	// the next snapshot starts after a restart, e.g. the position was reset.
+	w = newTruncateWriter(db)
+
+	if err := w.trackSnapshot(ctx, "CLIENTS", snapshot); err != nil {
+		t.Fatalf("track snapshot: %v", err)
+	}
+
+	if got := truncates(db); got != 2 {
+		t.Fatalf("truncates = %d, a new snapshot must truncate the table", got)
+	}
+}
+
+func TestWriter_trackSnapshot_Disabled(t *testing.T) {
+	t.Parallel()
+
+	db := markersDB()
+
+	w := newTruncateWriter(db)
+	w.truncateOnSnapshot = false
+
+	err := w.trackSnapshot(context.Background(), "CLIENTS", opencdc.Record{Operation: opencdc.OperationSnapshot})
+	if err != nil {
+		t.Fatalf("track snapshot: %v", err)
+	}
+
+	if queries := db.Queries(); len(queries) != 0 {
+		t.Errorf("queries = %v, want none", queries)
+	}
+}
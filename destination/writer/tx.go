@@ -0,0 +1,90 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Begin opens the flush transaction, writes are executed in it until it's committed or rolled back.
+func (w *Writer) Begin(ctx context.Context) error {
+	tx, err := w.db.BeginTx(ctx, w.txOptions)
+	if err != nil {
+		return fmt.Errorf("begin flush transaction: %w", err)
+	}
+
+	w.flushTx = tx
+
+	return nil
+}
+
+// Commit commits the flush transaction.
+func (w *Writer) Commit(context.Context) error {
+	tx := w.flushTx
+	w.flushTx = nil
+
+	if tx == nil {
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return classify(fmt.Errorf("commit flush transaction: %w", err))
+	}
+
+	return nil
+}
+
+// Rollback rolls back the flush transaction.
+func (w *Writer) Rollback(context.Context) error {
+	tx := w.flushTx
+	w.flushTx = nil
+
+	if tx == nil {
+		return nil
+	}
+
+	if err := tx.Rollback(); err != nil {
+		return fmt.Errorf("rollback flush transaction: %w", err)
+	}
+
+	return nil
+}
+
+// inTx runs the write in a transaction, so either all its statements are applied or none of them.
+// Within the flush transaction the write is executed in it, the flush transaction is rolled back if it fails.
+func (w *Writer) inTx(ctx context.Context, write func(tx *sql.Tx) error) error {
+	if w.flushTx != nil {
+		return write(w.flushTx)
+	}
+
+	tx, err := w.db.BeginTx(ctx, w.txOptions)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	if err = write(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+)
+
+func TestWriter_inTx(t *testing.T) {
+	t.Parallel()
+
+	errWrite := errors.New("write failed")
+
+	// write executes the statements in the transaction.
+	write := func(ctx context.Context, queries ...string) func(tx *sql.Tx) error {
+		return func(tx *sql.Tx) error {
+			for _, query := range queries {
+				if _, err := tx.ExecContext(ctx, query); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, w *Writer) error
+		want []string
+	}{
+		{
+			name: "own transaction",
+			run: func(ctx context.Context, w *Writer) error {
+				return w.inTx(ctx, write(ctx, "CLOSE", "INSERT"))
+			},
+			want: []string{fakedb.Begin, "CLOSE", "INSERT", fakedb.Commit},
+		},
+		{
+			name: "committed flush transaction",
+			run: func(ctx context.Context, w *Writer) error {
+				if err := w.Begin(ctx); err != nil {
+					return err
+				}
+
+				if err := w.inTx(ctx, write(ctx, "CLOSE", "INSERT")); err != nil {
+					return err
+				}
+
+				if err := w.inTx(ctx, write(ctx, "CLOSE", "INSERT")); err != nil {
+					return err
+				}
+
+				return w.Commit(ctx)
+			},
+			want: []string{fakedb.Begin, "CLOSE", "INSERT", "CLOSE", "INSERT", fakedb.Commit},
+		},
+		{
+			name: "rolled back flush transaction",
+			run: func(ctx context.Context, w *Writer) error {
+				if err := w.Begin(ctx); err != nil {
+					return err
+				}
+
+				if err := w.inTx(ctx, write(ctx, "CLOSE", "INSERT")); err != nil {
+					return err
+				}
+
+				if err := w.inTx(ctx, write(ctx, "CLOSE", "FAIL")); !errors.Is(err, errWrite) {
+					return err
+				}
+
+				return w.Rollback(ctx)
+			},
+			want: []string{fakedb.Begin, "CLOSE", "INSERT", "CLOSE", "FAIL", fakedb.Rollback},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, _ []any) fakedb.Result {
+				if query == "FAIL" {
+					return fakedb.Result{Err: errWrite}
+				}
+
+				return fakedb.Result{}
+			})
+
+			w := &Writer{db: db.Open()}
+
+			if err := tt.run(context.Background(), w); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			if got := db.Queries(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -15,12 +15,22 @@
 package writer
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/conduitio-labs/conduit-connector-sap-hana/catalog"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
 	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/jmoiron/sqlx"
 )
@@ -28,64 +38,271 @@ import (
 const (
 	// metadata related.
 	metadataTable = "saphana.table"
+
+	// currentTimestamp is the value of the audit columns.
+	currentTimestamp = "CURRENT_UTCTIMESTAMP"
+
+	// queryTruncate truncates the table.
+	queryTruncate = "TRUNCATE TABLE %s"
+
+	// maxCachedStatements limits the number of prepared statements kept open.
+	maxCachedStatements = 100
+)
+
+const (
+	// OnConflictError fails inserts of rows with existing unique keys.
+	OnConflictError = "error"
+	// OnConflictUpdate updates the existing rows instead of inserting rows with existing unique keys.
+	OnConflictUpdate = "update"
+	// OnConflictIgnore skips inserts of rows with existing unique keys.
+	OnConflictIgnore = "ignore"
 )
 
 // Writer implements a writer logic for Sap hana destination.
 type Writer struct {
-	db          *sqlx.DB
-	table       string
-	columnTypes map[string]string
+	db    *sqlx.DB
+	table string
+	// truncate defines whether too long values are truncated instead of failing.
+	truncate bool
+	// defaults SQL literals or expressions for missing not null columns.
+	defaults map[string]string
+	// skipGeneratedAlways, skipGeneratedByDefault define which generated columns are excluded
+	// from inserts and updates.
+	skipGeneratedAlways    bool
+	skipGeneratedByDefault bool
+	// ignoreUnknownFields defines whether payload fields missing in the table are dropped instead of failing.
+	ignoreUnknownFields bool
+	// createdAtColumn column set to the current timestamp on insert.
+	createdAtColumn string
+	// updatedAtColumn column set to the current timestamp on insert and update.
+	updatedAtColumn string
+	// truncateOnSnapshot defines whether tables are truncated before the first snapshot record.
+	truncateOnSnapshot bool
+	// destination id of the connector, which keys its truncate markers.
+	destination string
+
+	// truncatedMu guards truncated and snapshotEnded, it's held while a table is truncated.
+	truncatedMu sync.Mutex
+	// truncated tables which were truncated before the current snapshot.
+	truncated map[string]bool
+	// snapshotEnded tables which truncate markers were removed after the snapshot.
+	snapshotEnded map[string]bool
+
+	// scd2 defines whether rows are kept as SCD Type 2 versions instead of being updated and deleted.
+	scd2 bool
+	// validFromColumn start of the validity period of the version.
+	validFromColumn string
+	// validToColumn end of the validity period of the version, it's null for the current version.
+	validToColumn string
+	// currentColumn flag of the current version.
+	currentColumn string
+
+	// versionColumn column compared with the version of the record on update, stale records are skipped.
+	versionColumn string
+	// staleUpdates number of skipped stale updates.
+	staleUpdates atomic.Int64
+	// missingUpdates number of versioned updates skipped, as their rows don't exist.
+	missingUpdates atomic.Int64
+
+	// tablesMu guards tables.
+	tablesMu sync.Mutex
+	// tables column metadata by table names, it's loaded on the first write to the table.
+	tables map[string]*tableMeta
+	// catalog cache of the table metadata the column metadata is built from.
+	catalog *catalog.Catalog
+
+	// stmts cache of the prepared statements of the recently executed queries.
+	stmts *stmtCache
+
+	// flattenSeparator joins names of nested object fields, nested objects aren't flattened if it's empty.
+	flattenSeparator string
+	// excludedFields payload fields dropped before building statements.
+	excludedFields map[string]bool
+	// jsonColumn column with the whole payload as a JSON document, payload fields aren't mapped to columns
+	// if it's set.
+	jsonColumn string
+	// onConflict defines what happens to inserts of rows with existing unique keys:
+	// OnConflictError, OnConflictUpdate or OnConflictIgnore.
+	onConflict string
+	// quoteIdentifiers defines whether column names of statements are quoted.
+	quoteIdentifiers bool
+	// convertOpts options of converting values to the column types.
+	convertOpts columntypes.ConvertOptions
+
+	// txOptions options of the write transactions, the default isolation level is used if it's nil.
+	txOptions *sql.TxOptions
+	// flushTx transaction all writes are executed in until it's committed, writes are committed
+	// on their own if it's nil.
+	flushTx *sql.Tx
 }
 
 // Params is an incoming params for the New function.
 type Params struct {
-	DB    *sqlx.DB
-	Table string
+	DB                       *sqlx.DB
+	Table                    string
+	TruncateOnLengthOverflow bool
+	Defaults                 map[string]string
+	// SkipGeneratedAlways excludes columns generated always from inserts and updates.
+	SkipGeneratedAlways bool
+	// SkipGeneratedByDefault excludes identity columns generated by default from inserts and updates.
+	SkipGeneratedByDefault bool
+	// IgnoreUnknownFields drops payload fields missing in the table instead of failing.
+	IgnoreUnknownFields bool
+	// CreatedAtColumn is set to the current UTC timestamp on insert and kept on update.
+	CreatedAtColumn string
+	// UpdatedAtColumn is set to the current UTC timestamp on insert and update.
+	UpdatedAtColumn string
+	// TruncateOnSnapshot truncates tables before the first snapshot record written to them.
+	TruncateOnSnapshot bool
+	// SCD2 keeps rows as SCD Type 2 versions, updates and deletes close the current version of the key.
+	SCD2            bool
+	ValidFromColumn string
+	ValidToColumn   string
+	CurrentColumn   string
+	// VersionColumn makes updates skip rows with the same or a newer version.
+	VersionColumn string
+	// FlattenSeparator flattens nested objects of payloads and keys, names of the fields are joined by it.
+	FlattenSeparator string
+	// ExcludeFields are payload fields dropped before building statements.
+	ExcludeFields []string
+	// JSONColumn writes the whole payload as a JSON document into the column, along with the key fields.
+	JSONColumn string
+	// OnConflict defines what happens to inserts of rows with existing unique keys:
+	// OnConflictError, OnConflictUpdate or OnConflictIgnore.
+	OnConflict string
+	// QuoteIdentifiers quotes column names of statements, field names are upper-cased then.
+	QuoteIdentifiers bool
+	// IsolationLevel is the isolation level of the write transactions, the default one is used if it's zero.
+	IsolationLevel sql.IsolationLevel
+	// ThousandsSeparator is removed from strings written into decimal and float columns.
+	ThousandsSeparator string
+	// Catalog is the cache of the table metadata shared with other connectors of the database,
+	// the writer has its own one if it's nil.
+	Catalog *catalog.Catalog
 }
 
 // New creates new instance of the Writer.
 func New(ctx context.Context, params Params) (*Writer, error) {
 	writer := &Writer{
-		db:    params.DB,
-		table: params.Table,
+		db:       params.DB,
+		table:    params.Table,
+		truncate: params.TruncateOnLengthOverflow,
+		defaults: params.Defaults,
+		stmts:    newStmtCache(params.DB, maxCachedStatements),
+		tables:   make(map[string]*tableMeta),
+		catalog:  params.Catalog,
+
+		ignoreUnknownFields: params.IgnoreUnknownFields,
+		createdAtColumn:     params.CreatedAtColumn,
+		updatedAtColumn:     params.UpdatedAtColumn,
+		truncateOnSnapshot:  params.TruncateOnSnapshot,
+		destination:         sdk.ConnectorIDFromContext(ctx),
+		truncated:           make(map[string]bool),
+		snapshotEnded:       make(map[string]bool),
+		scd2:                params.SCD2,
+		validFromColumn:     params.ValidFromColumn,
+		validToColumn:       params.ValidToColumn,
+		currentColumn:       params.CurrentColumn,
+		versionColumn:       params.VersionColumn,
+
+		skipGeneratedAlways:    params.SkipGeneratedAlways,
+		skipGeneratedByDefault: params.SkipGeneratedByDefault,
+
+		flattenSeparator: params.FlattenSeparator,
+		excludedFields:   make(map[string]bool, len(params.ExcludeFields)),
+		jsonColumn:       params.JSONColumn,
+		onConflict:       params.OnConflict,
+		quoteIdentifiers: params.QuoteIdentifiers,
+		convertOpts:      columntypes.ConvertOptions{ThousandsSeparator: params.ThousandsSeparator},
 	}
 
-	tableInfo, err := columntypes.GetTableInfo(ctx, writer.db, writer.table)
+	for _, field := range params.ExcludeFields {
+		writer.excludedFields[field] = true
+	}
+
+	if writer.catalog == nil {
+		writer.catalog = catalog.New(catalog.DefaultTTL)
+	}
+
+	if params.IsolationLevel != sql.LevelDefault {
+		writer.txOptions = &sql.TxOptions{Isolation: params.IsolationLevel}
+	}
+
+	meta, err := writer.tableMeta(ctx, writer.table)
 	if err != nil {
-		return nil, fmt.Errorf("get table info: %w", err)
+		return nil, err
+	}
+
+	if writer.truncateOnSnapshot {
+		if err = writer.createTruncatesTable(ctx); err != nil {
+			return nil, fmt.Errorf("create truncates table: %w", err)
+		}
 	}
 
-	writer.columnTypes = tableInfo.ColumnTypes
+	if writer.scd2 {
+		for _, column := range []string{writer.validFromColumn, writer.validToColumn, writer.currentColumn} {
+			if _, ok := meta.columnTypes[column]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrNoVersionColumn, column)
+			}
+		}
+	}
 
 	return writer, nil
 }
 
-// Close closes the underlying db connection.
-func (w *Writer) Close(context.Context) error {
-	err := w.db.Close()
-	if err != nil {
-		return fmt.Errorf("close db: %w", err)
+// Close closes the prepared statements and the underlying db connection.
+func (w *Writer) Close(ctx context.Context) error {
+	if stale := w.staleUpdates.Load(); stale > 0 {
+		sdk.Logger(ctx).Info().Int64("staleUpdates", stale).Msg("stale updates were skipped")
 	}
 
-	return nil
+	if missing := w.missingUpdates.Load(); missing > 0 {
+		sdk.Logger(ctx).Info().Int64("missingUpdates", missing).Msg("updates of missing rows were skipped")
+	}
+
+	// the db is closed even if some statements fail to close, so the connections don't leak.
+	var errs []error
+
+	if err := w.stmts.close(); err != nil {
+		errs = append(errs, fmt.Errorf("close statements: %w", err))
+	}
+
+	if err := w.db.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close db: %w", err))
+	}
+
+	return errors.Join(errs...)
 }
 
 // Delete deletes records by a key.
 func (w *Writer) Delete(ctx context.Context, record opencdc.Record) error {
 	tableName := w.getTableName(record.Metadata)
 
-	keys, err := w.structurizeData(record.Key)
+	if err := w.trackSnapshot(ctx, tableName, record); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		return w.delete(ctx, tableName, meta, record)
+	})
+
+	return classify(err)
+}
+
+// delete deletes records by a key using the column metadata of the table.
+func (w *Writer) delete(ctx context.Context, tableName string, meta *tableMeta, record opencdc.Record) error {
+	keys, err := w.recordKeys(ctx, meta, record)
 	if err != nil {
-		return fmt.Errorf("structurize key: %w", err)
+		return err
 	}
 
-	if len(keys) == 0 {
-		return ErrNoKey
+	if w.scd2 {
+		return w.closeVersion(ctx, tableName, keys)
 	}
 
 	query, args := w.buildDeleteQuery(tableName, keys)
 
-	_, err = w.db.ExecContext(ctx, query, args...)
+	err = w.exec(ctx, query, args)
 	if err != nil {
 		return fmt.Errorf("exec delete: %w", err)
 	}
@@ -97,38 +314,168 @@ func (w *Writer) Delete(ctx context.Context, record opencdc.Record) error {
 func (w *Writer) Update(ctx context.Context, record opencdc.Record) error {
 	tableName := w.getTableName(record.Metadata)
 
+	if err := w.trackSnapshot(ctx, tableName, record); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		return w.update(ctx, tableName, meta, record)
+	})
+
+	return classify(err)
+}
+
+// update updates records by a key using the column metadata of the table.
+func (w *Writer) update(ctx context.Context, tableName string, meta *tableMeta, record opencdc.Record) error {
+	if w.scd2 {
+		return w.updateVersion(ctx, tableName, meta, record)
+	}
+
+	payload, keys, err := w.updatePayload(ctx, meta, record)
+	if err != nil {
+		return err
+	}
+
+	version, versioned := w.version(payload)
+
+	query, args := w.buildUpdateQuery(tableName, keys, payload, version)
+
+	affected, err := w.execAffected(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("exec update: %w", err)
+	}
+
+	if !versioned || affected > 0 {
+		return nil
+	}
+
+	// the row has the same or a newer version, the record is out of order, unless there's no row at all.
+	exists, err := w.rowExists(ctx, tableName, keys)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		missing := w.missingUpdates.Add(1)
+
+		sdk.Logger(ctx).Debug().
+			Str("table", tableName).
+			Any("version", version).
+			Int64("missingUpdates", missing).
+			Msg("skip update of a missing row")
+
+		return nil
+	}
+
+	stale := w.staleUpdates.Add(1)
+
+	sdk.Logger(ctx).Debug().
+		Str("table", tableName).
+		Any("version", version).
+		Int64("staleUpdates", stale).
+		Msg("skip stale update")
+
+	return nil
+}
+
+// updatePayload returns the payload and the keys of the updated record, converted to the column types.
+func (w *Writer) updatePayload(
+	ctx context.Context,
+	meta *tableMeta,
+	record opencdc.Record,
+) (opencdc.StructuredData, opencdc.StructuredData, error) {
+	payload, err := w.recordPayload(record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = w.checkUnknownFields(ctx, meta, payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("check unknown fields: %w", err)
+	}
+
+	meta.removeSkippedColumns(payload)
+
+	payload, err = columntypes.ConvertStructuredData(ctx, meta.columnTypes, payload, w.convertOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert structure data: %w", err)
+	}
+
+	payload, err = columntypes.FitColumnLengths(payload, meta.columnTypes, meta.columnLengths, w.truncate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fit column lengths: %w", err)
+	}
+
+	// the creation time of the row doesn't change.
+	removeColumn(payload, w.createdAtColumn)
+	setCurrentTimestamp(payload, w.updatedAtColumn)
+
+	keys, err := w.recordKeys(ctx, meta, record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payload, keys, nil
+}
+
+// recordPayload returns the payload of the record without the excluded fields.
+// In the JSON column mode the payload is replaced with the JSON document and the key fields.
+func (w *Writer) recordPayload(record opencdc.Record) (opencdc.StructuredData, error) {
 	payload, err := w.structurizeData(record.Payload.After)
 	if err != nil {
-		return fmt.Errorf("structurize payload: %w", err)
+		return nil, fmt.Errorf("structurize payload: %w", err)
 	}
 
 	// if payload is empty return empty payload error
 	if payload == nil {
-		return ErrNoPayload
+		return nil, ErrNoPayload
 	}
 
-	payload, err = columntypes.ConvertStructuredData(ctx, w.columnTypes, payload)
-	if err != nil {
-		return fmt.Errorf("convert structure data: %w", err)
+	w.removeExcludedFields(payload)
+
+	if w.jsonColumn != "" {
+		return w.jsonPayload(record, payload)
 	}
 
+	return payload, nil
+}
+
+// recordKeys returns the key of the record converted to the column types.
+func (w *Writer) recordKeys(
+	ctx context.Context,
+	meta *tableMeta,
+	record opencdc.Record,
+) (opencdc.StructuredData, error) {
 	keys, err := w.structurizeData(record.Key)
 	if err != nil {
-		return fmt.Errorf("structurize key: %w", err)
+		return nil, fmt.Errorf("structurize key: %w", err)
 	}
 
 	if len(keys) == 0 {
-		return ErrNoKey
+		return nil, ErrNoKey
 	}
 
-	query, args := w.buildUpdateQuery(tableName, keys, payload)
-
-	_, err = w.db.ExecContext(ctx, query, args...)
+	keys, err = columntypes.ConvertStructuredData(ctx, meta.columnTypes, keys, w.convertOpts)
 	if err != nil {
-		return fmt.Errorf("exec update: %w", err)
+		return nil, fmt.Errorf("convert key: %w", err)
 	}
 
-	return nil
+	return keys, nil
+}
+
+// version returns the value of the version column from the payload.
+func (w *Writer) version(payload opencdc.StructuredData) (any, bool) {
+	if w.versionColumn == "" {
+		return nil, false
+	}
+
+	for key, value := range payload {
+		if strings.ToUpper(key) == w.versionColumn && value != nil {
+			return value, true
+		}
+	}
+
+	return nil, false
 }
 
 // gettableName returns either the records metadata value for table
@@ -146,43 +493,195 @@ func (w *Writer) getTableName(metadata map[string]string) string {
 func (w *Writer) Insert(ctx context.Context, record opencdc.Record) error {
 	tableName := w.getTableName(record.Metadata)
 
-	payload, err := w.structurizeData(record.Payload.After)
+	if err := w.trackSnapshot(ctx, tableName, record); err != nil {
+		return err
+	}
+
+	err := w.refreshOnInvalidColumn(ctx, tableName, func(meta *tableMeta) error {
+		return w.insert(ctx, tableName, meta, record)
+	})
+
+	return classify(err)
+}
+
+// insert inserts the row using the column metadata of the table.
+func (w *Writer) insert(ctx context.Context, tableName string, meta *tableMeta, record opencdc.Record) error {
+	query, args, err := w.insertQuery(ctx, tableName, meta, record)
 	if err != nil {
-		return fmt.Errorf("structurize payload: %w", err)
+		return err
 	}
 
-	// if payload is empty return empty payload error
-	if payload == nil {
-		return ErrNoPayload
+	err = w.exec(ctx, query, args)
+	if err != nil {
+		if isUniqueViolation(err) && w.onConflict != "" && w.onConflict != OnConflictError {
+			return w.resolveConflict(ctx, tableName, meta, record)
+		}
+
+		return fmt.Errorf("exec insert: %w", err)
+	}
+
+	return nil
+}
+
+// resolveConflict updates the existing row with the same unique key as the inserted record, or skips the record.
+// It makes replays of records, e.g. of the snapshot after a crash, safe.
+func (w *Writer) resolveConflict(ctx context.Context, tableName string, meta *tableMeta, record opencdc.Record) error {
+	if w.onConflict == OnConflictIgnore {
+		sdk.Logger(ctx).Debug().
+			Str("table", tableName).
+			Str("position", string(record.Position)).
+			Msg("skip insert of an existing row")
+
+		return nil
 	}
 
-	payload, err = columntypes.ConvertStructuredData(ctx, w.columnTypes, payload)
+	if err := w.update(ctx, tableName, meta, record); err != nil {
+		return fmt.Errorf("update existing row: %w", err)
+	}
+
+	return nil
+}
+
+// insertQuery builds the insert of the record using the column metadata of the table.
+func (w *Writer) insertQuery(
+	ctx context.Context,
+	tableName string,
+	meta *tableMeta,
+	record opencdc.Record,
+) (string, []any, error) {
+	payload, err := w.insertPayload(ctx, meta, record)
 	if err != nil {
-		return fmt.Errorf("convert structure data: %w", err)
+		return "", nil, err
+	}
+
+	if w.scd2 {
+		w.openVersion(payload, time.Now().UTC())
 	}
 
 	columns, values := w.extractColumnsAndValues(payload)
 
 	query, args := w.buildInsertQuery(tableName, columns, values)
 
-	_, err = w.db.ExecContext(ctx, query, args...)
+	return query, args, nil
+}
+
+// insertPayload returns the payload of the inserted record, converted to the column types,
+// with the defaults and the audit columns.
+func (w *Writer) insertPayload(
+	ctx context.Context,
+	meta *tableMeta,
+	record opencdc.Record,
+) (opencdc.StructuredData, error) {
+	payload, err := w.recordPayload(record)
 	if err != nil {
-		return fmt.Errorf("exec insert: %w", err)
+		return nil, err
+	}
+
+	err = w.checkUnknownFields(ctx, meta, payload)
+	if err != nil {
+		return nil, fmt.Errorf("check unknown fields: %w", err)
+	}
+
+	meta.removeSkippedColumns(payload)
+
+	payload, err = columntypes.ConvertStructuredData(ctx, meta.columnTypes, payload, w.convertOpts)
+	if err != nil {
+		return nil, fmt.Errorf("convert structure data: %w", err)
+	}
+
+	payload, err = columntypes.FitColumnLengths(payload, meta.columnTypes, meta.columnLengths, w.truncate)
+	if err != nil {
+		return nil, fmt.Errorf("fit column lengths: %w", err)
+	}
+
+	w.setDefaults(meta, payload)
+	setCurrentTimestamp(payload, w.createdAtColumn)
+	setCurrentTimestamp(payload, w.updatedAtColumn)
+
+	return payload, nil
+}
+
+// checkUnknownFields drops or rejects payload fields which don't exist in the table.
+func (w *Writer) checkUnknownFields(ctx context.Context, meta *tableMeta, payload opencdc.StructuredData) error {
+	for _, key := range sortedKeys(payload) {
+		if _, ok := meta.columnTypes[strings.ToUpper(key)]; ok {
+			continue
+		}
+
+		if !w.ignoreUnknownFields {
+			return fmt.Errorf("%w: %q", ErrUnknownField, key)
+		}
+
+		sdk.Logger(ctx).Debug().Str("field", key).Msg("ignore unknown field")
+
+		delete(payload, key)
 	}
 
 	return nil
 }
 
+// removeExcludedFields drops the excluded fields from the payload.
+func (w *Writer) removeExcludedFields(payload opencdc.StructuredData) {
+	for field := range w.excludedFields {
+		delete(payload, field)
+	}
+}
+
+// setDefaults adds configured default values of not null columns missing in the payload.
+// Defaults are SQL literals or expressions, so they are inlined into the query.
+func (w *Writer) setDefaults(meta *tableMeta, payload opencdc.StructuredData) {
+	if len(w.defaults) == 0 {
+		return
+	}
+
+	present := make(map[string]bool, len(payload))
+	for key := range payload {
+		present[strings.ToUpper(key)] = true
+	}
+
+	for column, value := range w.defaults {
+		if !meta.notNullColumns[column] || present[column] {
+			continue
+		}
+
+		payload[column] = sqlbuilder.Raw(value)
+	}
+}
+
+// setCurrentTimestamp sets the audit column to the current UTC timestamp, replacing the payload value.
+func setCurrentTimestamp(payload opencdc.StructuredData, column string) {
+	if column == "" {
+		return
+	}
+
+	removeColumn(payload, column)
+
+	payload[column] = sqlbuilder.Raw(currentTimestamp)
+}
+
+// removeColumn removes the column from the payload regardless of the case of the field name.
+func removeColumn(payload opencdc.StructuredData, column string) {
+	if column == "" {
+		return
+	}
+
+	for key := range payload {
+		if strings.ToUpper(key) == column {
+			delete(payload, key)
+		}
+	}
+}
+
 // buildDeleteQuery generates an SQL DELETE statement query,
 // based on the provided table, and keys.
 func (w *Writer) buildDeleteQuery(table string, keys map[string]any) (string, []any) {
 	db := sqlbuilder.NewDeleteBuilder()
 
-	db.DeleteFrom(table)
+	db.DeleteFrom(w.identifier(table))
 
-	for key, val := range keys {
+	for _, key := range sortedKeys(keys) {
 		db.Where(
-			db.Equal(key, val),
+			db.Equal(w.identifier(key), keys[key]),
 		)
 	}
 
@@ -198,10 +697,19 @@ func (w *Writer) structurizeData(data opencdc.Data) (opencdc.StructuredData, err
 	}
 
 	structuredData := make(opencdc.StructuredData)
-	if err := json.Unmarshal(data.Bytes(), &structuredData); err != nil {
+
+	// use json.Number to keep precision of big integers and decimals.
+	decoder := json.NewDecoder(bytes.NewReader(data.Bytes()))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&structuredData); err != nil {
 		return nil, fmt.Errorf("unmarshal data into structured data: %w", err)
 	}
 
+	if w.flattenSeparator != "" {
+		return flatten(structuredData, w.flattenSeparator), nil
+	}
+
 	return structuredData, nil
 }
 
@@ -213,9 +721,9 @@ func (w *Writer) extractColumnsAndValues(payload opencdc.StructuredData) ([]stri
 		values  []any
 	)
 
-	for key, value := range payload {
+	for _, key := range sortedKeys(payload) {
 		columns = append(columns, key)
-		values = append(values, value)
+		values = append(values, payload[key])
 	}
 
 	return columns, values
@@ -224,30 +732,134 @@ func (w *Writer) extractColumnsAndValues(payload opencdc.StructuredData) ([]stri
 func (w *Writer) buildInsertQuery(table string, columns []string, values []any) (string, []any) {
 	sb := sqlbuilder.NewInsertBuilder()
 
-	sb.InsertInto(table)
-	sb.Cols(columns...)
+	sb.InsertInto(w.identifier(table))
+	sb.Cols(w.identifiers(columns)...)
 	sb.Values(values...)
 
 	return sb.Build()
 }
 
-func (w *Writer) buildUpdateQuery(table string, keys, payload map[string]any) (string, []any) {
+// buildUpdateQuery generates an SQL UPDATE statement query. If the version is set,
+// only rows with an older version are updated.
+func (w *Writer) buildUpdateQuery(table string, keys, payload map[string]any, version any) (string, []any) {
 	up := sqlbuilder.NewUpdateBuilder()
 
-	up.Update(table)
+	up.Update(w.identifier(table))
 
 	setVal := make([]string, 0)
-	for key, val := range payload {
-		setVal = append(setVal, up.Assign(key, val))
+	for _, key := range sortedKeys(payload) {
+		setVal = append(setVal, up.Assign(w.identifier(key), payload[key]))
 	}
 
 	up.Set(setVal...)
 
-	for key, val := range keys {
+	for _, key := range sortedKeys(keys) {
 		up.Where(
-			up.Equal(key, val),
+			up.Equal(w.identifier(key), keys[key]),
 		)
 	}
 
+	if version != nil {
+		up.Where(up.LessThan(w.identifier(w.versionColumn), version))
+	}
+
 	return up.Build()
 }
+
+// identifier returns the table or column name as it's referenced in statements. Quoted names are upper-cased,
+// because field names are matched with the columns case-insensitively. Names which aren't plain identifiers,
+// e.g. /BIC/AZSALES00, are always quoted.
+func (w *Writer) identifier(name string) string {
+	upper := strings.ToUpper(name)
+	if !w.quoteIdentifiers && columntypes.IsPlainIdentifier(upper) {
+		return name
+	}
+
+	return columntypes.QuoteIdentifier(upper)
+}
+
+// identifiers returns the column names as they're referenced in statements.
+func (w *Writer) identifiers(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		result[i] = w.identifier(column)
+	}
+
+	return result
+}
+
+// exec executes the query using a cached prepared statement.
+// Queries are built from sorted columns, so records with the same table and column set share a statement.
+func (w *Writer) exec(ctx context.Context, query string, args []any) error {
+	_, err := w.execAffected(ctx, query, args)
+
+	return err
+}
+
+// execAffected executes the query the same as exec and returns the number of affected rows.
+func (w *Writer) execAffected(ctx context.Context, query string, args []any) (int64, error) {
+	entry, err := w.stmts.acquire(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("prepare statement: %w", err)
+	}
+
+	defer w.stmts.release(ctx, entry)
+
+	var result sql.Result
+
+	if w.flushTx != nil {
+		// the statement is bound to the transaction, it's closed when the transaction ends.
+		result, err = w.flushTx.StmtContext(ctx, entry.stmt).ExecContext(ctx, args...)
+	} else {
+		result, err = entry.stmt.ExecContext(ctx, args...)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("exec statement: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return affected, nil
+}
+
+// rowExists checks whether the table has the row with the keys, in the flush transaction if it's open.
+func (w *Writer) rowExists(ctx context.Context, table string, keys map[string]any) (bool, error) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select("COUNT(*)").From(w.identifier(table))
+
+	for _, key := range sortedKeys(keys) {
+		sb.Where(sb.Equal(w.identifier(key), keys[key]))
+	}
+
+	query, args := sb.Build()
+
+	var row *sql.Row
+	if w.flushTx != nil {
+		row = w.flushTx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = w.db.QueryRowContext(ctx, query, args...)
+	}
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return false, fmt.Errorf("count rows: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// sortedKeys returns the map keys in a sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,297 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+func TestWriter_buildUpdateQuery_QuoteIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{quoteIdentifiers: true}
+
+	query, args := w.buildUpdateQuery("ORDERS", map[string]any{"id": 1}, map[string]any{"order": 2, "/bic/zamount": 3}, nil)
+
+	want := `UPDATE "ORDERS" SET "/BIC/ZAMOUNT" = ?, "ORDER" = ? WHERE "ID" = ?`
+	if query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{3, 2, 1}) {
+		t.Errorf("args = %v, want [3 2 1]", args)
+	}
+}
+
+func TestWriter_buildUpdateQuery_Version(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{versionColumn: "VERSION"}
+
+	query, args := w.buildUpdateQuery("ORDERS", map[string]any{"id": 1}, map[string]any{"total": 2, "version": 3}, 3)
+
+	want := "UPDATE ORDERS SET total = ?, version = ? WHERE id = ? AND VERSION < ?"
+	if query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{2, 3, 1, 3}) {
+		t.Errorf("args = %v, want [2 3 1 3]", args)
+	}
+}
+
+func TestWriter_update_Version(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		payload     opencdc.StructuredData
+		affected    int64
+		rows        int64
+		wantStale   int64
+		wantMissing int64
+	}{
+		{
+			name:     "newer version",
+			payload:  opencdc.StructuredData{"total": 2, "version": 3},
+			affected: 1,
+		},
+		{
+			name:      "stale version",
+			payload:   opencdc.StructuredData{"total": 2, "version": 3},
+			rows:      1,
+			wantStale: 1,
+		},
+		{
+			name:        "missing row",
+			payload:     opencdc.StructuredData{"total": 2, "version": 3},
+			wantMissing: 1,
+		},
+		{
+			name:    "record without version",
+			payload: opencdc.StructuredData{"total": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, _ []any) fakedb.Result {
+				if strings.HasPrefix(query, "SELECT COUNT(*)") {
+					return fakedb.Value(tt.rows)
+				}
+
+				return fakedb.Result{RowsAffected: tt.affected}
+			})
+			sqlDB := db.Open()
+
+			w := &Writer{db: sqlDB, stmts: newStmtCache(sqlDB, maxCachedStatements), versionColumn: "VERSION"}
+			meta := &tableMeta{columnTypes: map[string]string{"ID": "INTEGER", "TOTAL": "INTEGER", "VERSION": "INTEGER"}}
+
+			record := opencdc.Record{
+				Operation: opencdc.OperationUpdate,
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload:   opencdc.Change{After: tt.payload},
+			}
+
+			if err := w.update(context.Background(), "ORDERS", meta, record); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+
+			if got := w.staleUpdates.Load(); got != tt.wantStale {
+				t.Errorf("stale updates = %d, want %d", got, tt.wantStale)
+			}
+
+			if got := w.missingUpdates.Load(); got != tt.wantMissing {
+				t.Errorf("missing updates = %d, want %d", got, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestWriter_identifier(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{}
+
+	if got := w.identifier("id"); got != "id" {
+		t.Errorf("identifier(id) = %s, want id", got)
+	}
+
+	if got, want := w.identifier("/bic/azsales00"), `"/BIC/AZSALES00"`; got != want {
+		t.Errorf("identifier(/bic/azsales00) = %s, want %s", got, want)
+	}
+}
+
+func TestWriter_setDefaults(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{defaults: map[string]string{
+		"STATUS":   "'active'",
+		"REGION":   "'eu'",
+		"COMMENT":  "'none'",
+		"SEQUENCE": "ORDERS_SEQ.NEXTVAL",
+	}}
+	meta := &tableMeta{notNullColumns: map[string]bool{"STATUS": true, "REGION": true, "SEQUENCE": true}}
+
+	// region is set by the record, the comment column is nullable.
+	payload := opencdc.StructuredData{"id": 1, "region": "us"}
+	w.setDefaults(meta, payload)
+
+	want := opencdc.StructuredData{
+		"id":       1,
+		"region":   "us",
+		"STATUS":   sqlbuilder.Raw("'active'"),
+		"SEQUENCE": sqlbuilder.Raw("ORDERS_SEQ.NEXTVAL"),
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+
+	// defaults are SQL expressions, so they are inlined into the query instead of being bound.
+	columns, values := w.extractColumnsAndValues(payload)
+	query, args := w.buildInsertQuery("ORDERS", columns, values)
+
+	wantQuery := "INSERT INTO ORDERS (SEQUENCE, STATUS, id, region) VALUES (ORDERS_SEQ.NEXTVAL, 'active', ?, ?)"
+	if query != wantQuery {
+		t.Errorf("query = %s, want %s", query, wantQuery)
+	}
+
+	if !reflect.DeepEqual(args, []any{1, "us"}) {
+		t.Errorf("args = %v, want [1 us]", args)
+	}
+}
+
+func TestWriter_checkUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	meta := &tableMeta{columnTypes: map[string]string{"ID": "INTEGER", "NAME": "NVARCHAR"}}
+
+	tests := []struct {
+		name    string
+		ignore  bool
+		payload opencdc.StructuredData
+		want    opencdc.StructuredData
+		wantErr error
+	}{
+		{
+			name:    "known fields of any case",
+			payload: opencdc.StructuredData{"id": 1, "NAME": "john"},
+			want:    opencdc.StructuredData{"id": 1, "NAME": "john"},
+		},
+		{
+			name:    "unknown field",
+			payload: opencdc.StructuredData{"id": 1, "email": "john@example.com"},
+			want:    opencdc.StructuredData{"id": 1, "email": "john@example.com"},
+			wantErr: ErrUnknownField,
+		},
+		{
+			name:    "ignored unknown field",
+			ignore:  true,
+			payload: opencdc.StructuredData{"id": 1, "email": "john@example.com"},
+			want:    opencdc.StructuredData{"id": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := &Writer{ignoreUnknownFields: tt.ignore}
+
+			err := w.checkUnknownFields(context.Background(), meta, tt.payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkUnknownFields() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(tt.payload, tt.want) {
+				t.Errorf("payload = %v, want %v", tt.payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCurrentTimestamp(t *testing.T) {
+	t.Parallel()
+
+	// the value of the record is replaced regardless of the case of the field name.
+	payload := opencdc.StructuredData{"id": 1, "updated_at": "2023-01-02 03:04:05"}
+	setCurrentTimestamp(payload, "UPDATED_AT")
+
+	want := opencdc.StructuredData{"id": 1, "UPDATED_AT": sqlbuilder.Raw(currentTimestamp)}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+
+	setCurrentTimestamp(payload, "")
+
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, the audit column isn't configured", payload)
+	}
+}
+
+func TestWriter_AuditColumns(t *testing.T) {
+	t.Parallel()
+
+	db := fakedb.New(func(string, []any) fakedb.Result { return fakedb.Result{RowsAffected: 1} })
+	sqlDB := db.Open()
+
+	w := &Writer{
+		db:              sqlDB,
+		stmts:           newStmtCache(sqlDB, maxCachedStatements),
+		createdAtColumn: "CREATED_AT",
+		updatedAtColumn: "UPDATED_AT",
+	}
+	meta := &tableMeta{columnTypes: map[string]string{"ID": "INTEGER", "CREATED_AT": "TIMESTAMP"}}
+
+	// the record carries the creation time of the source row.
+	record := opencdc.Record{
+		Key: opencdc.StructuredData{"id": 1},
+		Payload: opencdc.Change{After: opencdc.StructuredData{
+			"id":         1,
+			"created_at": time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	}
+
+	ctx := context.Background()
+
+	if err := w.insert(ctx, "ORDERS", meta, record); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	if err := w.update(ctx, "ORDERS", meta, record); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	// the creation time is set on insert only, the update keeps it.
+	want := []string{
+		"INSERT INTO ORDERS (CREATED_AT, UPDATED_AT, id) VALUES (CURRENT_UTCTIMESTAMP, CURRENT_UTCTIMESTAMP, ?)",
+		"UPDATE ORDERS SET UPDATED_AT = CURRENT_UTCTIMESTAMP, id = ? WHERE id = ?",
+	}
+
+	if got := db.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("queries = %q, want %q", got, want)
+	}
+}
@@ -10,6 +10,7 @@ require (
 	github.com/golangci/golangci-lint v1.63.4
 	github.com/huandu/go-sqlbuilder v1.33.1
 	github.com/jmoiron/sqlx v1.4.0
+	github.com/jpillora/backoff v1.0.0
 	github.com/matryer/is v1.4.1
 	go.uber.org/goleak v1.3.0
 	go.uber.org/mock v0.5.0
@@ -107,7 +108,6 @@ require (
 	github.com/jgautheron/goconst v1.7.1 // indirect
 	github.com/jingyugao/rowserrcheck v1.1.1 // indirect
 	github.com/jjti/go-spancheck v0.6.4 // indirect
-	github.com/jpillora/backoff v1.0.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/julz/importas v0.2.0 // indirect
 	github.com/karamaru-alpha/copyloopvar v1.1.0 // indirect
@@ -15,8 +15,11 @@
 package helper
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/SAP/go-hdb/driver"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
@@ -27,32 +30,141 @@ const (
 	driverName = "hdb"
 )
 
-// ConnectToDB - connect to Sap Hana db.
-func ConnectToDB(c config.AuthConfig) (*sqlx.DB, error) {
+// Option configures the SAP HANA connector before opening the db.
+type Option func(c *driver.Connector)
+
+// WithDefaultSchema sets the schema used for unqualified object names.
+func WithDefaultSchema(schema string) Option {
+	return func(c *driver.Connector) {
+		if schema != "" {
+			c.SetDefaultSchema(schema)
+		}
+	}
+}
+
+// WithFetchSize sets the number of rows fetched from the database at once, zero keeps the driver default.
+func WithFetchSize(fetchSize int) Option {
+	return func(c *driver.Connector) {
+		if fetchSize > 0 {
+			c.SetFetchSize(fetchSize)
+		}
+	}
+}
+
+// WithKeepalive sets the interval of pinging the db in the background, zero disables it.
+// Idle connections are pinged before reuse after the interval, and broken ones are replaced by new connections.
+func WithKeepalive(interval time.Duration) Option {
+	return func(c *driver.Connector) {
+		if interval > 0 {
+			c.SetPingInterval(interval)
+		}
+	}
+}
+
+// ConnectToDB - connect to Sap Hana db. Secrets of the auth config are redacted from the returned error.
+// If the connector has the ping interval, the db is pinged in the background until it's closed.
+func ConnectToDB(c config.AuthConfig, opts ...Option) (*sqlx.DB, error) {
+	con, err := newConnector(c)
+	if err != nil {
+		return nil, RedactError(err, c)
+	}
+
+	for _, opt := range opts {
+		opt(con)
+	}
+
+	if con.PingInterval() <= 0 {
+		return sqlx.NewDb(sql.OpenDB(con), driverName), nil
+	}
+
+	keepalive := &keepaliveConnector{Connector: con, stop: make(chan struct{})}
+	db := sql.OpenDB(keepalive)
+
+	go keepalive.run(db, con.PingInterval())
+
+	return sqlx.NewDb(db, driverName), nil
+}
+
+// keepaliveConnector pings the db in the background, so its connections aren't dropped while idle.
+// The db closes the connector, when it's closed, which stops pinging.
+type keepaliveConnector struct {
+	*driver.Connector
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// run pings the db every interval until the connector is closed. Errors are ignored, a failed ping
+// discards the broken connection, so the next query gets a new one.
+func (c *keepaliveConnector) run(db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			db.PingContext(ctx) //nolint:errcheck // see the comment above
+			cancel()
+		}
+	}
+}
+
+// Close stops pinging, it's called by the db when it's closed.
+func (c *keepaliveConnector) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	return nil
+}
+
+// newConnector creates a connector for the auth mechanism and the tenant database.
+func newConnector(c config.AuthConfig) (*driver.Connector, error) {
+	con, err := newAuthConnector(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// the host is the system database, which redirects connections to the tenant database.
+	if c.DatabaseName != "" {
+		con = con.WithDatabase(c.DatabaseName)
+	}
+
+	return con, nil
+}
+
+// newAuthConnector creates a connector for the auth mechanism.
+func newAuthConnector(c config.AuthConfig) (*driver.Connector, error) {
 	switch c.Mechanism {
 	case config.DSNAuthType:
-		db, err := sqlx.Open(driverName, c.DSN)
+		con, err := driver.NewDSNConnector(c.DSN)
 		if err != nil {
 			return nil, fmt.Errorf("open db, DSN auth: %w", err)
 		}
 
-		return db, nil
+		return con, nil
 	case config.BasicAuthType:
-		con := driver.NewBasicAuthConnector(c.Host, c.Username, c.Password)
-
-		return sqlx.NewDb(sql.OpenDB(con), driverName), nil
+		return withOptions(driver.NewBasicAuthConnector(c.Host, c.Username, c.Password), c.Options)
 	case config.JWTAuthType:
-		con := driver.NewJWTAuthConnector(c.Host, c.Token)
-
-		return sqlx.NewDb(sql.OpenDB(con), driverName), nil
+		return withOptions(driver.NewJWTAuthConnector(c.Host, c.Token), c.Options)
 	case config.X509AuthType:
 		con, err := driver.NewX509AuthConnectorByFiles(c.Host, c.ClientCertFilePath, c.ClientKeyFilePath)
 		if err != nil {
 			return nil, fmt.Errorf("new X509 auth: %w", err)
 		}
 
-		return sqlx.NewDb(sql.OpenDB(con), driverName), nil
+		return withOptions(con, c.Options)
 	default:
 		return nil, fmt.Errorf("invalid auth mechanism :%s", c.Mechanism)
 	}
 }
+
+// withOptions applies the auth options to the connector.
+func withOptions(con *driver.Connector, options map[string]string) (*driver.Connector, error) {
+	if err := applyOptions(con, options); err != nil {
+		return nil, fmt.Errorf("apply auth options: %w", err)
+	}
+
+	return con, nil
+}
@@ -0,0 +1,43 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInstanceName(t *testing.T) {
+	t.Parallel()
+
+	first, err := InstanceName()
+	if err != nil {
+		t.Fatalf("instance name: %v", err)
+	}
+
+	second, err := InstanceName()
+	if err != nil {
+		t.Fatalf("instance name: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("names are equal: %s", first)
+	}
+
+	if host, er := os.Hostname(); er == nil && !strings.HasPrefix(first, host+"-") {
+		t.Errorf("name %s doesn't start with the host name %s", first, host)
+	}
+}
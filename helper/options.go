@@ -0,0 +1,92 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// ErrUnknownOption occurs when the auth options have an option not supported by the connector.
+var ErrUnknownOption = errors.New("unknown auth option")
+
+// applyOptions sets the go-hdb DSN options to the connector.
+// Options are applied in the order of their names, so errors are reproducible.
+func applyOptions(con *driver.Connector, options map[string]string) error {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	tlsPrms := driver.TLSPrms{}
+	withTLS := false
+
+	for _, name := range names {
+		value := options[name]
+
+		switch name {
+		case driver.DSNDefaultSchema:
+			con.SetDefaultSchema(value)
+		case driver.DSNTimeout:
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", name, err)
+			}
+
+			con.SetTimeout(time.Duration(seconds) * time.Second)
+		case driver.DSNPingInterval:
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", name, err)
+			}
+
+			con.SetPingInterval(time.Duration(seconds) * time.Second)
+		case driver.DSNTLSServerName:
+			tlsPrms.ServerName, withTLS = value, true
+		case driver.DSNTLSInsecureSkipVerify:
+			skip, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", name, err)
+			}
+
+			tlsPrms.InsecureSkipVerify, withTLS = skip, true
+		case driver.DSNTLSRootCAFile:
+			// several files are separated by commas.
+			for _, file := range strings.Split(value, ",") {
+				tlsPrms.RootCAFiles = append(tlsPrms.RootCAFiles, strings.TrimSpace(file))
+			}
+
+			withTLS = true
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownOption, name)
+		}
+	}
+
+	if withTLS {
+		if err := con.SetTLS(tlsPrms.ServerName, tlsPrms.InsecureSkipVerify, tlsPrms.RootCAFiles...); err != nil {
+			return fmt.Errorf("set tls: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+func TestApplyOptions(t *testing.T) {
+	t.Parallel()
+
+	con := driver.NewBasicAuthConnector("host:443", "user", "password")
+
+	err := applyOptions(con, map[string]string{
+		"timeout":       "30",
+		"defaultSchema": "SALES",
+		"TLSServerName": "name",
+	})
+	if err != nil {
+		t.Fatalf("apply options: %v", err)
+	}
+
+	if con.Timeout() != 30*time.Second {
+		t.Errorf("timeout = %s, want 30s", con.Timeout())
+	}
+
+	if con.DefaultSchema() != "SALES" {
+		t.Errorf("default schema = %s, want SALES", con.DefaultSchema())
+	}
+
+	if con.TLSConfig() == nil || con.TLSConfig().ServerName != "name" {
+		t.Errorf("tls server name isn't set")
+	}
+
+	err = applyOptions(con, map[string]string{"databaseName": "HXE"})
+	if !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("error = %v, want %v", err, ErrUnknownOption)
+	}
+}
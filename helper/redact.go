@@ -0,0 +1,93 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
+)
+
+// redacted replaces secrets, the same as in [url.URL.Redacted].
+const redacted = "xxxxx"
+
+// redactedError is an error with secrets removed from its message, the original error is still unwrapped.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// RedactError removes the DSN, passwords and tokens of the auth config from the error message.
+func RedactError(err error, auth config.AuthConfig) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	for _, secret := range secrets(auth) {
+		msg = strings.ReplaceAll(msg, secret, RedactDSN(secret))
+	}
+
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{err: err, msg: msg}
+}
+
+// RedactDSN replaces the password of the DSN.
+// If the DSN can't be parsed, the whole value is replaced.
+func RedactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" || u.Opaque != "" {
+		return redacted
+	}
+
+	return u.Redacted()
+}
+
+// secrets returns sensitive values of the auth config,
+// the DSN goes first, so it's replaced before the password it contains.
+func secrets(auth config.AuthConfig) []string {
+	var result []string
+
+	if auth.DSN != "" {
+		result = append(result, auth.DSN)
+
+		// the password can be percent-encoded in the DSN.
+		if u, err := url.Parse(auth.DSN); err == nil && u.User != nil {
+			if password, ok := u.User.Password(); ok && password != "" {
+				result = append(result, password)
+			}
+		}
+	}
+
+	for _, secret := range []string{auth.Password, auth.Token} {
+		if secret != "" {
+			result = append(result, secret)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,303 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakedb implements an in-memory database/sql driver for unit tests. It records the statements
+// and answers them with a handler, so code running queries can be tested without SAP HANA.
+package fakedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Statements of transactions recorded next to the executed ones.
+const (
+	Begin    = "BEGIN"
+	Commit   = "COMMIT"
+	Rollback = "ROLLBACK"
+)
+
+// Result is the answer of the database to a statement.
+type Result struct {
+	// Columns and Rows are returned by queries.
+	Columns []string
+	Rows    [][]driver.Value
+	// RowsAffected is returned by executed statements.
+	RowsAffected int64
+	// Err fails the statement.
+	Err error
+	// Wait makes the statement run until the channel is closed, the statement fails when its context is done.
+	Wait <-chan struct{}
+}
+
+// Value returns the result of a query selecting the single value.
+func Value(value any) Result {
+	return Result{Columns: []string{"VALUE"}, Rows: [][]driver.Value{{value}}}
+}
+
+// Statement is a recorded statement. Begin statements have the isolation level as the argument.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Handler answers the statement with the arguments. Statements of transactions aren't passed to it.
+type Handler func(query string, args []any) Result
+
+// DB is the fake database.
+type DB struct {
+	handler Handler
+
+	mu         sync.Mutex
+	statements []Statement
+	prepared   int
+	closed     int
+}
+
+// New creates the fake database answering statements with the handler,
+// statements succeed without rows if it's nil.
+func New(handler Handler) *DB {
+	if handler == nil {
+		handler = func(string, []any) Result { return Result{} }
+	}
+
+	return &DB{handler: handler}
+}
+
+// Open returns a new connection pool of the database.
+func (d *DB) Open() *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(connector{db: d}), "hdb")
+}
+
+// Statements returns the recorded statements in the order they were run.
+func (d *DB) Statements() []Statement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Statement(nil), d.statements...)
+}
+
+// Queries returns the queries of the recorded statements.
+func (d *DB) Queries() []string {
+	statements := d.Statements()
+
+	queries := make([]string, len(statements))
+	for i, statement := range statements {
+		queries[i] = statement.Query
+	}
+
+	return queries
+}
+
+// Prepared returns the number of prepared statements.
+func (d *DB) Prepared() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.prepared
+}
+
+// Closed returns the number of closed prepared statements.
+func (d *DB) Closed() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.closed
+}
+
+// run records the statement and answers it.
+func (d *DB) run(ctx context.Context, query string, args []driver.NamedValue) Result {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	d.mu.Lock()
+	d.statements = append(d.statements, Statement{Query: query, Args: values})
+	d.mu.Unlock()
+
+	result := d.handler(query, values)
+	if result.Wait == nil {
+		return result
+	}
+
+	select {
+	case <-result.Wait:
+		return result
+	case <-ctx.Done():
+		return Result{Err: ctx.Err()}
+	}
+}
+
+// record records the statement of a transaction.
+func (d *DB) record(query string, args ...any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.statements = append(d.statements, Statement{Query: query, Args: args})
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{db: c.db} }
+
+type fakeDriver struct {
+	db *DB
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return &conn{db: d.db}, nil }
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.prepared++
+
+	return &stmt{db: c.db, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.record(Begin, sql.IsolationLevel(opts.Isolation).String())
+
+	return tx{db: c.db}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return execResult(c.db.run(ctx, query, args))
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return queryResult(c.db.run(ctx, query, args))
+}
+
+// CheckNamedValue passes arguments of any type to the handler as they are.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error {
+	t.db.record(Commit)
+
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.db.record(Rollback)
+
+	return nil
+}
+
+type stmt struct {
+	db    *DB
+	query string
+}
+
+func (s *stmt) Close() error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.closed++
+
+	return nil
+}
+
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return execResult(s.db.run(context.Background(), s.query, named(args)))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return queryResult(s.db.run(context.Background(), s.query, named(args)))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return execResult(s.db.run(ctx, s.query, args))
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return queryResult(s.db.run(ctx, s.query, args))
+}
+
+// CheckNamedValue passes arguments of any type to the handler as they are.
+func (s *stmt) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func named(args []driver.Value) []driver.NamedValue {
+	result := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		result[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return result
+}
+
+func execResult(result Result) (driver.Result, error) {
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+func queryResult(result Result) (driver.Rows, error) {
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return &rows{columns: result.Columns, values: result.Rows}, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	if len(r.values[0]) != len(dest) {
+		return fmt.Errorf("fakedb: row has %d values, want %d", len(r.values[0]), len(dest))
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+
+	return nil
+}
@@ -15,6 +15,8 @@
 package source
 
 import (
+	"time"
+
 	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
 )
 
@@ -22,12 +24,261 @@ import (
 type Config struct {
 	config.Config
 
+	// Schema is a name of the schema, all tables of which the connector should read from instead of the single table.
+	Schema string `json:"schema"`
 	// OrderingColumn is a name of a column that the connector will use for ordering rows.
-	OrderingColumn string `json:"orderingColumn" validate:"required"`
+	// It's required for the single table. In the schema mode tables without this column are ordered
+	// by their single column primary key.
+	OrderingColumn string `json:"orderingColumn"`
 	// BatchSize is a size of rows batch.
 	BatchSize int `json:"batchSize" default:"1000" validate:"gt=0,lt=10001"`
-	// PrimaryKeys list of column names should use for their `Key` fields.
+	// PrimaryKeys list of column names should use for their `Key` fields. It's ignored in the schema mode.
 	PrimaryKeys []string `json:"primaryKeys"`
 	// Snapshot whether or not the plugin will take a snapshot of the entire table before starting cdc.
 	Snapshot bool `json:"snapshot" default:"true"`
+	// SnapshotFunction is a name of a table function the snapshot reads from instead of the table.
+	// The function result is paginated by the ordering column, as the table is.
+	SnapshotFunction string `json:"snapshot.function"`
+	// SnapshotProcedure is a name of a procedure the snapshot reads the first result set of instead of the table.
+	// The result is read with a single call, so the snapshot restarts from the beginning after a restart.
+	SnapshotProcedure string `json:"snapshot.procedure"`
+	// SnapshotCursor makes the snapshot read the table through a single cursor, which is fetched in batches,
+	// instead of a query per batch.
+	SnapshotCursor bool `json:"snapshot.cursor" default:"false"`
+	// SnapshotIsolation is the isolation level of the transaction the snapshot is read in.
+	// Valid values: readCommitted, repeatableRead, serializable. The snapshot isn't read in a transaction by default.
+	SnapshotIsolation string `json:"snapshot.isolationLevel" validate:"inclusion=readCommitted|repeatableRead|serializable"` //nolint:lll // struct tags can't be wrapped
+	// SnapshotMaxValueRefreshInterval is the interval of refreshing the max value of the ordering column during
+	// the snapshot, so rows inserted after the snapshot started are read by it. Zero disables it.
+	SnapshotMaxValueRefreshInterval time.Duration `json:"snapshot.maxValueRefreshInterval" default:"0"`
+	// SnapshotMaxDuration is the maximum time of reading the snapshot, after it the connector switches to CDC.
+	// Zero disables it.
+	SnapshotMaxDuration time.Duration `json:"snapshot.maxDuration" default:"0"`
+	// SnapshotOnMaxDuration defines what happens to the rest of the snapshot after its max duration.
+	// Valid values: resume - it's read in batches whenever CDC has no changes, abandon - it isn't read.
+	SnapshotOnMaxDuration string `json:"snapshot.onMaxDuration" default:"resume" validate:"inclusion=resume|abandon"`
+	// SnapshotLimit is the maximum number of records of the snapshot, after them the connector switches to CDC,
+	// the rest of the snapshot isn't read. In the schema mode it limits the snapshot of every table. Zero disables it.
+	SnapshotLimit int `json:"snapshot.limit" default:"0" validate:"gt=-1"`
+	// SnapshotReadReplicaHost is the host and port of the read-enabled secondary system of the system replication,
+	// the snapshot is read from it, while CDC stays on the primary system.
+	SnapshotReadReplicaHost string `json:"snapshot.readReplicaHost"`
+	// SnapshotArguments is a list of values bound to the parameters of the snapshot function or procedure.
+	SnapshotArguments []string `json:"snapshot.arguments"`
+	// SnapshotRangeStart is the lowest value of the ordering column the snapshot reads, e.g. 2024-01-01,
+	// so a part of the table is backfilled again without the full snapshot. It's inclusive.
+	SnapshotRangeStart string `json:"snapshot.rangeStart"`
+	// SnapshotRangeEnd is the highest value of the ordering column the snapshot reads. It's inclusive.
+	SnapshotRangeEnd string `json:"snapshot.rangeEnd"`
+	// SnapshotTieBreakerColumn is a name of a unique column, which orders rows sharing a value of the ordering column,
+	// e.g. the key of a table ordered by a DATE or SECONDDATE column, so none of them are skipped on resume.
+	SnapshotTieBreakerColumn string `json:"snapshot.tieBreakerColumn"`
+	// TimeFormat defines how time values are represented in records.
+	// Valid values: rfc3339, unixMillis - epoch milliseconds, date - DATE columns without time, e.g. 2018-01-01.
+	TimeFormat string `json:"timeFormat" default:"rfc3339" validate:"inclusion=rfc3339|unixMillis|date"`
+	// QueryHints are hints added as WITH HINT(...) to the snapshot and CDC select queries,
+	// e.g. NO_USE_OLAP_PLAN, RESULT_LAG('hana_sr', 30).
+	QueryHints string `json:"queryHints"`
+	// CDCMode defines how changes are captured. Valid values: trigger - triggers on the table,
+	// sdi - the Smart Data Integration remote subscription on the table, which has to be a virtual table.
+	CDCMode string `json:"cdcMode" default:"trigger" validate:"inclusion=trigger|sdi"`
+	// KeyCase defines the case of field names of keys and payloads.
+	// Valid values: preserve - column names of the table, usually upper case, upper, lower.
+	KeyCase string `json:"keyCase" default:"preserve" validate:"inclusion=upper|preserve|lower"`
+	// BooleanColumns is a list of integer columns, usually TINYINT, which hold logical booleans,
+	// their values are represented as true or false in records.
+	BooleanColumns []string `json:"booleanColumns"`
+	// DebugMetadata adds the id, the operation and the capture time of the tracking row into the metadata
+	// of CDC records, so a record can be traced back to its tracking table entry.
+	DebugMetadata bool `json:"debugMetadata" default:"false"`
+	// SchemaCheckInterval is the interval of checking the table columns for changes, 0 disables the check.
+	SchemaCheckInterval time.Duration `json:"schemaCheckInterval" default:"1m"`
+	// OnOrphanTrackingTable defines what to do with the tracking table left by the previous runs of the connector,
+	// when there is no position. Valid values: adopt - read changes from the existing tracking table,
+	// recreate - drop it and create a new one, fail - stop with an error.
+	OnOrphanTrackingTable string `json:"onOrphanTrackingTable" default:"adopt" validate:"inclusion=adopt|recreate|fail"`
+	// OnTableRecreate defines what to do when the table was dropped and created again since the position was saved.
+	// Valid values: fail - stop with an error, resnapshot - drop the tracking table and read the table
+	// from the beginning.
+	OnTableRecreate string `json:"onTableRecreate" default:"fail" validate:"inclusion=fail|resnapshot"`
+
+	CDC CDCConfig `json:"cdc"`
+
+	Tables TablesConfig `json:"tables"`
+
+	History HistoryConfig `json:"history"`
+
+	Masking MaskingConfig `json:"masking"`
+
+	Nulls NullsConfig `json:"nulls"`
+
+	Collection CollectionConfig `json:"collection"`
+
+	Retry RetryConfig `json:"retry"`
+
+	Metadata MetadataConfig `json:"metadata"`
+
+	HA HAConfig `json:"ha"`
+
+	PollBackoff PollBackoffConfig `json:"pollBackoff"`
+}
+
+// PollBackoffConfig holds configurable values of pacing reads when there are no new records.
+type PollBackoffConfig struct {
+	// Min is the delay of the first read without new records, it's doubled for every next one up to Max,
+	// and reset by a new record. Zero leaves the pacing to Conduit.
+	Min time.Duration `json:"min" default:"0"`
+	// Max is the maximum delay of reads without new records.
+	Max time.Duration `json:"max" default:"30s"`
+}
+
+// HAConfig holds configurable values of coordinating instances of the connector running the same pipeline.
+type HAConfig struct {
+	// LockName is a name of the lock shared by the instances of the pipeline, the instance holding it reads
+	// records, and the other ones stand by until its lease expires. Instances don't coordinate if it's empty.
+	LockName string `json:"lockName"`
+	// LeaseDuration is the time the lock is held without renewing it, a standby instance takes over after it.
+	LeaseDuration time.Duration `json:"leaseDuration" default:"30s"`
+}
+
+// MetadataConfig holds configurable values of enriching the metadata of every record.
+type MetadataConfig struct {
+	// Schema adds the name of the schema of the table into the saphana.schema metadata field.
+	Schema bool `json:"schema" default:"false"`
+	// Database adds the name of the database, the tenant database of a multitenant system,
+	// into the saphana.database metadata field.
+	Database bool `json:"database" default:"false"`
+	// Host adds the host and port of the database into the saphana.host metadata field.
+	Host bool `json:"host" default:"false"`
+	// ConnectorVersion adds the version of the connector into the saphana.connectorVersion metadata field.
+	ConnectorVersion bool `json:"connectorVersion" default:"false"`
+	// ColumnTypes adds the JSON object of column types of the table with their lengths, precisions and scales,
+	// e.g. DECIMAL(10,2), NVARCHAR(40), into the saphana.columnTypes metadata field.
+	ColumnTypes bool `json:"columnTypes" default:"false"`
+	// Checksum adds the SHA-256 hash of the JSON of the payload with sorted field names into the saphana.checksum
+	// metadata field, so reconciliation jobs can compare rows of the source and the target by their keys.
+	Checksum bool `json:"checksum" default:"false"`
+	// Lag adds the replication lag of CDC records in milliseconds into the saphana.lag metadata field.
+	// The tracking table gets the CONDUIT_CHANGED_AT column with the time of changes, if it's set.
+	Lag bool `json:"lag" default:"false"`
+	// Prefix is the prefix of the metadata fields of the connector, e.g. erp. makes it erp.table instead of
+	// saphana.table.
+	Prefix string `json:"prefix" default:"saphana."`
+	// Static is a map of metadata fields with constant values added to every record as they are,
+	// e.g. environment or the system ID, which downstream processors route records by.
+	Static map[string]string `json:"static"`
+}
+
+// RetryConfig holds configurable values of retrying reads failed by transient database errors.
+type RetryConfig struct {
+	// Max is the maximum number of retries of a failed read, zero disables retries.
+	Max int `json:"max" default:"3" validate:"gt=-1"`
+	// Backoff is the delay before the first retry, it's doubled for every next retry.
+	Backoff time.Duration `json:"backoff" default:"1s"`
+}
+
+// CollectionConfig holds configurable values of reading a collection of the HANA JSON Document Store.
+type CollectionConfig struct {
+	// Name is a name of the collection the connector should read from instead of the table or schema.
+	Name string `json:"name"`
+	// OrderingField is a name of a top-level document field that the connector will use for ordering documents.
+	// Field names are case-sensitive.
+	OrderingField string `json:"orderingField" default:"_id"`
+}
+
+// MaskingConfig holds configurable values of masking column values before records are emitted.
+type MaskingConfig struct {
+	// Columns is a map of column names to masking strategies: null, hash, fixed.
+	Columns map[string]string `json:"columns"`
+	// FixedValue is the value of columns masked with the fixed strategy.
+	FixedValue string `json:"fixedValue" default:"****"`
+	// HashSalt is the key of HMAC-SHA256 for columns masked with the hash strategy, plain SHA-256 is used if it's empty.
+	HashSalt string `json:"hashSalt"`
+}
+
+// NullsConfig holds configurable values of representing NULL column values in payloads of records.
+type NullsConfig struct {
+	// Mode defines how NULL column values are represented. Valid values: include - explicit nulls,
+	// omit - the fields are left out, default - the defaults of the columns replace nulls.
+	Mode string `json:"mode" default:"include" validate:"inclusion=include|omit|default"`
+	// Defaults is a map of column names to values replacing their nulls in the default mode. Values which are
+	// valid JSON, like 0, false or "", are parsed, other values are strings. Columns without a default keep nulls.
+	Defaults map[string]string `json:"defaults"`
+}
+
+// HistoryConfig holds configurable values of reading the history of a system-versioned table.
+type HistoryConfig struct {
+	// Table is a name of the history table of the system-versioned table.
+	// If it's set, all versions from the history table are read before the snapshot.
+	Table string `json:"table"`
+	// ValidFromColumn is a name of the column with the start of the version validity period.
+	ValidFromColumn string `json:"validFromColumn"`
+	// ValidToColumn is a name of the column with the end of the version validity period.
+	ValidToColumn string `json:"validToColumn"`
+}
+
+// TablesConfig holds configurable values of the schema mode.
+type TablesConfig struct {
+	// IncludeRegex is a regular expression, only tables matching it are read in the schema mode.
+	IncludeRegex string `json:"includeRegex"`
+	// ExcludeRegex is a regular expression, tables matching it aren't read in the schema mode.
+	ExcludeRegex string `json:"excludeRegex"`
+	// DiscoveryInterval is the interval of checking the schema for new tables.
+	DiscoveryInterval time.Duration `json:"discoveryInterval" default:"1m"`
+	// Overrides is a list of options of single tables, which override the connector options in the schema mode,
+	// e.g. ORDERS?batchSize=500, CURRENCIES?pollInterval=1m. Options are separated by &.
+	// Valid options: batchSize, pollInterval - the minimum interval of polling the table after it had no changes.
+	Overrides []string `json:"overrides"`
+}
+
+// CDCConfig holds configurable values of the cdc mode.
+type CDCConfig struct {
+	// StopTimeout is the maximum time the connector waits for the tracking table cleanup on stop.
+	StopTimeout time.Duration `json:"stopTimeout" default:"20s"`
+	// ChangedColumnsOnly makes update records contain only keys and changed columns,
+	// the names of changed columns are in the saphana.changedColumns metadata field.
+	ChangedColumnsOnly bool `json:"changedColumnsOnly" default:"false"`
+	// Compaction makes CDC return only the latest change of every key within a batch of tracking rows.
+	// It can't be used with changedColumnsOnly.
+	Compaction bool `json:"compaction" default:"false"`
+	// ConsumerName is a unique name of the pipeline among the pipelines reading the same table. If it's set,
+	// the pipelines share one tracking table, and its rows are removed when all of them acknowledge the rows.
+	ConsumerName string `json:"consumerName"`
+	// ConsumerTTL is the time after which the offset of a consumer which isn't running is removed, so a deleted
+	// pipeline doesn't keep rows in the shared tracking table forever. A consumer started after its offset
+	// was removed fails, as changes it hasn't read can be deleted. Offsets never expire if it's zero.
+	ConsumerTTL time.Duration `json:"consumerTTL" default:"168h"`
+	// Operations is a list of operations captured by CDC: create, update, delete.
+	Operations []string `json:"operations" default:"create,update,delete"`
+	// DropOnTeardown makes the connector drop the tracking table and the triggers when it stops, for pipelines
+	// which stop permanently, like batch pipelines. Changes made while the connector is stopped aren't captured.
+	DropOnTeardown bool `json:"dropOnTeardown" default:"false"`
+	// Exclusive makes the connector claim the tracking table, so only one instance reads it. An instance started
+	// later with the same tracking table takes it over, the previous one fails before its next batch of changes
+	// and doesn't remove rows from the tracking table anymore.
+	Exclusive bool `json:"exclusive" default:"false"`
+	// GapTimeout is the time after which a gap of tracking ids is skipped. Ids are taken when changes are made,
+	// but changes become visible when transactions commit, so changes after a gap aren't read until the gap
+	// is filled or times out, the transaction was rolled back then. Zero disables waiting for gaps.
+	GapTimeout time.Duration `json:"gapTimeout" default:"10s"`
+	// LagThreshold is the replication lag, the time between a change and its read by the connector, above which
+	// a warning is logged for a batch of changes. The tracking table gets the CONDUIT_CHANGED_AT column with
+	// the time of changes, if it's set. Zero disables warnings.
+	LagThreshold time.Duration `json:"lagThreshold" default:"0"`
+	// TransactionID makes the triggers capture the id of the transaction of every change, records carry it
+	// in the saphana.transactionId metadata field, so changes can be applied atomically per transaction.
+	TransactionID bool `json:"transactionId" default:"false"`
+	// OrderBy defines the order of changes. Valid values: id - tracking ids, timestamp - time of changes and
+	// tracking ids, the tracking table gets the CONDUIT_CHANGED_AT column then.
+	OrderBy string `json:"orderBy" default:"id" validate:"inclusion=id|timestamp"`
+	// StartFrom is the time of the first change read when CDC starts without a position, in RFC 3339 format.
+	// It requires the timestamp order.
+	StartFrom string `json:"startFrom"`
+	// Retention is the period acknowledged changes are kept in the tracking table, so CDC can be started from
+	// a time within it. It requires the timestamp order. Changes are deleted once acknowledged if it's zero.
+	Retention time.Duration `json:"retention" default:"0"`
+	// ColumnPrefix is the prefix of the service columns of the tracking table, like CONDUIT_TRACKING_ID.
+	// It's changed if the table has columns with the same names. The tracking table keeps the prefix
+	// it was created with, so a new prefix requires a new tracking table.
+	ColumnPrefix string `json:"columnPrefix" default:"CONDUIT_"`
 }
@@ -0,0 +1,72 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"errors"
+)
+
+var (
+	// ErrTableOrSchemaRequired occurs when there's neither table nor schema config value, or both are set.
+	ErrTableOrSchemaRequired = errors.New("either table or schema is required")
+	// ErrCollectionWithTable occurs when the collection is set together with the table or schema.
+	ErrCollectionWithTable = errors.New("collection can't be used with table or schema")
+	// ErrOrderingColumnRequired occurs when there's no ordering column config value for the single table.
+	ErrOrderingColumnRequired = errors.New("ordering column is required")
+	// ErrHistoryColumnsRequired occurs when the history table is set without its validity columns.
+	ErrHistoryColumnsRequired = errors.New("history validFromColumn and validToColumn are required")
+	// ErrHistoryWithSchema occurs when the history table is set in the schema mode.
+	ErrHistoryWithSchema = errors.New("history isn't supported in the schema mode")
+	// ErrUnknownOperation occurs when the cdc operations config value has an unknown operation.
+	ErrUnknownOperation = errors.New("unknown operation")
+	// ErrCompactionWithChangedColumns occurs when both cdc compaction and changedColumnsOnly are enabled.
+	ErrCompactionWithChangedColumns = errors.New("cdc.compaction can't be used with cdc.changedColumnsOnly")
+	// ErrSnapshotSourceWithSchema occurs when the snapshot function or procedure is set in the schema mode.
+	ErrSnapshotSourceWithSchema = errors.New("snapshot function or procedure isn't supported in the schema mode")
+	// ErrSnapshotFunctionAndProcedure occurs when both the snapshot function and procedure are set.
+	ErrSnapshotFunctionAndProcedure = errors.New("either snapshot function or procedure can be set")
+	// ErrUnknownMaskingStrategy occurs when the masking config value has an unknown strategy.
+	ErrUnknownMaskingStrategy = errors.New("unknown masking strategy")
+	// ErrDefaultSchemaWithSchema occurs when the default schema is set in the schema mode.
+	ErrDefaultSchemaWithSchema = errors.New("defaultSchema can't be used with schema")
+	// ErrDropOnTeardownWithConsumer occurs when the shared tracking table is set to be dropped on teardown.
+	ErrDropOnTeardownWithConsumer = errors.New("cdc.dropOnTeardown can't be used with cdc.consumerName")
+	// ErrTimestampOrderRequired occurs when the start time or the retention of cdc is set in the id order.
+	ErrTimestampOrderRequired = errors.New("cdc.startFrom and cdc.retention require cdc.orderBy timestamp")
+	// ErrRetentionWithConsumer occurs when the retention is set for the shared tracking table.
+	ErrRetentionWithConsumer = errors.New("cdc.retention can't be used with cdc.consumerName")
+	// ErrConsumerTTLTooShort occurs when the offsets of consumers expire in less than a minute.
+	ErrConsumerTTLTooShort = errors.New("cdc.consumerTTL must be at least 1m")
+	// ErrTriggerOptionsWithSDI occurs when options of the trigger cdc mode are set in the sdi cdc mode.
+	ErrTriggerOptionsWithSDI = errors.New("cdc.changedColumnsOnly and cdc.transactionId require the trigger cdcMode")
+	// ErrResumeWithProcedure occurs when the snapshot of the procedure is set to be resumed after its max duration.
+	ErrResumeWithProcedure = errors.New("snapshot of the procedure can't be resumed after snapshot.maxDuration")
+	// ErrOverridesWithoutSchema occurs when the table overrides are set outside the schema mode.
+	ErrOverridesWithoutSchema = errors.New("tables.overrides is supported only in the schema mode")
+	// ErrInvalidTableOverride occurs when a table override has an invalid format, option or value.
+	ErrInvalidTableOverride = errors.New("invalid table override")
+	// ErrNullDefaultsWithoutDefault occurs when the defaults of nulls are set without the default nulls mode.
+	ErrNullDefaultsWithoutDefault = errors.New("nulls.defaults require nulls.mode default")
+	// ErrLeaseTooShort occurs when the lease of the lock is shorter than a second.
+	ErrLeaseTooShort = errors.New("ha.leaseDuration must be at least 1s")
+	// ErrPollBackoffRange occurs when the minimum delay of reads without new records exceeds the maximum one.
+	ErrPollBackoffRange = errors.New("pollBackoff.min must not exceed pollBackoff.max")
+	// ErrRangeWithProcedure occurs when the snapshot range is set for the snapshot of the procedure.
+	ErrRangeWithProcedure = errors.New("snapshot.rangeStart and snapshot.rangeEnd can't be used with snapshot.procedure")
+	// ErrInvalidColumnPrefix occurs when the prefix of the tracking table service columns isn't a plain identifier.
+	ErrInvalidColumnPrefix = errors.New("cdc.columnPrefix must contain only letters, digits and underscores")
+	// ErrLockLost occurs when another instance has taken the lock of the pipeline over.
+	ErrLockLost = errors.New("lock was taken over by another instance")
+)
@@ -0,0 +1,85 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"slices"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// idRange is a range of consecutive tracking ids, both ends are included.
+type idRange struct {
+	first int
+	last  int
+}
+
+// compactIDs sorts the ids and joins the consecutive ones into ranges.
+func compactIDs(ids []any) []idRange {
+	sorted := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := id.(int); ok {
+			sorted = append(sorted, v)
+		}
+	}
+
+	slices.Sort(sorted)
+
+	var ranges []idRange
+
+	for _, id := range slices.Compact(sorted) {
+		if n := len(ranges); n > 0 && ranges[n-1].last+1 == id {
+			ranges[n-1].last = id
+
+			continue
+		}
+
+		ranges = append(ranges, idRange{first: id, last: id})
+	}
+
+	return ranges
+}
+
+// acknowledgedCondition returns the condition of the acknowledged rows of the tracking table.
+//
+// Rows are read and acknowledged in the order of the tracking ids, unless the batches are compacted
+// or ordered by the time of changes, so all rows up to the latest acknowledged id are removed at once.
+// Otherwise, the ids are compacted into ranges and only the single ids are listed.
+func (i *CDCIterator) acknowledgedCondition(cond *sqlbuilder.Cond, ids []any) string {
+	if !i.compaction && !i.orderByTimestamp {
+		return cond.LessEqualThan(i.columns.trackingID, maxID(ids))
+	}
+
+	var (
+		conditions []string
+		singles    []any
+	)
+
+	for _, r := range compactIDs(ids) {
+		if r.first == r.last {
+			singles = append(singles, r.first)
+
+			continue
+		}
+
+		conditions = append(conditions, cond.Between(i.columns.trackingID, r.first, r.last))
+	}
+
+	if len(singles) > 0 {
+		conditions = append(conditions, cond.In(i.columns.trackingID, singles...))
+	}
+
+	return cond.Or(conditions...)
+}
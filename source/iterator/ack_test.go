@@ -0,0 +1,83 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+func TestCompactIDs(t *testing.T) {
+	t.Parallel()
+
+	got := compactIDs([]any{7, 3, 4, 5, 10, 4, 8})
+	want := []idRange{{first: 3, last: 5}, {first: 7, last: 8}, {first: 10, last: 10}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compactIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestCDCIterator_acknowledgedCondition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		compaction bool
+		ids        []any
+		wantQuery  string
+		wantArgs   []any
+	}{
+		{
+			name:      "ordered acknowledgments",
+			ids:       []any{1, 2, 5, 3},
+			wantQuery: "DELETE FROM T WHERE CONDUIT_TRACKING_ID <= ?",
+			wantArgs:  []any{5},
+		},
+		{
+			name:       "compacted batches",
+			compaction: true,
+			ids:        []any{9, 1, 2, 3, 5},
+			wantQuery: "DELETE FROM T WHERE (CONDUIT_TRACKING_ID BETWEEN ? AND ? " +
+				"OR CONDUIT_TRACKING_ID IN (?, ?))",
+			wantArgs: []any{1, 3, 5, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			it := &CDCIterator{compaction: tt.compaction, columns: newServiceColumns("")}
+
+			deleteBuilder := sqlbuilder.NewDeleteBuilder()
+
+			q, args := deleteBuilder.
+				DeleteFrom("T").
+				Where(it.acknowledgedCondition(&deleteBuilder.Cond, tt.ids)).
+				Build()
+
+			if q != tt.wantQuery {
+				t.Errorf("query = %q, want %q", q, tt.wantQuery)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
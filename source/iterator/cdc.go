@@ -18,12 +18,18 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	hdbdriver "github.com/SAP/go-hdb/driver"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
 	"github.com/conduitio/conduit-commons/opencdc"
 	sdk "github.com/conduitio/conduit-connector-sdk"
@@ -37,9 +43,18 @@ const (
 	trackingTablePattern = "CONDUIT_%s_%s"
 	triggerNamePattern   = "CD_%s_%s_%s"
 
-	// tracking table columns.
-	columnOperationType = "CONDUIT_OPERATION_TYPE"
-	columnTrackingID    = "CONDUIT_TRACKING_ID"
+	// maxChangedColumnsLength - max number of columns in the bitmap of changed columns.
+	maxChangedColumnsLength = 5000
+
+	metadataChangedColumns = "saphana.changedColumns"
+	metadataTransactionID  = "saphana.transactionId"
+
+	metadataTrackingID        = "saphana.trackingId"
+	metadataTrackingOperation = "saphana.trackingOperation"
+	metadataCapturedAt        = "saphana.capturedAt"
+
+	// valueTransactionID - the trigger value of the transaction id column.
+	valueTransactionID = "CURRENT_UPDATE_TRANSACTION()"
 )
 
 const (
@@ -50,7 +65,6 @@ const (
 )
 
 const (
-	waitingTimeoutSec            = 20
 	clearTrackingTableTimeoutSec = 5
 )
 
@@ -62,28 +76,32 @@ type trackingTableService struct {
 	stopCh chan struct{}
 	// channel for errors.
 	errCh chan error
-	// channel for notify that all queries finished and db can be closed.
-	canCloseCh chan struct{}
+	// cancel cancels the queries of the clearing goroutine.
+	cancel context.CancelFunc
+	// done is closed when the clearing goroutine returns, so db can be closed.
+	done chan struct{}
 	// idsForRemoving - ids of rows what need to clear.
 	idsForRemoving []any
 }
 
 func newTrackingTableService() *trackingTableService {
 	return &trackingTableService{
-		stopCh:     make(chan struct{}, 1),
-		errCh:      make(chan error, 1),
-		canCloseCh: make(chan struct{}, 1),
+		stopCh: make(chan struct{}, 1),
+		errCh:  make(chan error, 1),
 	}
 }
 
-func (t *trackingTableService) close() {
-	close(t.canCloseCh)
-	close(t.errCh)
-	close(t.stopCh)
+// sendErr passes the error to Ack, the error isn't sent if the previous one isn't received yet.
+func (t *trackingTableService) sendErr(err error) {
+	select {
+	case t.errCh <- err:
+	default:
+	}
 }
 
-// cdcIterator - cdc iterator.
-type cdcIterator struct {
+// CDCIterator - cdc iterator, it reads changes from the tracking table filled by the triggers or
+// the remote subscription, which are set up by [SetupCDC]. Acknowledged changes are removed from the tracking table.
+type CDCIterator struct {
 	db   *sqlx.DB
 	rows *sqlx.Rows
 
@@ -102,39 +120,177 @@ type cdcIterator struct {
 	position *position.Position
 	// columnTypes column types from table.
 	columnTypes map[string]string
+	// stopTimeout max time for waiting tracking table cleanup on stop.
+	stopTimeout time.Duration
+	// transformOpts options of row values transformation.
+	transformOpts columntypes.TransformOptions
+	// changedColumnsOnly updates capture only changed columns and keys.
+	changedColumnsOnly bool
+	// trackingColumns columns of the tracking table in the order of the bitmap of changed columns.
+	trackingColumns []string
+	// compaction only the latest row per key of a batch is returned.
+	compaction bool
+	// compacted rows of the current batch, which are left to return.
+	compacted []map[string]any
+	// superseded ids of tracking rows, which were replaced by the later rows with the same key, by the ids
+	// of the later rows. They are removed from the tracking table, when the later row is acknowledged.
+	superseded map[int][]any
+	// consumer name of the pipeline in the shared tracking table, the table is private if it's empty.
+	consumer string
+	// consumerTTL offsets of consumers which haven't been running for it are removed, they never expire if it's zero.
+	consumerTTL time.Duration
+	// owner unique name of the instance which claimed the tracking table, it isn't claimed if it's empty.
+	owner string
+	// dropOnTeardown the tracking table and the triggers are dropped on stop.
+	dropOnTeardown bool
+	// hints of the select queries, they aren't added if it's empty.
+	hints string
+	// gaps of tracking ids, rows after a gap aren't read until the gap is filled or times out.
+	// It's nil if gaps aren't tracked.
+	gaps *gapTracker
+
+	// orderByTimestamp rows are read in the order of the time of changes and the tracking ids.
+	orderByTimestamp bool
+	// startFrom time of the first change read without a position, in the timestamp order.
+	startFrom time.Time
+	// watermark rows changed within it aren't read in the timestamp order.
+	watermark time.Duration
+	// retention acknowledged rows are kept for it in the timestamp order, they're deleted immediately if it's zero.
+	retention time.Duration
+	// ackedAt, ackedID position of the last acknowledged row, in the timestamp order with the retention.
+	ackedAt *time.Time
+	ackedID int
+
+	// debugMetadata records carry the id, the operation and the capture time of their tracking rows.
+	debugMetadata bool
+
+	// lag replication lag of the records of the current batch.
+	lag *lagTracker
+	// lagMetadata records carry their replication lag.
+	lagMetadata bool
+
+	// columns names of the service columns of the tracking table.
+	columns serviceColumns
 }
 
-type cdcParams struct {
-	db            *sqlx.DB
-	table         string
-	trackingTable string
-	keys          []string
-	batchSize     int
-	columnTypes   map[string]string
-	position      *position.Position
+// CDCParams is an incoming params for the [NewCDCIterator] function.
+type CDCParams struct {
+	DB            *sqlx.DB
+	Table         string
+	TrackingTable string
+	// Keys - columns of the record keys.
+	Keys      []string
+	BatchSize int
+	// ColumnTypes - types of the table columns by their names, see [columntypes.TableInfo].
+	ColumnTypes map[string]string
+	// Position - position of the last read change, changes are read from the beginning if it's nil.
+	Position *position.Position
+	// StopTimeout - max time of waiting for the tracking table cleanup on stop.
+	StopTimeout   time.Duration
+	TransformOpts columntypes.TransformOptions
+
+	// ChangedColumnsOnly - updates carry only keys and changed columns, it requires the bitmap of changed columns
+	// captured by the triggers.
+	ChangedColumnsOnly bool
+	// Compaction - only the latest change of every key within a batch is returned.
+	Compaction bool
+	// Consumer - name of the pipeline in the shared tracking table, the table is private if it's empty.
+	Consumer string
+	// ConsumerTTL - offsets of consumers which haven't been running for it are removed from the shared tracking table,
+	// so stopped consumers don't keep its rows forever. Offsets never expire if it's zero.
+	ConsumerTTL time.Duration
+	// DropOnTeardown - drop the tracking table and the triggers on stop.
+	DropOnTeardown bool
+	// Exclusive - the iterator claims the tracking table, so another instance with the same tracking table
+	// and consumer takes it over and the iterator fails at its next batch with ErrTrackingTableClaimed.
+	Exclusive bool
+	// Hints - hints of the select queries.
+	Hints string
+	// GapTimeout - time after which a gap of tracking ids is skipped, zero disables waiting for gaps.
+	// It's the watermark of changes in the timestamp order.
+	GapTimeout time.Duration
+	// OrderByTimestamp - changes are read in the order of their time and tracking ids.
+	OrderByTimestamp bool
+	// StartFrom - time of the first change read without a position, in the timestamp order.
+	StartFrom time.Time
+	// Retention - period acknowledged changes are kept, in the timestamp order.
+	Retention time.Duration
+	// DebugMetadata - records carry the id, the operation and the capture time of their tracking rows.
+	DebugMetadata bool
+
+	// LagThreshold - replication lag above which a warning is logged, zero disables warnings.
+	// The lag is measured if the tracking table has the column with the time of the change.
+	LagThreshold time.Duration
+	// LagMetadata - records carry their replication lag in milliseconds.
+	LagMetadata bool
+
+	// ColumnPrefix - prefix of the service columns of the tracking table, [DefaultColumnPrefix] if it's empty.
+	ColumnPrefix string
 }
 
-// newCDCIterator create new cdc iterator.
-func newCDCIterator(ctx context.Context, params cdcParams) (*cdcIterator, error) {
+// NewCDCIterator creates new cdc iterator, the tracking table must be set up by [SetupCDC].
+func NewCDCIterator(ctx context.Context, params CDCParams) (*CDCIterator, error) {
 	var err error
 
-	it := &cdcIterator{
-		db:            params.db,
-		table:         params.table,
-		trackingTable: params.trackingTable,
-		keys:          params.keys,
-		batchSize:     params.batchSize,
-		position:      params.position,
-		columnTypes:   params.columnTypes,
+	it := &CDCIterator{
+		db:            params.DB,
+		table:         params.Table,
+		trackingTable: params.TrackingTable,
+		keys:          params.Keys,
+		batchSize:     params.BatchSize,
+		position:      params.Position,
+		columnTypes:   params.ColumnTypes,
+		stopTimeout:   params.StopTimeout,
+		transformOpts: params.TransformOpts,
 		tableSrv:      newTrackingTableService(),
+
+		changedColumnsOnly: params.ChangedColumnsOnly,
+		compaction:         params.Compaction,
+		superseded:         make(map[int][]any),
+		consumer:           params.Consumer,
+		consumerTTL:        params.ConsumerTTL,
+		dropOnTeardown:     params.DropOnTeardown,
+		hints:              params.Hints,
+		debugMetadata:      params.DebugMetadata,
+		lag:                &lagTracker{threshold: params.LagThreshold},
+		lagMetadata:        params.LagMetadata,
+
+		columns: newServiceColumns(params.ColumnPrefix),
+	}
+
+	// gaps aren't tracked in the timestamp order, rows newer than the gap timeout aren't read instead.
+	switch {
+	case params.OrderByTimestamp:
+		it.orderByTimestamp = true
+		it.startFrom = params.StartFrom
+		it.watermark = params.GapTimeout
+		it.retention = params.Retention
+	case params.GapTimeout > 0:
+		it.gaps = newGapTracker(params.GapTimeout)
+	}
+
+	if err = it.loadTrackingColumns(ctx); err != nil {
+		return nil, fmt.Errorf("load tracking columns: %w", err)
 	}
 
-	if err = it.loadRows(ctx); err != nil {
-		return nil, fmt.Errorf("load rows: %w", err)
+	if params.Exclusive {
+		if it.owner, err = helper.InstanceName(); err != nil {
+			return nil, fmt.Errorf("instance name: %w", err)
+		}
+
+		if err = claimTrackingTable(ctx, it.db, it.trackingTable, it.consumer, it.owner); err != nil {
+			return nil, fmt.Errorf("claim tracking table: %w", err)
+		}
+	}
+
+	// compacted rows are loaded by batches in HasNext.
+	if !it.compaction {
+		if err = it.loadRows(ctx); err != nil {
+			return nil, fmt.Errorf("load rows: %w", err)
+		}
 	}
 
-	// run clearing tracking table.
-	go it.clearTrackingTable(ctx)
+	it.startClearing(ctx)
 
 	return it, nil
 }
@@ -142,11 +298,19 @@ func newCDCIterator(ctx context.Context, params cdcParams) (*cdcIterator, error)
 // HasNext check ability to get next record.
 //
 //nolint:funlen,nolintlint
-func (i *cdcIterator) HasNext(ctx context.Context) (bool, error) {
+func (i *CDCIterator) HasNext(ctx context.Context) (bool, error) {
+	if i.compaction {
+		return i.hasNextCompacted(ctx)
+	}
+
 	if i.rows != nil && i.rows.Next() {
 		return true, nil
 	}
 
+	if i.rows != nil && i.rows.Err() != nil {
+		return false, fmt.Errorf("iterate rows: %w", i.rows.Err())
+	}
+
 	if err := i.loadRows(ctx); err != nil {
 		return false, fmt.Errorf("load rows: %w", err)
 	}
@@ -156,23 +320,23 @@ func (i *cdcIterator) HasNext(ctx context.Context) (bool, error) {
 
 // Next get new record.
 // nolint:funlen,nolintlint
-func (i *cdcIterator) Next(ctx context.Context) (opencdc.Record, error) {
-	row := make(map[string]any)
-	if err := i.rows.MapScan(row); err != nil {
-		return opencdc.Record{}, fmt.Errorf("scan rows: %w", err)
+func (i *CDCIterator) Next(ctx context.Context) (opencdc.Record, error) {
+	row, err := i.nextRow()
+	if err != nil {
+		return opencdc.Record{}, err
 	}
 
-	transformedRow, err := columntypes.TransformRow(ctx, row, i.columnTypes)
+	transformedRow, err := columntypes.TransformRow(ctx, row, i.columnTypes, i.transformOpts)
 	if err != nil {
 		return opencdc.Record{}, fmt.Errorf("transform row column types: %w", err)
 	}
 
-	id, ok := transformedRow[columnTrackingID].(int64)
+	id, ok := transformedRow[i.columns.trackingID].(int64)
 	if !ok {
 		return opencdc.Record{}, ErrWrongTrackingIDType
 	}
 
-	operationTypeBt, ok := transformedRow[columnOperationType].([]byte)
+	operationTypeBt, ok := transformedRow[i.columns.operationType].([]byte)
 	if !ok {
 		return opencdc.Record{}, ErrWrongTrackingOperatorType
 	}
@@ -183,6 +347,10 @@ func (i *cdcIterator) Next(ctx context.Context) (opencdc.Record, error) {
 		TrackingTableName: i.trackingTable,
 	}
 
+	if changedAt, ok := row[i.columns.changedAt].(time.Time); ok && i.orderByTimestamp {
+		pos.CDCLastChangedAt = &changedAt
+	}
+
 	convertedPosition, err := pos.ConvertToSDKPosition()
 	if err != nil {
 		return opencdc.Record{}, fmt.Errorf("convert position %w", err)
@@ -196,8 +364,19 @@ func (i *cdcIterator) Next(ctx context.Context) (opencdc.Record, error) {
 		keysMap[val] = transformedRow[val]
 	}
 
-	delete(transformedRow, columnOperationType)
-	delete(transformedRow, columnTrackingID)
+	changedColumns := i.decodeChangedColumns(transformedRow[i.columns.changedColumns])
+	transactionID := transformedRow[i.columns.transactionID]
+
+	delete(transformedRow, i.columns.operationType)
+	delete(transformedRow, i.columns.trackingID)
+	delete(transformedRow, i.columns.changedColumns)
+	delete(transformedRow, i.columns.transactionID)
+	delete(transformedRow, i.columns.changedAt)
+
+	// unchanged columns are empty in the tracking table, so only keys and changed columns are returned.
+	if changedColumns != nil {
+		i.keepColumns(transformedRow, changedColumns)
+	}
 
 	transformedRowBytes, err := json.Marshal(transformedRow)
 	if err != nil {
@@ -208,7 +387,39 @@ func (i *cdcIterator) Next(ctx context.Context) (opencdc.Record, error) {
 	metadata := opencdc.Metadata(map[string]string{metadataTable: i.table})
 	metadata.SetCreatedAt(time.Now())
 
-	switch actionType(operationTypeBt) {
+	if changedColumns != nil {
+		metadata[metadataChangedColumns] = strings.Join(changedColumns, ",")
+	}
+
+	// rows captured before the transaction id column was added don't have it.
+	if transactionID != nil {
+		metadata[metadataTransactionID] = fmt.Sprint(transactionID)
+	}
+
+	if i.debugMetadata {
+		metadata[metadataTrackingID] = strconv.FormatInt(id, 10)
+		metadata[metadataTrackingOperation] = string(operationTypeBt)
+
+		// rows captured before the changed at column was added don't have it.
+		if capturedAt, ok := row[i.columns.changedAt].(time.Time); ok {
+			metadata[metadataCapturedAt] = capturedAt.Format(time.RFC3339Nano)
+		}
+	}
+
+	if capturedAt, ok := row[i.columns.changedAt].(time.Time); ok {
+		lag := i.lag.observe(capturedAt, time.Now())
+
+		if i.lagMetadata {
+			metadata[metadataLag] = strconv.FormatInt(lag.Milliseconds(), 10)
+		}
+	}
+
+	operation := actionType(operationTypeBt)
+	if sdiOperation, ok := sdiOperations[operation]; ok {
+		operation = sdiOperation
+	}
+
+	switch operation {
 	case insertOperation:
 		return sdk.Util.Source.NewRecordCreate(convertedPosition, metadata,
 			opencdc.StructuredData(keysMap), opencdc.RawData(transformedRowBytes)), nil
@@ -216,17 +427,244 @@ func (i *cdcIterator) Next(ctx context.Context) (opencdc.Record, error) {
 		return sdk.Util.Source.NewRecordUpdate(convertedPosition, metadata,
 			opencdc.StructuredData(keysMap), nil, opencdc.RawData(transformedRowBytes)), nil
 	case deleteOperation:
+		// the delete trigger saves the old row values, so the record has the full before-image.
 		return sdk.Util.Source.NewRecordDelete(convertedPosition, metadata,
-			opencdc.StructuredData(keysMap), nil), nil
+			opencdc.StructuredData(keysMap), opencdc.RawData(transformedRowBytes)), nil
 	default:
 		return opencdc.Record{}, ErrUnknownOperatorType
 	}
 }
 
+// nextRow returns the next compacted row or scans the next row.
+func (i *CDCIterator) nextRow() (map[string]any, error) {
+	if i.compaction {
+		if len(i.compacted) == 0 {
+			return nil, ErrNoInitializedIterator
+		}
+
+		row := i.compacted[0]
+		i.compacted = i.compacted[1:]
+
+		return row, nil
+	}
+
+	row := make(map[string]any)
+	if err := i.rows.MapScan(row); err != nil {
+		return nil, fmt.Errorf("scan rows: %w", err)
+	}
+
+	return row, nil
+}
+
+// hasNextCompacted checks if there are compacted rows left, otherwise it loads and compacts the next batch.
+func (i *CDCIterator) hasNextCompacted(ctx context.Context) (bool, error) {
+	if len(i.compacted) > 0 {
+		return true, nil
+	}
+
+	if err := i.loadCompactedRows(ctx); err != nil {
+		return false, fmt.Errorf("load compacted rows: %w", err)
+	}
+
+	return len(i.compacted) > 0, nil
+}
+
+// loadCompactedRows loads the next batch of rows and leaves only the latest row per key.
+func (i *CDCIterator) loadCompactedRows(ctx context.Context) error {
+	if err := i.loadRows(ctx); err != nil {
+		return fmt.Errorf("load rows: %w", err)
+	}
+
+	rows := make([]map[string]any, 0, i.batchSize)
+
+	for i.rows.Next() {
+		row := make(map[string]any)
+		if err := i.rows.MapScan(row); err != nil {
+			return fmt.Errorf("scan rows: %w", err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := i.rows.Err(); err != nil {
+		return fmt.Errorf("iterate rows: %w", err)
+	}
+
+	if err := i.rows.Close(); err != nil {
+		return fmt.Errorf("close rows: %w", err)
+	}
+
+	i.rows = nil
+
+	compacted, superseded, err := compactRows(rows, i.keys, i.columns.trackingID)
+	if err != nil {
+		return fmt.Errorf("compact rows: %w", err)
+	}
+
+	i.tableSrv.m.Lock()
+	for id, ids := range superseded {
+		i.superseded[id] = append(i.superseded[id], ids...)
+	}
+	i.tableSrv.m.Unlock()
+
+	i.compacted = compacted
+
+	return nil
+}
+
+// compactRows leaves only the latest row per key in the order of the tracking ids.
+// It returns the ids of the removed rows by the ids of the rows, which replaced them.
+func compactRows(rows []map[string]any, keys []string, trackingID string) ([]map[string]any, map[int][]any, error) {
+	rowKeys := make([]string, len(rows))
+	latest := make(map[string]int, len(rows))
+
+	for idx, row := range rows {
+		values := make([]any, len(keys))
+		for k, key := range keys {
+			values[k] = row[key]
+		}
+
+		rowKeys[idx] = fmt.Sprintf("%#v", values)
+		latest[rowKeys[idx]] = idx
+	}
+
+	compacted := make([]map[string]any, 0, len(latest))
+	superseded := make(map[int][]any)
+
+	for idx, row := range rows {
+		latestIdx := latest[rowKeys[idx]]
+		if latestIdx == idx {
+			compacted = append(compacted, row)
+
+			continue
+		}
+
+		id, ok := row[trackingID].(int64)
+		if !ok {
+			return nil, nil, ErrWrongTrackingIDType
+		}
+
+		latestID, ok := rows[latestIdx][trackingID].(int64)
+		if !ok {
+			return nil, nil, ErrWrongTrackingIDType
+		}
+
+		superseded[int(latestID)] = append(superseded[int(latestID)], int(id))
+	}
+
+	return compacted, superseded, nil
+}
+
+// loadTrackingColumns loads the columns of the tracking table, which are needed for decoding
+// the bitmap of changed columns.
+func (i *CDCIterator) loadTrackingColumns(ctx context.Context) error {
+	if !i.changedColumnsOnly {
+		return nil
+	}
+
+	columns, err := getTrackingColumns(ctx, i.db, i.trackingTable, i.columns)
+	if err != nil {
+		return err
+	}
+
+	i.trackingColumns = columns
+
+	return nil
+}
+
+// decodeChangedColumns returns names of the changed columns from the bitmap.
+// It returns nil, if the row has no bitmap, e.g. for inserts and deletes.
+func (i *CDCIterator) decodeChangedColumns(bitmap any) []string {
+	var bits string
+
+	switch v := bitmap.(type) {
+	case []byte:
+		bits = string(v)
+	case string:
+		bits = v
+	default:
+		return nil
+	}
+
+	changed := make([]string, 0)
+
+	for idx := 0; idx < len(bits) && idx < len(i.trackingColumns); idx++ {
+		if bits[idx] == '1' {
+			changed = append(changed, i.trackingColumns[idx])
+		}
+	}
+
+	return changed
+}
+
+// keepColumns removes all columns from the row, except the keys and the changed columns.
+func (i *CDCIterator) keepColumns(row map[string]any, changedColumns []string) {
+	keep := make(map[string]bool, len(i.keys)+len(changedColumns))
+	for _, column := range i.keys {
+		keep[column] = true
+	}
+
+	for _, column := range changedColumns {
+		keep[column] = true
+	}
+
+	for column := range row {
+		if !keep[column] {
+			delete(row, column)
+		}
+	}
+}
+
+// resume replaces the db connection and reloads rows from the current position.
+func (i *CDCIterator) resume(ctx context.Context, db *sqlx.DB) error {
+	if i.rows != nil {
+		// rows belong to the broken connection, the close error doesn't matter here.
+		i.rows.Close() //nolint:errcheck // see the comment above
+	}
+
+	// the tracking table cleanup uses the connection under the same lock.
+	i.tableSrv.m.Lock()
+	i.db = db
+	i.tableSrv.m.Unlock()
+
+	// compacted rows are reloaded from the current position in HasNext.
+	if i.compaction {
+		i.rows = nil
+		i.compacted = nil
+
+		return nil
+	}
+
+	if err := i.loadRows(ctx); err != nil {
+		return fmt.Errorf("load rows: %w", err)
+	}
+
+	return nil
+}
+
 // Stop shutdown iterator.
-func (i *cdcIterator) Stop(ctx context.Context) error {
-	// send signal to finish clearing tracking table rows.
-	i.tableSrv.stopCh <- struct{}{}
+func (i *CDCIterator) Stop(ctx context.Context) error {
+	// send signal to finish clearing tracking table rows, it's already pending if the iterator is stopped again.
+	select {
+	case i.tableSrv.stopCh <- struct{}{}:
+	default:
+	}
+
+	select {
+	// when tracking table will be empty we get signal about it, so connector can close connection
+	case <-i.tableSrv.done:
+		sdk.Logger(ctx).Debug().Msg("clearing tracking table was successfully finished")
+	// just in case if something wrong with clearing table, its queries are cancelled after timeout.
+	case <-time.After(i.stopTimeout):
+		sdk.Logger(ctx).Warn().Msg("cancel clearing tracking table after timeout")
+	// the caller doesn't want to wait anymore.
+	case <-ctx.Done():
+		sdk.Logger(ctx).Warn().Err(ctx.Err()).Msg("cancel clearing tracking table before it was finished")
+	}
+
+	// the tracking table can't be dropped and db can't be closed under a running query of the clearing goroutine.
+	i.tableSrv.cancel()
+	<-i.tableSrv.done
 
 	if i.rows != nil {
 		err := i.rows.Close()
@@ -235,30 +673,20 @@ func (i *cdcIterator) Stop(ctx context.Context) error {
 		}
 	}
 
-	select {
-	// when tracking table will be empty we get signal about it, so connector can close connection
-	case <-i.tableSrv.canCloseCh:
-		sdk.Logger(ctx).Debug().Msg("clearing tracking table was successfully finished")
-		if i.db != nil {
-			i.tableSrv.close()
+	if i.dropOnTeardown && i.db != nil {
+		sdk.Logger(ctx).Info().Str("trackingTable", i.trackingTable).Msg("drop tracking table and triggers")
 
-			err := i.db.Close()
-			if err != nil {
-				return fmt.Errorf("close db:%w", err)
-			}
-		}
-	// just in case if something wrong with clearing table, connector will close db after timeout.
-	case <-time.After(waitingTimeoutSec * time.Second):
-		sdk.Logger(ctx).Warn().Msg("close db after timeout")
-		if i.db != nil {
-			i.tableSrv.close()
+		if err := dropTrackingTable(ctx, i.db, i.table, i.trackingTable); err != nil {
+			i.db.Close() //nolint:errcheck // the drop error is more relevant
 
-			err := i.db.Close()
-			if err != nil {
-				return fmt.Errorf("close db:%w", err)
-			}
+			return fmt.Errorf("drop tracking table: %w", err)
+		}
+	}
 
-			return nil
+	if i.db != nil {
+		err := i.db.Close()
+		if err != nil {
+			return fmt.Errorf("close db:%w", err)
 		}
 	}
 
@@ -266,7 +694,7 @@ func (i *cdcIterator) Stop(ctx context.Context) error {
 }
 
 // Ack check if record with position was recorded.
-func (i *cdcIterator) Ack(_ context.Context, pos *position.Position) error {
+func (i *CDCIterator) Ack(_ context.Context, pos *position.Position) error {
 	if len(i.tableSrv.errCh) > 0 {
 		for v := range i.tableSrv.errCh {
 			return fmt.Errorf("clear tracking table: %w", v)
@@ -275,12 +703,30 @@ func (i *cdcIterator) Ack(_ context.Context, pos *position.Position) error {
 
 	i.tableSrv.m.Lock()
 
+	// acknowledged rows are kept for the retention period and deleted by the time of the change.
+	if i.retention > 0 {
+		if pos.CDCLastChangedAt != nil {
+			i.ackedAt, i.ackedID = pos.CDCLastChangedAt, pos.CDCLastID
+		}
+
+		delete(i.superseded, pos.CDCLastID)
+		i.tableSrv.m.Unlock()
+
+		return nil
+	}
+
 	if i.tableSrv.idsForRemoving == nil {
 		i.tableSrv.idsForRemoving = make([]any, 0)
 	}
 
 	i.tableSrv.idsForRemoving = append(i.tableSrv.idsForRemoving, pos.CDCLastID)
 
+	// rows replaced by the acknowledged one aren't needed anymore.
+	if ids, ok := i.superseded[pos.CDCLastID]; ok {
+		i.tableSrv.idsForRemoving = append(i.tableSrv.idsForRemoving, ids...)
+		delete(i.superseded, pos.CDCLastID)
+	}
+
 	i.tableSrv.m.Unlock()
 
 	return nil
@@ -288,25 +734,53 @@ func (i *cdcIterator) Ack(_ context.Context, pos *position.Position) error {
 
 // LoadRows selects a batch of rows from a database, based on the
 // table, columns, orderingColumn, batchSize and the current position.
-func (i *cdcIterator) loadRows(ctx context.Context) error {
+func (i *CDCIterator) loadRows(ctx context.Context) error {
+	// the previous batch is read completely, when the next one is loaded.
+	i.lag.report(ctx, i.table)
+
+	if i.owner != "" {
+		if err := i.verifyClaim(ctx); err != nil {
+			return fmt.Errorf("verify claim: %w", err)
+		}
+	}
+
 	selectBuilder := sqlbuilder.NewSelectBuilder()
 
 	selectBuilder.Select("*")
 
 	selectBuilder.From(i.trackingTable)
 
-	if i.position != nil {
+	orderBy := []string{i.columns.trackingID}
+
+	switch {
+	case i.orderByTimestamp:
+		if conditions := i.timestampConditions(selectBuilder); len(conditions) > 0 {
+			selectBuilder.Where(conditions...)
+		}
+
+		orderBy = []string{i.columns.changedAt, i.columns.trackingID}
+	case i.position != nil:
 		selectBuilder.Where(
-			selectBuilder.GreaterThan(columnTrackingID, i.position.CDCLastID),
+			selectBuilder.GreaterThan(i.columns.trackingID, i.position.CDCLastID),
 		)
 	}
 
+	// rows after a gap of ids are read when the gap is filled by the commit or times out.
+	if i.gaps != nil {
+		upperID, err := i.safeUpperID(ctx)
+		if err != nil {
+			return fmt.Errorf("safe upper id: %w", err)
+		}
+
+		selectBuilder.Where(selectBuilder.LessEqualThan(i.columns.trackingID, upperID))
+	}
+
 	q, args := selectBuilder.
-		OrderBy(columnTrackingID).
+		OrderBy(orderBy...).
 		Limit(i.batchSize).
 		Build()
 
-	rows, err := i.db.QueryxContext(ctx, q, args...)
+	rows, err := i.db.QueryxContext(ctx, withHints(q, i.hints), args...)
 	if err != nil {
 		return fmt.Errorf("execute select query: %w", err)
 	}
@@ -317,26 +791,67 @@ func (i *cdcIterator) loadRows(ctx context.Context) error {
 }
 
 // deleteRows - delete rows from tracking table.
-func (i *cdcIterator) deleteRows(ctx context.Context) error {
+func (i *CDCIterator) deleteRows(ctx context.Context) error {
 	i.tableSrv.m.Lock()
 	defer i.tableSrv.m.Unlock()
 
+	if i.retention > 0 {
+		return i.deleteExpiredRows(ctx)
+	}
+
 	if len(i.tableSrv.idsForRemoving) == 0 {
+		// the offset of the running consumer doesn't expire while there are no changes.
+		if i.consumer != "" && i.consumerTTL > 0 {
+			return touchOffset(ctx, i.db, i.trackingTable, i.consumer)
+		}
+
 		return nil
 	}
 
-	tx, err := i.db.Begin()
+	tx, err := i.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 
 	defer tx.Rollback() // nolint:errcheck,nolintlint
 
+	// the taken over instance doesn't remove rows, and the claim can't be taken over until the rows are removed.
+	if i.owner != "" {
+		if err = checkClaim(ctx, tx, i.trackingTable, i.consumer, i.owner); err != nil {
+			return err
+		}
+	}
+
+	// rows of the shared tracking table are removed when all consumers acknowledge them.
+	if i.consumer != "" {
+		if err = saveOffset(ctx, tx, i.trackingTable, i.consumer, maxID(i.tableSrv.idsForRemoving)); err != nil {
+			return err
+		}
+
+		if i.consumerTTL > 0 {
+			if err = deleteExpiredOffsets(ctx, tx, i.trackingTable, i.consumerTTL); err != nil {
+				return err
+			}
+		}
+
+		if err = deleteConsumedRows(ctx, tx, i.trackingTable, i.columns.trackingID); err != nil {
+			return err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("commit tx: %w", err)
+		}
+
+		i.tableSrv.idsForRemoving = nil
+
+		return nil
+	}
+
 	deleteBuilder := sqlbuilder.NewDeleteBuilder()
 
 	q, args := deleteBuilder.
 		DeleteFrom(i.trackingTable).
-		Where(deleteBuilder.In(columnTrackingID, i.tableSrv.idsForRemoving...)).
+		Where(i.acknowledgedCondition(&deleteBuilder.Cond, i.tableSrv.idsForRemoving)).
 		Build()
 
 	_, err = tx.ExecContext(ctx, q, args...)
@@ -354,25 +869,34 @@ func (i *cdcIterator) deleteRows(ctx context.Context) error {
 	return nil
 }
 
-func (i *cdcIterator) clearTrackingTable(ctx context.Context) {
+// startClearing runs clearing of the tracking table in the background until the iterator stops.
+// Its queries aren't bound to the context of Open, Stop cancels them.
+func (i *CDCIterator) startClearing(ctx context.Context) {
+	ctx, i.tableSrv.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	i.tableSrv.done = make(chan struct{})
+
+	go func() {
+		// clearing was finished, db can be closed.
+		defer close(i.tableSrv.done)
+
+		i.clearTrackingTable(ctx)
+	}()
+}
+
+func (i *CDCIterator) clearTrackingTable(ctx context.Context) {
 	for {
 		select {
 		// connector is stopping, clear table last time.
 		case <-i.tableSrv.stopCh:
-			err := i.deleteRows(ctx)
-			if err != nil {
-				i.tableSrv.errCh <- err
+			if err := i.deleteRows(ctx); err != nil {
+				i.tableSrv.sendErr(err)
 			}
 
-			// clearing was finished, db can be closed.
-			i.tableSrv.canCloseCh <- struct{}{}
-
 			return
 
 		case <-time.After(clearTrackingTableTimeoutSec * time.Second):
-			err := i.deleteRows(ctx)
-			if err != nil {
-				i.tableSrv.errCh <- err
+			if err := i.deleteRows(ctx); err != nil {
+				i.tableSrv.sendErr(err)
 
 				return
 			}
@@ -380,13 +904,45 @@ func (i *cdcIterator) clearTrackingTable(ctx context.Context) {
 	}
 }
 
-// setupCDC - create tracking table, add columns.
-func setupCDC(
-	ctx context.Context,
-	db *sqlx.DB,
-	tableName, trackingTableName string,
-	tableInfo columntypes.TableInfo,
-) error {
+// CDCSetupParams is an incoming params for the [SetupCDC] function.
+type CDCSetupParams struct {
+	// TableName - table name, the names of the triggers are based on it.
+	TableName string
+	// TrackingTableName - tracking table name.
+	TrackingTableName string
+	// TableInfo - information about columns of the base table.
+	TableInfo columntypes.TableInfo
+	// Keys - columns which are always captured by the update trigger.
+	Keys []string
+	// ChangedColumnsOnly - the update trigger captures only changed columns and the bitmap of them.
+	ChangedColumnsOnly bool
+	// Operations - record operations captured by the triggers: create, update, delete.
+	// All operations are captured if it's empty.
+	Operations []string
+	// TransactionID - the triggers capture the id of the transaction which made the change.
+	TransactionID bool
+	// ChangedAt - the tracking table has the column with the time of the change.
+	ChangedAt bool
+	// SDI - the remote subscription on the virtual table fills the tracking table instead of the triggers.
+	SDI bool
+	// ColumnPrefix - prefix of the service columns of the tracking table, [DefaultColumnPrefix] if it's empty.
+	// The tracking table keeps the prefix it was created with.
+	ColumnPrefix string
+}
+
+// columns returns the names of the service columns of the tracking table.
+func (p CDCSetupParams) columns() serviceColumns {
+	return newServiceColumns(p.ColumnPrefix)
+}
+
+// captures returns true if the triggers capture the operation.
+func (p CDCSetupParams) captures(operation actionType) bool {
+	return len(p.Operations) == 0 || slices.Contains(toActionTypes(p.Operations), operation)
+}
+
+// SetupCDC creates the tracking table, adds missing columns to it and sets the triggers or the remote
+// subscription, which capture changes of the table into it.
+func SetupCDC(ctx context.Context, db *sqlx.DB, params CDCSetupParams) error {
 	var trackingTableExist bool
 
 	tx, err := db.Begin()
@@ -397,7 +953,7 @@ func setupCDC(
 	defer tx.Rollback() // nolint:errcheck,nolintlint
 
 	// check if table exist.
-	rows, err := tx.QueryContext(ctx, queryIfTableExist, trackingTableName)
+	rows, err := tx.QueryContext(ctx, queryIfTableExist, params.TrackingTableName)
 	if err != nil {
 		return fmt.Errorf("execute query exist table: %w", err)
 	}
@@ -419,20 +975,125 @@ func setupCDC(
 		return fmt.Errorf("iterate rows error: %w", rows.Err())
 	}
 
+	if err = checkServiceColumns(ctx, tx, params, trackingTableExist); err != nil {
+		return fmt.Errorf("check service columns: %w", err)
+	}
+
+	columns := params.columns()
+
 	if !trackingTableExist {
 		// create tracking table
-		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryCreateTable, trackingTableName, tableInfo.GetColumnQueryPart(),
-			columnOperationType, columnTrackingID))
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryCreateTable, params.TrackingTableName,
+			params.TableInfo.GetColumnQueryPart(), columns.operationType, columns.trackingID))
 		if err != nil {
 			return fmt.Errorf("create tracking table: %w", err)
 		}
 	}
 
-	// setup triggers for catch insert, delete, update operations.
-	err = setTriggers(ctx, tx, tableInfo.ColumnTypes, tableName,
-		trackingTableName, trackingTableName[len(trackingTableName)-6:])
+	if params.ChangedColumnsOnly {
+		err = addTrackingColumn(ctx, tx, params.TrackingTableName, columns.changedColumns,
+			fmt.Sprintf("VARCHAR(%d)", maxChangedColumnsLength))
+		if err != nil {
+			return fmt.Errorf("add changed columns column: %w", err)
+		}
+	}
+
+	if params.TransactionID {
+		if err = addTrackingColumn(ctx, tx, params.TrackingTableName, columns.transactionID, "BIGINT"); err != nil {
+			return fmt.Errorf("add transaction id column: %w", err)
+		}
+	}
+
+	// the remote subscription fills the changed at column.
+	if params.ChangedAt || params.SDI {
+		if err = addTrackingColumn(ctx, tx, params.TrackingTableName, columns.changedAt, typeChangedAt); err != nil {
+			return fmt.Errorf("add changed at column: %w", err)
+		}
+	}
+
+	if params.SDI {
+		if err = setSubscription(ctx, tx, params); err != nil {
+			return fmt.Errorf("setup remote subscription: %w", err)
+		}
+	} else {
+		// setup triggers for catch insert, delete, update operations.
+		err = setTriggers(ctx, tx, params)
+		if err != nil {
+			return fmt.Errorf("setup triggers: %w", err)
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
-		return fmt.Errorf("setup triggers: %w", err)
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// repairCDC adds new columns to the tracking table, alters the changed ones and recreates the triggers,
+// so the triggers capture the current columns of the table. Columns of the tracking table are never dropped,
+// rows captured before the change keep their values.
+func repairCDC(ctx context.Context, db *sqlx.DB, params CDCSetupParams, change columntypes.SchemaChange) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+
+	defer conn.Close() //nolint:errcheck // the connection is returned to the pool
+
+	// DDL statements are committed immediately by default, this makes them a part of the transaction.
+	_, err = conn.ExecContext(ctx, querySetDDLAutocommitOff)
+	if err != nil {
+		return fmt.Errorf("turn off ddl autocommit: %w", err)
+	}
+
+	defer conn.ExecContext(ctx, querySetDDLAutocommitOn) //nolint:errcheck // nothing to do with the error on the way out
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("create transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	// the shared tracking table can be already repaired by another pipeline.
+	trackingColumns, err := getTrackingColumns(ctx, tx, params.TrackingTableName, params.columns())
+	if err != nil {
+		return fmt.Errorf("get tracking columns: %w", err)
+	}
+
+	// a column named as a service column can be added to the table.
+	if err = params.columns().checkCollisions(params.TableInfo.ColumnTypes); err != nil {
+		return err
+	}
+
+	added := slices.DeleteFunc(slices.Clone(change.Added), func(column string) bool {
+		return slices.Contains(trackingColumns, column)
+	})
+
+	if len(added) > 0 {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryAddColumns, params.TrackingTableName,
+			params.TableInfo.GetColumnsQueryPart(added)))
+		if err != nil {
+			return fmt.Errorf("add tracking table columns: %w", err)
+		}
+	}
+
+	if len(change.Changed) > 0 {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryAlterColumns, params.TrackingTableName,
+			params.TableInfo.GetColumnsQueryPart(change.Changed)))
+		if err != nil {
+			return fmt.Errorf("alter tracking table columns: %w", err)
+		}
+	}
+
+	// the remote subscription applies the columns it was created with.
+	if !params.SDI {
+		err = setTriggers(ctx, tx, params)
+		if err != nil {
+			return fmt.Errorf("setup triggers: %w", err)
+		}
 	}
 
 	err = tx.Commit()
@@ -443,51 +1104,273 @@ func setupCDC(
 	return nil
 }
 
-func setTriggers(
-	ctx context.Context,
-	tx *sql.Tx,
-	columnTypes map[string]string,
-	tableName, trackingTableName, suffixName string,
-) error {
-	triggerInsertName := fmt.Sprintf(triggerNamePattern, tableName, insertOperation, suffixName)
-	triggerUpdateName := fmt.Sprintf(triggerNamePattern, tableName, updateOperation, suffixName)
-	triggerDeleteName := fmt.Sprintf(triggerNamePattern, tableName, deleteOperation, suffixName)
-
-	columnNames := make([]string, len(columnTypes))
-	nwVal := make([]string, len(columnTypes))
-	olVal := make([]string, len(columnTypes))
-
-	i := 0
-	for key := range columnTypes {
-		columnNames[i] = key
-		nwVal[i] = fmt.Sprintf(":nw.%s", key)
-		olVal[i] = fmt.Sprintf(":rw.%s", key)
-		i++
-	}
-
-	//nolint:makezero // add operation type column to existing columns.
-	columnNames = append(columnNames, columnOperationType)
-
-	// add trigger to catch insert.
-	_, err := tx.ExecContext(ctx, fmt.Sprintf(queryAddInsertTrigger, triggerInsertName, tableName, trackingTableName,
-		strings.Join(columnNames, ","), strings.Join(nwVal, ",")))
+// addTrackingColumn adds the service column, like the bitmap of changed columns, to the tracking table,
+// if it doesn't have it yet.
+func addTrackingColumn(ctx context.Context, tx *sql.Tx, trackingTableName, column, columnType string) error {
+	var count int
+
+	err := tx.QueryRowContext(ctx, queryIfColumnExist, trackingTableName, column).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("add trigger catch insert: %w", err)
+		return fmt.Errorf("check column exists: %w", err)
+	}
+
+	if count > 0 {
+		return nil
 	}
 
-	// add trigger to catch update.
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryUpdateTrigger, triggerUpdateName, tableName, trackingTableName,
-		strings.Join(columnNames, ","), strings.Join(nwVal, ",")))
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryAddColumns, trackingTableName,
+		fmt.Sprintf("%s %s", column, columnType)))
 	if err != nil {
-		return fmt.Errorf("add trigger catch update: %w", err)
+		return fmt.Errorf("add column: %w", err)
 	}
 
-	// add trigger to catch delete.
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryDeleteTrigger, triggerDeleteName, tableName, trackingTableName,
-		strings.Join(columnNames, ","), strings.Join(olVal, ",")))
+	return nil
+}
+
+// getTrackingColumns returns the columns of the tracking table, which store values of the table,
+// in the order of their positions. The order is stable, because new columns are added to the end,
+// and columns are never dropped, so it's used for the bitmap of changed columns.
+func getTrackingColumns(
+	ctx context.Context, querier columntypes.Querier, trackingTableName string, serviceColumns serviceColumns,
+) ([]string, error) {
+	rows, err := querier.QueryContext(ctx, queryGetTableColumns, trackingTableName)
 	if err != nil {
-		return fmt.Errorf("add trigger catch delete: %w", err)
+		return nil, fmt.Errorf("query tracking table columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+
+	for rows.Next() {
+		var column string
+		if er := rows.Scan(&column); er != nil {
+			return nil, fmt.Errorf("scan rows: %w", er)
+		}
+
+		if !serviceColumns.contains(column) {
+			columns = append(columns, column)
+		}
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate rows error: %w", rows.Err())
+	}
+
+	return columns, nil
+}
+
+// setTriggers creates the triggers on the base table, the names of the triggers are based on the table name,
+// which can be a synonym.
+func setTriggers(ctx context.Context, tx *sql.Tx, params CDCSetupParams) error {
+	suffixName := params.TrackingTableName[len(params.TrackingTableName)-suffixLength:]
+	subjectTable := params.TableInfo.QualifiedName()
+
+	columnNames := make([]string, 0, len(params.TableInfo.ColumnTypes))
+	nwVal := make([]string, 0, len(params.TableInfo.ColumnTypes))
+	olVal := make([]string, 0, len(params.TableInfo.ColumnTypes))
+
+	for _, key := range sortedColumns(params.TableInfo.ColumnTypes) {
+		column := columntypes.Identifier(key, params.TableInfo.QuoteIdentifiers)
+
+		columnNames = append(columnNames, column)
+		nwVal = append(nwVal, fmt.Sprintf(":nw.%s", column))
+		olVal = append(olVal, fmt.Sprintf(":rw.%s", column))
+	}
+
+	updateColumns, updateVal := columnNames, nwVal
+	columns := params.columns()
+
+	if params.ChangedColumnsOnly {
+		trackingColumns, err := getTrackingColumns(ctx, tx, params.TrackingTableName, columns)
+		if err != nil {
+			return fmt.Errorf("get tracking columns: %w", err)
+		}
+
+		updateColumns, updateVal = changedColumnsValues(params.TableInfo.ColumnTypes, params.Keys, trackingColumns,
+			columns.changedColumns, params.TableInfo.QuoteIdentifiers)
+	}
+
+	var extraColumns, extraValues []string
+	if params.TransactionID {
+		extraColumns, extraValues = []string{columns.transactionID}, []string{valueTransactionID}
+	}
+
+	nwVal, olVal = slices.Concat(nwVal, extraValues), slices.Concat(olVal, extraValues)
+	updateVal = slices.Concat(updateVal, extraValues)
+
+	// add operation type column to existing columns.
+	insertColumns := strings.Join(slices.Concat(columnNames, extraColumns, []string{columns.operationType}), ",")
+	updateColumnsPart := strings.Join(slices.Concat(updateColumns, extraColumns, []string{columns.operationType}), ",")
+
+	triggers := []struct {
+		operation actionType
+		query     string
+		columns   string
+		values    []string
+	}{
+		{operation: insertOperation, query: queryAddInsertTrigger, columns: insertColumns, values: nwVal},
+		{operation: updateOperation, query: queryUpdateTrigger, columns: updateColumnsPart, values: updateVal},
+		{operation: deleteOperation, query: queryDeleteTrigger, columns: insertColumns, values: olVal},
+	}
+
+	for _, trigger := range triggers {
+		triggerName := formatTriggerName(params.TableName, trigger.operation, suffixName)
+
+		// the trigger of a skipped operation can be left from the previous run.
+		if !params.captures(trigger.operation) {
+			if err := dropTrigger(ctx, tx, triggerName); err != nil {
+				return fmt.Errorf("drop trigger catch %s: %w", strings.ToLower(string(trigger.operation)), err)
+			}
+
+			continue
+		}
+
+		query := fmt.Sprintf(trigger.query, triggerName, subjectTable,
+			params.TrackingTableName, trigger.columns, strings.Join(trigger.values, ","))
+
+		if err := createTrigger(ctx, tx, triggerName, query); err != nil {
+			return fmt.Errorf("add trigger catch %s: %w", strings.ToLower(string(trigger.operation)), err)
+		}
+	}
+
+	return nil
+}
+
+// createTrigger creates the trigger by the query. A trigger with the same name can be left from a crashed run,
+// it's kept if its definition is the same, and it's dropped and created again otherwise.
+func createTrigger(ctx context.Context, tx *sql.Tx, triggerName, query string) error {
+	definition, exists, err := getTriggerDefinition(ctx, tx, triggerName)
+	if err != nil {
+		return fmt.Errorf("get trigger definition: %w", err)
+	}
+
+	if exists {
+		if sameDefinition(definition, query) {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryDropTrigger, triggerName))
+		if err != nil {
+			return fmt.Errorf("execute drop trigger query: %w", err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("execute create trigger query: %w", err)
 	}
 
 	return nil
 }
+
+// getTriggerDefinition returns the definition of the trigger and whether the trigger exists.
+func getTriggerDefinition(ctx context.Context, tx *sql.Tx, triggerName string) (string, bool, error) {
+	var definition strings.Builder
+
+	err := tx.QueryRowContext(ctx, queryGetTriggerDefinition, triggerName).Scan(hdbdriver.NewLob(nil, &definition))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("select trigger definition: %w", err)
+	}
+
+	return definition.String(), true, nil
+}
+
+// sameDefinition compares definitions of triggers ignoring the whitespace layout.
+func sameDefinition(a, b string) bool {
+	return slices.Equal(strings.Fields(a), strings.Fields(b))
+}
+
+// dropTrigger drops the trigger, if it exists.
+func dropTrigger(ctx context.Context, tx *sql.Tx, triggerName string) error {
+	var count int
+
+	err := tx.QueryRowContext(ctx, queryIfTriggerExist, triggerName).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check trigger exists: %w", err)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryDropTrigger, triggerName))
+	if err != nil {
+		return fmt.Errorf("execute drop trigger query: %w", err)
+	}
+
+	return nil
+}
+
+// changedColumnsValues returns the columns and the values of the update trigger, which captures only changed columns.
+// Keys and columns which can't be compared, like LOBs, are always captured. The last value is the bitmap
+// of changed columns, with a character per tracking table column, '1' for a changed column and '0' otherwise,
+// it's stored in the changedColumnsColumn. Column names are quoted if quote is true.
+func changedColumnsValues(
+	columnTypes map[string]string,
+	keys, trackingColumns []string,
+	changedColumnsColumn string,
+	quote bool,
+) ([]string, []string) {
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+
+	columns := make([]string, 0, len(columnTypes)+1)
+	values := make([]string, 0, len(columnTypes)+1)
+
+	for _, column := range sortedColumns(columnTypes) {
+		identifier := columntypes.Identifier(column, quote)
+
+		columns = append(columns, identifier)
+
+		if isKey[column] || !columntypes.IsComparable(columnTypes[column]) {
+			values = append(values, fmt.Sprintf(":nw.%s", identifier))
+
+			continue
+		}
+
+		values = append(values, fmt.Sprintf("CASE WHEN %s THEN :nw.%s END", changedCondition(identifier), identifier))
+	}
+
+	bits := make([]string, len(trackingColumns))
+	for i, column := range trackingColumns {
+		columnType, ok := columnTypes[column]
+
+		switch {
+		// the column was dropped from the table.
+		case !ok:
+			bits[i] = "'0'"
+		case !columntypes.IsComparable(columnType):
+			bits[i] = "'1'"
+		default:
+			bits[i] = fmt.Sprintf("CASE WHEN %s THEN '1' ELSE '0' END",
+				changedCondition(columntypes.Identifier(column, quote)))
+		}
+	}
+
+	columns = append(columns, changedColumnsColumn)
+	values = append(values, strings.Join(bits, " || "))
+
+	return columns, values
+}
+
+// changedCondition returns the condition of the update trigger, which is true if the column value was changed.
+func changedCondition(column string) string {
+	return fmt.Sprintf("(:nw.%[1]s <> :rw.%[1]s OR (:nw.%[1]s IS NULL AND :rw.%[1]s IS NOT NULL) "+
+		"OR (:nw.%[1]s IS NOT NULL AND :rw.%[1]s IS NULL))", column)
+}
+
+// sortedColumns returns the column names in alphabetical order, so the triggers are deterministic.
+func sortedColumns(columnTypes map[string]string) []string {
+	columns := make([]string, 0, len(columnTypes))
+	for column := range columnTypes {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+
+	return columns
+}
@@ -0,0 +1,501 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/jmoiron/sqlx"
+)
+
+// trackingRow returns the rows of the tracking table positioned at the single row of the values.
+func trackingRow(t *testing.T, columns []string, values ...driver.Value) *sqlx.Rows {
+	t.Helper()
+
+	db := fakedb.New(func(string, []any) fakedb.Result {
+		return fakedb.Result{Columns: columns, Rows: [][]driver.Value{values}}
+	})
+
+	rows, err := db.Open().QueryxContext(context.Background(), "SELECT * FROM TT")
+	if err != nil {
+		t.Fatalf("query tracking table: %v", err)
+	}
+
+	t.Cleanup(func() { rows.Close() })
+
+	if !rows.Next() {
+		t.Fatalf("tracking table has no rows: %v", rows.Err())
+	}
+
+	return rows
+}
+
+func TestChangedColumnsValues(t *testing.T) {
+	t.Parallel()
+
+	columnTypes := map[string]string{"ID": "INTEGER", "NAME": "VARCHAR", "NOTES": "NCLOB"}
+
+	// FAX was dropped from the table, but it's still in the tracking table.
+	columns, values := changedColumnsValues(columnTypes, []string{"ID"}, []string{"ID", "FAX", "NAME", "NOTES"},
+		"CONDUIT_CHANGED_COLUMNS", false)
+
+	wantColumns := []string{"ID", "NAME", "NOTES", "CONDUIT_CHANGED_COLUMNS"}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Errorf("columns = %v, want %v", columns, wantColumns)
+	}
+
+	if values[0] != ":nw.ID" || values[2] != ":nw.NOTES" {
+		t.Errorf("keys and large objects must be always captured, got %v", values)
+	}
+
+	if !strings.HasPrefix(values[1], "CASE WHEN (:nw.NAME <> :rw.NAME") || !strings.HasSuffix(values[1], "THEN :nw.NAME END") {
+		t.Errorf("unexpected value of the NAME column: %s", values[1])
+	}
+
+	bits := strings.Split(values[3], " || ")
+	if len(bits) != 4 || bits[1] != "'0'" || bits[3] != "'1'" {
+		t.Errorf("unexpected bitmap: %s", values[3])
+	}
+
+	columns, values = changedColumnsValues(columnTypes, []string{"ID"}, []string{"ID", "NAME"}, "CONDUIT_CHANGED_COLUMNS", true)
+
+	if columns[1] != `"NAME"` || !strings.HasSuffix(values[1], `THEN :nw."NAME" END`) {
+		t.Errorf("unexpected quoted NAME column: %s %s", columns[1], values[1])
+	}
+}
+
+func TestCDCIterator_decodeChangedColumns(t *testing.T) {
+	t.Parallel()
+
+	it := &CDCIterator{
+		keys:            []string{"ID"},
+		trackingColumns: []string{"ID", "NAME", "AGE"},
+	}
+
+	if got := it.decodeChangedColumns(nil); got != nil {
+		t.Errorf("expected nil for a row without bitmap, got %v", got)
+	}
+
+	changed := it.decodeChangedColumns([]byte("001"))
+	if !reflect.DeepEqual(changed, []string{"AGE"}) {
+		t.Errorf("decodeChangedColumns() = %v, want [AGE]", changed)
+	}
+
+	row := map[string]any{"ID": 1, "NAME": nil, "AGE": 42}
+	it.keepColumns(row, changed)
+
+	if !reflect.DeepEqual(row, map[string]any{"ID": 1, "AGE": 42}) {
+		t.Errorf("keepColumns() = %v", row)
+	}
+}
+
+func TestCDCSetupParams_captures(t *testing.T) {
+	t.Parallel()
+
+	all := CDCSetupParams{}
+	if !all.captures(deleteOperation) {
+		t.Errorf("all operations must be captured by default")
+	}
+
+	noDeletes := CDCSetupParams{Operations: []string{"create", "update"}}
+	if !noDeletes.captures(insertOperation) || !noDeletes.captures(updateOperation) {
+		t.Errorf("inserts and updates must be captured")
+	}
+
+	if noDeletes.captures(deleteOperation) {
+		t.Errorf("deletes mustn't be captured")
+	}
+}
+
+func TestSameDefinition(t *testing.T) {
+	t.Parallel()
+
+	query := fmt.Sprintf(queryAddInsertTrigger, "CONDUIT_CLIENTS_INSERT_ABC", "CLIENTS",
+		"CONDUIT_TRACKING_CLIENTS_ABC", "ID,NAME,CONDUIT_OPERATION_TYPE", ":nw.ID,:nw.NAME")
+
+	stored := "CREATE TRIGGER CONDUIT_CLIENTS_INSERT_ABC AFTER INSERT ON CLIENTS REFERENCING NEW ROW nw, OLD ROW rw " +
+		"FOR EACH ROW BEGIN INSERT INTO CONDUIT_TRACKING_CLIENTS_ABC (ID,NAME,CONDUIT_OPERATION_TYPE) " +
+		"VALUES(:nw.ID,:nw.NAME, 'INSERT'); END"
+
+	if !sameDefinition(stored, query) {
+		t.Errorf("definitions with a different layout must be the same")
+	}
+
+	if sameDefinition(strings.Replace(stored, ",:nw.NAME", "", 1), query) {
+		t.Errorf("definitions with different values must differ")
+	}
+}
+
+func TestCompactRows(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{
+		{"CONDUIT_TRACKING_ID": int64(1), "ID": int64(1), "NAME": "a"},
+		{"CONDUIT_TRACKING_ID": int64(2), "ID": int64(2), "NAME": "b"},
+		{"CONDUIT_TRACKING_ID": int64(3), "ID": int64(1), "NAME": "c"},
+		{"CONDUIT_TRACKING_ID": int64(4), "ID": int64(1), "NAME": "d"},
+	}
+
+	compacted, superseded, err := compactRows(rows, []string{"ID"}, "CONDUIT_TRACKING_ID")
+	if err != nil {
+		t.Fatalf("compact rows: %v", err)
+	}
+
+	if !reflect.DeepEqual(compacted, []map[string]any{rows[1], rows[3]}) {
+		t.Errorf("compacted = %v, want the latest rows of every key in the tracking order", compacted)
+	}
+
+	if !reflect.DeepEqual(superseded, map[int][]any{4: {1, 3}}) {
+		t.Errorf("superseded = %v, want ids 1 and 3 replaced by 4", superseded)
+	}
+}
+
+func TestCDCIterator_Stop(t *testing.T) {
+	t.Parallel()
+
+	// never is the channel of a delete which runs until it's cancelled.
+	never := make(chan struct{})
+
+	tests := []struct {
+		name        string
+		deleteWait  <-chan struct{}
+		stopTimeout time.Duration
+		ctxTimeout  time.Duration
+		wantErr     error
+	}{
+		{
+			name:        "clearing finished",
+			stopTimeout: time.Minute,
+		},
+		{
+			name:        "clearing cancelled after the stop timeout",
+			deleteWait:  never,
+			stopTimeout: 10 * time.Millisecond,
+			wantErr:     context.Canceled,
+		},
+		{
+			name:        "clearing cancelled with the context",
+			deleteWait:  never,
+			stopTimeout: time.Minute,
+			ctxTimeout:  10 * time.Millisecond,
+			wantErr:     context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, _ []any) fakedb.Result {
+				if strings.HasPrefix(query, "DELETE") {
+					return fakedb.Result{RowsAffected: 1, Wait: tt.deleteWait}
+				}
+
+				return fakedb.Result{}
+			})
+
+			it := &CDCIterator{
+				db:            db.Open(),
+				trackingTable: "CONDUIT_CLIENTS_150405",
+				stopTimeout:   tt.stopTimeout,
+				tableSrv:      newTrackingTableService(),
+				columns:       newServiceColumns(""),
+			}
+			it.tableSrv.idsForRemoving = []any{1, 2}
+			it.startClearing(context.Background())
+
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+
+			if err := it.Stop(ctx); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+
+			// the clearing goroutine is done before the connection is closed.
+			select {
+			case <-it.tableSrv.done:
+			default:
+				t.Fatal("clearing is still running")
+			}
+
+			var err error
+			if len(it.tableSrv.errCh) > 0 {
+				err = <-it.tableSrv.errCh
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("clearing error = %v, want %v", err, tt.wantErr)
+			}
+
+			queries := db.Queries()
+			if tt.wantErr == nil && queries[len(queries)-1] != fakedb.Commit {
+				t.Errorf("queries = %q, want the clearing committed", queries)
+			}
+		})
+	}
+}
+
+func TestAddTrackingColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		exists bool
+		want   []string
+	}{
+		{
+			name:   "column exists",
+			exists: true,
+		},
+		{
+			name: "column is missing",
+			want: []string{"ALTER TABLE TT ADD (CONDUIT_TRANSACTION_ID BIGINT)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, _ []any) fakedb.Result {
+				if query == queryIfColumnExist {
+					if tt.exists {
+						return fakedb.Value(int64(1))
+					}
+
+					return fakedb.Value(int64(0))
+				}
+
+				return fakedb.Result{}
+			})
+
+			tx, err := db.Open().BeginTx(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("begin: %v", err)
+			}
+			defer tx.Rollback() //nolint:errcheck // the transaction is only used by the test
+
+			err = addTrackingColumn(context.Background(), tx, "TT", "CONDUIT_TRANSACTION_ID", "BIGINT")
+			if err != nil {
+				t.Fatalf("addTrackingColumn() error = %v", err)
+			}
+
+			var got []string
+			for _, query := range db.Queries() {
+				if query != fakedb.Begin && query != queryIfColumnExist {
+					got = append(got, query)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTriggers_TransactionID(t *testing.T) {
+	t.Parallel()
+
+	db := fakedb.New(nil)
+
+	tx, err := db.Open().BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // the transaction is only used by the test
+
+	err = setTriggers(context.Background(), tx, CDCSetupParams{
+		TableName:         "ORDERS",
+		TrackingTableName: "CONDUIT_ORDERS_ABC123",
+		TableInfo: columntypes.TableInfo{
+			Schema:      "SALES",
+			Name:        "ORDERS",
+			ColumnTypes: map[string]string{"ID": "INTEGER", "NAME": "NVARCHAR"},
+		},
+		TransactionID: true,
+	})
+	if err != nil {
+		t.Fatalf("setTriggers() error = %v", err)
+	}
+
+	var got []string
+	for _, query := range db.Queries() {
+		if strings.Contains(query, "CREATE TRIGGER") {
+			got = append(got, strings.Join(strings.Fields(query), " "))
+		}
+	}
+
+	want := []string{
+		"INSERT INTO CONDUIT_ORDERS_ABC123 (ID,NAME,CONDUIT_TRANSACTION_ID,CONDUIT_OPERATION_TYPE) " +
+			"VALUES(:nw.ID,:nw.NAME,CURRENT_UPDATE_TRANSACTION(), 'INSERT');",
+		"INSERT INTO CONDUIT_ORDERS_ABC123 (ID,NAME,CONDUIT_TRANSACTION_ID,CONDUIT_OPERATION_TYPE) " +
+			"VALUES(:nw.ID,:nw.NAME,CURRENT_UPDATE_TRANSACTION(), 'UPDATE');",
+		"INSERT INTO CONDUIT_ORDERS_ABC123 (ID,NAME,CONDUIT_TRANSACTION_ID,CONDUIT_OPERATION_TYPE) " +
+			"VALUES(:rw.ID,:rw.NAME,CURRENT_UPDATE_TRANSACTION(), 'DELETE');",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("triggers = %v, want %d triggers", got, len(want))
+	}
+
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("trigger = %s, want it to contain %s", got[i], want[i])
+		}
+	}
+}
+
+func TestCDCIterator_Next_TransactionID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		columns []string
+		values  []driver.Value
+		want    string
+	}{
+		{
+			name:    "captured transaction id",
+			columns: []string{"ID", "CONDUIT_TRACKING_ID", "CONDUIT_OPERATION_TYPE", "CONDUIT_TRANSACTION_ID"},
+			values:  []driver.Value{int64(1), int64(7), []byte("INSERT"), int64(42)},
+			want:    "42",
+		},
+		{
+			name:    "row captured before the column was added",
+			columns: []string{"ID", "CONDUIT_TRACKING_ID", "CONDUIT_OPERATION_TYPE", "CONDUIT_TRANSACTION_ID"},
+			values:  []driver.Value{int64(1), int64(7), []byte("INSERT"), nil},
+		},
+		{
+			name:    "column isn't captured",
+			columns: []string{"ID", "CONDUIT_TRACKING_ID", "CONDUIT_OPERATION_TYPE"},
+			values:  []driver.Value{int64(1), int64(7), []byte("INSERT")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := &CDCIterator{
+				rows:    trackingRow(t, tt.columns, tt.values...),
+				table:   "ORDERS",
+				keys:    []string{"ID"},
+				columns: newServiceColumns(""),
+			}
+
+			record, err := i.Next(context.Background())
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+
+			if got := record.Metadata[metadataTransactionID]; got != tt.want {
+				t.Errorf("transaction id = %q, want %q", got, tt.want)
+			}
+
+			var payload map[string]any
+			if err := json.Unmarshal(record.Payload.After.Bytes(), &payload); err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
+			}
+
+			if _, ok := payload["CONDUIT_TRANSACTION_ID"]; ok {
+				t.Errorf("payload = %v, want it without the transaction id column", payload)
+			}
+		})
+	}
+}
+
+func TestCDCIterator_Next_DebugMetadata(t *testing.T) {
+	t.Parallel()
+
+	capturedAt := time.Date(2024, 3, 1, 10, 0, 0, 123000000, time.UTC)
+	columns := []string{"ID", "CONDUIT_TRACKING_ID", "CONDUIT_OPERATION_TYPE", "CONDUIT_CHANGED_AT"}
+
+	tests := []struct {
+		name          string
+		debugMetadata bool
+		changedAt     driver.Value
+		want          map[string]string
+	}{
+		{
+			name:      "disabled",
+			changedAt: capturedAt,
+			want:      map[string]string{},
+		},
+		{
+			name:          "enabled",
+			debugMetadata: true,
+			changedAt:     capturedAt,
+			want: map[string]string{
+				metadataTrackingID:        "7",
+				metadataTrackingOperation: "UPDATE",
+				metadataCapturedAt:        "2024-03-01T10:00:00.123Z",
+			},
+		},
+		{
+			name:          "row captured before the changed at column was added",
+			debugMetadata: true,
+			want: map[string]string{
+				metadataTrackingID:        "7",
+				metadataTrackingOperation: "UPDATE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := &CDCIterator{
+				rows:          trackingRow(t, columns, int64(1), int64(7), []byte("UPDATE"), tt.changedAt),
+				table:         "ORDERS",
+				keys:          []string{"ID"},
+				columns:       newServiceColumns(""),
+				debugMetadata: tt.debugMetadata,
+				lag:           &lagTracker{},
+			}
+
+			record, err := i.Next(context.Background())
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, key := range []string{metadataTrackingID, metadataTrackingOperation, metadataCapturedAt} {
+				if value, ok := record.Metadata[key]; ok {
+					got[key] = value
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadata = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// checksum returns the hex encoded SHA-256 hash of the JSON of the data with sorted field names, so the same row
+// has the same checksum whatever the order of its fields is. HTML characters aren't escaped and there's no trailing
+// newline, e.g. {"ID":1,"NAME":"<b>"}. It's empty if the data is empty.
+func checksum(data opencdc.Data) (string, error) {
+	if data == nil || len(data.Bytes()) == 0 {
+		return "", nil
+	}
+
+	var fields map[string]any
+
+	switch data := data.(type) {
+	case opencdc.StructuredData:
+		fields = data
+	default:
+		// use json.Number to keep precision of big integers and decimals.
+		decoder := json.NewDecoder(bytes.NewReader(data.Bytes()))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&fields); err != nil {
+			return "", fmt.Errorf("unmarshal data: %w", err)
+		}
+	}
+
+	// maps are encoded with sorted keys.
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(fields); err != nil {
+		return "", fmt.Errorf("marshal data: %w", err)
+	}
+
+	hash := sha256.Sum256(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	// sha256 of {"AMOUNT":12.5,"ID":1,"NAME":"<b>"}.
+	const want = "03a156e4e13e377302763d02357029deb09989251dcffd510ed945af829407ad"
+
+	tests := []struct {
+		name string
+		data opencdc.Data
+		want string
+	}{
+		{
+			name: "structured data",
+			data: opencdc.StructuredData{"NAME": "<b>", "ID": 1, "AMOUNT": json.Number("12.5")},
+			want: want,
+		},
+		{
+			name: "raw data in another order",
+			data: opencdc.RawData(`{"ID": 1, "AMOUNT": 12.5, "NAME": "<b>"}`),
+			want: want,
+		},
+		{
+			name: "empty",
+			data: nil,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := checksum(tt.data)
+			if err != nil {
+				t.Fatalf("checksum: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("checksum() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// claimsTable - table with the connector instances reading the tracking tables exclusively.
+const claimsTable = "CONDUIT_CLAIMS"
+
+// claimTrackingTable makes the owner the only instance reading the tracking table as the consumer.
+// The claim of the previous owner is taken over, so the previous owner stops at its next batch.
+// The claim row is locked while the previous owner checks it, so the takeover waits for its running check.
+func claimTrackingTable(ctx context.Context, db *sqlx.DB, trackingTable, consumer, owner string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	var count int
+
+	err = tx.QueryRowContext(ctx, queryIfTableExist, claimsTable).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check claims table exists: %w", err)
+	}
+
+	if count == 0 {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(queryCreateClaimsTable, claimsTable)); err != nil {
+			return fmt.Errorf("create claims table: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryUpsertClaim, claimsTable), trackingTable, consumer, owner)
+	if err != nil {
+		return fmt.Errorf("save claim: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkClaim returns ErrTrackingTableClaimed if another instance has taken over the tracking table.
+// The claim row stays locked until the transaction ends, so it can't be taken over in the meantime.
+func checkClaim(ctx context.Context, tx *sql.Tx, trackingTable, consumer, owner string) error {
+	var claimant string
+
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(querySelectClaimForUpdate, claimsTable), trackingTable, consumer).
+		Scan(&claimant)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("select claim: %w", err)
+	}
+
+	if claimant != owner {
+		return fmt.Errorf("%w: tracking table %s, owner %s", ErrTrackingTableClaimed, trackingTable, claimant)
+	}
+
+	return nil
+}
+
+// verifyClaim returns ErrTrackingTableClaimed if another instance has taken over the tracking table,
+// it's called before every batch of the tracking rows is read.
+func (i *CDCIterator) verifyClaim(ctx context.Context) error {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	if err = checkClaim(ctx, tx, i.trackingTable, i.consumer, i.owner); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
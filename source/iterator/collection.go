@@ -0,0 +1,307 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	queryGetMaxDocumentValue = `SELECT max(%s) FROM %s`
+	querySelectDocuments     = `SELECT * FROM %s %s ORDER BY %s LIMIT %d`
+)
+
+// CollectionIterator reads documents of a HANA JSON Document Store collection.
+// Documents are read in batches ordered by the ordering field. The first time the iterator starts work,
+// it gets max value from the ordering field and returns documents up to it as snapshot records,
+// documents with greater values are polled after that and returned as create records.
+// Changes of existing documents aren't captured, unless the ordering field is updated with them.
+type CollectionIterator struct {
+	db *sqlx.DB
+
+	// collection - name of the collection.
+	collection string
+	// orderingField - name of the top-level document field the iterator uses for ordering documents.
+	orderingField string
+	// batchSize size of batch.
+	batchSize int
+	// position last recorded position.
+	position *position.Position
+	// documents - the loaded batch of documents.
+	documents []map[string]any
+}
+
+// CollectionParams is an incoming params for the [NewCollectionIterator] function.
+type CollectionParams struct {
+	DB            *sqlx.DB
+	Collection    string
+	OrderingField string
+	BatchSize     int
+	Snapshot      bool
+	SdkPosition   opencdc.Position
+}
+
+// NewCollectionIterator - create new iterator for the document store collection.
+func NewCollectionIterator(ctx context.Context, params CollectionParams) (*CollectionIterator, error) {
+	pos, err := position.ParseSDKPosition(params.SdkPosition)
+	if err != nil {
+		return nil, fmt.Errorf("parse position: %w", err)
+	}
+
+	it := &CollectionIterator{
+		db:            params.DB,
+		collection:    params.Collection,
+		orderingField: params.OrderingField,
+		batchSize:     params.BatchSize,
+		position:      pos,
+	}
+
+	if it.position == nil {
+		maxValue, er := it.getMaxValue(ctx)
+		if er != nil {
+			return nil, fmt.Errorf("get max value: %w", er)
+		}
+
+		it.position = &position.Position{IteratorType: position.TypeCollection}
+
+		if params.Snapshot {
+			it.position.CollectionMaxValue = maxValue
+		} else {
+			// existing documents are skipped.
+			it.position.CollectionLastValue = maxValue
+		}
+	}
+
+	return it, nil
+}
+
+// HasNext returns a bool indicating whether the iterator has the next document to return or not.
+func (i *CollectionIterator) HasNext(ctx context.Context) (bool, error) {
+	if len(i.documents) > 0 {
+		return true, nil
+	}
+
+	if err := i.loadDocuments(ctx); err != nil {
+		return false, fmt.Errorf("load documents: %w", err)
+	}
+
+	// the snapshot is done, documents added after it are polled.
+	if len(i.documents) == 0 && i.position.CollectionMaxValue != nil {
+		i.position.CollectionMaxValue = nil
+
+		if err := i.loadDocuments(ctx); err != nil {
+			return false, fmt.Errorf("load documents: %w", err)
+		}
+	}
+
+	return len(i.documents) > 0, nil
+}
+
+// Next returns the next document as a record.
+func (i *CollectionIterator) Next(context.Context) (opencdc.Record, error) {
+	if len(i.documents) == 0 {
+		return opencdc.Record{}, ErrNoInitializedIterator
+	}
+
+	document := i.documents[0]
+	i.documents = i.documents[1:]
+
+	value, ok := document[i.orderingField]
+	if !ok || value == nil {
+		return opencdc.Record{}, fmt.Errorf("field %s: %w", i.orderingField, ErrNoOrderingColumn)
+	}
+
+	pos := position.Position{
+		IteratorType:        position.TypeCollection,
+		CollectionLastValue: orderingValue(value),
+		CollectionMaxValue:  i.position.CollectionMaxValue,
+	}
+
+	sdkPos, err := pos.ConvertToSDKPosition()
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("convert position %w", err)
+	}
+
+	i.position = &pos
+
+	metadata := opencdc.Metadata(map[string]string{metadataTable: i.collection})
+	metadata.SetCreatedAt(time.Now())
+
+	key := opencdc.StructuredData{i.orderingField: value}
+
+	if pos.CollectionMaxValue != nil {
+		return sdk.Util.Source.NewRecordSnapshot(sdkPos, metadata, key, opencdc.StructuredData(document)), nil
+	}
+
+	return sdk.Util.Source.NewRecordCreate(sdkPos, metadata, key, opencdc.StructuredData(document)), nil
+}
+
+// Stop closes the db connection.
+func (i *CollectionIterator) Stop(context.Context) error {
+	if i.db != nil {
+		if err := i.db.Close(); err != nil {
+			return fmt.Errorf("close db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Ack does nothing, documents aren't removed from the collection.
+func (i *CollectionIterator) Ack(ctx context.Context, rp opencdc.Position) error {
+	sdk.Logger(ctx).Debug().Str("position", string(rp)).Msg("got ack")
+
+	return nil
+}
+
+// getMaxValue returns max value of the ordering field, it's nil for an empty collection.
+func (i *CollectionIterator) getMaxValue(ctx context.Context) (any, error) {
+	var maxValue any
+
+	err := i.db.GetContext(ctx, &maxValue,
+		fmt.Sprintf(queryGetMaxDocumentValue, quoteField(i.orderingField), i.collection))
+	if err != nil {
+		return nil, fmt.Errorf("select max value: %w", err)
+	}
+
+	return documentValue(maxValue), nil
+}
+
+// loadDocuments selects a batch of documents, which come after the current position.
+func (i *CollectionIterator) loadDocuments(ctx context.Context) error {
+	field := quoteField(i.orderingField)
+
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if i.position.CollectionLastValue != nil {
+		conditions = append(conditions, field+" > ?")
+		args = append(args, i.position.CollectionLastValue)
+	}
+
+	if i.position.CollectionMaxValue != nil {
+		conditions = append(conditions, field+" <= ?")
+		args = append(args, i.position.CollectionMaxValue)
+	}
+
+	var where string
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := i.db.QueryxContext(ctx,
+		fmt.Sprintf(querySelectDocuments, i.collection, where, field, i.batchSize), args...)
+	if err != nil {
+		return fmt.Errorf("execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		if err = rows.Scan(&raw); err != nil {
+			return fmt.Errorf("scan document: %w", err)
+		}
+
+		document, er := parseDocument(raw)
+		if er != nil {
+			return er
+		}
+
+		i.documents = append(i.documents, document)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("iterate documents: %w", err)
+	}
+
+	return nil
+}
+
+// parseDocument parses the JSON document, numbers are kept as they are.
+func parseDocument(raw []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+
+	return document, nil
+}
+
+// documentValue converts a value of a document field selected by the query to a Go value.
+// The database returns JSON values, strings which aren't valid JSON are returned as they are.
+func documentValue(value any) any {
+	var raw []byte
+
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return v
+	}
+
+	if !json.Valid(raw) {
+		return string(raw)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var result any
+	if err := decoder.Decode(&result); err != nil {
+		return string(raw)
+	}
+
+	return orderingValue(result)
+}
+
+// orderingValue converts JSON numbers to numbers, so they are compared as numbers by the database.
+func orderingValue(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if v, err := number.Int64(); err == nil {
+		return v
+	}
+
+	if v, err := number.Float64(); err == nil {
+		return v
+	}
+
+	return number.String()
+}
+
+// quoteField quotes the document field name, field names are case-sensitive.
+func quoteField(field string) string {
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
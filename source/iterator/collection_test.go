@@ -0,0 +1,71 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	t.Parallel()
+
+	document, err := parseDocument([]byte(`{"_id": "a1", "price": 12345678901234567890, "tags": ["x"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"_id":   "a1",
+		"price": json.Number("12345678901234567890"),
+		"tags":  []any{"x"},
+	}
+
+	if !reflect.DeepEqual(document, want) {
+		t.Errorf("got %v, want %v", document, want)
+	}
+
+	if _, err = parseDocument([]byte(`not a document`)); err == nil {
+		t.Error("expected error for invalid document")
+	}
+}
+
+func TestDocumentValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "nil", value: nil, want: nil},
+		{name: "json string", value: []byte(`"a1"`), want: "a1"},
+		{name: "json integer", value: []byte(`42`), want: int64(42)},
+		{name: "json float", value: `4.5`, want: 4.5},
+		{name: "plain string", value: []byte(`2024-01-01`), want: "2024-01-01"},
+		{name: "number", value: int64(7), want: int64(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := documentValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultColumnPrefix - the prefix of the service columns of the tracking table, if it isn't set.
+const DefaultColumnPrefix = "CONDUIT_"
+
+// serviceColumns - names of the service columns of the tracking table, which are stored next to the columns
+// of the table. They share a prefix, which can be changed, if the table has columns with the default names.
+type serviceColumns struct {
+	operationType  string
+	trackingID     string
+	changedColumns string
+	transactionID  string
+	changedAt      string
+}
+
+// newServiceColumns returns the names of the service columns with the prefix,
+// the default prefix is used if it's empty.
+func newServiceColumns(prefix string) serviceColumns {
+	if prefix == "" {
+		prefix = DefaultColumnPrefix
+	}
+
+	return serviceColumns{
+		operationType:  prefix + "OPERATION_TYPE",
+		trackingID:     prefix + "TRACKING_ID",
+		changedColumns: prefix + "CHANGED_COLUMNS",
+		transactionID:  prefix + "TRANSACTION_ID",
+		changedAt:      prefix + "CHANGED_AT",
+	}
+}
+
+// names returns the names of all service columns.
+func (c serviceColumns) names() []string {
+	return []string{c.operationType, c.trackingID, c.changedColumns, c.transactionID, c.changedAt}
+}
+
+// contains returns true if the column is a service column.
+func (c serviceColumns) contains(column string) bool {
+	return slices.Contains(c.names(), column)
+}
+
+// checkCollisions returns ErrColumnPrefixCollision with the columns of the table named as the service columns,
+// the tracking table can't store both of them.
+func (c serviceColumns) checkCollisions(columnTypes map[string]string) error {
+	var collisions []string
+
+	for _, column := range sortedColumns(columnTypes) {
+		if c.contains(column) {
+			collisions = append(collisions, column)
+		}
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("%w: %s, set another column prefix", ErrColumnPrefixCollision, strings.Join(collisions, ", "))
+	}
+
+	return nil
+}
+
+// checkServiceColumns returns ErrColumnPrefixCollision if the table has columns named as the service columns,
+// and ErrColumnPrefixMismatch if the existing tracking table was created with another prefix.
+func checkServiceColumns(ctx context.Context, tx *sql.Tx, params CDCSetupParams, trackingTableExist bool) error {
+	columns := params.columns()
+
+	if err := columns.checkCollisions(params.TableInfo.ColumnTypes); err != nil {
+		return err
+	}
+
+	if !trackingTableExist {
+		return nil
+	}
+
+	var count int
+
+	err := tx.QueryRowContext(ctx, queryIfColumnExist, params.TrackingTableName, columns.trackingID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check column exists: %w", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("%w: %s has no %s column", ErrColumnPrefixMismatch, params.TrackingTableName,
+			columns.trackingID)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewServiceColumns(t *testing.T) {
+	t.Parallel()
+
+	if got := newServiceColumns("").trackingID; got != "CONDUIT_TRACKING_ID" {
+		t.Errorf("trackingID = %s, want the default prefix", got)
+	}
+
+	columns := newServiceColumns("CDC_")
+	if columns.operationType != "CDC_OPERATION_TYPE" || columns.changedAt != "CDC_CHANGED_AT" {
+		t.Errorf("columns = %+v, want the CDC_ prefix", columns)
+	}
+
+	if columns.contains("CONDUIT_TRACKING_ID") {
+		t.Errorf("columns with another prefix must not contain the default ones")
+	}
+}
+
+func TestServiceColumns_checkCollisions(t *testing.T) {
+	t.Parallel()
+
+	columnTypes := map[string]string{
+		"ID":                     "INTEGER",
+		"CONDUIT_TRACKING_ID":    "INTEGER",
+		"CONDUIT_OPERATION_TYPE": "VARCHAR",
+	}
+
+	err := newServiceColumns("").checkCollisions(columnTypes)
+	if !errors.Is(err, ErrColumnPrefixCollision) {
+		t.Fatalf("checkCollisions() = %v, want %v", err, ErrColumnPrefixCollision)
+	}
+
+	want := ErrColumnPrefixCollision.Error() + ": CONDUIT_OPERATION_TYPE, CONDUIT_TRACKING_ID, set another column prefix"
+	if err.Error() != want {
+		t.Errorf("checkCollisions() = %q, want %q", err, want)
+	}
+
+	if err = newServiceColumns("CDC_").checkCollisions(columnTypes); err != nil {
+		t.Errorf("checkCollisions() = %v, want nil for another prefix", err)
+	}
+}
@@ -15,14 +15,134 @@
 package iterator
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	hdbdriver "github.com/SAP/go-hdb/driver"
 )
 
 var (
 	ErrNoKey                     = errors.New("no key")
 	ErrNoOrderingColumn          = errors.New("no ordering column")
+	ErrNoTieBreakerColumn        = errors.New("no tie-breaker column")
 	ErrWrongTrackingIDType       = errors.New("tracking id wrong type")
 	ErrWrongTrackingOperatorType = errors.New("tracking column wrong type")
 	ErrUnknownOperatorType       = errors.New("unknown iterator type")
 	ErrNoInitializedIterator     = errors.New("not initialized iterator")
+	ErrReconnectAttemptsExceeded = errors.New("reconnect attempts exceeded")
+	ErrMissingPrivilege          = errors.New("missing privilege")
+	ErrNoValidityColumn          = errors.New("no validity time column")
+	ErrOrphanTrackingTable       = errors.New("orphan tracking table exists")
+	ErrMaskedOrderingColumn      = errors.New("ordering column can't be masked")
+	ErrTableRecreated            = errors.New("table was recreated")
+	ErrTrackingTableClaimed      = errors.New("tracking table is claimed by another instance")
+	ErrReadOnlyCDC               = errors.New("cdc can't be resumed on a read-only database")
+	ErrColumnPrefixCollision     = errors.New("table has columns named as the tracking table service columns")
+	ErrColumnPrefixMismatch      = errors.New("tracking table was created with another column prefix")
+	ErrConsumerExpired           = errors.New("offset of the consumer expired, changes after it may be deleted")
+
+	// ErrRetryable wraps read failures which are transient, e.g. a lost connection, a lock wait timeout
+	// or a deadlock, so reading can go on after the pipeline restarts.
+	ErrRetryable = errors.New("retryable error")
+	// ErrFatal wraps read failures which fail again if they are retried, e.g. a missing privilege or
+	// an unknown table or column.
+	ErrFatal = errors.New("fatal error")
+)
+
+// sap hana error codes of transient failures.
+const (
+	errCodeInternalRollback = 129
+	errCodeLockWaitTimeout  = 131
+	errCodeDeadlock         = 133
+	errCodeResourceBusy     = 146
 )
+
+// errCodeDuplicateTableName - sap hana error code of creating a table which already exists.
+const errCodeDuplicateTableName = 288
+
+// retryableErrorCodes are sap hana error codes of failures which can succeed if they are retried.
+var retryableErrorCodes = map[int]bool{
+	errCodeInternalRollback: true,
+	errCodeLockWaitTimeout:  true,
+	errCodeDeadlock:         true,
+	errCodeResourceBusy:     true,
+}
+
+func missingPrivilegeErr(privilege, objectType, object string) error {
+	return fmt.Errorf("%w %s on %s %s", ErrMissingPrivilege, privilege, strings.ToLower(objectType), object)
+}
+
+// classify wraps the error with its class, errors of unknown classes are returned as they are.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if class := errorClass(err); class != nil && !errors.Is(err, class) {
+		return fmt.Errorf("%w: %w", class, err)
+	}
+
+	return err
+}
+
+// errorClass returns ErrRetryable or ErrFatal for database errors, and nil for other errors.
+func errorClass(err error) error {
+	if errors.Is(err, ErrRetryable) {
+		return ErrRetryable
+	}
+
+	// reconnect attempts are already spent, as well as privileges aren't going to be granted by retries.
+	if errors.Is(err, ErrFatal) || errors.Is(err, ErrReconnectAttemptsExceeded) || errors.Is(err, ErrMissingPrivilege) ||
+		errors.Is(err, ErrTableRecreated) || errors.Is(err, ErrTrackingTableClaimed) ||
+		errors.Is(err, ErrConsumerExpired) {
+		return ErrFatal
+	}
+
+	var dbErr hdbdriver.DBError
+	if errors.As(err, &dbErr) {
+		if retryableErrorCodes[dbErr.Code()] {
+			return ErrRetryable
+		}
+
+		return ErrFatal
+	}
+
+	if isConnectionError(err) {
+		return ErrRetryable
+	}
+
+	return nil
+}
+
+// isAlreadyExists reports whether the error is caused by a table created concurrently by another pipeline.
+func isAlreadyExists(err error) bool {
+	var dbErr hdbdriver.DBError
+
+	return errors.As(err, &dbErr) && dbErr.Code() == errCodeDuplicateTableName
+}
+
+// isConnectionError reports whether the error is caused by a broken database connection.
+// A plain io.EOF isn't a connection error, it ends reads of many kinds, as well as network errors which
+// aren't failed operations on the connection, so a failing statement isn't retried by reconnecting forever.
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// reads and writes of the broken connection, as well as dials of the unreachable database.
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr)
+}
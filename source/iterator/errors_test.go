@@ -0,0 +1,158 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	hdbdriver "github.com/SAP/go-hdb/driver"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "bad connection",
+			err:  fmt.Errorf("execute select query: %w", driver.ErrBadConn),
+			want: true,
+		},
+		{
+			name: "connection reset",
+			err:  fmt.Errorf("load rows: %w", syscall.ECONNRESET),
+			want: true,
+		},
+		{
+			name: "read of the broken connection",
+			err:  fmt.Errorf("load rows: %w", &net.OpError{Op: "read", Net: "tcp", Err: io.EOF}),
+			want: true,
+		},
+		{
+			name: "truncated response",
+			err:  fmt.Errorf("load rows: %w", io.ErrUnexpectedEOF),
+			want: true,
+		},
+		{
+			name: "end of data",
+			err:  fmt.Errorf("read lob: %w", io.EOF),
+			want: false,
+		},
+		{
+			name: "network error which isn't an operation on the connection",
+			err:  fmt.Errorf("resolve host: %w", &net.AddrError{Err: "missing port", Addr: "host"}),
+			want: false,
+		},
+		{
+			name: "sql error",
+			err:  errors.New("invalid column name"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isConnectionError(tt.err); got != tt.want {
+				t.Errorf("isConnectionError() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// dbError is a go-hdb database error with the given code and text.
+type dbError struct {
+	hdbdriver.DBError
+	code int
+	text string
+}
+
+func (e dbError) Error() string { return fmt.Sprintf("sql error %d", e.code) }
+func (e dbError) Code() int     { return e.code }
+func (e dbError) Text() string  { return e.text }
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "lock wait timeout",
+			err:  fmt.Errorf("load rows: %w", dbError{code: errCodeLockWaitTimeout}),
+			want: ErrRetryable,
+		},
+		{
+			name: "connection reset",
+			err:  fmt.Errorf("load rows: %w", syscall.ECONNRESET),
+			want: ErrRetryable,
+		},
+		{
+			name: "invalid column name",
+			err:  fmt.Errorf("load rows: %w", dbError{code: 260}),
+			want: ErrFatal,
+		},
+		{
+			name: "reconnect attempts exceeded",
+			err:  fmt.Errorf("%w: %w", ErrReconnectAttemptsExceeded, driver.ErrBadConn),
+			want: ErrFatal,
+		},
+		{
+			name: "table recreated",
+			err:  fmt.Errorf("check schema: %w", ErrTableRecreated),
+			want: ErrFatal,
+		},
+		{
+			name: "tracking table claimed",
+			err:  fmt.Errorf("verify claim: %w", ErrTrackingTableClaimed),
+			want: ErrFatal,
+		},
+		{
+			name: "other error",
+			err:  ErrNoKey,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := classify(tt.err)
+
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classify() = %v, the original error isn't wrapped", got)
+			}
+
+			for _, class := range []error{ErrRetryable, ErrFatal} {
+				if errors.Is(got, class) != (class == tt.want) {
+					t.Errorf("classify() = %v, want class %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// gapTracker holds gaps between tracking ids. Ids are taken when triggers insert rows, but rows become
+// visible when their transactions commit, so a gap can be a change of a transaction which isn't committed yet.
+type gapTracker struct {
+	// timeout - time after which a gap is skipped, the transaction was rolled back then or the ids were lost.
+	timeout time.Duration
+	// since - time the gap was seen first, by the first missing id of the gap.
+	since map[int]time.Time
+}
+
+// newGapTracker creates new gap tracker, gaps aren't tracked if the timeout is zero.
+func newGapTracker(timeout time.Duration) *gapTracker {
+	return &gapTracker{
+		timeout: timeout,
+		since:   make(map[int]time.Time),
+	}
+}
+
+// upperID returns the highest of the ids, which can be read without passing a gap younger than the timeout.
+// The ids are sorted ids following the last read id.
+func (g *gapTracker) upperID(ctx context.Context, ids []int, lastID int, now time.Time) int {
+	upper := lastID
+
+	for _, id := range ids {
+		if id != upper+1 {
+			since, ok := g.since[upper+1]
+			if !ok {
+				g.since[upper+1] = now
+			}
+
+			if !ok || now.Sub(since) < g.timeout {
+				break
+			}
+
+			sdk.Logger(ctx).Warn().
+				Int("from", upper+1).
+				Int("to", id-1).
+				Msg("skip gap of tracking ids, the transactions weren't committed within cdc.gapTimeout")
+		}
+
+		upper = id
+	}
+
+	// gaps before the upper id are passed.
+	for id := range g.since {
+		if id <= upper {
+			delete(g.since, id)
+		}
+	}
+
+	return upper
+}
+
+// safeUpperID returns the highest tracking id, which can be read without skipping changes of transactions
+// which aren't committed yet.
+func (i *CDCIterator) safeUpperID(ctx context.Context) (int, error) {
+	selectBuilder := sqlbuilder.NewSelectBuilder()
+
+	selectBuilder.Select(i.columns.trackingID).From(i.trackingTable)
+
+	if i.position != nil {
+		selectBuilder.Where(selectBuilder.GreaterThan(i.columns.trackingID, i.position.CDCLastID))
+	}
+
+	q, args := selectBuilder.OrderBy(i.columns.trackingID).Limit(i.batchSize).Build()
+
+	var ids []int
+	if err := i.db.SelectContext(ctx, &ids, withHints(q, i.hints), args...); err != nil {
+		return 0, fmt.Errorf("select tracking ids: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// the first read id has nothing to follow.
+	lastID := ids[0] - 1
+	if i.position != nil {
+		lastID = i.position.CDCLastID
+	}
+
+	return i.gaps.upperID(ctx, ids, lastID, time.Now()), nil
+}
@@ -0,0 +1,64 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGapTracker_upperID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now()
+	g := newGapTracker(10 * time.Second)
+
+	if got := g.upperID(ctx, []int{11, 12, 13}, 10, now); got != 13 {
+		t.Errorf("contiguous ids: upper id = %d, want 13", got)
+	}
+
+	// 14 isn't committed yet.
+	if got := g.upperID(ctx, []int{15, 16}, 13, now); got != 13 {
+		t.Errorf("new gap: upper id = %d, want 13", got)
+	}
+
+	if got := g.upperID(ctx, []int{15, 16}, 13, now.Add(5*time.Second)); got != 13 {
+		t.Errorf("young gap: upper id = %d, want 13", got)
+	}
+
+	// 14 is committed.
+	if got := g.upperID(ctx, []int{14, 15, 16, 18}, 13, now.Add(6*time.Second)); got != 16 {
+		t.Errorf("filled gap: upper id = %d, want 16", got)
+	}
+
+	if _, ok := g.since[14]; ok {
+		t.Errorf("filled gap is still tracked")
+	}
+
+	// 17 was rolled back, the gap is seen since the previous call.
+	if got := g.upperID(ctx, []int{18}, 16, now.Add(10*time.Second)); got != 16 {
+		t.Errorf("young gap: upper id = %d, want 16", got)
+	}
+
+	if got := g.upperID(ctx, []int{18}, 16, now.Add(16*time.Second)); got != 18 {
+		t.Errorf("timed out gap: upper id = %d, want 18", got)
+	}
+
+	if len(g.since) != 0 {
+		t.Errorf("gaps = %v, want none", g.since)
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	metadataValidFrom = "saphana.validFrom"
+	metadataValidTo   = "saphana.validTo"
+)
+
+// historyIterator - iterator which reads the history table of a system-versioned table.
+// Every row of the history table is a version of a row which was valid from the validFrom to the validTo time.
+// Versions are read in batches ordered by the validFrom column and the ordering column.
+// The first time the iterator starts work, it gets max value from the validTo column and saves it to position,
+// versions archived after that are captured by the tracking table.
+type historyIterator struct {
+	db   *sqlx.DB
+	rows *sqlx.Rows
+
+	// table - name of the system-versioned table.
+	table string
+	// historyTable - name of the history table.
+	historyTable string
+	// validFromColumn - name of the column with the start of the version validity.
+	validFromColumn string
+	// validToColumn - name of the column with the end of the version validity.
+	validToColumn string
+	// keys Names of columns what iterator use for setting key in record.
+	keys []string
+	// orderingColumn Name of column what iterator use for ordering versions with the same validFrom.
+	orderingColumn string
+	// maxValidTo max value from validTo column. Connector uses it like boundary value for history.
+	maxValidTo any
+	// batchSize size of batch.
+	batchSize int
+	// position last recorded position.
+	position *position.Position
+	// columnTypes column types from table.
+	columnTypes map[string]string
+	// trackingTable name.
+	trackingTable string
+	// transformOpts options of row values transformation.
+	transformOpts columntypes.TransformOptions
+}
+
+type historyParams struct {
+	db              *sqlx.DB
+	table           string
+	historyTable    string
+	validFromColumn string
+	validToColumn   string
+	orderingColumn  string
+	keys            []string
+	batchSize       int
+	position        *position.Position
+	columnTypes     map[string]string
+	trackingTable   string
+	transformOpts   columntypes.TransformOptions
+}
+
+func newHistoryIterator(ctx context.Context, params historyParams) (*historyIterator, error) {
+	it := &historyIterator{
+		db:              params.db,
+		table:           params.table,
+		historyTable:    params.historyTable,
+		validFromColumn: params.validFromColumn,
+		validToColumn:   params.validToColumn,
+		keys:            params.keys,
+		orderingColumn:  params.orderingColumn,
+		batchSize:       params.batchSize,
+		position:        params.position,
+		columnTypes:     params.columnTypes,
+		trackingTable:   params.trackingTable,
+		transformOpts:   params.transformOpts,
+	}
+
+	if params.position != nil {
+		it.maxValidTo = params.position.HistoryMaxValidTo
+	} else {
+		err := it.db.GetContext(ctx, &it.maxValidTo, fmt.Sprintf(queryGetMaxValue, it.validToColumn, it.historyTable))
+		if err != nil {
+			return nil, fmt.Errorf("get max valid to: %w", err)
+		}
+	}
+
+	if err := it.loadRows(ctx); err != nil {
+		return nil, fmt.Errorf("load rows: %w", err)
+	}
+
+	return it, nil
+}
+
+// HasNext check ability to get next record.
+func (i *historyIterator) HasNext(ctx context.Context) (bool, error) {
+	if i.rows != nil && i.rows.Next() {
+		return true, nil
+	}
+
+	if i.rows != nil && i.rows.Err() != nil {
+		return false, fmt.Errorf("iterate rows: %w", i.rows.Err())
+	}
+
+	if err := i.loadRows(ctx); err != nil {
+		return false, fmt.Errorf("load rows: %w", err)
+	}
+
+	// check new batch.
+	if i.rows != nil && i.rows.Next() {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Next get new record.
+func (i *historyIterator) Next(ctx context.Context) (opencdc.Record, error) {
+	row := make(map[string]any)
+	if err := i.rows.MapScan(row); err != nil {
+		return opencdc.Record{}, fmt.Errorf("scan rows: %w", err)
+	}
+
+	validFrom, ok := row[i.validFromColumn].(time.Time)
+	if !ok {
+		return opencdc.Record{}, fmt.Errorf("column %s: %w", i.validFromColumn, ErrNoValidityColumn)
+	}
+
+	validTo, ok := row[i.validToColumn].(time.Time)
+	if !ok {
+		return opencdc.Record{}, fmt.Errorf("column %s: %w", i.validToColumn, ErrNoValidityColumn)
+	}
+
+	transformedRow, err := columntypes.TransformRow(ctx, row, i.columnTypes, i.transformOpts)
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("transform row column types: %w", err)
+	}
+
+	if _, ok := transformedRow[i.orderingColumn]; !ok {
+		return opencdc.Record{}, ErrNoOrderingColumn
+	}
+
+	// the position keeps time values as they are, regardless of the time format of records.
+	lastProcessedVal := transformedRow[i.orderingColumn]
+	if timeValue, ok := row[i.orderingColumn].(time.Time); ok {
+		lastProcessedVal = timeValue
+	}
+
+	pos := position.Position{
+		IteratorType:            position.TypeHistory,
+		HistoryLastValidFrom:    validFrom,
+		HistoryLastProcessedVal: lastProcessedVal,
+		HistoryMaxValidTo:       i.maxValidTo,
+		TrackingTableName:       i.trackingTable,
+	}
+
+	sdkPos, err := pos.ConvertToSDKPosition()
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("convert position %w", err)
+	}
+
+	keysMap := make(map[string]any)
+	for _, val := range i.keys {
+		if _, ok := transformedRow[val]; !ok {
+			return opencdc.Record{}, fmt.Errorf("key %v, %w", val, ErrNoKey)
+		}
+
+		keysMap[val] = transformedRow[val]
+	}
+
+	transformedRowBytes, err := json.Marshal(transformedRow)
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("marshal row: %w", err)
+	}
+
+	i.position = &pos
+
+	metadata := opencdc.Metadata(map[string]string{
+		metadataTable:     i.table,
+		metadataValidFrom: columntypes.FormatTime(validFrom, i.columnTypes[i.validFromColumn]),
+		metadataValidTo:   columntypes.FormatTime(validTo, i.columnTypes[i.validToColumn]),
+	})
+	metadata.SetCreatedAt(time.Now())
+
+	return sdk.Util.Source.NewRecordSnapshot(
+			sdkPos,
+			metadata,
+			opencdc.StructuredData(keysMap),
+			opencdc.RawData(transformedRowBytes)),
+		nil
+}
+
+// CloseRows close sql rows.
+func (i *historyIterator) CloseRows() error {
+	if i.rows != nil {
+		err := i.rows.Close()
+		if err != nil {
+			return fmt.Errorf("close rows: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resume replaces the db connection and reloads rows from the current position.
+func (i *historyIterator) resume(ctx context.Context, db *sqlx.DB) error {
+	// rows belong to the broken connection, the close error doesn't matter here.
+	i.CloseRows() //nolint:errcheck // see the comment above
+
+	i.db = db
+
+	if err := i.loadRows(ctx); err != nil {
+		return fmt.Errorf("load rows: %w", err)
+	}
+
+	return nil
+}
+
+// Stop shutdown iterator.
+func (i *historyIterator) Stop() error {
+	err := i.CloseRows()
+	if err != nil {
+		return fmt.Errorf("close rows: %w", err)
+	}
+
+	if i.db != nil {
+		err := i.db.Close()
+		if err != nil {
+			return fmt.Errorf("close db %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadRows selects a batch of versions from the history table, which come after the current position.
+func (i *historyIterator) loadRows(ctx context.Context) error {
+	builder := sqlbuilder.NewSelectBuilder()
+
+	builder.Select("*")
+	builder.From(i.historyTable)
+	builder.Where(builder.LessEqualThan(i.validToColumn, i.maxValidTo))
+
+	if i.position != nil {
+		builder.Where(
+			builder.Or(
+				builder.GreaterThan(i.validFromColumn, i.position.HistoryLastValidFrom),
+				builder.And(
+					builder.Equal(i.validFromColumn, i.position.HistoryLastValidFrom),
+					builder.GreaterThan(i.orderingColumn, i.position.HistoryLastProcessedVal),
+				),
+			),
+		)
+	}
+
+	q, args := builder.
+		OrderBy(i.validFromColumn, i.orderingColumn).
+		Limit(i.batchSize).
+		Build()
+
+	rows, err := i.db.QueryxContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("execute select query: %w", err)
+	}
+
+	i.rows = rows
+
+	return nil
+}
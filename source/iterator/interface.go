@@ -0,0 +1,37 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// TableIterator reads records of a single table, it's implemented by [SnapshotIterator] and [CDCIterator],
+// so they can be used on their own, without the [CombinedIterator] switching between them.
+type TableIterator interface {
+	// HasNext returns true if there is the next record. It loads the next batch of rows, if the current one is read.
+	HasNext(ctx context.Context) (bool, error)
+	// Next returns the next record, HasNext must return true before it.
+	Next(ctx context.Context) (opencdc.Record, error)
+	// Stop releases rows and closes the connection of the iterator.
+	Stop(ctx context.Context) error
+}
+
+var (
+	_ TableIterator = (*SnapshotIterator)(nil)
+	_ TableIterator = (*CDCIterator)(nil)
+)
@@ -16,26 +16,55 @@ package iterator
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/conduitio-labs/conduit-connector-sap-hana/catalog"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/config"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
 	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
 	"github.com/jmoiron/sqlx"
+	"github.com/jpillora/backoff"
 )
 
 const (
-	metadataTable = "saphana.table"
+	metadataTable        = "saphana.table"
+	metadataSchemaChange = "saphana.schemaChange"
+	metadataColumnTypes  = "saphana.columnTypes"
+	metadataChecksum     = "saphana.checksum"
+)
+
+const (
+	// reconnect backoff settings.
+	reconnectMinDelay    = time.Second
+	reconnectMaxDelay    = 30 * time.Second
+	reconnectMaxAttempts = 10
 )
 
 // CombinedIterator combined iterator.
 type CombinedIterator struct {
 	db *sqlx.DB
+	// auth - auth config, used for reopening the connection.
+	auth config.AuthConfig
+	// replicaAuth - auth config of the read-enabled secondary system the snapshot is read from, it's nil
+	// if the snapshot is read from the primary system.
+	replicaAuth *config.AuthConfig
+	// snapshotDB - connection to the secondary system, it's open while the snapshot needs it.
+	snapshotDB *sqlx.DB
+	// schema - default schema of the connection, used for reopening the connection.
+	schema string
+	// open - opens the database connection with the auth config.
+	open func(ctx context.Context, auth config.AuthConfig) (*sqlx.DB, error)
 
-	snapshot *snapshotIterator
-	cdc      *cdcIterator
+	history  *historyIterator
+	snapshot *SnapshotIterator
+	cdc      *CDCIterator
 
 	// table - table name.
 	table string
@@ -47,19 +76,238 @@ type CombinedIterator struct {
 	orderingColumn string
 	// batchSize size of batch.
 	batchSize int
+	// snapshot - whether the snapshot is taken after the history.
+	snapshotEnabled bool
+	// snapshotSource - table function or procedure the snapshot reads from instead of the table.
+	snapshotSource SnapshotSource
+	// snapshotCursor - whether the snapshot is read through a single cursor.
+	snapshotCursor bool
+	// snapshotIsolation - isolation level of the snapshot transaction.
+	snapshotIsolation sql.IsolationLevel
+	// snapshotRefreshMax - interval of refreshing the max value of the snapshot, zero disables it.
+	snapshotRefreshMax time.Duration
+	// snapshotMaxDuration - max time of reading the snapshot before switching to cdc, zero disables it.
+	snapshotMaxDuration time.Duration
+	// snapshotAbandon - the rest of the snapshot isn't read after the max duration.
+	snapshotAbandon bool
+	// snapshotLimit - max number of snapshot records, the rest of the snapshot isn't read, zero disables it.
+	snapshotLimit int
+	// snapshotRangeStart, snapshotRangeEnd - inclusive bounds of the ordering column values the snapshot reads.
+	snapshotRangeStart string
+	snapshotRangeEnd   string
+	// snapshotTieBreaker - unique column which orders snapshot rows sharing a value of the ordering column.
+	snapshotTieBreaker string
+	// snapshotCount - number of snapshot records returned, including the ones returned before the restart.
+	snapshotCount int
+	// readOnly - the database is read-only, so the table is polled by the ordering column instead of cdc.
+	readOnly bool
+	// pendingSnapshot - progress of the snapshot interrupted by the max duration,
+	// the snapshot is resumed in batches, when cdc has no changes.
+	pendingSnapshot *position.Position
+	// interleavedLeft - number of records the resumed snapshot returns before cdc is read again.
+	interleavedLeft int
+	// baseSchema, baseTable - the table the table name refers to, the table name can be a synonym.
+	baseSchema string
+	baseTable  string
+	// tableOID - object id of the table, it changes when the table is dropped and created again.
+	tableOID int64
+	// queryHints - hints of the snapshot and cdc select queries.
+	queryHints string
+	// keyCaser - changes the case of field names of records, it's nil if names are preserved.
+	keyCaser keyCaser
+	// nulls - omits or replaces null fields of payloads, it's nil if nulls are included.
+	nulls *nullHandler
+	// historyTable, validFromColumn, validToColumn - history table of the system-versioned table and its
+	// validity time columns.
+	historyTable    string
+	validFromColumn string
+	validToColumn   string
 	// tableInfo - general information about column types, primary keys.
 	tableInfo columntypes.TableInfo
+	// cdcStopTimeout - max time for waiting tracking table cleanup on stop.
+	cdcStopTimeout time.Duration
+	// cdcChangedColumnsOnly - updates capture only changed columns.
+	cdcChangedColumnsOnly bool
+	// cdcCompaction - only the latest change of every key within a batch is returned.
+	cdcCompaction bool
+	// cdcConsumer - name of the pipeline in the shared tracking table, the tracking table is private if it's empty.
+	cdcConsumer string
+	// cdcConsumerTTL - offsets of consumers which haven't been running for it are removed, zero keeps them.
+	cdcConsumerTTL time.Duration
+	// cdcColumnPrefix - prefix of the service columns of the tracking table.
+	cdcColumnPrefix string
+	// cdcDropOnTeardown - the tracking table and the triggers are dropped when the cdc iterator stops.
+	cdcDropOnTeardown bool
+	// cdcExclusive - the cdc iterator claims the tracking table, so only the latest instance reads it.
+	cdcExclusive bool
+	// cdcGapTimeout - time after which a gap of tracking ids is skipped, zero disables waiting for gaps.
+	cdcGapTimeout time.Duration
+	// cdcLagThreshold - replication lag above which a warning is logged, zero disables warnings.
+	cdcLagThreshold time.Duration
+	// lagMetadata - cdc records carry their replication lag.
+	lagMetadata bool
+	// cdcTransactionID - the triggers capture ids of transactions, records carry them in the metadata.
+	cdcTransactionID bool
+	// cdcOrderByTimestamp - changes are ordered by the time they were made and the tracking ids.
+	cdcOrderByTimestamp bool
+	// cdcStartFrom - time of the first change read when cdc starts without a position.
+	cdcStartFrom time.Time
+	// cdcRetention - acknowledged changes are kept in the tracking table for it.
+	cdcRetention time.Duration
+	// cdcSDI - the remote subscription on the virtual table captures changes instead of the triggers.
+	cdcSDI bool
+	// debugMetadata - cdc records carry the id, the operation and the capture time of their tracking rows.
+	debugMetadata bool
+	// columnTypesMetadata - records carry the column types of the table with their lengths, precisions and scales.
+	columnTypesMetadata bool
+	// checksumMetadata - records carry the checksum of their rows.
+	checksumMetadata bool
+	// columnDefinitions - JSON of the column types of the table, which is added to the metadata of records.
+	columnDefinitions string
+	// quoteIdentifiers - column names of the tracking table DDL, the triggers and the snapshot queries are quoted.
+	quoteIdentifiers bool
+	// keepaliveInterval - interval of pinging the connections in the background, zero disables it.
+	keepaliveInterval time.Duration
+	// operations - record operations captured by the triggers.
+	operations []string
+	// transformOptions - options of row values transformation.
+	transformOptions columntypes.TransformOptions
+	// schemaCheckInterval - interval of comparing the table columns with the cached ones, zero disables it.
+	schemaCheckInterval time.Duration
+	// schemaCheckedAt - time of the last columns check.
+	schemaCheckedAt time.Time
+	// schemaChange - detected change of columns, which is reported in the next record.
+	schemaChange *columntypes.SchemaChange
+	// retryMax - maximum number of retries of reads failed by transient errors.
+	retryMax int
+	// retryBackoff - delay before the first retry, it's doubled for every next retry.
+	retryBackoff time.Duration
+
+	// catalog - cache of the table metadata.
+	catalog *catalog.Catalog
 }
 
 // CombinedParams is an incoming params for the [NewCombinedIterator] function.
 type CombinedParams struct {
-	DB             *sqlx.DB
-	Table          string
-	OrderingColumn string
-	CfgKeys        []string
-	BatchSize      int
-	Snapshot       bool
-	SdkPosition    opencdc.Position
+	DB                    *sqlx.DB
+	Auth                  config.AuthConfig
+	Schema                string
+	Table                 string
+	OrderingColumn        string
+	CfgKeys               []string
+	BatchSize             int
+	Snapshot              bool
+	SdkPosition           opencdc.Position
+	CDCStopTimeout        time.Duration
+	CDCChangedColumnsOnly bool
+	CDCCompaction         bool
+	// CDCConsumer - name of the pipeline, pipelines with names read the table from the shared tracking table.
+	CDCConsumer string
+	// CDCConsumerTTL - offsets of consumers which haven't been running for it are removed from the shared
+	// tracking table, so its rows aren't kept for deleted pipelines. Offsets never expire if it's zero.
+	CDCConsumerTTL time.Duration
+	// CDCColumnPrefix - prefix of the service columns of the tracking table, [DefaultColumnPrefix] if it's empty.
+	CDCColumnPrefix string
+	// CDCDropOnTeardown - drop the tracking table and the triggers when the cdc iterator stops.
+	CDCDropOnTeardown bool
+	// CDCExclusive - the cdc iterator claims the tracking table, an instance started later takes it over.
+	CDCExclusive bool
+	// CDCGapTimeout - time after which a gap of tracking ids is skipped, changes after a gap aren't read
+	// until it's filled by the commit of the transaction or times out. Zero disables waiting for gaps.
+	CDCGapTimeout time.Duration
+	// CDCLagThreshold - replication lag, the time between a change and its read, above which a warning
+	// is logged. The tracking table gets the column with the time of changes, if it's set.
+	CDCLagThreshold time.Duration
+	// LagMetadata - add the replication lag in milliseconds into the metadata of cdc records.
+	// The tracking table gets the column with the time of changes, if it's set.
+	LagMetadata bool
+	// CDCTransactionID - capture the id of the transaction of every change into the record metadata.
+	CDCTransactionID bool
+	// CDCOrderBy - order of changes: CDCOrderID or CDCOrderTimestamp.
+	CDCOrderBy string
+	// CDCStartFrom - time of the first change read when cdc starts without a position, in the timestamp order.
+	CDCStartFrom time.Time
+	// CDCRetention - period acknowledged changes are kept in the tracking table, in the timestamp order.
+	// They are deleted immediately if it's zero.
+	CDCRetention time.Duration
+	// CDCMode - how changes are captured: CDCModeTrigger or CDCModeSDI.
+	CDCMode string
+	// DebugMetadata - add the id, the operation and the capture time of the tracking row
+	// into the metadata of cdc records.
+	DebugMetadata bool
+	// ColumnTypesMetadata - add the column types of the table with their lengths, precisions and scales
+	// into the metadata of records.
+	ColumnTypesMetadata bool
+	// ChecksumMetadata - add the checksum of the row, see [checksum], into the metadata of records.
+	ChecksumMetadata bool
+	// QuoteIdentifiers - quote column names of the tracking table DDL, the triggers and the snapshot queries.
+	QuoteIdentifiers bool
+	// KeepaliveInterval - interval of pinging the connections in the background, zero disables it.
+	KeepaliveInterval time.Duration
+	// Operations - operations captured by CDC: create, update, delete. All operations are captured if it's empty.
+	Operations []string
+	TimeFormat string
+	// Masking - masking of column values.
+	Masking             columntypes.Masking
+	SchemaCheckInterval time.Duration
+	// HistoryTable - history table of the system-versioned table, the history is read first if it's set.
+	HistoryTable           string
+	HistoryValidFromColumn string
+	HistoryValidToColumn   string
+	// OnOrphanTrackingTable - policy of handling tracking tables left by the previous runs without the position:
+	// adopt, recreate or fail.
+	OnOrphanTrackingTable string
+	// SnapshotFunction, SnapshotProcedure - table function or procedure the snapshot reads from instead of
+	// the table, SnapshotArguments are bound to their parameters.
+	SnapshotFunction  string
+	SnapshotProcedure string
+	SnapshotArguments []string
+	// SnapshotCursor - the snapshot is read through a single cursor instead of a query per batch.
+	SnapshotCursor bool
+	// SnapshotIsolationLevel - isolation level of the snapshot transaction: readCommitted, repeatableRead or
+	// serializable. The snapshot runs without a transaction if it's empty.
+	SnapshotIsolationLevel string
+	// SnapshotMaxValueRefreshInterval - interval of refreshing the max value of the ordering column during
+	// the snapshot, so rows inserted after the snapshot started are read by it. Zero disables it.
+	SnapshotMaxValueRefreshInterval time.Duration
+	// SnapshotMaxDuration - max time of reading the snapshot before switching to cdc, zero disables it.
+	SnapshotMaxDuration time.Duration
+	// SnapshotLimit - max number of snapshot records, cdc starts right after them, zero disables it.
+	SnapshotLimit int
+	// SnapshotRangeStart, SnapshotRangeEnd - inclusive bounds of the ordering column values the snapshot reads,
+	// the snapshot isn't bounded by them if they're empty.
+	SnapshotRangeStart string
+	SnapshotRangeEnd   string
+	// SnapshotTieBreakerColumn - unique column which orders snapshot rows sharing a value of the ordering column,
+	// e.g. the key of a table ordered by a DATE column.
+	SnapshotTieBreakerColumn string
+	// SnapshotReadReplicaHost - host of the read-enabled secondary system the snapshot is read from,
+	// the primary system is used if it's empty.
+	SnapshotReadReplicaHost string
+	// QueryHints - hints added as WITH HINT(...) to the snapshot and cdc select queries.
+	QueryHints string
+	// KeyCase - case of field names of records: KeyCaseUpper, KeyCasePreserve or KeyCaseLower.
+	KeyCase string
+	// BooleanColumns - integer columns of logical booleans, which are represented as true or false.
+	BooleanColumns []string
+	// Nulls - how null fields of payloads are represented: NullsInclude, NullsOmit or NullsDefault.
+	Nulls string
+	// NullDefaults - values replacing nulls by column names with NullsDefault.
+	NullDefaults map[string]string
+	// OnTableRecreate - what happens when the table is recreated since the position was saved:
+	// TableRecreateFail or TableRecreateResnapshot.
+	OnTableRecreate string
+	// SnapshotOnMaxDuration - what happens to the rest of the snapshot after the max duration:
+	// SnapshotResume or SnapshotAbandon.
+	SnapshotOnMaxDuration string
+	// RetryMax, RetryBackoff - maximum number of retries of reads failed by transient errors and the delay
+	// before the first retry.
+	RetryMax     int
+	RetryBackoff time.Duration
+
+	// Catalog - cache of the table metadata shared with other connectors of the database,
+	// the iterator has its own one if it's nil.
+	Catalog *catalog.Catalog
 }
 
 // NewCombinedIterator - create new iterator.
@@ -69,57 +317,196 @@ func NewCombinedIterator(ctx context.Context, params CombinedParams) (*CombinedI
 		return nil, fmt.Errorf("parse position: %w", err)
 	}
 
-	trakingTableName := getTrackingTableName(pos, params.Table)
+	trakingTableName := getTrackingTableName(ctx, pos, params.Table, params.CDCConsumer != "")
 
 	it := &CombinedIterator{
 		db:             params.DB,
+		auth:           params.Auth,
+		schema:         params.Schema,
 		table:          params.Table,
 		orderingColumn: params.OrderingColumn,
 		batchSize:      params.BatchSize,
 		trackingTable:  trakingTableName,
+		cdcStopTimeout: params.CDCStopTimeout,
+		transformOptions: columntypes.TransformOptions{
+			TimeFormat:     columntypes.TimeFormat(params.TimeFormat),
+			Masking:        params.Masking,
+			BooleanColumns: params.BooleanColumns,
+		},
+		schemaCheckInterval:   params.SchemaCheckInterval,
+		schemaCheckedAt:       time.Now(),
+		snapshotEnabled:       params.Snapshot,
+		snapshotSource:        newSnapshotSource(params),
+		snapshotCursor:        params.SnapshotCursor,
+		snapshotIsolation:     isolationLevels[params.SnapshotIsolationLevel],
+		snapshotRefreshMax:    params.SnapshotMaxValueRefreshInterval,
+		snapshotMaxDuration:   params.SnapshotMaxDuration,
+		snapshotAbandon:       params.SnapshotOnMaxDuration == SnapshotAbandon,
+		snapshotLimit:         params.SnapshotLimit,
+		snapshotRangeStart:    params.SnapshotRangeStart,
+		snapshotRangeEnd:      params.SnapshotRangeEnd,
+		snapshotTieBreaker:    params.SnapshotTieBreakerColumn,
+		queryHints:            params.QueryHints,
+		keyCaser:              newKeyCaser(params.KeyCase),
+		nulls:                 newNullHandler(params.Nulls, params.NullDefaults),
+		historyTable:          params.HistoryTable,
+		validFromColumn:       params.HistoryValidFromColumn,
+		validToColumn:         params.HistoryValidToColumn,
+		cdcChangedColumnsOnly: params.CDCChangedColumnsOnly,
+		cdcCompaction:         params.CDCCompaction,
+		cdcConsumer:           params.CDCConsumer,
+		cdcConsumerTTL:        params.CDCConsumerTTL,
+		cdcColumnPrefix:       params.CDCColumnPrefix,
+		cdcDropOnTeardown:     params.CDCDropOnTeardown,
+		cdcExclusive:          params.CDCExclusive,
+		cdcGapTimeout:         params.CDCGapTimeout,
+		cdcLagThreshold:       params.CDCLagThreshold,
+		lagMetadata:           params.LagMetadata,
+		cdcTransactionID:      params.CDCTransactionID,
+		cdcOrderByTimestamp:   params.CDCOrderBy == CDCOrderTimestamp,
+		cdcStartFrom:          params.CDCStartFrom,
+		cdcRetention:          params.CDCRetention,
+		cdcSDI:                params.CDCMode == CDCModeSDI,
+		debugMetadata:         params.DebugMetadata,
+		columnTypesMetadata:   params.ColumnTypesMetadata,
+		checksumMetadata:      params.ChecksumMetadata,
+		quoteIdentifiers:      params.QuoteIdentifiers,
+		keepaliveInterval:     params.KeepaliveInterval,
+		operations:            params.Operations,
+		retryMax:              params.RetryMax,
+		retryBackoff:          params.RetryBackoff,
+
+		catalog: params.Catalog,
+	}
+
+	it.open = it.connectTo
+
+	if it.catalog == nil {
+		it.catalog = catalog.New(catalog.DefaultTTL)
+	}
+
+	if params.SnapshotReadReplicaHost != "" {
+		replicaAuth, er := params.Auth.WithHost(params.SnapshotReadReplicaHost)
+		if er != nil {
+			return nil, fmt.Errorf("snapshot read replica auth: %w", er)
+		}
+
+		it.replicaAuth = &replicaAuth
 	}
 
-	it.tableInfo, err = columntypes.GetTableInfo(ctx, params.DB, params.Table)
+	// the table can be a synonym, privileges are checked for its base table.
+	baseSchema, baseTable, err := columntypes.ResolveTable(ctx, it.db, it.table)
+	if err != nil {
+		return nil, fmt.Errorf("resolve table: %w", err)
+	}
+
+	err = checkPrivileges(ctx, it.db, baseSchema, baseTable)
+	if err != nil {
+		return nil, fmt.Errorf("check privileges: %w", err)
+	}
+
+	it.baseSchema, it.baseTable = baseSchema, baseTable
+
+	it.tableOID, err = getTableOID(ctx, it.db, baseSchema, baseTable)
+	if err != nil {
+		return nil, fmt.Errorf("get table oid: %w", err)
+	}
+
+	// positions without the oid were saved before it was added to them.
+	if pos != nil && pos.TableOID != 0 && pos.TableOID != it.tableOID {
+		pos, err = it.handleRecreatedTable(ctx, pos, params.OnTableRecreate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	it.tableInfo, err = it.catalog.TableInfo(ctx, params.DB, params.Table)
 	if err != nil {
 		return nil, fmt.Errorf("get table info: %w", err)
 	}
 
-	it.setKeys(params.CfgKeys, it.tableInfo.PrimaryKeys)
+	if err = it.setColumnDefinitions(it.tableInfo); err != nil {
+		return nil, fmt.Errorf("set column definitions: %w", err)
+	}
+
+	// without the ordering column a single column primary key is used instead.
+	if it.orderingColumn == "" {
+		if len(it.tableInfo.PrimaryKeys) != 1 {
+			return nil, fmt.Errorf("table %s: %w", it.table, ErrNoOrderingColumn)
+		}
 
-	err = setupCDC(ctx, it.db, it.table, it.trackingTable, it.tableInfo)
+		it.orderingColumn = it.tableInfo.PrimaryKeys[0]
+	}
+
+	// positions keep values of the ordering column, so masked values would leak through them.
+	if _, ok := it.transformOptions.Masking.Columns[it.orderingColumn]; ok {
+		return nil, fmt.Errorf("table %s: %w", it.table, ErrMaskedOrderingColumn)
+	}
+
+	it.setKeys(params.CfgKeys, it.tableInfo.PrimaryKeys, it.tableInfo.UniqueKeys)
+
+	// the tracking table and the triggers can't be created on a read-only database, the read-enabled secondary
+	// system of the system replication, so the table is polled by the ordering column instead.
+	it.readOnly, err = isReadOnly(ctx, it.db)
 	if err != nil {
-		return nil, fmt.Errorf("setup cdc: %w", err)
+		return nil, fmt.Errorf("check read-only database: %w", err)
+	}
+
+	if it.readOnly {
+		sdk.Logger(ctx).Warn().Str("table", it.table).
+			Msg("database is read-only, poll the table by the ordering column instead of cdc")
+	} else if err = it.setupCDC(ctx, pos, params.OnOrphanTrackingTable); err != nil {
+		return nil, err
+	}
+
+	if pos != nil && (pos.IteratorType == position.TypeSnapshot || pos.SnapshotPending) {
+		it.snapshotCount = pos.SnapshotCount
 	}
 
-	if params.Snapshot && (pos == nil || pos.IteratorType == position.TypeSnapshot) {
-		it.snapshot, err = newSnapshotIterator(ctx, snapshotParams{
-			db:             it.db,
-			table:          it.table,
-			orderingColumn: it.orderingColumn,
-			keys:           it.keys,
-			batchSize:      it.batchSize,
-			position:       pos,
-			columnTypes:    it.tableInfo.ColumnTypes,
-			trackingTable:  it.trackingTable,
+	switch {
+	case it.readOnly && (it.historyTable == "" || (pos != nil && pos.IteratorType != position.TypeHistory)):
+		err = it.startPolling(ctx, pos, it.tableInfo.ColumnTypes)
+		if err != nil {
+			return nil, err
+		}
+	case pos != nil && pos.SnapshotPending:
+		// the snapshot was interrupted by the max duration, cdc goes on and the snapshot is resumed later.
+		it.pendingSnapshot = snapshotProgress(pos)
+
+		cdcPos := *pos
+		cdcPos.IteratorType = position.TypeCDC
+
+		err = it.startCDCIterator(ctx, it.tableInfo.ColumnTypes, &cdcPos)
+		if err != nil {
+			return nil, err
+		}
+	case it.historyTable != "" && (pos == nil || pos.IteratorType == position.TypeHistory):
+		it.history, err = newHistoryIterator(ctx, historyParams{
+			db:              it.db,
+			table:           it.table,
+			historyTable:    it.historyTable,
+			validFromColumn: it.validFromColumn,
+			validToColumn:   it.validToColumn,
+			orderingColumn:  it.orderingColumn,
+			keys:            it.keys,
+			batchSize:       it.batchSize,
+			position:        pos,
+			columnTypes:     it.tableInfo.ColumnTypes,
+			trackingTable:   it.trackingTable,
+			transformOpts:   it.transformOptions,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("new shapshot iterator: %w", err)
+			return nil, fmt.Errorf("new history iterator: %w", err)
 		}
-	} else {
-		it.cdc, err = newCDCIterator(
-			ctx,
-			cdcParams{
-				db:            it.db,
-				table:         it.table,
-				trackingTable: it.trackingTable,
-				keys:          it.keys,
-				batchSize:     it.batchSize,
-				columnTypes:   it.tableInfo.ColumnTypes,
-				position:      pos,
-			},
-		)
+	case params.Snapshot && (pos == nil || pos.IteratorType == position.TypeSnapshot):
+		it.snapshot, err = it.newSnapshotIterator(ctx, pos, it.tableInfo.ColumnTypes)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		err = it.startCDCIterator(ctx, it.tableInfo.ColumnTypes, pos)
 		if err != nil {
-			return nil, fmt.Errorf("new cdc iterator: %w", err)
+			return nil, err
 		}
 	}
 
@@ -128,53 +515,525 @@ func NewCombinedIterator(ctx context.Context, params CombinedParams) (*CombinedI
 
 // HasNext returns a bool indicating whether the iterator has the next record to return or not.
 // If the underlying snapshot iterator returns false, the combined iterator will try to switch to the cdc iterator.
+// If the connection is lost, the combined iterator reopens it and resumes from the current position.
+// Reads failed by other transient errors are retried with backoff, the returned error wraps
+// ErrRetryable or ErrFatal if the error is classified.
 func (c *CombinedIterator) HasNext(ctx context.Context) (bool, error) {
+	hasNext, err := c.hasNextReconnect(ctx)
+	if err == nil {
+		return hasNext, nil
+	}
+
+	b := &backoff.Backoff{
+		Min:    c.retryBackoff,
+		Max:    reconnectMaxDelay,
+		Factor: 2,
+	}
+
+	for attempt := 1; attempt <= c.retryMax && errorClass(err) == ErrRetryable; attempt++ {
+		sdk.Logger(ctx).Warn().Err(err).Msgf("read failed, retry %d of %d", attempt, c.retryMax)
+
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("wait for retry: %w", ctx.Err())
+		case <-time.After(b.Duration()):
+		}
+
+		// the failed query can leave the rows of the active iterator broken, so they are reloaded.
+		if er := c.resume(ctx, c.db); er != nil {
+			return false, classify(fmt.Errorf("resume: %w", er))
+		}
+
+		hasNext, err = c.hasNextReconnect(ctx)
+		if err == nil {
+			return hasNext, nil
+		}
+	}
+
+	return false, classify(err)
+}
+
+// hasNextReconnect returns whether the iterator has the next record, reopening the lost connection once.
+func (c *CombinedIterator) hasNextReconnect(ctx context.Context) (bool, error) {
+	hasNext, err := c.hasNext(ctx)
+	if err != nil && isConnectionError(err) {
+		sdk.Logger(ctx).Warn().Err(err).Msg("connection lost, reconnecting")
+
+		if er := c.reconnect(ctx); er != nil {
+			return false, fmt.Errorf("reconnect: %w", er)
+		}
+
+		return c.hasNext(ctx)
+	}
+
+	return hasNext, err
+}
+
+// Next returns the next record.
+// If the connection is lost, the combined iterator reopens it and resumes from the current position.
+// The returned error wraps ErrRetryable or ErrFatal if the error is classified.
+func (c *CombinedIterator) Next(ctx context.Context) (opencdc.Record, error) {
+	record, err := c.next(ctx)
+	if err != nil && isConnectionError(err) {
+		sdk.Logger(ctx).Warn().Err(err).Msg("connection lost, reconnecting")
+
+		if er := c.reconnect(ctx); er != nil {
+			return opencdc.Record{}, classify(fmt.Errorf("reconnect: %w", er))
+		}
+
+		hasNext, er := c.hasNext(ctx)
+		if er != nil {
+			return opencdc.Record{}, classify(fmt.Errorf("has next: %w", er))
+		}
+
+		if !hasNext {
+			return opencdc.Record{}, sdk.ErrBackoffRetry
+		}
+
+		record, err = c.next(ctx)
+	}
+
+	return record, classify(err)
+}
+
+// hasNext asks the active iterator for the next record. When the active iterator is done, the next one is started
+// and asked in the same call, so the switch doesn't cost an empty read and no record is missed.
+func (c *CombinedIterator) hasNext(ctx context.Context) (bool, error) {
+	if err := c.checkSchema(ctx); err != nil {
+		return false, fmt.Errorf("check schema: %w", err)
+	}
+
+	for {
+		hasNext, switched, err := c.step(ctx)
+		if err != nil || !switched {
+			return hasNext, err
+		}
+	}
+}
+
+// step returns whether the active iterator has the next record. If the active iterator is done,
+// it starts the next one and returns switched, so the caller asks the new iterator.
+// A failed switch keeps the active iterator, so the switch is repeated by the next call.
+//
+//nolint:funlen,nolintlint
+func (c *CombinedIterator) step(ctx context.Context) (hasNext, switched bool, err error) {
 	switch {
-	case c.snapshot != nil:
-		hasNext, err := c.snapshot.HasNext(ctx)
+	case c.history != nil:
+		hasNext, err = c.history.HasNext(ctx)
 		if err != nil {
-			return false, fmt.Errorf("snapshot has next: %w", err)
+			return false, false, fmt.Errorf("history has next: %w", err)
 		}
 
-		if !hasNext {
-			if er := c.switchToCDCIterator(ctx); er != nil {
-				return false, fmt.Errorf("switch to cdc iterator: %w", err)
+		if hasNext {
+			return true, false, nil
+		}
+
+		if err = c.switchFromHistoryIterator(ctx); err != nil {
+			return false, false, fmt.Errorf("switch from history iterator: %w", err)
+		}
+
+		return false, true, nil
+
+	case c.snapshot != nil && c.cdc != nil:
+		return c.hasNextInterleaved(ctx)
+
+	case c.snapshot != nil && c.readOnly:
+		hasNext, err = c.hasNextPolling(ctx)
+
+		return hasNext, false, err
+
+	case c.snapshot != nil:
+		if c.snapshotLimitReached() {
+			sdk.Logger(ctx).Info().Str("table", c.table).Int("limit", c.snapshotLimit).
+				Msg("snapshot limit reached, skip the rest of it")
+
+			if err = c.switchToCDCIterator(ctx); err != nil {
+				return false, false, fmt.Errorf("switch to cdc iterator: %w", err)
 			}
 
-			return c.cdc.HasNext(ctx)
+			return false, true, nil
 		}
 
-		return true, nil
+		if c.snapshotMaxDuration > 0 && time.Since(c.snapshot.startedAt) >= c.snapshotMaxDuration {
+			if err = c.interruptSnapshot(ctx); err != nil {
+				return false, false, fmt.Errorf("interrupt snapshot: %w", err)
+			}
+
+			return false, true, nil
+		}
+
+		hasNext, err = c.snapshot.HasNext(ctx)
+		if err != nil {
+			return false, false, fmt.Errorf("snapshot has next: %w", err)
+		}
+
+		if hasNext {
+			return true, false, nil
+		}
+
+		if err = c.switchToCDCIterator(ctx); err != nil {
+			return false, false, fmt.Errorf("switch to cdc iterator: %w", err)
+		}
+
+		return false, true, nil
 
 	case c.cdc != nil:
-		return c.cdc.HasNext(ctx)
+		hasNext, err = c.cdc.HasNext(ctx)
+		if err != nil || hasNext || c.pendingSnapshot == nil {
+			return hasNext, false, err
+		}
+
+		// cdc has no changes, so the interrupted snapshot goes on with a batch.
+		c.snapshot, err = c.newSnapshotIterator(ctx, c.pendingSnapshot, c.cdc.columnTypes)
+		if err != nil {
+			return false, false, fmt.Errorf("resume snapshot: %w", err)
+		}
+
+		c.interleavedLeft = c.batchSize
+
+		return false, true, nil
 
 	default:
-		return false, nil
+		return false, false, nil
 	}
 }
 
-// Next returns the next record.
-func (c *CombinedIterator) Next(ctx context.Context) (opencdc.Record, error) {
+// interruptSnapshot switches to the cdc iterator after the max duration of the snapshot.
+// The progress of the snapshot is kept for resuming it, unless the rest of the snapshot is abandoned.
+func (c *CombinedIterator) interruptSnapshot(ctx context.Context) error {
+	if c.snapshotAbandon {
+		sdk.Logger(ctx).Warn().Str("table", c.table).Msg("snapshot max duration exceeded, abandon the rest of it")
+	} else {
+		sdk.Logger(ctx).Info().Str("table", c.table).Msg("snapshot max duration exceeded, resume it later")
+
+		c.pendingSnapshot = c.snapshot.progress()
+	}
+
+	return c.switchToCDCIterator(ctx)
+}
+
+// hasNextInterleaved returns whether the resumed snapshot has the next record. After a batch of records
+// the snapshot is paused and switched is returned, so cdc changes are read first.
+func (c *CombinedIterator) hasNextInterleaved(ctx context.Context) (hasNext, switched bool, err error) {
 	switch {
+	case c.snapshotLimitReached():
+		sdk.Logger(ctx).Info().Str("table", c.table).Int("limit", c.snapshotLimit).
+			Msg("snapshot limit reached, skip the rest of it")
+
+		c.pendingSnapshot = nil
+	case c.interleavedLeft > 0:
+		hasNext, err = c.snapshot.HasNext(ctx)
+		if err != nil {
+			return false, false, fmt.Errorf("snapshot has next: %w", err)
+		}
+
+		if hasNext {
+			return true, false, nil
+		}
+
+		sdk.Logger(ctx).Info().Str("table", c.table).Msg("resumed snapshot is done")
+
+		c.pendingSnapshot = nil
+	default:
+		c.pendingSnapshot = c.snapshot.progress()
+	}
+
+	if err = c.snapshot.CloseRows(); err != nil {
+		return false, false, fmt.Errorf("close snapshot rows: %w", err)
+	}
+
+	c.snapshot = nil
+
+	return false, true, nil
+}
+
+// hasNextPolling returns whether the table has rows after the last read value of the ordering column.
+// When there are no more rows, the max value is refreshed, so the next call reads the rows inserted since then.
+func (c *CombinedIterator) hasNextPolling(ctx context.Context) (bool, error) {
+	hasNext, err := c.snapshot.HasNext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("snapshot has next: %w", err)
+	}
+
+	if hasNext {
+		return true, nil
+	}
+
+	// the snapshot transaction is ended, so the polls see rows inserted after it began.
+	if err = c.snapshot.CloseRows(); err != nil {
+		return false, fmt.Errorf("close snapshot rows: %w", err)
+	}
+
+	if err = c.snapshot.setMaxValue(ctx); err != nil {
+		return false, fmt.Errorf("refresh max value: %w", err)
+	}
+
+	return false, nil
+}
+
+// snapshotLimitReached returns whether the snapshot returned as many records as its limit.
+func (c *CombinedIterator) snapshotLimitReached() bool {
+	return c.snapshotLimit > 0 && c.snapshotCount >= c.snapshotLimit
+}
+
+// recordPosition adds the table OID to the position of the record, so a recreated table is detected.
+// While the snapshot is interleaved with cdc, it adds the progress of the paused iterator as well,
+// so both the snapshot and cdc are resumed from the position.
+func (c *CombinedIterator) recordPosition(sdkPos opencdc.Position) (opencdc.Position, error) {
+	pos, err := position.ParseSDKPosition(sdkPos)
+	if err != nil {
+		return nil, fmt.Errorf("parse position: %w", err)
+	}
+
+	pos.TableOID = c.tableOID
+
+	if c.snapshotLimit > 0 && (pos.IteratorType == position.TypeSnapshot || c.pendingSnapshot != nil) {
+		pos.SnapshotCount = c.snapshotCount
+	}
+
+	switch {
+	case pos.IteratorType == position.TypeSnapshot && c.cdc != nil:
+		if c.cdc.position != nil {
+			pos.CDCLastID = c.cdc.position.CDCLastID
+		}
+
+		pos.SnapshotPending = true
+	case pos.IteratorType == position.TypeCDC && c.pendingSnapshot != nil:
+		pos.SnapshotLastProcessedVal = c.pendingSnapshot.SnapshotLastProcessedVal
+		pos.SnapshotMaxValue = c.pendingSnapshot.SnapshotMaxValue
+		pos.SnapshotLastTieBreaker = c.pendingSnapshot.SnapshotLastTieBreaker
+		pos.SnapshotPending = true
+	}
+
+	sdkPos, err = pos.ConvertToSDKPosition()
+	if err != nil {
+		return nil, fmt.Errorf("convert position: %w", err)
+	}
+
+	return sdkPos, nil
+}
+
+func (c *CombinedIterator) next(ctx context.Context) (opencdc.Record, error) {
+	var (
+		record opencdc.Record
+		err    error
+	)
+
+	switch {
+	case c.history != nil:
+		record, err = c.history.Next(ctx)
+
 	case c.snapshot != nil:
-		return c.snapshot.Next(ctx)
+		record, err = c.snapshot.Next(ctx)
+		if err == nil {
+			c.snapshotCount++
+		}
+
+		if c.cdc != nil {
+			c.interleavedLeft--
+		}
 
 	case c.cdc != nil:
-		return c.cdc.Next(ctx)
+		record, err = c.cdc.Next(ctx)
 
 	default:
 		return opencdc.Record{}, ErrNoInitializedIterator
 	}
+
+	if err != nil {
+		return opencdc.Record{}, err
+	}
+
+	record.Position, err = c.recordPosition(record.Position)
+	if err != nil {
+		return opencdc.Record{}, err
+	}
+
+	// defaults are set by column names, so nulls are handled before the field names change.
+	if c.nulls != nil {
+		if err = c.nulls.apply(&record); err != nil {
+			return opencdc.Record{}, fmt.Errorf("apply nulls: %w", err)
+		}
+	}
+
+	if c.keyCaser != nil {
+		if err = c.keyCaser.apply(&record); err != nil {
+			return opencdc.Record{}, fmt.Errorf("apply key case: %w", err)
+		}
+	}
+
+	if c.columnDefinitions != "" {
+		record.Metadata[metadataColumnTypes] = c.columnDefinitions
+	}
+
+	// the checksum is of the row as it's emitted, deletes without the payload after don't have it.
+	if c.checksumMetadata {
+		sum, er := checksum(record.Payload.After)
+		if er != nil {
+			return opencdc.Record{}, fmt.Errorf("checksum: %w", er)
+		}
+
+		if sum != "" {
+			record.Metadata[metadataChecksum] = sum
+		}
+	}
+
+	if c.schemaChange != nil {
+		change, er := json.Marshal(c.schemaChange)
+		if er != nil {
+			return opencdc.Record{}, fmt.Errorf("marshal schema change: %w", er)
+		}
+
+		record.Metadata[metadataSchemaChange] = string(change)
+		c.schemaChange = nil
+	}
+
+	return record, nil
+}
+
+// checkSchema compares the table columns with the cached ones, when the check interval has passed.
+// A change is logged, the tracking table and the triggers are updated, the change is reported
+// in the metadata of the next record and the column types of the iterators are updated.
+// Types of dropped columns are kept, because rows of the tracking table can still have them.
+func (c *CombinedIterator) checkSchema(ctx context.Context) error {
+	if c.schemaCheckInterval <= 0 || time.Since(c.schemaCheckedAt) < c.schemaCheckInterval {
+		return nil
+	}
+
+	c.schemaCheckedAt = time.Now()
+
+	// triggers are dropped together with the table, so changes of the recreated table aren't captured.
+	tableOID, err := getTableOID(ctx, c.db, c.baseSchema, c.baseTable)
+	if err != nil {
+		return fmt.Errorf("get table oid: %w", err)
+	}
+
+	if tableOID != c.tableOID {
+		return fmt.Errorf("%w: table %s, restart the pipeline to handle it", ErrTableRecreated, c.table)
+	}
+
+	// the schema check compares the cached columns with the current ones, so they're always queried.
+	tableInfo, err := c.catalog.Refresh(ctx, c.db, c.table)
+	if err != nil {
+		return fmt.Errorf("get table info: %w", err)
+	}
+
+	change := columntypes.CompareColumns(c.tableInfo.ColumnTypes, tableInfo.ColumnTypes)
+	if change.IsEmpty() {
+		return nil
+	}
+
+	sdk.Logger(ctx).Warn().
+		Str("table", c.table).
+		Strs("added", change.Added).
+		Strs("dropped", change.Dropped).
+		Strs("changed", change.Changed).
+		Msg("table columns changed")
+
+	// there are no tracking table and triggers on a read-only database.
+	if !c.readOnly {
+		err = repairCDC(ctx, c.db, c.cdcSetupParams(tableInfo), change)
+		if err != nil {
+			return fmt.Errorf("repair cdc: %w", err)
+		}
+	}
+
+	columnTypes := make(map[string]string, len(tableInfo.ColumnTypes)+len(change.Dropped))
+	for column, columnType := range tableInfo.ColumnTypes {
+		columnTypes[column] = columnType
+	}
+
+	for _, column := range change.Dropped {
+		columnTypes[column] = c.tableInfo.ColumnTypes[column]
+	}
+
+	// the resumed snapshot and the cdc iterator are active together.
+	if c.snapshot != nil {
+		c.snapshot.columnTypes = columnTypes
+	}
+
+	switch {
+	case c.history != nil:
+		c.history.columnTypes = columnTypes
+	case c.cdc != nil:
+		c.cdc.columnTypes = columnTypes
+
+		if err = c.cdc.loadTrackingColumns(ctx); err != nil {
+			return fmt.Errorf("load tracking columns: %w", err)
+		}
+	}
+
+	if err = c.setColumnDefinitions(tableInfo); err != nil {
+		return fmt.Errorf("set column definitions: %w", err)
+	}
+
+	c.tableInfo = tableInfo
+	c.schemaChange = &change
+
+	return nil
+}
+
+// setColumnDefinitions caches the JSON of the column types of the table with their lengths, precisions and scales,
+// the column names are in the case of the record fields.
+func (c *CombinedIterator) setColumnDefinitions(tableInfo columntypes.TableInfo) error {
+	if !c.columnTypesMetadata {
+		return nil
+	}
+
+	definitions := tableInfo.ColumnDefinitions()
+
+	if c.keyCaser != nil {
+		renamed := make(map[string]string, len(definitions))
+		for column, definition := range definitions {
+			renamed[c.keyCaser(column)] = definition
+		}
+
+		definitions = renamed
+	}
+
+	bs, err := json.Marshal(definitions)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	c.columnDefinitions = string(bs)
+
+	return nil
 }
 
 // Stop the underlying iterators.
 func (c *CombinedIterator) Stop(ctx context.Context) error {
-	if c.snapshot != nil {
-		return c.snapshot.Stop()
+	if c.history != nil {
+		return c.history.Stop()
+	}
+
+	if c.snapshot != nil && c.cdc == nil {
+		if err := c.snapshot.Stop(ctx); err != nil {
+			return err
+		}
+
+		// the snapshot of the replica closes the replica connection, the primary one is left.
+		if c.snapshotDB != nil {
+			c.snapshotDB = nil
+
+			if err := c.db.Close(); err != nil {
+				return fmt.Errorf("close db: %w", err)
+			}
+		}
+
+		return nil
 	}
 
 	if c.cdc != nil {
+		// the resumed snapshot shares the connection of the cdc iterator.
+		if c.snapshot != nil {
+			if err := c.snapshot.CloseRows(); err != nil {
+				return fmt.Errorf("close snapshot rows: %w", err)
+			}
+		}
+
+		c.closeReplica(ctx)
+
 		return c.cdc.Stop(ctx)
 	}
 
@@ -195,24 +1054,185 @@ func (c *CombinedIterator) Ack(ctx context.Context, rp opencdc.Position) error {
 	return nil
 }
 
+// switchToCDCIterator starts the cdc iterator after the snapshot. The snapshot is dropped only after cdc has started,
+// so a failed switch is repeated from the snapshot.
 func (c *CombinedIterator) switchToCDCIterator(ctx context.Context) error {
 	err := c.snapshot.CloseRows()
 	if err != nil {
 		return fmt.Errorf("close rows: %w", err)
 	}
 
+	// column types can include dropped columns, which the tracking table still has.
+	if err = c.startCDCIterator(ctx, c.snapshot.columnTypes, nil); err != nil {
+		return err
+	}
+
 	c.snapshot = nil
 
-	c.cdc, err = newCDCIterator(
+	c.closeReplica(ctx)
+
+	return nil
+}
+
+// switchFromHistoryIterator starts the snapshot, if it's enabled, or the cdc iterator after the history is read.
+// The history is dropped only after the next iterator has started, so a failed switch is repeated.
+func (c *CombinedIterator) switchFromHistoryIterator(ctx context.Context) error {
+	err := c.history.CloseRows()
+	if err != nil {
+		return fmt.Errorf("close rows: %w", err)
+	}
+
+	columnTypes := c.history.columnTypes
+
+	switch {
+	case c.readOnly:
+		err = c.startPolling(ctx, nil, columnTypes)
+	case !c.snapshotEnabled:
+		err = c.startCDCIterator(ctx, columnTypes, nil)
+	default:
+		c.snapshot, err = c.newSnapshotIterator(ctx, nil, columnTypes)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.history = nil
+
+	return nil
+}
+
+// startPolling starts reading the table by the ordering column instead of cdc on a read-only database.
+// The snapshot is read first, if it's enabled, then the table is polled for rows with greater values of the ordering
+// column, so only inserted rows are read, or updated ones too if the ordering column is the time of the update.
+func (c *CombinedIterator) startPolling(
+	ctx context.Context, pos *position.Position, columnTypes map[string]string,
+) error {
+	if pos != nil && pos.IteratorType != position.TypeSnapshot {
+		return fmt.Errorf("table %s: %w", c.table, ErrReadOnlyCDC)
+	}
+
+	snapshot, err := c.newSnapshotIterator(ctx, pos, columnTypes)
+	if err != nil {
+		return err
+	}
+
+	if pos == nil && !c.snapshotEnabled {
+		if err = snapshot.skipToMax(ctx); err != nil {
+			return fmt.Errorf("skip to max value: %w", err)
+		}
+	}
+
+	c.snapshot = snapshot
+
+	return nil
+}
+
+// setupCDC handles the orphan tracking tables, sets up the tracking table and the triggers,
+// and registers the consumer of the shared tracking table.
+func (c *CombinedIterator) setupCDC(ctx context.Context, pos *position.Position, onOrphanTrackingTable string) error {
+	var err error
+
+	// without the position a new tracking table is created, the ones left by the previous runs are handled first.
+	// the shared tracking table is never an orphan one, as well as private tracking tables of other pipelines.
+	if pos == nil && c.cdcConsumer == "" {
+		c.trackingTable, err = resolveOrphanTrackingTable(ctx, c.db, c.table, c.trackingTable, onOrphanTrackingTable)
+		if err != nil {
+			return fmt.Errorf("resolve orphan tracking table: %w", err)
+		}
+	}
+
+	err = SetupCDC(ctx, c.db, c.cdcSetupParams(c.tableInfo))
+	if err != nil {
+		return fmt.Errorf("setup cdc: %w", err)
+	}
+
+	if c.cdcConsumer != "" {
+		var lastID int
+		if pos != nil && pos.IteratorType == position.TypeCDC {
+			lastID = pos.CDCLastID
+		}
+
+		err = registerConsumer(ctx, c.db, c.trackingTable, c.cdcConsumer, lastID)
+		if err != nil {
+			return fmt.Errorf("register consumer: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newSnapshotIterator creates the snapshot iterator, which resumes from the position, if it's set.
+func (c *CombinedIterator) newSnapshotIterator(
+	ctx context.Context, pos *position.Position, columnTypes map[string]string,
+) (*SnapshotIterator, error) {
+	db, err := c.snapshotConn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot connection: %w", err)
+	}
+
+	it, err := NewSnapshotIterator(ctx, SnapshotParams{
+		DB:             db,
+		Table:          c.table,
+		Source:         c.snapshotSource,
+		Cursor:         c.snapshotCursor,
+		Isolation:      c.snapshotIsolation,
+		RefreshMax:     c.snapshotRefreshMax,
+		OrderingColumn: c.orderingColumn,
+		Keys:           c.keys,
+		BatchSize:      c.batchSize,
+		Position:       pos,
+		ColumnTypes:    columnTypes,
+		TrackingTable:  c.trackingTable,
+		TransformOpts:  c.transformOptions,
+		Hints:          c.queryHints,
+
+		QuoteIdentifiers: c.quoteIdentifiers,
+
+		RangeStart: c.snapshotRangeStart,
+		RangeEnd:   c.snapshotRangeEnd,
+		TieBreaker: c.snapshotTieBreaker,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("new shapshot iterator: %w", err)
+	}
+
+	return it, nil
+}
+
+func (c *CombinedIterator) startCDCIterator(
+	ctx context.Context, columnTypes map[string]string, pos *position.Position,
+) error {
+	var err error
+
+	c.cdc, err = NewCDCIterator(
 		ctx,
-		cdcParams{
-			db:            c.db,
-			table:         c.table,
-			trackingTable: c.trackingTable,
-			keys:          c.keys,
-			batchSize:     c.batchSize,
-			columnTypes:   c.tableInfo.ColumnTypes,
-			position:      nil,
+		CDCParams{
+			DB:            c.db,
+			Table:         c.table,
+			TrackingTable: c.trackingTable,
+			Keys:          c.keys,
+			BatchSize:     c.batchSize,
+			ColumnTypes:   columnTypes,
+			Position:      pos,
+			StopTimeout:   c.cdcStopTimeout,
+			TransformOpts: c.transformOptions,
+
+			ChangedColumnsOnly: c.cdcChangedColumnsOnly,
+			Compaction:         c.cdcCompaction,
+			Consumer:           c.cdcConsumer,
+			ConsumerTTL:        c.cdcConsumerTTL,
+			DropOnTeardown:     c.cdcDropOnTeardown,
+			Exclusive:          c.cdcExclusive,
+			Hints:              c.queryHints,
+			GapTimeout:         c.cdcGapTimeout,
+			OrderByTimestamp:   c.cdcOrderByTimestamp,
+			StartFrom:          c.cdcStartFrom,
+			Retention:          c.cdcRetention,
+			DebugMetadata:      c.debugMetadata,
+			LagThreshold:       c.cdcLagThreshold,
+			LagMetadata:        c.lagMetadata,
+			ColumnPrefix:       c.cdcColumnPrefix,
 		},
 	)
 	if err != nil {
@@ -222,7 +1242,175 @@ func (c *CombinedIterator) switchToCDCIterator(ctx context.Context) error {
 	return nil
 }
 
-func (c *CombinedIterator) setKeys(cfgKeys, tableKeys []string) {
+// reconnect reopens the database connection with backoff
+// and reloads rows of the active iterator from its current position.
+func (c *CombinedIterator) reconnect(ctx context.Context) error {
+	b := &backoff.Backoff{
+		Min:    reconnectMinDelay,
+		Max:    reconnectMaxDelay,
+		Factor: 2,
+	}
+
+	var (
+		db  *sqlx.DB
+		err error
+	)
+
+	var replica *sqlx.DB
+
+	for {
+		db, err = c.connect(ctx)
+		// the snapshot reads the replica with its own connection, which can be broken as well.
+		if err == nil && c.snapshotDB != nil {
+			if replica, err = c.open(ctx, *c.replicaAuth); err != nil {
+				db.Close() //nolint:errcheck // the replica error is more relevant
+			}
+		}
+
+		if err == nil {
+			break
+		}
+
+		if b.Attempt() >= reconnectMaxAttempts-1 {
+			return fmt.Errorf("%w: %w", ErrReconnectAttemptsExceeded, err)
+		}
+
+		sdk.Logger(ctx).Warn().Err(err).Msgf("reconnect attempt %d failed", int(b.Attempt())+1)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for reconnect: %w", ctx.Err())
+		case <-time.After(b.Duration()):
+		}
+	}
+
+	if er := c.db.Close(); er != nil {
+		sdk.Logger(ctx).Warn().Err(er).Msg("close broken db connection")
+	}
+
+	c.db = db
+
+	if replica != nil {
+		if er := c.snapshotDB.Close(); er != nil {
+			sdk.Logger(ctx).Warn().Err(er).Msg("close broken replica db connection")
+		}
+
+		c.snapshotDB = replica
+	}
+
+	return c.resume(ctx, db)
+}
+
+// resume reloads rows of the active iterator from its current position using the database connection.
+func (c *CombinedIterator) resume(ctx context.Context, db *sqlx.DB) error {
+	switch {
+	case c.history != nil:
+		return c.history.resume(ctx, db)
+	case c.snapshot != nil && c.cdc != nil:
+		if err := c.cdc.resume(ctx, db); err != nil {
+			return err
+		}
+
+		return c.snapshot.resume(ctx, c.replicaOr(db))
+	case c.snapshot != nil:
+		return c.snapshot.resume(ctx, c.replicaOr(db))
+	case c.cdc != nil:
+		return c.cdc.resume(ctx, db)
+	default:
+		return nil
+	}
+}
+
+func (c *CombinedIterator) connect(ctx context.Context) (*sqlx.DB, error) {
+	return c.open(ctx, c.auth)
+}
+
+// connectTo opens the database connection with the auth config.
+func (c *CombinedIterator) connectTo(ctx context.Context, auth config.AuthConfig) (*sqlx.DB, error) {
+	db, err := helper.ConnectToDB(auth, helper.WithDefaultSchema(c.schema), helper.WithFetchSize(c.fetchSize()),
+		helper.WithKeepalive(c.keepaliveInterval))
+	if err != nil {
+		return nil, fmt.Errorf("connect to db: %w", err)
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close() //nolint:errcheck // the ping error is more relevant
+
+		return nil, fmt.Errorf("ping db: %w", helper.RedactError(err, auth))
+	}
+
+	return db, nil
+}
+
+// snapshotConn returns the connection the snapshot is read with. The replica connection is opened
+// on the first snapshot, the primary connection is used without the replica.
+func (c *CombinedIterator) snapshotConn(ctx context.Context) (*sqlx.DB, error) {
+	if c.replicaAuth == nil {
+		return c.db, nil
+	}
+
+	if c.snapshotDB == nil {
+		db, err := c.open(ctx, *c.replicaAuth)
+		if err != nil {
+			return nil, fmt.Errorf("connect to replica: %w", err)
+		}
+
+		c.snapshotDB = db
+	}
+
+	return c.snapshotDB, nil
+}
+
+// replicaOr returns the replica connection, if it's open, or the given connection.
+func (c *CombinedIterator) replicaOr(db *sqlx.DB) *sqlx.DB {
+	if c.snapshotDB != nil {
+		return c.snapshotDB
+	}
+
+	return db
+}
+
+// closeReplica closes the replica connection, when the snapshot doesn't need it anymore.
+func (c *CombinedIterator) closeReplica(ctx context.Context) {
+	if c.snapshotDB == nil {
+		return
+	}
+
+	if err := c.snapshotDB.Close(); err != nil {
+		sdk.Logger(ctx).Warn().Err(err).Msg("close replica db connection")
+	}
+
+	c.snapshotDB = nil
+}
+
+// fetchSize returns the number of rows fetched at once, the snapshot cursor is fetched in batches.
+func (c *CombinedIterator) fetchSize() int {
+	if c.snapshotCursor {
+		return c.batchSize
+	}
+
+	return 0
+}
+
+// cdcSetupParams returns params of setting up the tracking table and the triggers for the table info.
+func (c *CombinedIterator) cdcSetupParams(tableInfo columntypes.TableInfo) CDCSetupParams {
+	tableInfo.QuoteIdentifiers = c.quoteIdentifiers
+
+	return CDCSetupParams{
+		TableName:          c.table,
+		TrackingTableName:  c.trackingTable,
+		TableInfo:          tableInfo,
+		Keys:               c.keys,
+		ChangedColumnsOnly: c.cdcChangedColumnsOnly,
+		Operations:         c.operations,
+		TransactionID:      c.cdcTransactionID,
+		ChangedAt:          c.cdcOrderByTimestamp || c.debugMetadata || c.cdcLagThreshold > 0 || c.lagMetadata,
+		SDI:                c.cdcSDI,
+		ColumnPrefix:       c.cdcColumnPrefix,
+	}
+}
+
+func (c *CombinedIterator) setKeys(cfgKeys, tableKeys, uniqueKeys []string) {
 	// first priority keys from config.
 	if len(cfgKeys) > 0 {
 		for i := range cfgKeys {
@@ -241,15 +1429,43 @@ func (c *CombinedIterator) setKeys(cfgKeys, tableKeys []string) {
 		return
 	}
 
+	// third priority columns of a unique index from table.
+	if len(uniqueKeys) > 0 {
+		c.keys = uniqueKeys
+
+		return
+	}
+
 	// last priority ordering column.
 	c.keys = []string{c.orderingColumn}
 }
 
-func getTrackingTableName(pos *position.Position, table string) string {
+// toActionTypes converts record operations to the operation types of the triggers.
+func toActionTypes(operations []string) []actionType {
+	result := make([]actionType, 0, len(operations))
+
+	for _, operation := range operations {
+		switch operation {
+		case opencdc.OperationCreate.String():
+			result = append(result, insertOperation)
+		case opencdc.OperationUpdate.String():
+			result = append(result, updateOperation)
+		case opencdc.OperationDelete.String():
+			result = append(result, deleteOperation)
+		}
+	}
+
+	return result
+}
+
+func getTrackingTableName(ctx context.Context, pos *position.Position, table string, shared bool) string {
 	if pos != nil {
 		return pos.TrackingTableName
 	}
 
-	// create new suffix
-	return fmt.Sprintf(trackingTablePattern, table, time.Now().Format("150405"))
+	if shared {
+		return formatTrackingTableName(table, sharedSuffix)
+	}
+
+	return formatTrackingTableName(table, newSuffix(ctx))
 }
@@ -0,0 +1,112 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+const (
+	// KeyCaseUpper makes field names of records upper case.
+	KeyCaseUpper = "upper"
+	// KeyCasePreserve keeps field names of records as column names of the table.
+	KeyCasePreserve = "preserve"
+	// KeyCaseLower makes field names of records lower case.
+	KeyCaseLower = "lower"
+)
+
+// keyCaser changes the case of field names of records.
+type keyCaser func(string) string
+
+// newKeyCaser returns the function changing the case of field names, it's nil if names are preserved.
+func newKeyCaser(keyCase string) keyCaser {
+	switch keyCase {
+	case KeyCaseUpper:
+		return strings.ToUpper
+	case KeyCaseLower:
+		return strings.ToLower
+	default:
+		return nil
+	}
+}
+
+// apply changes the case of field names of the key, the payload and the changed columns of the record.
+func (k keyCaser) apply(record *opencdc.Record) error {
+	var err error
+
+	if record.Key, err = k.data(record.Key); err != nil {
+		return fmt.Errorf("key: %w", err)
+	}
+
+	if record.Payload.Before, err = k.data(record.Payload.Before); err != nil {
+		return fmt.Errorf("payload before: %w", err)
+	}
+
+	if record.Payload.After, err = k.data(record.Payload.After); err != nil {
+		return fmt.Errorf("payload after: %w", err)
+	}
+
+	if columns, ok := record.Metadata[metadataChangedColumns]; ok {
+		record.Metadata[metadataChangedColumns] = k(columns)
+	}
+
+	return nil
+}
+
+// data changes the case of top level field names of the data, nested objects are kept as they are.
+func (k keyCaser) data(data opencdc.Data) (opencdc.Data, error) {
+	switch data := data.(type) {
+	case opencdc.StructuredData:
+		return k.fields(data), nil
+	case opencdc.RawData:
+		if len(data) == 0 {
+			return data, nil
+		}
+
+		fields := make(map[string]any)
+
+		// use json.Number to keep precision of big integers and decimals.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("unmarshal data: %w", err)
+		}
+
+		bs, err := json.Marshal(k.fields(fields))
+		if err != nil {
+			return nil, fmt.Errorf("marshal data: %w", err)
+		}
+
+		return opencdc.RawData(bs), nil
+	default:
+		return data, nil
+	}
+}
+
+// fields returns the fields with names in the case.
+func (k keyCaser) fields(fields map[string]any) opencdc.StructuredData {
+	renamed := make(opencdc.StructuredData, len(fields))
+	for name, value := range fields {
+		renamed[k(name)] = value
+	}
+
+	return renamed
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestKeyCaser_apply(t *testing.T) {
+	t.Parallel()
+
+	record := opencdc.Record{
+		Key:      opencdc.StructuredData{"USER_ID": 1},
+		Metadata: opencdc.Metadata{metadataChangedColumns: "USER_ID,FULL_NAME"},
+		Payload: opencdc.Change{
+			After: opencdc.RawData(`{"USER_ID":9007199254740993,"FULL_NAME":"Bob","ADDRESS":{"CITY":"Kyiv"}}`),
+		},
+	}
+
+	if err := newKeyCaser(KeyCaseLower).apply(&record); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if want := (opencdc.StructuredData{"user_id": 1}); !reflect.DeepEqual(record.Key, want) {
+		t.Errorf("key = %v, want %v", record.Key, want)
+	}
+
+	if want := `{"address":{"CITY":"Kyiv"},"full_name":"Bob","user_id":9007199254740993}`; string(
+		record.Payload.After.Bytes()) != want {
+		t.Errorf("payload = %s, want %s", record.Payload.After.Bytes(), want)
+	}
+
+	if want := "user_id,full_name"; record.Metadata[metadataChangedColumns] != want {
+		t.Errorf("changed columns = %s, want %s", record.Metadata[metadataChangedColumns], want)
+	}
+
+	if newKeyCaser(KeyCasePreserve) != nil {
+		t.Errorf("preserve key caser isn't nil")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"time"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// metadataLag - the replication lag of the record in milliseconds.
+const metadataLag = "saphana.lag"
+
+// lagTracker measures the replication lag, the time between a change and its read by the connector,
+// by the time the change was captured in the tracking table.
+type lagTracker struct {
+	// threshold - lag above which a warning is logged, zero disables warnings.
+	threshold time.Duration
+	// records, max, total - number of records of the current batch, and their max and total lag.
+	records int
+	max     time.Duration
+	total   time.Duration
+}
+
+// observe returns the lag of the change captured at the time, and adds it to the lag of the batch.
+// Clocks of the database and the connector can differ, so the lag is never negative.
+func (l *lagTracker) observe(changedAt, now time.Time) time.Duration {
+	lag := max(now.Sub(changedAt), 0)
+
+	l.records++
+	l.max = max(l.max, lag)
+	l.total += lag
+
+	return lag
+}
+
+// report logs the lag of the batch, and resets it. It's a warning if the max lag exceeds the threshold.
+func (l *lagTracker) report(ctx context.Context, table string) {
+	if l.records == 0 {
+		return
+	}
+
+	event := sdk.Logger(ctx).Debug()
+	if l.threshold > 0 && l.max > l.threshold {
+		event = sdk.Logger(ctx).Warn().Dur("threshold", l.threshold)
+	}
+
+	event.Str("table", table).
+		Int("records", l.records).
+		Dur("maxLag", l.max).
+		Dur("avgLag", l.total/time.Duration(l.records)).
+		Msg("replication lag")
+
+	l.records, l.max, l.total = 0, 0, 0
+}
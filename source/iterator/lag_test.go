@@ -0,0 +1,52 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLagTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	l := &lagTracker{threshold: time.Second}
+
+	for _, tt := range []struct {
+		changedAt time.Time
+		want      time.Duration
+	}{
+		{changedAt: now.Add(-3 * time.Second), want: 3 * time.Second},
+		{changedAt: now.Add(-time.Second), want: time.Second},
+		// the clock of the database is ahead of the connector.
+		{changedAt: now.Add(time.Second), want: 0},
+	} {
+		if got := l.observe(tt.changedAt, now); got != tt.want {
+			t.Errorf("observe(%s) = %s, want %s", tt.changedAt, got, tt.want)
+		}
+	}
+
+	if l.records != 3 || l.max != 3*time.Second || l.total != 4*time.Second {
+		t.Errorf("records = %d, max = %s, total = %s, want 3, 3s, 4s", l.records, l.max, l.total)
+	}
+
+	l.report(context.Background(), "ORDERS")
+
+	if l.records != 0 || l.max != 0 || l.total != 0 {
+		t.Errorf("lag of the batch isn't reset after the report")
+	}
+}
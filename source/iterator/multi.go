@@ -0,0 +1,398 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// MultiIterator reads all tables of a schema.
+// Each table is read by its own combined iterator, so every table gets a snapshot and a tracking table.
+// Tables are polled in turns, and the position of every record keeps the last positions of all tables.
+// The catalog is checked for new tables periodically.
+type MultiIterator struct {
+	db *sqlx.DB
+
+	// params - params of table iterators.
+	params CombinedParams
+	// schema - schema name.
+	schema string
+	// includeRegex - only tables matching it are read, if it's set.
+	includeRegex *regexp.Regexp
+	// excludeRegex - tables matching it aren't read, if it's set.
+	excludeRegex *regexp.Regexp
+	// discoveryInterval - interval of checking the catalog for new tables.
+	discoveryInterval time.Duration
+	// discoveredAt - time of the last check of the catalog.
+	discoveredAt time.Time
+	// skipped - tables which can't or shouldn't be read.
+	skipped map[string]bool
+	// tables - table names in the polling order.
+	tables []string
+	// iterators - combined iterators by table names.
+	iterators map[string]*CombinedIterator
+	// positions - last positions of tables.
+	positions map[string]*position.Position
+	// current - index of the table, which iterator has the next record.
+	current int
+
+	// overrides - options of single tables by table names.
+	overrides map[string]TableOverrides
+	// polledAt - time of the last poll of tables, which had no records.
+	polledAt map[string]time.Time
+}
+
+// TableOverrides holds options of a single table, which override the options of all tables.
+type TableOverrides struct {
+	// BatchSize is a size of rows batch of the table, zero keeps the common one.
+	BatchSize int
+	// PollInterval is the minimum interval of polling the table after it had no records, zero polls it every time.
+	PollInterval time.Duration
+}
+
+// MultiParams is an incoming params for the [NewMultiIterator] function.
+// The Table and CfgKeys of the CombinedParams are ignored.
+type MultiParams struct {
+	CombinedParams
+
+	Schema            string
+	IncludeRegex      *regexp.Regexp
+	ExcludeRegex      *regexp.Regexp
+	DiscoveryInterval time.Duration
+	Overrides         map[string]TableOverrides
+}
+
+// NewMultiIterator - create new iterator for all tables of the schema.
+func NewMultiIterator(ctx context.Context, params MultiParams) (*MultiIterator, error) {
+	pos, err := position.ParseSDKMultiPosition(params.SdkPosition)
+	if err != nil {
+		return nil, fmt.Errorf("parse position: %w", err)
+	}
+
+	it := &MultiIterator{
+		db:                params.DB,
+		params:            params.CombinedParams,
+		schema:            params.Schema,
+		includeRegex:      params.IncludeRegex,
+		excludeRegex:      params.ExcludeRegex,
+		discoveryInterval: params.DiscoveryInterval,
+		skipped:           make(map[string]bool),
+		iterators:         make(map[string]*CombinedIterator),
+		positions:         make(map[string]*position.Position),
+		overrides:         params.Overrides,
+		polledAt:          make(map[string]time.Time),
+	}
+
+	// table iterators get the schema and the position of their table.
+	it.params.Schema = params.Schema
+	it.params.CfgKeys = nil
+
+	if pos != nil {
+		it.positions = pos.Positions
+	}
+
+	if err = it.discover(ctx); err != nil {
+		// release iterators of the discovered tables, the db belongs to the caller.
+		it.db = nil
+		if stopErr := it.Stop(ctx); stopErr != nil {
+			sdk.Logger(ctx).Warn().Err(stopErr).Msg("stop table iterators")
+		}
+
+		return nil, fmt.Errorf("discover tables: %w", err)
+	}
+
+	return it, nil
+}
+
+// HasNext returns a bool indicating whether any table has the next record to return or not.
+func (m *MultiIterator) HasNext(ctx context.Context) (bool, error) {
+	if time.Since(m.discoveredAt) >= m.discoveryInterval {
+		// new tables are picked up on the next check, reading the known ones goes on.
+		if err := m.discover(ctx); err != nil {
+			sdk.Logger(ctx).Warn().Err(err).Msg("discover tables")
+		}
+	}
+
+	for i := range m.tables {
+		idx := (m.current + i) % len(m.tables)
+		table := m.tables[idx]
+
+		if !m.due(table) {
+			continue
+		}
+
+		hasNext, err := m.iterators[table].HasNext(ctx)
+		if err != nil {
+			return false, fmt.Errorf("table %s has next: %w", table, err)
+		}
+
+		if hasNext {
+			m.current = idx
+
+			return true, nil
+		}
+
+		m.polledAt[table] = time.Now()
+	}
+
+	return false, nil
+}
+
+// Next returns the next record of the table found by HasNext.
+func (m *MultiIterator) Next(ctx context.Context) (opencdc.Record, error) {
+	if len(m.tables) == 0 {
+		return opencdc.Record{}, ErrNoInitializedIterator
+	}
+
+	table := m.tables[m.current]
+
+	record, err := m.iterators[table].Next(ctx)
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("table %s next: %w", table, err)
+	}
+
+	tablePos, err := position.ParseSDKPosition(record.Position)
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("parse position: %w", err)
+	}
+
+	m.positions[table] = tablePos
+
+	record.Position, err = position.MultiPosition{
+		Table:     table,
+		Positions: m.positions,
+	}.ConvertToSDKPosition()
+	if err != nil {
+		return opencdc.Record{}, fmt.Errorf("convert position: %w", err)
+	}
+
+	// give other tables a turn.
+	m.current = (m.current + 1) % len(m.tables)
+
+	return record, nil
+}
+
+// Stop the underlying iterators.
+func (m *MultiIterator) Stop(ctx context.Context) error {
+	var errs []error
+
+	for _, table := range m.tables {
+		if err := m.iterators[table].Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop iterator for table %s: %w", table, err))
+		}
+	}
+
+	if m.db != nil {
+		if err := m.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close db: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Ack check if record with position was recorded.
+func (m *MultiIterator) Ack(ctx context.Context, rp opencdc.Position) error {
+	pos, err := position.ParseSDKMultiPosition(rp)
+	if err != nil {
+		return fmt.Errorf("parse position: %w", err)
+	}
+
+	tableIt, ok := m.iterators[pos.Table]
+	if !ok || pos.Positions[pos.Table] == nil {
+		return nil
+	}
+
+	tablePos, err := pos.Positions[pos.Table].ConvertToSDKPosition()
+	if err != nil {
+		return fmt.Errorf("convert position: %w", err)
+	}
+
+	return tableIt.Ack(ctx, tablePos)
+}
+
+// discover creates iterators for tables of the schema, which aren't read yet.
+func (m *MultiIterator) discover(ctx context.Context) error {
+	m.discoveredAt = time.Now()
+
+	tables, err := m.getTables(ctx)
+	if err != nil {
+		return fmt.Errorf("get tables: %w", err)
+	}
+
+	withOrderingColumn, err := m.getTablesWithColumn(ctx, m.params.OrderingColumn)
+	if err != nil {
+		return fmt.Errorf("get tables with ordering column: %w", err)
+	}
+
+	withTieBreaker, err := m.getTablesWithColumn(ctx, m.params.SnapshotTieBreakerColumn)
+	if err != nil {
+		return fmt.Errorf("get tables with tie-breaker column: %w", err)
+	}
+
+	for _, table := range tables {
+		if _, ok := m.iterators[table]; ok || m.skipped[table] {
+			continue
+		}
+
+		if !m.matchFilters(table) {
+			sdk.Logger(ctx).Debug().Str("table", table).Msg("skip table filtered out by regex")
+			m.skipped[table] = true
+
+			continue
+		}
+
+		tableParams := m.params
+		tableParams.Table = table
+
+		if batchSize := m.overrides[table].BatchSize; batchSize > 0 {
+			tableParams.BatchSize = batchSize
+		}
+
+		// tables without the configured ordering column are ordered by their primary key.
+		if !withOrderingColumn[table] {
+			tableParams.OrderingColumn = ""
+		}
+
+		// the primary key is unique, so rows ordered by it don't need a tie-breaker.
+		if tableParams.OrderingColumn == "" || !withTieBreaker[table] {
+			tableParams.SnapshotTieBreakerColumn = ""
+		}
+
+		tableIt, er := m.newTableIterator(ctx, tableParams)
+		if er != nil {
+			if errors.Is(er, ErrNoOrderingColumn) || errors.Is(er, ErrMaskedOrderingColumn) {
+				sdk.Logger(ctx).Warn().Err(er).Str("table", table).Msg("skip table without usable ordering column")
+				m.skipped[table] = true
+
+				continue
+			}
+
+			return fmt.Errorf("new iterator for table %s: %w", table, er)
+		}
+
+		if m.tables != nil {
+			sdk.Logger(ctx).Info().Str("table", table).Msg("start reading new table")
+		}
+
+		m.tables = append(m.tables, table)
+		m.iterators[table] = tableIt
+	}
+
+	return nil
+}
+
+// due checks whether the poll interval of the table passed since it had no records.
+// Tables with records are polled every time.
+func (m *MultiIterator) due(table string) bool {
+	polledAt, ok := m.polledAt[table]
+	if !ok {
+		return true
+	}
+
+	return time.Since(polledAt) >= m.overrides[table].PollInterval
+}
+
+// matchFilters checks the table name against the include and exclude regular expressions.
+func (m *MultiIterator) matchFilters(table string) bool {
+	if m.includeRegex != nil && !m.includeRegex.MatchString(table) {
+		return false
+	}
+
+	if m.excludeRegex != nil && m.excludeRegex.MatchString(table) {
+		return false
+	}
+
+	return true
+}
+
+// newTableIterator creates a combined iterator for the table with its own connection.
+func (m *MultiIterator) newTableIterator(ctx context.Context, params CombinedParams) (*CombinedIterator, error) {
+	var fetchSize int
+	if params.SnapshotCursor {
+		fetchSize = params.BatchSize
+	}
+
+	db, err := helper.ConnectToDB(params.Auth, helper.WithDefaultSchema(params.Schema), helper.WithFetchSize(fetchSize),
+		helper.WithKeepalive(params.KeepaliveInterval))
+	if err != nil {
+		return nil, fmt.Errorf("connect to db: %w", err)
+	}
+
+	params.DB = db
+	params.SdkPosition = nil
+
+	if tablePos := m.positions[params.Table]; tablePos != nil {
+		params.SdkPosition, err = tablePos.ConvertToSDKPosition()
+		if err != nil {
+			db.Close() //nolint:errcheck // the convert error is more relevant
+
+			return nil, fmt.Errorf("convert position: %w", err)
+		}
+	}
+
+	it, err := NewCombinedIterator(ctx, params)
+	if err != nil {
+		db.Close() //nolint:errcheck // the iterator error is more relevant
+
+		return nil, err
+	}
+
+	return it, nil
+}
+
+// getTables returns names of the schema tables.
+func (m *MultiIterator) getTables(ctx context.Context) ([]string, error) {
+	var tables []string
+
+	err := m.db.SelectContext(ctx, &tables, queryGetSchemaTables, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("select tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// getTablesWithColumn returns names of the schema tables which have the column.
+func (m *MultiIterator) getTablesWithColumn(ctx context.Context, column string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if column == "" {
+		return result, nil
+	}
+
+	var tables []string
+
+	err := m.db.SelectContext(ctx, &tables, queryGetTablesWithColumn, m.schema, column)
+	if err != nil {
+		return nil, fmt.Errorf("select tables: %w", err)
+	}
+
+	for _, table := range tables {
+		result[table] = true
+	}
+
+	return result, nil
+}
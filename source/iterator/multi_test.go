@@ -0,0 +1,122 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMultiIterator_matchFilters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		include *regexp.Regexp
+		exclude *regexp.Regexp
+		table   string
+		want    bool
+	}{
+		{
+			name:  "no filters",
+			table: "ORDERS",
+			want:  true,
+		},
+		{
+			name:    "included",
+			include: regexp.MustCompile(`^ORDER`),
+			table:   "ORDERS",
+			want:    true,
+		},
+		{
+			name:    "not included",
+			include: regexp.MustCompile(`^ORDER`),
+			table:   "CLIENTS",
+			want:    false,
+		},
+		{
+			name:    "excluded",
+			exclude: regexp.MustCompile(`^(TMP|STG)_`),
+			table:   "TMP_ORDERS",
+			want:    false,
+		},
+		{
+			name:    "exclude wins over include",
+			include: regexp.MustCompile(`ORDERS`),
+			exclude: regexp.MustCompile(`^STG_`),
+			table:   "STG_ORDERS",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &MultiIterator{includeRegex: tt.include, excludeRegex: tt.exclude}
+
+			if got := m.matchFilters(tt.table); got != tt.want {
+				t.Errorf("matchFilters() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiIterator_due(t *testing.T) {
+	t.Parallel()
+
+	m := &MultiIterator{
+		overrides: map[string]TableOverrides{
+			"CURRENCIES": {PollInterval: time.Hour},
+		},
+		polledAt: map[string]time.Time{
+			"CURRENCIES": time.Now(),
+			"ORDERS":     time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name  string
+		table string
+		want  bool
+	}{
+		{
+			name:  "not polled yet",
+			table: "CLIENTS",
+			want:  true,
+		},
+		{
+			name:  "no poll interval",
+			table: "ORDERS",
+			want:  true,
+		},
+		{
+			name:  "within poll interval",
+			table: "CURRENCIES",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := m.due(tt.table); got != tt.want {
+				t.Errorf("due() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
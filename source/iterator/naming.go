@@ -0,0 +1,101 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+const (
+	// maxIdentifierLength - max length of table and trigger names in SAP HANA.
+	maxIdentifierLength = 127
+	// nameHashLength - length of the hash replacing the end of the table name, which is too long.
+	nameHashLength = 8
+	// suffixLength - length of the suffix of tracking tables and triggers.
+	suffixLength = 6
+)
+
+// newSuffix returns the suffix of the tracking table and the triggers of the connector.
+// The suffix is based on the connector id, so it's the same after restarts and differs for connectors
+// started at the same time. The time of creation is used if the connector id is unknown.
+func newSuffix(ctx context.Context) string {
+	if connectorID := sdk.ConnectorIDFromContext(ctx); connectorID != "" {
+		return nameHash(connectorID)[:suffixLength]
+	}
+
+	return time.Now().Format("150405")
+}
+
+// formatTrackingTableName returns the name of the tracking table of the table.
+func formatTrackingTableName(table, suffix string) string {
+	reserved := utf8.RuneCountInString(fmt.Sprintf(trackingTablePattern, "", suffix))
+
+	return fmt.Sprintf(trackingTablePattern, fitName(sanitizeName(table), reserved), suffix)
+}
+
+// formatTriggerName returns the name of the trigger of the operation on the table.
+func formatTriggerName(table string, operation actionType, suffix string) string {
+	reserved := utf8.RuneCountInString(fmt.Sprintf(triggerNamePattern, "", operation, suffix))
+
+	return fmt.Sprintf(triggerNamePattern, fitName(sanitizeName(table), reserved), operation, suffix)
+}
+
+// formatSubscriptionName returns the name of the remote subscription of the table.
+func formatSubscriptionName(table, suffix string) string {
+	reserved := utf8.RuneCountInString(fmt.Sprintf(subscriptionNamePattern, "", suffix))
+
+	return fmt.Sprintf(subscriptionNamePattern, fitName(sanitizeName(table), reserved), suffix)
+}
+
+// sanitizeName replaces characters, which aren't valid in plain identifiers, with underscores, so names derived
+// from the table name are valid without quotes, e.g. tables of SAP namespaces, like /BIC/AZSALES00.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '#' || r == '$' {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, name)
+}
+
+// fitName shortens the name, so it fits the max identifier length with the reserved number of characters.
+// The end of the name is replaced with its hash, so shortened names of different tables don't collide.
+func fitName(name string, reserved int) string {
+	limit := maxIdentifierLength - reserved
+
+	runes := []rune(name)
+	if len(runes) <= limit {
+		return name
+	}
+
+	return string(runes[:limit-nameHashLength-1]) + "_" + nameHash(name)[:nameHashLength]
+}
+
+// nameHash returns the upper case hex encoded SHA-256 hash of the value.
+func nameHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
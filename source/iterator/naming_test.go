@@ -0,0 +1,82 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatTrackingTableName(t *testing.T) {
+	t.Parallel()
+
+	if got, want := formatTrackingTableName("CLIENTS", "9F86D0"), "CONDUIT_CLIENTS_9F86D0"; got != want {
+		t.Errorf("formatTrackingTableName() = %s, want %s", got, want)
+	}
+
+	long := strings.Repeat("A", 120)
+
+	name := formatTrackingTableName(long, "9F86D0")
+	if utf8.RuneCountInString(name) > maxIdentifierLength {
+		t.Errorf("tracking table name %s is longer than %d", name, maxIdentifierLength)
+	}
+
+	if !strings.HasSuffix(name, "_9F86D0") {
+		t.Errorf("tracking table name %s lost the suffix", name)
+	}
+
+	if name == formatTrackingTableName(long+"B", "9F86D0") {
+		t.Errorf("shortened names of different tables collide")
+	}
+}
+
+func TestFormatTrackingTableName_Namespace(t *testing.T) {
+	t.Parallel()
+
+	if got, want := formatTrackingTableName("/BIC/AZSALES00", "9F86D0"), "CONDUIT__BIC_AZSALES00_9F86D0"; got != want {
+		t.Errorf("formatTrackingTableName() = %s, want %s", got, want)
+	}
+
+	if got, want := formatTriggerName("/BIC/AZSALES00", insertOperation, "9F86D0"), "CD__BIC_AZSALES00_INSERT_9F86D0"; got != want {
+		t.Errorf("formatTriggerName() = %s, want %s", got, want)
+	}
+}
+
+func TestFormatTriggerName(t *testing.T) {
+	t.Parallel()
+
+	if got, want := formatTriggerName("CLIENTS", insertOperation, "9F86D0"), "CD_CLIENTS_INSERT_9F86D0"; got != want {
+		t.Errorf("formatTriggerName() = %s, want %s", got, want)
+	}
+
+	name := formatTriggerName(strings.Repeat("Б", 130), deleteOperation, "9F86D0")
+	if utf8.RuneCountInString(name) != maxIdentifierLength {
+		t.Errorf("trigger name %s must be shortened to %d characters", name, maxIdentifierLength)
+	}
+}
+
+func TestFormatSubscriptionName(t *testing.T) {
+	t.Parallel()
+
+	if got, want := formatSubscriptionName("CLIENTS_VT", "9F86D0"), "CS_CLIENTS_VT_9F86D0"; got != want {
+		t.Errorf("formatSubscriptionName() = %s, want %s", got, want)
+	}
+
+	name := formatSubscriptionName(strings.Repeat("Б", 130), "9F86D0")
+	if utf8.RuneCountInString(name) != maxIdentifierLength {
+		t.Errorf("subscription name %s must be shortened to %d characters", name, maxIdentifierLength)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+const (
+	// NullsInclude keeps NULL column values as explicit nulls in payloads.
+	NullsInclude = "include"
+	// NullsOmit leaves fields of NULL column values out of payloads.
+	NullsOmit = "omit"
+	// NullsDefault replaces NULL column values by the defaults of their columns,
+	// columns without a default keep explicit nulls.
+	NullsDefault = "default"
+)
+
+// nullHandler omits or replaces null fields of payloads of records.
+type nullHandler struct {
+	// omit - null fields are left out.
+	omit bool
+	// defaults - values replacing nulls by column names.
+	defaults map[string]any
+}
+
+// newNullHandler returns the handler of null fields, it's nil if nulls are included.
+// Defaults which are valid JSON, like 0, false or "", are parsed, the other ones are strings.
+func newNullHandler(mode string, defaults map[string]string) *nullHandler {
+	switch mode {
+	case NullsOmit:
+		return &nullHandler{omit: true}
+	case NullsDefault:
+		n := &nullHandler{defaults: make(map[string]any, len(defaults))}
+
+		for column, value := range defaults {
+			decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+			decoder.UseNumber()
+
+			var parsed any
+			if err := decoder.Decode(&parsed); err != nil || decoder.More() {
+				parsed = value
+			}
+
+			n.defaults[column] = parsed
+		}
+
+		return n
+	default:
+		return nil
+	}
+}
+
+// apply omits or replaces null fields of the payload of the record, keys are kept as they are.
+func (n *nullHandler) apply(record *opencdc.Record) error {
+	var err error
+
+	if record.Payload.Before, err = n.data(record.Payload.Before); err != nil {
+		return fmt.Errorf("payload before: %w", err)
+	}
+
+	if record.Payload.After, err = n.data(record.Payload.After); err != nil {
+		return fmt.Errorf("payload after: %w", err)
+	}
+
+	return nil
+}
+
+// data omits or replaces top level null fields of the data, nested objects are kept as they are.
+func (n *nullHandler) data(data opencdc.Data) (opencdc.Data, error) {
+	switch data := data.(type) {
+	case opencdc.StructuredData:
+		n.fields(data)
+
+		return data, nil
+	case opencdc.RawData:
+		if len(data) == 0 {
+			return data, nil
+		}
+
+		fields := make(map[string]any)
+
+		// use json.Number to keep precision of big integers and decimals.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("unmarshal data: %w", err)
+		}
+
+		n.fields(fields)
+
+		bs, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("marshal data: %w", err)
+		}
+
+		return opencdc.RawData(bs), nil
+	default:
+		return data, nil
+	}
+}
+
+// fields omits or replaces null fields in place.
+func (n *nullHandler) fields(fields map[string]any) {
+	for name, value := range fields {
+		if value != nil {
+			continue
+		}
+
+		if n.omit {
+			delete(fields, name)
+
+			continue
+		}
+
+		if value, ok := n.defaults[name]; ok {
+			fields[name] = value
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func TestNullHandler_apply(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"ID":1,"NAME":null,"AGE":null,"ACTIVE":null,"CODE":null}`
+
+	tests := []struct {
+		name     string
+		mode     string
+		defaults map[string]string
+		want     string
+	}{
+		{
+			name: "omit",
+			mode: NullsOmit,
+			want: `{"ID":1}`,
+		},
+		{
+			name:     "default",
+			mode:     NullsDefault,
+			defaults: map[string]string{"NAME": "unknown", "AGE": "0", "ACTIVE": "false", "CODE": `""`},
+			want:     `{"ACTIVE":false,"AGE":0,"CODE":"","ID":1,"NAME":"unknown"}`,
+		},
+		{
+			name:     "default keeps nulls of columns without defaults",
+			mode:     NullsDefault,
+			defaults: map[string]string{"AGE": "0"},
+			want:     `{"ACTIVE":null,"AGE":0,"CODE":null,"ID":1,"NAME":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			record := opencdc.Record{
+				Key:     opencdc.StructuredData{"ID": 1, "CODE": nil},
+				Payload: opencdc.Change{After: opencdc.RawData(payload)},
+			}
+
+			if err := newNullHandler(tt.mode, tt.defaults).apply(&record); err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+
+			if got := string(record.Payload.After.Bytes()); got != tt.want {
+				t.Errorf("payload = %s, want %s", got, tt.want)
+			}
+
+			if want := (opencdc.StructuredData{"ID": 1, "CODE": nil}); !reflect.DeepEqual(record.Key, want) {
+				t.Errorf("key = %v, want %v", record.Key, want)
+			}
+		})
+	}
+
+	if newNullHandler(NullsInclude, nil) != nil {
+		t.Errorf("include null handler isn't nil")
+	}
+}
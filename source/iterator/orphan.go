@@ -0,0 +1,143 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// Policies of handling tracking tables left by the previous runs, when the position is lost.
+const (
+	// OrphanAdopt - the orphan tracking table and its triggers are used instead of new ones.
+	OrphanAdopt = "adopt"
+	// OrphanRecreate - the orphan tracking table and its triggers are dropped, new ones are created.
+	OrphanRecreate = "recreate"
+	// OrphanFail - the iterator isn't created.
+	OrphanFail = "fail"
+)
+
+// resolveOrphanTrackingTable handles the tracking table of the table left by the previous runs of the connector
+// according to the policy. It returns the name of the tracking table to use.
+func resolveOrphanTrackingTable(
+	ctx context.Context, db *sqlx.DB, table, trackingTable, policy string,
+) (string, error) {
+	orphans, err := getOrphanTrackingTables(ctx, db, table)
+	if err != nil {
+		return "", fmt.Errorf("get orphan tracking tables: %w", err)
+	}
+
+	if len(orphans) == 0 {
+		return trackingTable, nil
+	}
+
+	switch policy {
+	case OrphanFail:
+		return "", fmt.Errorf("%w: %s", ErrOrphanTrackingTable, strings.Join(orphans, ", "))
+	case OrphanRecreate:
+		for _, orphan := range orphans {
+			sdk.Logger(ctx).Warn().Str("trackingTable", orphan).Msg("drop orphan tracking table")
+
+			if err = dropTrackingTable(ctx, db, table, orphan); err != nil {
+				return "", fmt.Errorf("drop tracking table %s: %w", orphan, err)
+			}
+		}
+
+		return trackingTable, nil
+	default:
+		sdk.Logger(ctx).Info().Str("trackingTable", orphans[0]).Msg("adopt orphan tracking table")
+
+		return orphans[0], nil
+	}
+}
+
+// getOrphanTrackingTables returns names of the tracking tables of the table in the current schema, which were
+// created by the connector. Tracking tables of other connectors reading the same table are live, they're never
+// returned. Without the connector id the suffix is the time of creation, so tracking tables of the connector
+// can't be told apart from the ones of other connectors, and none are returned.
+func getOrphanTrackingTables(ctx context.Context, db *sqlx.DB, table string) ([]string, error) {
+	if sdk.ConnectorIDFromContext(ctx) == "" {
+		return nil, nil
+	}
+
+	name := formatTrackingTableName(table, strings.Repeat("0", suffixLength))
+	prefix := name[:len(name)-suffixLength]
+
+	var tables []string
+
+	err := db.SelectContext(ctx, &tables, queryGetTrackingTables, escapeLike(prefix)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("select tables: %w", err)
+	}
+
+	return ownTrackingTables(tables, prefix, newSuffix(ctx)), nil
+}
+
+// ownTrackingTables returns the tables with the prefix and the suffix of the connector. Tracking tables
+// of other connectors have other suffixes, and tracking tables of other tables can match the prefix,
+// like CONDUIT_A_B_* for the table A.
+func ownTrackingTables(tables []string, prefix, suffix string) []string {
+	result := make([]string, 0, 1)
+	for _, name := range tables {
+		if name == prefix+suffix {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// dropTrackingTable drops the triggers or the remote subscription writing to the tracking table
+// and the tracking table.
+func dropTrackingTable(ctx context.Context, db *sqlx.DB, table, trackingTable string) error {
+	suffix := trackingTable[len(trackingTable)-suffixLength:]
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	for _, operation := range []actionType{insertOperation, updateOperation, deleteOperation} {
+		if err = dropTrigger(ctx, tx, formatTriggerName(table, operation, suffix)); err != nil {
+			return fmt.Errorf("drop trigger catch %s: %w", strings.ToLower(string(operation)), err)
+		}
+	}
+
+	// the tracking table of the sdi cdc mode is filled by the remote subscription instead of the triggers.
+	if err = dropSubscription(ctx, tx, formatSubscriptionName(table, suffix)); err != nil {
+		return fmt.Errorf("drop remote subscription: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(queryDropTable, trackingTable)); err != nil {
+		return fmt.Errorf("drop table: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the wildcards of the LIKE predicate with the backslash.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOwnTrackingTables(t *testing.T) {
+	t.Parallel()
+
+	// two connectors read the table CLIENTS, the table CLIENTS_B is read by one of them.
+	first, second := nameHash("first-connector")[:suffixLength], nameHash("second-connector")[:suffixLength]
+
+	tables := []string{
+		formatTrackingTableName("CLIENTS", first),
+		formatTrackingTableName("CLIENTS", second),
+		formatTrackingTableName("CLIENTS_B", first),
+		formatTrackingTableName("CLIENTS", "150405"),
+	}
+
+	prefix := "CONDUIT_CLIENTS_"
+
+	if got := ownTrackingTables(tables, prefix, first); !reflect.DeepEqual(got, []string{tables[0]}) {
+		t.Errorf("ownTrackingTables() = %v, want only the tracking table of the first connector", got)
+	}
+
+	if got := ownTrackingTables(tables, prefix, second); !reflect.DeepEqual(got, []string{tables[1]}) {
+		t.Errorf("ownTrackingTables() = %v, want only the tracking table of the second connector", got)
+	}
+
+	if got := ownTrackingTables(tables, prefix, nameHash("third-connector")[:suffixLength]); len(got) != 0 {
+		t.Errorf("ownTrackingTables() = %v, want none for a new connector", got)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	t.Parallel()
+
+	if got, want := escapeLike(`CONDUIT_A%B\_`), `CONDUIT\_A\%B\\\_`; got != want {
+		t.Errorf("escapeLike() = %s, want %s", got, want)
+	}
+}
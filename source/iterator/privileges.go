@@ -0,0 +1,85 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// object types of the EFFECTIVE_PRIVILEGES view.
+	objectTypeSchema = "SCHEMA"
+	objectTypeTable  = "TABLE"
+)
+
+var (
+	// tablePrivileges - privileges for reading the table and creating triggers on it.
+	tablePrivileges = []string{"SELECT", "TRIGGER"}
+	// schemaPrivileges - privileges for creating the tracking table and triggers.
+	schemaPrivileges = []string{"CREATE ANY"}
+)
+
+// grantedPrivilege - a row of the EFFECTIVE_PRIVILEGES view.
+type grantedPrivilege struct {
+	ObjectType string `db:"OBJECT_TYPE"`
+	Privilege  string `db:"PRIVILEGE"`
+}
+
+// checkPrivileges checks that the current user has all privileges the connector needs for the table,
+// so the missing ones are reported before the connector tries to set up CDC.
+func checkPrivileges(ctx context.Context, db *sqlx.DB, schema, table string) error {
+	var granted []grantedPrivilege
+
+	err := db.SelectContext(ctx, &granted, queryGetPrivileges, schema, table)
+	if err != nil {
+		return fmt.Errorf("select effective privileges: %w", err)
+	}
+
+	return findMissingPrivileges(granted, schema, table)
+}
+
+// findMissingPrivileges returns an error for each required privilege which isn't granted.
+func findMissingPrivileges(granted []grantedPrivilege, schema, table string) error {
+	byType := map[string]map[string]bool{
+		objectTypeSchema: {},
+		objectTypeTable:  {},
+	}
+
+	for _, p := range granted {
+		if _, ok := byType[p.ObjectType]; ok {
+			byType[p.ObjectType][p.Privilege] = true
+		}
+	}
+
+	var errs []error
+
+	for _, privilege := range tablePrivileges {
+		if !byType[objectTypeTable][privilege] && !byType[objectTypeSchema][privilege] {
+			errs = append(errs, missingPrivilegeErr(privilege, objectTypeTable, schema+"."+table))
+		}
+	}
+
+	for _, privilege := range schemaPrivileges {
+		if !byType[objectTypeSchema][privilege] {
+			errs = append(errs, missingPrivilegeErr(privilege, objectTypeSchema, schema))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindMissingPrivileges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		granted []grantedPrivilege
+		wantErr string
+	}{
+		{
+			name: "granted on the schema",
+			granted: []grantedPrivilege{
+				{ObjectType: objectTypeSchema, Privilege: "SELECT"},
+				{ObjectType: objectTypeSchema, Privilege: "TRIGGER"},
+				{ObjectType: objectTypeSchema, Privilege: "CREATE ANY"},
+			},
+		},
+		{
+			name: "granted on the table",
+			granted: []grantedPrivilege{
+				{ObjectType: objectTypeTable, Privilege: "SELECT"},
+				{ObjectType: objectTypeTable, Privilege: "TRIGGER"},
+				{ObjectType: objectTypeSchema, Privilege: "CREATE ANY"},
+			},
+		},
+		{
+			name: "missing trigger and create any",
+			granted: []grantedPrivilege{
+				{ObjectType: objectTypeTable, Privilege: "SELECT"},
+			},
+			wantErr: "missing privilege TRIGGER on table SALES.ORDERS\n" +
+				"missing privilege CREATE ANY on schema SALES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := findMissingPrivileges(tt.granted, "SALES", "ORDERS")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrMissingPrivilege) {
+				t.Errorf("expected error %v, got %v", ErrMissingPrivilege, err)
+			}
+
+			if err.Error() != tt.wantErr {
+				t.Errorf("expected error %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
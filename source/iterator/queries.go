@@ -16,6 +16,10 @@ package iterator
 
 const (
 	queryGetMaxValue = `SELECT max(%s) FROM %s`
+	// queryGetMaxValueUpTo gets the max value of the column not greater than the end of the snapshot range.
+	queryGetMaxValueUpTo = `SELECT max(%[1]s) FROM %[2]s WHERE %[1]s <= ?`
+
+	queryCallProcedure = `CALL %s(%s)`
 
 	queryCreateTable = `
 		CREATE TABLE %s (
@@ -24,10 +28,160 @@ const (
 		    %s INT GENERATED BY DEFAULT AS IDENTITY (CYCLE)
 		)
 	`
-	queryIfTableExist = `SELECT count(*) AS count FROM TABLES WHERE TABLE_NAME = $1`
+	queryAddColumns   = `ALTER TABLE %s ADD (%s)`
+	queryAlterColumns = `ALTER TABLE %s ALTER (%s)`
+
+	querySetDDLAutocommitOff = `SET TRANSACTION AUTOCOMMIT DDL OFF`
+	querySetDDLAutocommitOn  = `SET TRANSACTION AUTOCOMMIT DDL ON`
+
+	queryIfColumnExist = `
+		SELECT 
+		  count(*) AS count 
+		FROM 
+		  TABLE_COLUMNS 
+		WHERE 
+		  TABLE_NAME = $1 
+		  AND COLUMN_NAME = $2 
+		  AND SCHEMA_NAME = CURRENT_SCHEMA
+	`
+	queryGetTableColumns = `
+		SELECT 
+		  COLUMN_NAME 
+		FROM 
+		  TABLE_COLUMNS 
+		WHERE 
+		  TABLE_NAME = $1 
+		  AND SCHEMA_NAME = CURRENT_SCHEMA 
+		ORDER BY 
+		  POSITION
+	`
+
+	queryIfTriggerExist = `SELECT count(*) AS count FROM TRIGGERS WHERE TRIGGER_NAME = $1 AND SCHEMA_NAME = CURRENT_SCHEMA`
+	queryDropTrigger    = `DROP TRIGGER %s`
+
+	queryGetTableOID = `SELECT TABLE_OID FROM TABLES WHERE SCHEMA_NAME = $1 AND TABLE_NAME = $2`
+
+	queryGetTriggerDefinition = `SELECT DEFINITION FROM TRIGGERS WHERE TRIGGER_NAME = $1 AND SCHEMA_NAME = CURRENT_SCHEMA`
+
+	queryIfTableExist = `SELECT count(*) AS count FROM TABLES WHERE TABLE_NAME = $1 AND SCHEMA_NAME = CURRENT_SCHEMA`
+
+	// tracking tables of the connector are excluded.
+	queryGetSchemaTables = `
+		SELECT 
+		  TABLE_NAME 
+		FROM 
+		  TABLES 
+		WHERE 
+		  SCHEMA_NAME = $1 
+		  AND IS_TEMPORARY = 'FALSE' 
+		  AND TABLE_NAME NOT LIKE 'CONDUIT\_%' ESCAPE '\'
+		ORDER BY 
+		  TABLE_NAME
+	`
+	// privileges granted on the schema apply to all its objects.
+	queryGetPrivileges = `
+		SELECT 
+		  OBJECT_TYPE, 
+		  PRIVILEGE 
+		FROM 
+		  EFFECTIVE_PRIVILEGES 
+		WHERE 
+		  USER_NAME = CURRENT_USER 
+		  AND IS_VALID = 'TRUE' 
+		  AND SCHEMA_NAME = $1 
+		  AND (
+		    OBJECT_TYPE = 'SCHEMA' 
+		    OR (OBJECT_TYPE = 'TABLE' AND OBJECT_NAME = $2)
+		  )
+	`
+
+	queryGetTrackingTables = `
+		SELECT 
+		  TABLE_NAME 
+		FROM 
+		  TABLES 
+		WHERE 
+		  SCHEMA_NAME = CURRENT_SCHEMA 
+		  AND TABLE_NAME LIKE $1 ESCAPE '\' 
+		ORDER BY 
+		  TABLE_NAME
+	`
+	queryDropTable = `DROP TABLE %s`
+
+	queryIfSubscriptionExist = `
+		SELECT 
+		  count(*) AS count 
+		FROM 
+		  REMOTE_SUBSCRIPTIONS 
+		WHERE 
+		  SUBSCRIPTION_NAME = $1 
+		  AND SCHEMA_NAME = CURRENT_SCHEMA
+	`
+	queryCreateSubscription = `
+		CREATE REMOTE SUBSCRIPTION %s ON %s 
+		TARGET TABLE %s CHANGE TYPE COLUMN %s CHANGE TIME COLUMN %s
+	`
+	queryQueueSubscription      = `ALTER REMOTE SUBSCRIPTION %s QUEUE`
+	queryDistributeSubscription = `ALTER REMOTE SUBSCRIPTION %s DISTRIBUTE`
+	queryResetSubscription      = `ALTER REMOTE SUBSCRIPTION %s RESET`
+	queryDropSubscription       = `DROP REMOTE SUBSCRIPTION %s`
+
+	// the secondary system of the system replication replicates in one of the modes, the primary one has the primary mode.
+	queryIsSecondarySystem = `
+		SELECT
+		  COUNT(*)
+		FROM
+		  M_INIFILE_CONTENTS
+		WHERE
+		  FILE_NAME = 'global.ini'
+		  AND SECTION = 'system_replication'
+		  AND KEY = 'mode'
+		  AND VALUE IN ('sync', 'syncmem', 'async')
+	`
+
+	queryCreateOffsetsTable = `
+		CREATE TABLE %s (
+		    TRACKING_TABLE NVARCHAR(256),
+		    CONSUMER NVARCHAR(256),
+		    LAST_ID BIGINT,
+		    UPDATED_AT TIMESTAMP,
+		    PRIMARY KEY (TRACKING_TABLE, CONSUMER)
+		)
+	`
+	queryUpsertOffset = `
+		UPSERT %s (TRACKING_TABLE, CONSUMER, LAST_ID, UPDATED_AT)
+		VALUES ($1, $2, $3, CURRENT_UTCTIMESTAMP) WITH PRIMARY KEY
+	`
+	queryTouchOffset = `
+		UPDATE %s SET UPDATED_AT = CURRENT_UTCTIMESTAMP WHERE TRACKING_TABLE = $1 AND CONSUMER = $2
+	`
+	queryIfOffsetExist = `SELECT COUNT(*) FROM %s WHERE TRACKING_TABLE = $1 AND CONSUMER = $2`
+	// offsets of consumers which haven't been running for the ttl don't hold rows anymore.
+	queryDeleteExpiredOffsets = `
+		DELETE FROM %s WHERE TRACKING_TABLE = $1 AND UPDATED_AT < ADD_SECONDS(CURRENT_UTCTIMESTAMP, $2)
+	`
+	// rows are kept until the slowest consumer acknowledges them.
+	queryDeleteConsumedRows = `DELETE FROM %s WHERE %s <= (SELECT min(LAST_ID) FROM %s WHERE TRACKING_TABLE = $1)`
+
+	queryCreateClaimsTable = `
+		CREATE TABLE %s (
+		    TRACKING_TABLE NVARCHAR(256),
+		    CONSUMER NVARCHAR(256),
+		    OWNER NVARCHAR(512),
+		    CLAIMED_AT TIMESTAMP,
+		    PRIMARY KEY (TRACKING_TABLE, CONSUMER)
+		)
+	`
+	queryUpsertClaim = `
+		UPSERT %s (TRACKING_TABLE, CONSUMER, OWNER, CLAIMED_AT) 
+		VALUES ($1, $2, $3, CURRENT_UTCTIMESTAMP) WITH PRIMARY KEY
+	`
+	querySelectClaimForUpdate = `SELECT OWNER FROM %s WHERE TRACKING_TABLE = $1 AND CONSUMER = $2 FOR UPDATE`
+
+	queryGetTablesWithColumn = `SELECT TABLE_NAME FROM TABLE_COLUMNS WHERE SCHEMA_NAME = $1 AND COLUMN_NAME = $2`
 
 	queryAddInsertTrigger = `
-		 CREATE OR REPLACE TRIGGER %s                  
+		 CREATE TRIGGER %s                  
 		 AFTER INSERT ON %s                                   
 		 REFERENCING NEW ROW nw, OLD ROW rw          
 		 FOR EACH ROW                                             
@@ -36,7 +190,7 @@ const (
 		 END
 	`
 	queryUpdateTrigger = `
-		 CREATE OR REPLACE TRIGGER %s                  
+		 CREATE TRIGGER %s                  
 		 AFTER UPDATE ON %s                                   
 		 REFERENCING NEW ROW nw, OLD ROW rw          
 		 FOR EACH ROW                                             
@@ -45,7 +199,7 @@ const (
 		 END
 	`
 	queryDeleteTrigger = `
-		 CREATE OR REPLACE TRIGGER %s                  
+		 CREATE TRIGGER %s                  
 		 AFTER DELETE ON %s                                   
 		 REFERENCING NEW ROW nw, OLD ROW rw          
 		 FOR EACH ROW                                             
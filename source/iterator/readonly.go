@@ -0,0 +1,50 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	hdbdriver "github.com/SAP/go-hdb/driver"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// errCodeInsufficientPrivilege - sap hana error code of a statement the user has no privilege for.
+const errCodeInsufficientPrivilege = 258
+
+// isReadOnly reports whether the database is read-only, which is the read-enabled secondary system
+// of the system replication. The replication mode of the primary system is primary, and the one of
+// the secondary system is the mode it replicates in.
+// The database isn't considered read-only if the user can't read the configuration.
+func isReadOnly(ctx context.Context, db *sqlx.DB) (bool, error) {
+	var count int
+
+	err := db.QueryRowContext(ctx, queryIsSecondarySystem).Scan(&count)
+	if err != nil {
+		var dbErr hdbdriver.DBError
+		if errors.As(err, &dbErr) && dbErr.Code() == errCodeInsufficientPrivilege {
+			sdk.Logger(ctx).Debug().Err(err).Msg("can't read the system replication mode, the database isn't read-only")
+
+			return false, nil
+		}
+
+		return false, fmt.Errorf("select system replication mode: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+)
+
+func TestIsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		result  fakedb.Result
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "secondary system",
+			result: fakedb.Value(int64(1)),
+			want:   true,
+		},
+		{
+			name:   "primary system or no replication",
+			result: fakedb.Value(int64(0)),
+		},
+		{
+			name:   "configuration isn't readable",
+			result: fakedb.Result{Err: dbError{code: errCodeInsufficientPrivilege}},
+		},
+		{
+			name:    "failed query",
+			result:  fakedb.Result{Err: dbError{code: 260}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(string, []any) fakedb.Result { return tt.result })
+
+			got, err := isReadOnly(context.Background(), db.Open())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isReadOnly() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("isReadOnly() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jmoiron/sqlx"
+)
+
+// Policies of handling the table, which was dropped and created again since the position was saved.
+const (
+	// TableRecreateFail - the iterator isn't created.
+	TableRecreateFail = "fail"
+	// TableRecreateResnapshot - the tracking table of the old table is dropped, and the table is read
+	// from the beginning, as if there was no position.
+	TableRecreateResnapshot = "resnapshot"
+)
+
+// getTableOID returns the object id of the table.
+func getTableOID(ctx context.Context, db *sqlx.DB, schema, table string) (int64, error) {
+	var oid int64
+
+	if err := db.GetContext(ctx, &oid, queryGetTableOID, schema, table); err != nil {
+		return 0, fmt.Errorf("select table oid: %w", err)
+	}
+
+	return oid, nil
+}
+
+// handleRecreatedTable handles the table recreated since the position was saved according to the policy.
+// It returns the position to start from.
+func (c *CombinedIterator) handleRecreatedTable(
+	ctx context.Context, pos *position.Position, policy string,
+) (*position.Position, error) {
+	if policy != TableRecreateResnapshot {
+		return nil, fmt.Errorf("%w: table %s, oid %d, position oid %d", ErrTableRecreated, c.table, c.tableOID, pos.TableOID)
+	}
+
+	sdk.Logger(ctx).Warn().Str("table", c.table).Msg("table was recreated, start from the beginning")
+
+	// the shared tracking table is used by other pipelines, the triggers of the new table are created for it.
+	if c.cdcConsumer == "" {
+		if err := dropTrackingTable(ctx, c.db, c.table, c.trackingTable); err != nil {
+			return nil, fmt.Errorf("drop tracking table %s: %w", c.trackingTable, err)
+		}
+	}
+
+	return nil, nil //nolint:nilnil // there is no position to start from
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const (
+	// CDCModeTrigger captures changes by triggers on the table.
+	CDCModeTrigger = "trigger"
+	// CDCModeSDI captures changes by the Smart Data Integration remote subscription on the virtual table.
+	CDCModeSDI = "sdi"
+
+	subscriptionNamePattern = "CS_%s_%s"
+)
+
+// sdiOperations - operations of the change type column filled by the remote subscription.
+// Upserts are returned as updates.
+var sdiOperations = map[actionType]actionType{
+	"I": insertOperation,
+	"U": updateOperation,
+	"A": updateOperation,
+	"D": deleteOperation,
+}
+
+// setSubscription creates the remote subscription applying changes of the virtual table to the tracking table,
+// if it doesn't exist yet, and starts applying changes. The remote subscription fills the operation type
+// and the changed at columns of the tracking table.
+func setSubscription(ctx context.Context, tx *sql.Tx, params CDCSetupParams) error {
+	suffix := params.TrackingTableName[len(params.TrackingTableName)-suffixLength:]
+	subscription := formatSubscriptionName(params.TableName, suffix)
+
+	var count int
+
+	err := tx.QueryRowContext(ctx, queryIfSubscriptionExist, subscription).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check remote subscription exists: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	queries := []string{
+		fmt.Sprintf(queryCreateSubscription, subscription, params.TableInfo.QualifiedName(),
+			params.TrackingTableName, params.columns().operationType, params.columns().changedAt),
+		fmt.Sprintf(queryQueueSubscription, subscription),
+		fmt.Sprintf(queryDistributeSubscription, subscription),
+	}
+
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("execute %q: %w", query, err)
+		}
+	}
+
+	return nil
+}
+
+// dropSubscription stops applying changes and drops the remote subscription, if it exists.
+func dropSubscription(ctx context.Context, tx *sql.Tx, subscription string) error {
+	var count int
+
+	err := tx.QueryRowContext(ctx, queryIfSubscriptionExist, subscription).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check remote subscription exists: %w", err)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	for _, query := range []string{queryResetSubscription, queryDropSubscription} {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(query, subscription)); err != nil {
+			return fmt.Errorf("execute %q: %w", fmt.Sprintf(query, subscription), err)
+		}
+	}
+
+	return nil
+}
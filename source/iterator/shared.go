@@ -0,0 +1,145 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// sharedSuffix - suffix of the tracking table shared by several pipelines.
+	sharedSuffix = "SHARED"
+	// offsetsTable - table with the last consumed tracking ids of the consumers of the shared tracking tables.
+	offsetsTable = "CONDUIT_OFFSETS"
+)
+
+// registerConsumer saves the offset the consumer starts reading the shared tracking table from,
+// the rows after it aren't removed until the consumer acknowledges them.
+// A consumer resumed from the position fails with ErrConsumerExpired if its offset expired while it was stopped,
+// as other consumers could delete changes it hasn't read.
+func registerConsumer(ctx context.Context, db *sqlx.DB, trackingTable, consumer string, lastID int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	var count int
+
+	err = tx.QueryRowContext(ctx, queryIfTableExist, offsetsTable).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check offsets table exists: %w", err)
+	}
+
+	// the table can be created by another pipeline in the meantime.
+	if count == 0 {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(queryCreateOffsetsTable, offsetsTable))
+		if err != nil && !isAlreadyExists(err) {
+			return fmt.Errorf("create offsets table: %w", err)
+		}
+	}
+
+	if lastID > 0 {
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(queryIfOffsetExist, offsetsTable), trackingTable, consumer).
+			Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check offset exists: %w", err)
+		}
+
+		if count == 0 {
+			return fmt.Errorf("%w: tracking table %s, consumer %s", ErrConsumerExpired, trackingTable, consumer)
+		}
+	}
+
+	if err = saveOffset(ctx, tx, trackingTable, consumer, lastID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// saveOffset saves the last consumed tracking id of the consumer.
+func saveOffset(ctx context.Context, tx *sql.Tx, trackingTable, consumer string, lastID int) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(queryUpsertOffset, offsetsTable), trackingTable, consumer, lastID)
+	if err != nil {
+		return fmt.Errorf("save offset: %w", err)
+	}
+
+	return nil
+}
+
+// touchOffset marks the consumer as running, so its offset doesn't expire while there are no changes to acknowledge.
+func touchOffset(ctx context.Context, db *sqlx.DB, trackingTable, consumer string) error {
+	res, err := db.ExecContext(ctx, fmt.Sprintf(queryTouchOffset, offsetsTable), trackingTable, consumer)
+	if err != nil {
+		return fmt.Errorf("touch offset: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: tracking table %s, consumer %s", ErrConsumerExpired, trackingTable, consumer)
+	}
+
+	return nil
+}
+
+// deleteExpiredOffsets removes offsets of the consumers which haven't been running for the ttl,
+// e.g. of deleted pipelines, so they don't keep rows in the shared tracking table forever.
+func deleteExpiredOffsets(ctx context.Context, tx *sql.Tx, trackingTable string, ttl time.Duration) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(queryDeleteExpiredOffsets, offsetsTable), trackingTable, -ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("delete expired offsets: %w", err)
+	}
+
+	return nil
+}
+
+// deleteConsumedRows removes rows of the shared tracking table consumed by all its consumers.
+func deleteConsumedRows(ctx context.Context, tx *sql.Tx, trackingTable, trackingID string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(queryDeleteConsumedRows, trackingTable, trackingID, offsetsTable),
+		trackingTable)
+	if err != nil {
+		return fmt.Errorf("delete consumed rows: %w", err)
+	}
+
+	return nil
+}
+
+// maxID returns the max of the acknowledged tracking ids.
+func maxID(ids []any) int {
+	var result int
+
+	for _, id := range ids {
+		if v, ok := id.(int); ok && v > result {
+			result = v
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,213 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+)
+
+func TestGetTrackingTableName(t *testing.T) {
+	t.Parallel()
+
+	if got, want := getTrackingTableName(context.Background(), nil, "CLIENTS", true), "CONDUIT_CLIENTS_SHARED"; got != want {
+		t.Errorf("shared tracking table = %s, want %s", got, want)
+	}
+
+	pos := &position.Position{TrackingTableName: "CONDUIT_CLIENTS_150405"}
+	if got := getTrackingTableName(context.Background(), pos, "CLIENTS", true); got != pos.TrackingTableName {
+		t.Errorf("tracking table = %s, want the one from the position", got)
+	}
+}
+
+func TestMaxID(t *testing.T) {
+	t.Parallel()
+
+	if got := maxID([]any{3, 7, 5}); got != 7 {
+		t.Errorf("maxID() = %d, want 7", got)
+	}
+}
+
+// offsetsDB returns the fake database with the offsets table, which has the offset of the consumer if it's set.
+func offsetsDB(tableExists, offsetExists bool, createErr error) *fakedb.DB {
+	count := func(exists bool) fakedb.Result {
+		if exists {
+			return fakedb.Value(int64(1))
+		}
+
+		return fakedb.Value(int64(0))
+	}
+
+	return fakedb.New(func(query string, _ []any) fakedb.Result {
+		switch {
+		case query == queryIfTableExist:
+			return count(tableExists)
+		case strings.Contains(query, "CREATE TABLE "+offsetsTable):
+			return fakedb.Result{Err: createErr}
+		case strings.HasPrefix(query, "SELECT COUNT(*) FROM "+offsetsTable):
+			return count(offsetExists)
+		}
+
+		return fakedb.Result{RowsAffected: 1}
+	})
+}
+
+func TestRegisterConsumer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		db        *fakedb.DB
+		lastID    int
+		wantErr   error
+		wantSaved bool
+	}{
+		{
+			name:      "new consumer creates the offsets table",
+			db:        offsetsDB(false, false, nil),
+			wantSaved: true,
+		},
+		{
+			name:      "offsets table created by another pipeline",
+			db:        offsetsDB(false, false, dbError{code: errCodeDuplicateTableName}),
+			wantSaved: true,
+		},
+		{
+			name:      "resumed consumer",
+			db:        offsetsDB(true, true, nil),
+			lastID:    5,
+			wantSaved: true,
+		},
+		{
+			name:    "resumed consumer with expired offset",
+			db:      offsetsDB(true, false, nil),
+			lastID:  5,
+			wantErr: ErrConsumerExpired,
+		},
+		{
+			name:    "failed create",
+			db:      offsetsDB(false, false, dbError{code: 258}),
+			wantErr: dbError{code: 258},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := registerConsumer(context.Background(), tt.db.Open(), "CONDUIT_CLIENTS_SHARED", "analytics", tt.lastID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("registerConsumer() error = %v, want %v", err, tt.wantErr)
+			}
+
+			queries := tt.db.Queries()
+			saved := queries[len(queries)-1] == fakedb.Commit
+			if saved != tt.wantSaved {
+				t.Errorf("offset saved = %t, want %t, queries %q", saved, tt.wantSaved, queries)
+			}
+		})
+	}
+}
+
+func TestTouchOffset(t *testing.T) {
+	t.Parallel()
+
+	for _, affected := range []int64{0, 1} {
+		db := fakedb.New(func(string, []any) fakedb.Result {
+			return fakedb.Result{RowsAffected: affected}
+		})
+
+		err := touchOffset(context.Background(), db.Open(), "CONDUIT_CLIENTS_SHARED", "analytics")
+		if expired := errors.Is(err, ErrConsumerExpired); expired != (affected == 0) {
+			t.Errorf("affected %d: touchOffset() error = %v", affected, err)
+		}
+	}
+}
+
+func TestCDCIterator_deleteRows_Consumer(t *testing.T) {
+	t.Parallel()
+
+	const trackingTable = "CONDUIT_CLIENTS_SHARED"
+
+	var (
+		saveOffset     = fmt.Sprintf(queryUpsertOffset, offsetsTable)
+		expireOffsets  = fmt.Sprintf(queryDeleteExpiredOffsets, offsetsTable)
+		deleteConsumed = fmt.Sprintf(queryDeleteConsumedRows, trackingTable,
+			newServiceColumns("").trackingID, offsetsTable)
+	)
+
+	tests := []struct {
+		name        string
+		ids         []any
+		ttl         time.Duration
+		wantQueries []string
+	}{
+		{
+			name:        "expired offsets are removed before consumed rows",
+			ids:         []any{3, 5},
+			ttl:         time.Hour,
+			wantQueries: []string{fakedb.Begin, saveOffset, expireOffsets, deleteConsumed, fakedb.Commit},
+		},
+		{
+			name:        "offsets never expire",
+			ids:         []any{3, 5},
+			wantQueries: []string{fakedb.Begin, saveOffset, deleteConsumed, fakedb.Commit},
+		},
+		{
+			name:        "running consumer without changes refreshes its offset",
+			ttl:         time.Hour,
+			wantQueries: []string{fmt.Sprintf(queryTouchOffset, offsetsTable)},
+		},
+		{
+			name: "nothing to refresh without ttl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(string, []any) fakedb.Result {
+				return fakedb.Result{RowsAffected: 1}
+			})
+
+			it := &CDCIterator{
+				db:            db.Open(),
+				trackingTable: trackingTable,
+				tableSrv:      newTrackingTableService(),
+				consumer:      "analytics",
+				consumerTTL:   tt.ttl,
+				columns:       newServiceColumns(""),
+			}
+			it.tableSrv.idsForRemoving = tt.ids
+
+			if err := it.deleteRows(context.Background()); err != nil {
+				t.Fatalf("deleteRows() error = %v", err)
+			}
+
+			if queries := db.Queries(); !slices.Equal(queries, tt.wantQueries) {
+				t.Errorf("queries = %q, want %q", queries, tt.wantQueries)
+			}
+		})
+	}
+}
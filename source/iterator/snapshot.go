@@ -16,8 +16,10 @@ package iterator
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
@@ -28,7 +30,7 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// snapshotIterator - iterator which get snapshot data.
+// SnapshotIterator - iterator which get snapshot data.
 // A "snapshot" is the state of a table data at a particular point in time when connector starts work.
 // The first time when the snapshot iterator starts work, it is gets max value from `orderingColumn` and saves
 // this value to position.
@@ -38,18 +40,34 @@ import (
 // Iterators saves last processed value from `orderingColumn` column to position to field `SnapshotLastProcessedVal`.
 // If snapshot stops it will parse position from last record and will
 // try gets row where `{{orderingColumn}} > {{position.SnapshotLastProcessedVal}}`.
-type snapshotIterator struct {
+// If the ordering column isn't unique, e.g. a DATE or SECONDDATE column, rows are ordered by the tie-breaker column
+// as well, and rows which share the last value of the ordering column are read after the last tie-breaker value.
+type SnapshotIterator struct {
 	db   *sqlx.DB
 	rows *sqlx.Rows
+	// tx - transaction of the snapshot queries, it's used if the isolation level is set.
+	tx *sqlx.Tx
 
 	// table - table name.
 	table string
+	// source - table function or procedure the rows are read from instead of the table.
+	source SnapshotSource
+	// called - whether the procedure is already called.
+	called bool
+	// cursor - whether all rows are read by a single query, instead of a query per batch.
+	cursor bool
+	// isolation - isolation level of the snapshot transaction, the queries run without it by default.
+	isolation sql.IsolationLevel
 	// keys Names of columns what iterator use for setting key in record.
 	keys []string
 	// orderingColumn Name of column what iterator using for sorting data.
 	orderingColumn string
 	// maxValue max value from ordering column. Connector uses this variable like boundary value for snapshot.
 	maxValue any
+	// refreshMax - interval of refreshing the max value, zero disables it.
+	refreshMax time.Duration
+	// refreshedAt - time of the last refresh of the max value.
+	refreshedAt time.Time
 	// batchSize size of batch.
 	batchSize int
 	// position last recorded position.
@@ -58,43 +76,139 @@ type snapshotIterator struct {
 	columnTypes map[string]string
 	// trackingTable name.
 	trackingTable string
+	// transformOpts options of row values transformation.
+	transformOpts columntypes.TransformOptions
+	// startedAt - time the iterator was created.
+	startedAt time.Time
+	// hints - hints of the select queries, they aren't added if it's empty.
+	hints string
+	// quoteIdentifiers - the ordering column is quoted in the queries.
+	quoteIdentifiers bool
+
+	// rangeStart, rangeEnd - bounds of the ordering column values the snapshot reads, they're inclusive.
+	// The snapshot isn't bounded by them if they're empty.
+	rangeStart string
+	rangeEnd   string
+	// tieBreaker - unique column which orders rows sharing a value of the ordering column, it isn't used if it's empty.
+	tieBreaker string
 }
 
-type snapshotParams struct {
-	db             *sqlx.DB
-	table          string
-	orderingColumn string
-	keys           []string
-	batchSize      int
-	position       *position.Position
-	columnTypes    map[string]string
-	trackingTable  string
+// Policies of handling the rest of the snapshot after its max duration.
+const (
+	// SnapshotResume - the rest of the snapshot is read in batches, when cdc has no changes.
+	SnapshotResume = "resume"
+	// SnapshotAbandon - the rest of the snapshot isn't read.
+	SnapshotAbandon = "abandon"
+)
+
+// SnapshotSource - table function or procedure the snapshot reads from instead of the table.
+// The function result is paginated as the table is. The procedure result is read with a single call,
+// so the snapshot of the procedure restarts from the beginning instead of resuming from the position.
+type SnapshotSource struct {
+	Function  string
+	Procedure string
+	Arguments []any
 }
 
-func newSnapshotIterator(
+// isolationLevels - isolation levels of the snapshot transaction by their config values.
+var isolationLevels = map[string]sql.IsolationLevel{
+	"readCommitted":  sql.LevelReadCommitted,
+	"repeatableRead": sql.LevelRepeatableRead,
+	"serializable":   sql.LevelSerializable,
+}
+
+// newSnapshotSource returns the snapshot source of the params.
+func newSnapshotSource(params CombinedParams) SnapshotSource {
+	arguments := make([]any, len(params.SnapshotArguments))
+	for i, argument := range params.SnapshotArguments {
+		arguments[i] = argument
+	}
+
+	return SnapshotSource{
+		Function:  params.SnapshotFunction,
+		Procedure: params.SnapshotProcedure,
+		Arguments: arguments,
+	}
+}
+
+// SnapshotParams is an incoming params for the [NewSnapshotIterator] function.
+type SnapshotParams struct {
+	DB    *sqlx.DB
+	Table string
+	// Source - table function or procedure the rows are read from, the table is read if it's empty.
+	Source SnapshotSource
+	// Cursor - all rows are read by a single query, instead of a query per batch.
+	Cursor bool
+	// Isolation - isolation level of the snapshot transaction, the queries run without it if it's the default.
+	Isolation sql.IsolationLevel
+	// RefreshMax - interval of refreshing the max value of the ordering column, zero disables it.
+	RefreshMax     time.Duration
+	OrderingColumn string
+	// Keys - columns of the record keys.
+	Keys      []string
+	BatchSize int
+	// Position - position the snapshot resumes from, it's read from the beginning if it's nil.
+	Position *position.Position
+	// ColumnTypes - types of the table columns by their names, see [columntypes.TableInfo].
+	ColumnTypes map[string]string
+	// TrackingTable - name of the tracking table, which is saved into the positions.
+	TrackingTable string
+	TransformOpts columntypes.TransformOptions
+	// Hints - hints of the select queries.
+	Hints string
+
+	// QuoteIdentifiers - the ordering column and the table are quoted in the queries.
+	QuoteIdentifiers bool
+
+	// RangeStart, RangeEnd - inclusive bounds of the ordering column values the snapshot reads.
+	RangeStart string
+	RangeEnd   string
+	// TieBreaker - unique column which orders rows sharing a value of the ordering column.
+	TieBreaker string
+}
+
+// NewSnapshotIterator creates new snapshot iterator, which reads rows up to the max value of the ordering column.
+func NewSnapshotIterator(
 	ctx context.Context,
-	snapshotParams snapshotParams,
-) (*snapshotIterator, error) {
+	params SnapshotParams,
+) (*SnapshotIterator, error) {
 	var err error
 
-	it := &snapshotIterator{
-		db:             snapshotParams.db,
-		table:          snapshotParams.table,
-		keys:           snapshotParams.keys,
-		orderingColumn: snapshotParams.orderingColumn,
-		batchSize:      snapshotParams.batchSize,
-		position:       snapshotParams.position,
-		columnTypes:    snapshotParams.columnTypes,
-		trackingTable:  snapshotParams.trackingTable,
+	it := &SnapshotIterator{
+		db:             params.DB,
+		table:          params.Table,
+		source:         params.Source,
+		cursor:         params.Cursor,
+		isolation:      params.Isolation,
+		refreshMax:     params.RefreshMax,
+		keys:           params.Keys,
+		orderingColumn: params.OrderingColumn,
+		batchSize:      params.BatchSize,
+		position:       params.Position,
+		columnTypes:    params.ColumnTypes,
+		trackingTable:  params.TrackingTable,
+		transformOpts:  params.TransformOpts,
+		startedAt:      time.Now(),
+		hints:          params.Hints,
+
+		quoteIdentifiers: params.QuoteIdentifiers,
+
+		rangeStart: params.RangeStart,
+		rangeEnd:   params.RangeEnd,
+		tieBreaker: params.TieBreaker,
 	}
 
-	err = it.loadRows(ctx)
+	if params.Position != nil {
+		it.position = it.resumedPosition(params.Position)
+	}
+
+	err = it.beginTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("load rows: %w", err)
+		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 
-	if snapshotParams.position != nil {
-		it.maxValue = snapshotParams.position.SnapshotMaxValue
+	if params.Position != nil {
+		it.maxValue = it.position.SnapshotMaxValue
 	} else {
 		err = it.setMaxValue(ctx)
 		if err != nil {
@@ -102,15 +216,30 @@ func newSnapshotIterator(
 		}
 	}
 
+	err = it.loadRows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load rows: %w", err)
+	}
+
 	return it, nil
 }
 
 // HasNext check ability to get next record.
-func (i *snapshotIterator) HasNext(ctx context.Context) (bool, error) {
+func (i *SnapshotIterator) HasNext(ctx context.Context) (bool, error) {
 	if i.rows != nil && i.rows.Next() {
 		return true, nil
 	}
 
+	if i.rows != nil && i.rows.Err() != nil {
+		return false, fmt.Errorf("iterate rows: %w", i.rows.Err())
+	}
+
+	if i.refreshMax > 0 && time.Since(i.refreshedAt) >= i.refreshMax {
+		if err := i.setMaxValue(ctx); err != nil {
+			return false, fmt.Errorf("refresh max value: %w", err)
+		}
+	}
+
 	if err := i.loadRows(ctx); err != nil {
 		return false, fmt.Errorf("load rows: %w", err)
 	}
@@ -120,17 +249,36 @@ func (i *snapshotIterator) HasNext(ctx context.Context) (bool, error) {
 		return true, nil
 	}
 
+	if i.rows != nil && i.rows.Err() != nil {
+		return false, fmt.Errorf("iterate rows: %w", i.rows.Err())
+	}
+
+	// rows inserted since the last refresh are read before the snapshot is done.
+	if i.refreshMax > 0 && i.source.Procedure == "" {
+		if err := i.setMaxValue(ctx); err != nil {
+			return false, fmt.Errorf("refresh max value: %w", err)
+		}
+
+		if err := i.loadRows(ctx); err != nil {
+			return false, fmt.Errorf("load rows: %w", err)
+		}
+
+		if i.rows != nil && i.rows.Next() {
+			return true, nil
+		}
+	}
+
 	return false, nil
 }
 
 // Next get new record.
-func (i *snapshotIterator) Next(ctx context.Context) (opencdc.Record, error) {
+func (i *SnapshotIterator) Next(ctx context.Context) (opencdc.Record, error) {
 	row := make(map[string]any)
 	if err := i.rows.MapScan(row); err != nil {
 		return opencdc.Record{}, fmt.Errorf("scan rows: %w", err)
 	}
 
-	transformedRow, err := columntypes.TransformRow(ctx, row, i.columnTypes)
+	transformedRow, err := columntypes.TransformRow(ctx, row, i.columnTypes, i.transformOpts)
 	if err != nil {
 		return opencdc.Record{}, fmt.Errorf("transform row column types: %w", err)
 	}
@@ -141,11 +289,19 @@ func (i *snapshotIterator) Next(ctx context.Context) (opencdc.Record, error) {
 
 	pos := position.Position{
 		IteratorType:             position.TypeSnapshot,
-		SnapshotLastProcessedVal: transformedRow[i.orderingColumn],
+		SnapshotLastProcessedVal: positionValue(row, transformedRow, i.orderingColumn),
 		SnapshotMaxValue:         i.maxValue,
 		TrackingTableName:        i.trackingTable,
 	}
 
+	if i.tieBreaker != "" {
+		if _, ok := transformedRow[i.tieBreaker]; !ok {
+			return opencdc.Record{}, ErrNoTieBreakerColumn
+		}
+
+		pos.SnapshotLastTieBreaker = positionValue(row, transformedRow, i.tieBreaker)
+	}
+
 	sdkPos, err := pos.ConvertToSDKPosition()
 	if err != nil {
 		return opencdc.Record{}, fmt.Errorf("convert position %w", err)
@@ -178,8 +334,8 @@ func (i *snapshotIterator) Next(ctx context.Context) (opencdc.Record, error) {
 		nil
 }
 
-// CloseRows close sql rows.
-func (i *snapshotIterator) CloseRows() error {
+// CloseRows close sql rows and commits the snapshot transaction.
+func (i *SnapshotIterator) CloseRows() error {
 	if i.rows != nil {
 		err := i.rows.Close()
 		if err != nil {
@@ -187,11 +343,119 @@ func (i *snapshotIterator) CloseRows() error {
 		}
 	}
 
+	if i.tx != nil {
+		tx := i.tx
+		i.tx = nil
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit transaction: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// beginTx begins the read-only snapshot transaction with the isolation level, if it's set.
+// The transaction lasts until the snapshot is done, so all batches see the same data
+// with the repeatable read and serializable levels.
+func (i *SnapshotIterator) beginTx(ctx context.Context) error {
+	if i.isolation == sql.LevelDefault {
+		return nil
+	}
+
+	// the transaction outlives the call, it's ended by CloseRows.
+	tx, err := i.db.BeginTxx(context.WithoutCancel(ctx), &sql.TxOptions{Isolation: i.isolation, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	i.tx = tx
+
+	return nil
+}
+
+// queryer returns the snapshot transaction, if it's begun, or the db.
+func (i *SnapshotIterator) queryer() sqlx.QueryerContext {
+	if i.tx != nil {
+		return i.tx
+	}
+
+	return i.db
+}
+
+// resume replaces the db connection and reloads rows from the current position.
+func (i *SnapshotIterator) resume(ctx context.Context, db *sqlx.DB) error {
+	// rows belong to the broken connection, the close error doesn't matter here.
+	i.CloseRows() //nolint:errcheck // see the comment above
+
+	i.db = db
+	// the result of the procedure can't be resumed, the procedure is called again.
+	i.called = false
+
+	if err := i.beginTx(ctx); err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if err := i.loadRows(ctx); err != nil {
+		return fmt.Errorf("load rows: %w", err)
+	}
+
 	return nil
 }
 
+// skipToMax makes the iterator read only rows after the max value of the ordering column.
+func (i *SnapshotIterator) skipToMax(ctx context.Context) error {
+	// the table is empty, so there are no rows to skip.
+	if i.maxValue == nil {
+		return nil
+	}
+
+	if i.rows != nil {
+		if err := i.rows.Close(); err != nil {
+			return fmt.Errorf("close rows: %w", err)
+		}
+	}
+
+	i.position = &position.Position{
+		IteratorType:             position.TypeSnapshot,
+		SnapshotLastProcessedVal: i.maxValue,
+		SnapshotMaxValue:         i.maxValue,
+		TrackingTableName:        i.trackingTable,
+	}
+
+	if err := i.loadRows(ctx); err != nil {
+		return fmt.Errorf("load rows: %w", err)
+	}
+
+	return nil
+}
+
+// progress returns the position the snapshot is resumed from.
+func (i *SnapshotIterator) progress() *position.Position {
+	if i.position != nil {
+		return snapshotProgress(i.position)
+	}
+
+	return &position.Position{
+		IteratorType:      position.TypeSnapshot,
+		SnapshotMaxValue:  i.maxValue,
+		TrackingTableName: i.trackingTable,
+	}
+}
+
+// snapshotProgress returns the snapshot part of the position.
+func snapshotProgress(pos *position.Position) *position.Position {
+	return &position.Position{
+		IteratorType:             position.TypeSnapshot,
+		SnapshotLastProcessedVal: pos.SnapshotLastProcessedVal,
+		SnapshotMaxValue:         pos.SnapshotMaxValue,
+		SnapshotLastTieBreaker:   pos.SnapshotLastTieBreaker,
+		TrackingTableName:        pos.TrackingTableName,
+	}
+}
+
 // Stop shutdown iterator.
-func (i *snapshotIterator) Stop() error {
+func (i *SnapshotIterator) Stop(context.Context) error {
 	err := i.CloseRows()
 	if err != nil {
 		return fmt.Errorf("close rows: %w", err)
@@ -211,25 +475,34 @@ func (i *snapshotIterator) Stop() error {
 
 // LoadRows selects a batch of rows from a database, based on the CombinedIterator's
 // table, columns, orderingColumn, batchSize and the current position.
-func (i *snapshotIterator) loadRows(ctx context.Context) error {
+func (i *SnapshotIterator) loadRows(ctx context.Context) error {
+	if i.source.Procedure != "" {
+		return i.callProcedure(ctx)
+	}
+
 	builder := sqlbuilder.NewSelectBuilder()
 
 	builder.Select("*")
-	builder.From(i.table)
+	builder.From(i.from())
 
-	if i.position != nil {
-		builder.Where(
-			builder.GreaterThan(i.orderingColumn, i.position.SnapshotLastProcessedVal),
-			builder.LessEqualThan(i.orderingColumn, i.position.SnapshotMaxValue),
-		)
+	if conditions := i.conditions(builder); len(conditions) > 0 {
+		builder.Where(conditions...)
+	}
+
+	builder.OrderBy(i.orderBy()...)
+
+	// the cursor is fetched in batches by the driver, so a single query reads all rows.
+	if !i.cursor {
+		builder.Limit(i.batchSize)
 	}
 
-	q, args := builder.
-		OrderBy(i.orderingColumn).
-		Limit(i.batchSize).
-		Build()
+	q, args := builder.Build()
+	q = withHints(q, i.hints)
+
+	// arguments of the function go first, as its placeholders.
+	args = append(append([]any{}, i.source.Arguments...), args...)
 
-	rows, err := i.db.QueryxContext(ctx, q, args...)
+	rows, err := i.queryer().QueryxContext(ctx, q, args...)
 	if err != nil {
 		return fmt.Errorf("execute select query: %w", err)
 	}
@@ -239,9 +512,157 @@ func (i *snapshotIterator) loadRows(ctx context.Context) error {
 	return nil
 }
 
+// conditions returns the conditions of the ordering column for the next batch of the snapshot.
+func (i *SnapshotIterator) conditions(builder *sqlbuilder.SelectBuilder) []string {
+	orderingColumn := columntypes.Identifier(i.orderingColumn, i.quoteIdentifiers)
+
+	switch {
+	// rows sharing the last value of the ordering column are read after the last tie-breaker value.
+	case i.position != nil && i.tieBreaker != "" && i.position.SnapshotLastTieBreaker != nil:
+		tieBreaker := columntypes.Identifier(i.tieBreaker, i.quoteIdentifiers)
+
+		return []string{
+			builder.Or(
+				builder.GreaterThan(orderingColumn, i.position.SnapshotLastProcessedVal),
+				builder.And(
+					builder.Equal(orderingColumn, i.position.SnapshotLastProcessedVal),
+					builder.GreaterThan(tieBreaker, i.position.SnapshotLastTieBreaker),
+				),
+			),
+			builder.LessEqualThan(orderingColumn, i.maxValue),
+		}
+	case i.position != nil:
+		return []string{
+			builder.GreaterThan(orderingColumn, i.position.SnapshotLastProcessedVal),
+			builder.LessEqualThan(orderingColumn, i.maxValue),
+		}
+	case i.rangeStart != "" || i.rangeEnd != "":
+		var conditions []string
+
+		if i.rangeStart != "" {
+			conditions = append(conditions, builder.GreaterEqualThan(orderingColumn, i.rangeStart))
+		}
+
+		// the max value is already bounded by the end of the range.
+		return append(conditions, builder.LessEqualThan(orderingColumn, i.maxValue))
+	case i.cursor:
+		// the cursor reads all rows at once, so rows added after the snapshot started are cut off.
+		return []string{builder.LessEqualThan(orderingColumn, i.maxValue)}
+	}
+
+	return nil
+}
+
+// orderBy returns the columns the snapshot rows are ordered by.
+func (i *SnapshotIterator) orderBy() []string {
+	columns := []string{columntypes.Identifier(i.orderingColumn, i.quoteIdentifiers)}
+	if i.tieBreaker != "" {
+		columns = append(columns, columntypes.Identifier(i.tieBreaker, i.quoteIdentifiers))
+	}
+
+	return columns
+}
+
+// resumedPosition returns the copy of the position with time values restored from their JSON representation,
+// since they're decoded as strings, which the database doesn't compare with DATE and SECONDDATE values.
+func (i *SnapshotIterator) resumedPosition(pos *position.Position) *position.Position {
+	resumed := *pos
+
+	resumed.SnapshotLastProcessedVal = restoreTime(pos.SnapshotLastProcessedVal, i.columnTypes[i.orderingColumn])
+	resumed.SnapshotMaxValue = restoreTime(pos.SnapshotMaxValue, i.columnTypes[i.orderingColumn])
+
+	if i.tieBreaker != "" {
+		resumed.SnapshotLastTieBreaker = restoreTime(pos.SnapshotLastTieBreaker, i.columnTypes[i.tieBreaker])
+	}
+
+	return &resumed
+}
+
+// positionValue returns the value of the column kept in the position.
+// The position keeps time values as they are, regardless of the time format of records.
+func positionValue(row, transformedRow map[string]any, column string) any {
+	if timeValue, ok := row[column].(time.Time); ok {
+		return timeValue
+	}
+
+	return transformedRow[column]
+}
+
+// restoreTime returns the time of the RFC 3339 string, if the column type is a time type,
+// and the value as it is otherwise.
+func restoreTime(value any, columnType string) any {
+	s, ok := value.(string)
+	if !ok || !columntypes.IsTimeType(columnType) {
+		return value
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return value
+	}
+
+	return t
+}
+
+// callProcedure calls the procedure once, its result set is read as a single batch.
+func (i *SnapshotIterator) callProcedure(ctx context.Context) error {
+	if i.called {
+		i.rows = nil
+
+		return nil
+	}
+
+	rows, err := i.queryer().QueryxContext(ctx,
+		fmt.Sprintf(queryCallProcedure, i.source.Procedure, placeholders(len(i.source.Arguments))),
+		i.source.Arguments...)
+	if err != nil {
+		return fmt.Errorf("execute call query: %w", err)
+	}
+
+	i.rows = rows
+	i.called = true
+
+	return nil
+}
+
+// from returns the table or the call of the table function with placeholders of its arguments.
+func (i *SnapshotIterator) from() string {
+	if i.source.Function == "" {
+		return columntypes.Identifier(i.table, i.quoteIdentifiers)
+	}
+
+	return fmt.Sprintf("%s(%s)", i.source.Function, placeholders(len(i.source.Arguments)))
+}
+
+// placeholders returns the comma separated list of n placeholders.
+// withHints appends the hint clause to the select query, if there are hints.
+func withHints(query, hints string) string {
+	if hints == "" {
+		return query
+	}
+
+	return fmt.Sprintf("%s WITH HINT(%s)", query, hints)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
 // getMaxValue get max value from ordered column.
-func (i *snapshotIterator) setMaxValue(ctx context.Context) error {
-	rows, err := i.db.QueryxContext(ctx, fmt.Sprintf(queryGetMaxValue, i.orderingColumn, i.table))
+func (i *SnapshotIterator) setMaxValue(ctx context.Context) error {
+	// the procedure result isn't bounded, it's read with a single call.
+	if i.source.Procedure != "" {
+		return nil
+	}
+
+	query, args := fmt.Sprintf(queryGetMaxValue, columntypes.Identifier(i.orderingColumn, i.quoteIdentifiers), i.from()),
+		i.source.Arguments
+	if i.rangeEnd != "" {
+		query = fmt.Sprintf(queryGetMaxValueUpTo, columntypes.Identifier(i.orderingColumn, i.quoteIdentifiers), i.from())
+		args = append(append([]any{}, args...), i.rangeEnd)
+	}
+
+	rows, err := i.queryer().QueryxContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("execute query get max value: %w", err)
 	}
@@ -256,6 +677,7 @@ func (i *snapshotIterator) setMaxValue(ctx context.Context) error {
 	}
 
 	i.maxValue = maxValue
+	i.refreshedAt = time.Now()
 
 	return nil
 }
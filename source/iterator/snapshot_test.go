@@ -0,0 +1,437 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+func TestWithHints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		hints string
+		want  string
+	}{
+		{hints: "", want: "SELECT * FROM CLIENTS LIMIT 100"},
+		{hints: "NO_USE_OLAP_PLAN", want: "SELECT * FROM CLIENTS LIMIT 100 WITH HINT(NO_USE_OLAP_PLAN)"},
+		{
+			hints: "NO_USE_OLAP_PLAN, RESULT_LAG('hana_sr', 30)",
+			want:  "SELECT * FROM CLIENTS LIMIT 100 WITH HINT(NO_USE_OLAP_PLAN, RESULT_LAG('hana_sr', 30))",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := withHints("SELECT * FROM CLIENTS LIMIT 100", tt.hints); got != tt.want {
+			t.Errorf("withHints(%q) = %q, want %q", tt.hints, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotIterator_conditions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		it        *SnapshotIterator
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "first batch",
+			it:        &SnapshotIterator{orderingColumn: "ID", maxValue: 10},
+			wantQuery: "SELECT * FROM T",
+		},
+		{
+			name:      "range",
+			it:        &SnapshotIterator{orderingColumn: "ID", maxValue: 10, rangeStart: "5", rangeEnd: "10"},
+			wantQuery: "SELECT * FROM T WHERE ID >= ? AND ID <= ?",
+			wantArgs:  []any{"5", 10},
+		},
+		{
+			name:      "range without start",
+			it:        &SnapshotIterator{orderingColumn: "ID", maxValue: 10, rangeEnd: "10"},
+			wantQuery: "SELECT * FROM T WHERE ID <= ?",
+			wantArgs:  []any{10},
+		},
+		{
+			name: "resumed range",
+			it: &SnapshotIterator{
+				orderingColumn: "ID",
+				maxValue:       10,
+				rangeStart:     "5",
+				position:       &position.Position{SnapshotLastProcessedVal: 7},
+			},
+			wantQuery: "SELECT * FROM T WHERE ID > ? AND ID <= ?",
+			wantArgs:  []any{7, 10},
+		},
+		{
+			name: "tie-breaker",
+			it: &SnapshotIterator{
+				orderingColumn: "CREATED_ON",
+				tieBreaker:     "ID",
+				maxValue:       "2024-03-31",
+				position: &position.Position{
+					SnapshotLastProcessedVal: "2024-03-01",
+					SnapshotLastTieBreaker:   7,
+				},
+			},
+			wantQuery: "SELECT * FROM T WHERE (CREATED_ON > ? OR (CREATED_ON = ? AND ID > ?)) AND CREATED_ON <= ?",
+			wantArgs:  []any{"2024-03-01", "2024-03-01", 7, "2024-03-31"},
+		},
+		{
+			name: "tie-breaker of position without it",
+			it: &SnapshotIterator{
+				orderingColumn: "CREATED_ON",
+				tieBreaker:     "ID",
+				maxValue:       "2024-03-31",
+				position:       &position.Position{SnapshotLastProcessedVal: "2024-03-01"},
+			},
+			wantQuery: "SELECT * FROM T WHERE CREATED_ON > ? AND CREATED_ON <= ?",
+			wantArgs:  []any{"2024-03-01", "2024-03-31"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sb := sqlbuilder.NewSelectBuilder()
+			sb.Select("*").From("T")
+
+			if conditions := tt.it.conditions(sb); len(conditions) > 0 {
+				sb.Where(conditions...)
+			}
+
+			q, args := sb.Build()
+			if q != tt.wantQuery {
+				t.Errorf("query = %s, want %s", q, tt.wantQuery)
+			}
+
+			if len(args) > 0 || len(tt.wantArgs) > 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("args = %v, want %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotIterator_resumedPosition(t *testing.T) {
+	t.Parallel()
+
+	i := &SnapshotIterator{
+		orderingColumn: "CREATED_ON",
+		tieBreaker:     "ID",
+		columnTypes:    map[string]string{"CREATED_ON": "SECONDDATE", "ID": "INTEGER"},
+	}
+
+	pos := &position.Position{
+		SnapshotLastProcessedVal: "2024-03-01T10:00:00Z",
+		SnapshotMaxValue:         "2024-03-31T23:59:59Z",
+		SnapshotLastTieBreaker:   float64(7),
+	}
+
+	got := i.resumedPosition(pos)
+
+	if want := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); got.SnapshotLastProcessedVal != want {
+		t.Errorf("last processed value = %v, want %v", got.SnapshotLastProcessedVal, want)
+	}
+
+	if want := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC); got.SnapshotMaxValue != want {
+		t.Errorf("max value = %v, want %v", got.SnapshotMaxValue, want)
+	}
+
+	if got.SnapshotLastTieBreaker != float64(7) {
+		t.Errorf("last tie-breaker = %v, want 7", got.SnapshotLastTieBreaker)
+	}
+
+	if pos.SnapshotLastProcessedVal != "2024-03-01T10:00:00Z" {
+		t.Errorf("original position is changed: %v", pos.SnapshotLastProcessedVal)
+	}
+}
+
+func TestRestoreTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		value      any
+		columnType string
+		want       any
+	}{
+		{
+			name:       "date",
+			value:      "2024-03-01T00:00:00Z",
+			columnType: "DATE",
+			want:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "timestamp",
+			value:      "2024-03-01T10:00:00.1234567Z",
+			columnType: "TIMESTAMP",
+			want:       time.Date(2024, 3, 1, 10, 0, 0, 123456700, time.UTC),
+		},
+		{
+			name:       "string column",
+			value:      "2024-03-01T00:00:00Z",
+			columnType: "NVARCHAR",
+			want:       "2024-03-01T00:00:00Z",
+		},
+		{
+			name:       "not a time",
+			value:      "2024-03-01",
+			columnType: "DATE",
+			want:       "2024-03-01",
+		},
+		{
+			name:       "number",
+			value:      float64(7),
+			columnType: "INTEGER",
+			want:       float64(7),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := restoreTime(tt.value, tt.columnType); got != tt.want {
+				t.Errorf("restoreTime(%v, %s) = %v, want %v", tt.value, tt.columnType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotIterator_loadRows_Cursor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cursor    bool
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "batch",
+			wantQuery: "SELECT * FROM T ORDER BY ID LIMIT 100",
+		},
+		{
+			name:      "cursor",
+			cursor:    true,
+			wantQuery: "SELECT * FROM T WHERE ID <= ? ORDER BY ID",
+			wantArgs:  []any{10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(nil)
+
+			i := &SnapshotIterator{
+				db:             db.Open(),
+				table:          "T",
+				cursor:         tt.cursor,
+				orderingColumn: "ID",
+				maxValue:       10,
+				batchSize:      100,
+			}
+
+			if err := i.loadRows(context.Background()); err != nil {
+				t.Fatalf("loadRows() error = %v", err)
+			}
+
+			statements := db.Statements()
+			if len(statements) != 1 {
+				t.Fatalf("statements = %v, want a single query", statements)
+			}
+
+			if statements[0].Query != tt.wantQuery {
+				t.Errorf("query = %s, want %s", statements[0].Query, tt.wantQuery)
+			}
+
+			if len(statements[0].Args) > 0 || len(tt.wantArgs) > 0 {
+				if !reflect.DeepEqual(statements[0].Args, tt.wantArgs) {
+					t.Errorf("args = %v, want %v", statements[0].Args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotIterator_beginTx(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		isolation string
+		want      []fakedb.Statement
+	}{
+		{
+			name: "default",
+			want: []fakedb.Statement{{Query: "SELECT * FROM T ORDER BY ID LIMIT 100"}},
+		},
+		{
+			name:      "repeatable read",
+			isolation: "repeatableRead",
+			want: []fakedb.Statement{
+				{Query: fakedb.Begin, Args: []any{"Repeatable Read"}},
+				{Query: "SELECT * FROM T ORDER BY ID LIMIT 100"},
+				{Query: fakedb.Commit},
+			},
+		},
+		{
+			name:      "serializable",
+			isolation: "serializable",
+			want: []fakedb.Statement{
+				{Query: fakedb.Begin, Args: []any{"Serializable"}},
+				{Query: "SELECT * FROM T ORDER BY ID LIMIT 100"},
+				{Query: fakedb.Commit},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(nil)
+
+			i := &SnapshotIterator{
+				db:             db.Open(),
+				table:          "T",
+				isolation:      isolationLevels[tt.isolation],
+				orderingColumn: "ID",
+				batchSize:      100,
+			}
+
+			if err := i.beginTx(context.Background()); err != nil {
+				t.Fatalf("beginTx() error = %v", err)
+			}
+
+			// the batch is selected in the snapshot transaction, which is committed with the rows.
+			if err := i.loadRows(context.Background()); err != nil {
+				t.Fatalf("loadRows() error = %v", err)
+			}
+
+			if err := i.CloseRows(); err != nil {
+				t.Fatalf("CloseRows() error = %v", err)
+			}
+
+			if got := statements(db); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotIterator_HasNext_RefreshMax(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxQuery    = "SELECT max(ID) FROM T"
+		selectQuery = "SELECT * FROM T WHERE ID > ? AND ID <= ? ORDER BY ID LIMIT 100"
+	)
+
+	tests := []struct {
+		name        string
+		refreshedAt time.Time
+		want        []fakedb.Statement
+	}{
+		{
+			name:        "interval elapsed",
+			refreshedAt: time.Now().Add(-2 * time.Minute),
+			want: []fakedb.Statement{
+				{Query: maxQuery},
+				{Query: selectQuery, Args: []any{5, int64(20)}},
+				// rows inserted since the refresh are read before the snapshot is done.
+				{Query: maxQuery},
+				{Query: selectQuery, Args: []any{5, int64(20)}},
+			},
+		},
+		{
+			name:        "interval not elapsed",
+			refreshedAt: time.Now(),
+			want: []fakedb.Statement{
+				{Query: selectQuery, Args: []any{5, 10}},
+				{Query: maxQuery},
+				{Query: selectQuery, Args: []any{5, int64(20)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := fakedb.New(func(query string, _ []any) fakedb.Result {
+				if query == maxQuery {
+					return fakedb.Value(int64(20))
+				}
+
+				return fakedb.Result{}
+			})
+
+			i := &SnapshotIterator{
+				db:             db.Open(),
+				table:          "T",
+				orderingColumn: "ID",
+				maxValue:       10,
+				refreshMax:     time.Minute,
+				refreshedAt:    tt.refreshedAt,
+				batchSize:      100,
+				position:       &position.Position{SnapshotLastProcessedVal: 5},
+			}
+
+			hasNext, err := i.HasNext(context.Background())
+			if err != nil {
+				t.Fatalf("HasNext() error = %v", err)
+			}
+
+			if hasNext {
+				t.Error("HasNext() = true, want false")
+			}
+
+			if i.maxValue != int64(20) {
+				t.Errorf("max value = %v, want 20", i.maxValue)
+			}
+
+			if got := statements(db); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// statements returns the recorded statements, statements without arguments have nil arguments.
+func statements(db *fakedb.DB) []fakedb.Statement {
+	got := db.Statements()
+	for i := range got {
+		if len(got[i].Args) == 0 {
+			got[i].Args = nil
+		}
+	}
+
+	return got
+}
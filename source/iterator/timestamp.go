@@ -0,0 +1,97 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+const (
+	// CDCOrderID orders changes by the tracking ids.
+	CDCOrderID = "id"
+	// CDCOrderTimestamp orders changes by the time they were made and the tracking ids.
+	CDCOrderTimestamp = "timestamp"
+
+	// typeChangedAt - the type of the tracking table column with the time of the change,
+	// it's filled by the default value on insert.
+	typeChangedAt = "TIMESTAMP DEFAULT CURRENT_UTCTIMESTAMP"
+)
+
+// timestampConditions returns the conditions of rows following the position in the timestamp order.
+// Rows newer than the watermark aren't read, their transactions can be not committed yet.
+func (i *CDCIterator) timestampConditions(sb *sqlbuilder.SelectBuilder) []string {
+	var conditions []string
+
+	switch {
+	case i.position != nil && i.position.CDCLastChangedAt != nil:
+		conditions = append(conditions, sb.Or(
+			sb.GreaterThan(i.columns.changedAt, *i.position.CDCLastChangedAt),
+			sb.And(
+				sb.Equal(i.columns.changedAt, *i.position.CDCLastChangedAt),
+				sb.GreaterThan(i.columns.trackingID, i.position.CDCLastID),
+			),
+		))
+	// the position was saved in the id order.
+	case i.position != nil:
+		conditions = append(conditions, sb.GreaterThan(i.columns.trackingID, i.position.CDCLastID))
+	case !i.startFrom.IsZero():
+		conditions = append(conditions, sb.GreaterEqualThan(i.columns.changedAt, i.startFrom))
+	}
+
+	if i.watermark > 0 {
+		conditions = append(conditions, sb.LessEqualThan(i.columns.changedAt, secondsAgo(i.watermark)))
+	}
+
+	return conditions
+}
+
+// deleteExpiredRows deletes acknowledged rows older than the retention period.
+// It's called under the lock of the tracking table service.
+func (i *CDCIterator) deleteExpiredRows(ctx context.Context) error {
+	if i.ackedAt == nil {
+		return nil
+	}
+
+	deleteBuilder := sqlbuilder.NewDeleteBuilder()
+
+	q, args := deleteBuilder.
+		DeleteFrom(i.trackingTable).
+		Where(
+			deleteBuilder.LessThan(i.columns.changedAt, secondsAgo(i.retention)),
+			deleteBuilder.Or(
+				deleteBuilder.LessThan(i.columns.changedAt, *i.ackedAt),
+				deleteBuilder.And(
+					deleteBuilder.Equal(i.columns.changedAt, *i.ackedAt),
+					deleteBuilder.LessEqualThan(i.columns.trackingID, i.ackedID),
+				),
+			),
+		).
+		Build()
+
+	if _, err := i.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+// secondsAgo returns the SQL expression of the current UTC time minus the duration.
+func secondsAgo(d time.Duration) any {
+	return sqlbuilder.Raw(fmt.Sprintf("ADD_SECONDS(CURRENT_UTCTIMESTAMP, %g)", -d.Seconds()))
+}
@@ -0,0 +1,50 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iterator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/source/position"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+func TestCDCIterator_timestampConditions(t *testing.T) {
+	t.Parallel()
+
+	changedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	i := &CDCIterator{
+		orderByTimestamp: true,
+		watermark:        10 * time.Second,
+		columns:          newServiceColumns(""),
+		position:         &position.Position{CDCLastID: 42, CDCLastChangedAt: &changedAt},
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	q, args := sb.Select("*").From("T").Where(i.timestampConditions(sb)...).Build()
+
+	wantQuery := "SELECT * FROM T WHERE (CONDUIT_CHANGED_AT > ? OR (CONDUIT_CHANGED_AT = ? AND CONDUIT_TRACKING_ID > ?)) " +
+		"AND CONDUIT_CHANGED_AT <= ADD_SECONDS(CURRENT_UTCTIMESTAMP, -10)"
+	if q != wantQuery {
+		t.Errorf("query = %s, want %s", q, wantQuery)
+	}
+
+	if want := []any{changedAt, changedAt, 42}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	hdbdriver "github.com/SAP/go-hdb/driver"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// leadersTable - table with the locks of the pipelines coordinating their instances.
+	leadersTable = "CONDUIT_LEADERS"
+	// errCodeDuplicateTableName - sap hana error code of creating a table which already exists.
+	errCodeDuplicateTableName = 288
+)
+
+const (
+	queryIfLeadersTableExist = `
+		SELECT 
+		  count(*) AS count 
+		FROM 
+		  TABLES 
+		WHERE 
+		  TABLE_NAME = $1 
+		  AND SCHEMA_NAME = CURRENT_SCHEMA
+	`
+	queryCreateLeadersTable = `
+		CREATE TABLE %s (
+		    NAME NVARCHAR(256) PRIMARY KEY,
+		    OWNER NVARCHAR(512),
+		    EXPIRES_AT TIMESTAMP,
+		    POSITION NCLOB
+		)
+	`
+	querySelectLeaderForUpdate = `
+		SELECT 
+		  OWNER, 
+		  CASE WHEN EXPIRES_AT > CURRENT_UTCTIMESTAMP THEN 1 ELSE 0 END AS ACTIVE, 
+		  POSITION 
+		FROM 
+		  %s 
+		WHERE 
+		  NAME = $1 
+		FOR UPDATE
+	`
+	queryUpsertLeader = `
+		UPSERT %s (NAME, OWNER, EXPIRES_AT) 
+		VALUES ($1, $2, ADD_SECONDS(CURRENT_UTCTIMESTAMP, $3)) WITH PRIMARY KEY
+	`
+	// the position is kept if there's no new one.
+	queryRenewLeader = `
+		UPDATE %s SET EXPIRES_AT = ADD_SECONDS(CURRENT_UTCTIMESTAMP, $1), POSITION = COALESCE($2, POSITION) 
+		WHERE NAME = $3 AND OWNER = $4
+	`
+)
+
+// leader holds the lock of the pipeline in the database, so only one of the instances running the pipeline
+// reads records. The lock is a lease, which the active instance renews, and the other instances stand by
+// until it expires. The active instance saves the last acknowledged position with the lease,
+// so the instance taking over resumes from it and reuses the tracking tables of the previous one.
+type leader struct {
+	db *sqlx.DB
+	// name - name of the lock, it's shared by the instances of the pipeline.
+	name string
+	// owner - unique name of the instance.
+	owner string
+	// lease - time the lock is held without renewing it.
+	lease time.Duration
+	// active - whether the instance holds the lock.
+	active bool
+	// checkedAt - time of the last attempt to acquire the lock or of its renewal.
+	checkedAt time.Time
+
+	// positionMu guards position, it's acknowledged and saved by different goroutines.
+	positionMu sync.Mutex
+	// position - last acknowledged position, which isn't saved yet.
+	position opencdc.Position
+}
+
+// newLeader creates the table of the locks, if it doesn't exist, and returns the leader of the lock.
+func newLeader(ctx context.Context, db *sqlx.DB, name, owner string, lease time.Duration) (*leader, error) {
+	var count int
+
+	err := db.QueryRowContext(ctx, queryIfLeadersTableExist, leadersTable).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("check leaders table exists: %w", err)
+	}
+
+	if count == 0 {
+		_, err = db.ExecContext(ctx, fmt.Sprintf(queryCreateLeadersTable, leadersTable))
+		if err != nil && !isAlreadyExists(err) {
+			return nil, fmt.Errorf("create leaders table: %w", err)
+		}
+	}
+
+	return &leader{
+		db:    db,
+		name:  name,
+		owner: owner,
+		lease: lease,
+	}, nil
+}
+
+// due returns whether the lock should be acquired or renewed, it's done three times per lease.
+func (l *leader) due() bool {
+	return l.untilDue() == 0
+}
+
+// untilDue returns the time until the lock should be acquired or renewed.
+func (l *leader) untilDue() time.Duration {
+	return max(l.lease/3-time.Since(l.checkedAt), 0)
+}
+
+// acquire takes the lock, if it's free or its lease has expired. It returns whether the lock is acquired
+// and the position saved by the previous holder of the lock, which is nil if there's no position.
+func (l *leader) acquire(ctx context.Context) (bool, opencdc.Position, error) {
+	l.checkedAt = time.Now()
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer tx.Rollback() // nolint:errcheck,nolintlint
+
+	var (
+		owner    string
+		active   int
+		position strings.Builder
+		lob      = hdbdriver.NullLob{Lob: hdbdriver.NewLob(nil, &position)}
+	)
+
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(querySelectLeaderForUpdate, leadersTable), l.name).
+		Scan(&owner, &active, &lob)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, nil, fmt.Errorf("select leader: %w", err)
+	}
+
+	if active == 1 && owner != l.owner {
+		return false, nil, nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(queryUpsertLeader, leadersTable), l.name, l.owner, l.lease.Seconds())
+	if err != nil {
+		return false, nil, fmt.Errorf("upsert leader: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	l.active = true
+
+	if position.Len() == 0 {
+		return true, nil, nil
+	}
+
+	return true, opencdc.Position(position.String()), nil
+}
+
+// ack keeps the acknowledged position, it's saved with the next renewal of the lease.
+func (l *leader) ack(position opencdc.Position) {
+	l.positionMu.Lock()
+	defer l.positionMu.Unlock()
+
+	l.position = position
+}
+
+// renew extends the lease of the lock and saves the last acknowledged position.
+// It returns ErrLockLost if another instance has taken the lock over.
+func (l *leader) renew(ctx context.Context) error {
+	return l.extend(ctx, l.lease)
+}
+
+// release saves the last acknowledged position and lets the lease expire, so a standby instance takes
+// the lock over without waiting for the lease.
+func (l *leader) release(ctx context.Context) error {
+	if !l.active {
+		return nil
+	}
+
+	l.active = false
+
+	return l.extend(ctx, 0)
+}
+
+// extend sets the lease of the lock to expire after the duration and saves the last acknowledged position.
+func (l *leader) extend(ctx context.Context, lease time.Duration) error {
+	l.checkedAt = time.Now()
+
+	l.positionMu.Lock()
+	saved := l.position
+	l.positionMu.Unlock()
+
+	var position any
+	if saved != nil {
+		position = string(saved)
+	}
+
+	res, err := l.db.ExecContext(ctx, fmt.Sprintf(queryRenewLeader, leadersTable),
+		lease.Seconds(), position, l.name, l.owner)
+	if err != nil {
+		return fmt.Errorf("renew leader: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		l.active = false
+
+		return fmt.Errorf("%w: %s", ErrLockLost, l.name)
+	}
+
+	// a position acknowledged while the lease was renewed is saved with the next renewal.
+	l.positionMu.Lock()
+	if bytes.Equal(l.position, saved) {
+		l.position = nil
+	}
+	l.positionMu.Unlock()
+
+	return nil
+}
+
+// isAlreadyExists reports whether the error is caused by an object created concurrently by another instance.
+func isAlreadyExists(err error) bool {
+	var dbErr hdbdriver.DBError
+
+	return errors.As(err, &dbErr) && dbErr.Code() == errCodeDuplicateTableName
+}
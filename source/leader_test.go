@@ -0,0 +1,111 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/internal/fakedb"
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// renewals returns the fake database renewing the lease, it calls onRenew with the saved position.
+func renewals(onRenew func(position any)) *fakedb.DB {
+	return fakedb.New(func(query string, args []any) fakedb.Result {
+		if strings.Contains(query, "UPDATE "+leadersTable) {
+			onRenew(args[1])
+
+			return fakedb.Result{RowsAffected: 1}
+		}
+
+		return fakedb.Result{}
+	})
+}
+
+func TestLeader_renew_AckDuringRenewal(t *testing.T) {
+	t.Parallel()
+
+	l := &leader{name: "PIPELINE", owner: "a", lease: time.Minute, active: true}
+
+	var saved []any
+
+	l.db = renewals(func(position any) {
+		saved = append(saved, position)
+
+		// the record is acknowledged after the update and before the renewal returns.
+		if len(saved) == 1 {
+			l.ack(opencdc.Position("2"))
+		}
+	}).Open()
+
+	l.ack(opencdc.Position("1"))
+
+	for range 2 {
+		if err := l.renew(context.Background()); err != nil {
+			t.Fatalf("renew: %v", err)
+		}
+	}
+
+	if want := []any{"1", "2"}; fmt.Sprint(saved) != fmt.Sprint(want) {
+		t.Errorf("saved positions = %v, want %v", saved, want)
+	}
+
+	if l.position != nil {
+		t.Errorf("position = %s, want nil after it's saved", l.position)
+	}
+}
+
+func TestLeader_ConcurrentAckAndRenew(t *testing.T) {
+	t.Parallel()
+
+	l := &leader{name: "PIPELINE", owner: "a", lease: time.Minute, active: true}
+	l.db = renewals(func(any) {}).Open()
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+
+	// the sdk acknowledges records and reads them in different goroutines.
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			l.ack(opencdc.Position(fmt.Sprint(i)))
+		}
+	}()
+
+	for range 100 {
+		if err := l.renew(ctx); err != nil {
+			t.Fatalf("renew: %v", err)
+		}
+	}
+
+	wg.Wait()
+
+	if err := l.renew(ctx); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+
+	if l.position != nil {
+		t.Errorf("position = %s, want the last position saved", l.position)
+	}
+}
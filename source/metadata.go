@@ -0,0 +1,99 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// metadataPrefix is the default prefix of the metadata fields of the connector.
+	metadataPrefix = "saphana."
+
+	metadataSchema           = "saphana.schema"
+	metadataDatabase         = "saphana.database"
+	metadataHost             = "saphana.host"
+	metadataConnectorVersion = "saphana.connectorVersion"
+
+	queryGetCurrentSchema = `SELECT CURRENT_SCHEMA FROM DUMMY`
+	queryGetDatabaseName  = `SELECT DATABASE_NAME FROM M_DATABASE`
+)
+
+// recordMetadata returns the metadata fields added to every record, which are enabled by the config,
+// with the configured prefix, and the static metadata fields.
+// The schema is the current schema of the connection, if it isn't configured.
+func (s *Source) recordMetadata(ctx context.Context, db *sqlx.DB, schema string) (map[string]string, error) {
+	metadata := make(map[string]string)
+
+	if s.config.Metadata.Schema {
+		if schema == "" {
+			if err := db.QueryRowContext(ctx, queryGetCurrentSchema).Scan(&schema); err != nil {
+				return nil, fmt.Errorf("get current schema: %w", err)
+			}
+		}
+
+		metadata[metadataSchema] = schema
+	}
+
+	if s.config.Metadata.Database {
+		var database string
+		if err := db.QueryRowContext(ctx, queryGetDatabaseName).Scan(&database); err != nil {
+			return nil, fmt.Errorf("get database name: %w", err)
+		}
+
+		metadata[metadataDatabase] = database
+	}
+
+	if s.config.Metadata.Host {
+		metadata[metadataHost] = s.config.Auth.HostAddress()
+	}
+
+	if s.config.Metadata.ConnectorVersion {
+		metadata[metadataConnectorVersion] = s.version
+	}
+
+	metadata = withPrefix(metadata, s.config.Metadata.Prefix)
+
+	// static fields are added as they are, so they're kept with any prefix.
+	for k, v := range s.config.Metadata.Static {
+		metadata[k] = v
+	}
+
+	return metadata, nil
+}
+
+// withPrefix returns the metadata with the default prefix of the connector fields replaced by the prefix.
+// Other fields, e.g. opencdc.readAt, are kept. The metadata is returned as it is if the prefix is empty or default.
+func withPrefix(metadata opencdc.Metadata, prefix string) opencdc.Metadata {
+	if prefix == "" || prefix == metadataPrefix {
+		return metadata
+	}
+
+	renamed := make(opencdc.Metadata, len(metadata))
+	for k, v := range metadata {
+		if name, ok := strings.CutPrefix(k, metadataPrefix); ok {
+			k = prefix + name
+		}
+
+		renamed[k] = v
+	}
+
+	return renamed
+}
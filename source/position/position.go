@@ -17,6 +17,7 @@ package position
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/conduitio/conduit-commons/opencdc"
 )
@@ -25,8 +26,10 @@ import (
 type IteratorType string
 
 const (
-	TypeSnapshot = "s"
-	TypeCDC      = "c"
+	TypeSnapshot   = "s"
+	TypeCDC        = "c"
+	TypeHistory    = "h"
+	TypeCollection = "d"
 )
 
 // Position represents SAP Hana position.
@@ -39,12 +42,39 @@ type Position struct {
 	SnapshotLastProcessedVal any
 	// SnapshotMaxValue - max value from ordering column.
 	SnapshotMaxValue any
+	// SnapshotLastTieBreaker - tie-breaker column value of the last processed row, it's set if the snapshot
+	// has a tie-breaker column.
+	SnapshotLastTieBreaker any `json:",omitempty"`
+	// SnapshotPending - the snapshot was interrupted by its max duration, it's resumed when CDC has no changes.
+	// Positions of both CDC and snapshot records keep the progress of both iterators then.
+	SnapshotPending bool `json:",omitempty"`
+	// SnapshotCount - number of snapshot records returned, it's kept only if the snapshot has a limit.
+	SnapshotCount int `json:",omitempty"`
+
+	// History information.
+	// HistoryLastValidFrom - validFrom value of the last processed version.
+	HistoryLastValidFrom any `json:",omitempty"`
+	// HistoryLastProcessedVal - last processed value from ordering column.
+	HistoryLastProcessedVal any `json:",omitempty"`
+	// HistoryMaxValidTo - max value from validTo column.
+	HistoryMaxValidTo any `json:",omitempty"`
+
+	// Collection information.
+	// CollectionLastValue - ordering field value of the last processed document.
+	CollectionLastValue any `json:",omitempty"`
+	// CollectionMaxValue - max value from ordering field, it's nil after the snapshot is done.
+	CollectionMaxValue any `json:",omitempty"`
 
 	// CDC information.
 	// CDCLastID - last processed id from tracking table.
 	CDCLastID int
+	// CDCLastChangedAt - time of the last processed change, it's set if changes are ordered by time.
+	CDCLastChangedAt *time.Time `json:",omitempty"`
 	// TrackingTableName tracking table name.
 	TrackingTableName string
+
+	// TableOID - object id of the table, it changes when the table is dropped and created again.
+	TableOID int64 `json:",omitempty"`
 }
 
 // ParseSDKPosition parses SDK position and returns Position.
@@ -61,7 +91,7 @@ func ParseSDKPosition(p opencdc.Position) (*Position, error) {
 	}
 
 	switch pos.IteratorType {
-	case TypeSnapshot, TypeCDC:
+	case TypeSnapshot, TypeCDC, TypeHistory, TypeCollection:
 		return &pos, nil
 	default:
 		return nil, fmt.Errorf("%w : %s", ErrUnknownIteratorType, pos.IteratorType)
@@ -77,3 +107,45 @@ func (p Position) ConvertToSDKPosition() (opencdc.Position, error) {
 
 	return pos, nil
 }
+
+// MultiPosition represents SAP Hana position of the schema mode.
+type MultiPosition struct {
+	// Table - table of the record the position was created for.
+	Table string
+	// Positions - last positions of tables by their names.
+	Positions map[string]*Position
+}
+
+// ParseSDKMultiPosition parses SDK position and returns MultiPosition.
+func ParseSDKMultiPosition(p opencdc.Position) (*MultiPosition, error) {
+	var pos MultiPosition
+
+	if p == nil {
+		return nil, nil //nolint:nilnil // it is ok to return nils on this case
+	}
+
+	err := json.Unmarshal(p, &pos)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling: %w", err)
+	}
+
+	for table, tablePos := range pos.Positions {
+		switch tablePos.IteratorType {
+		case TypeSnapshot, TypeCDC, TypeHistory, TypeCollection:
+		default:
+			return nil, fmt.Errorf("%w : %s, table %s", ErrUnknownIteratorType, tablePos.IteratorType, table)
+		}
+	}
+
+	return &pos, nil
+}
+
+// ConvertToSDKPosition formats and returns opencdc.Position.
+func (p MultiPosition) ConvertToSDKPosition() (opencdc.Position, error) {
+	pos, err := json.Marshal(p)
+	if err != nil {
+		return opencdc.Position{}, fmt.Errorf("marshall: %w", err)
+	}
+
+	return pos, nil
+}
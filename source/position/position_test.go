@@ -88,3 +88,37 @@ func TestParseSDKPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSDKMultiPosition(t *testing.T) {
+	t.Parallel()
+
+	multiPos := MultiPosition{
+		Table: "USERS",
+		Positions: map[string]*Position{
+			"USERS":  {IteratorType: TypeCDC, CDCLastID: 5, TrackingTableName: "CONDUIT_USERS_213315"},
+			"ORDERS": {IteratorType: TypeSnapshot, SnapshotLastProcessedVal: "1", TrackingTableName: "CONDUIT_ORDERS_213315"},
+		},
+	}
+
+	multiPosBytes, _ := json.Marshal(multiPos)
+
+	wrongPosBytes, _ := json.Marshal(MultiPosition{
+		Table:     "USERS",
+		Positions: map[string]*Position{"USERS": {IteratorType: "i"}},
+	})
+
+	got, err := ParseSDKMultiPosition(multiPosBytes)
+	if err != nil {
+		t.Fatalf("parse error = \"%s\"", err.Error())
+	}
+
+	if got.Table != multiPos.Table || got.Positions["USERS"].CDCLastID != 5 ||
+		got.Positions["ORDERS"].IteratorType != TypeSnapshot {
+		t.Errorf("got = %v, want %v", got, multiPos)
+	}
+
+	_, err = ParseSDKMultiPosition(wrongPosBytes)
+	if err == nil || !errors.Is(err, ErrUnknownIteratorType) {
+		t.Errorf("expected unknown iterator type error, got %v", err)
+	}
+}
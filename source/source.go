@@ -17,21 +17,56 @@ package source
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/conduitio-labs/conduit-connector-sap-hana/catalog"
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/helper"
 	"github.com/conduitio-labs/conduit-connector-sap-hana/source/iterator"
 	"github.com/conduitio/conduit-commons/config"
 	"github.com/conduitio/conduit-commons/opencdc"
 	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/jpillora/backoff"
 )
 
+// maxBatchSize is the maximum size of rows batch, the same as the one of the batchSize parameter.
+const maxBatchSize = 10000
+
+// columnPrefixRegex matches prefixes of the tracking table service columns, the default one is used if it's empty.
+var columnPrefixRegex = regexp.MustCompile(`^([A-Z_][A-Z0-9_]*)?$`)
+
 // Source connector.
 type Source struct {
 	sdk.UnimplementedSource
 
 	config   Config
 	iterator Iterator
+
+	// includeRegex and excludeRegex compiled table filters of the schema mode.
+	includeRegex *regexp.Regexp
+	excludeRegex *regexp.Regexp
+	// masking parsed masking of column values.
+	masking columntypes.Masking
+	// nullDefaults values replacing nulls by upper case column names.
+	nullDefaults map[string]string
+	// overrides parsed options of single tables of the schema mode by table names.
+	overrides map[string]iterator.TableOverrides
+	// cdcStartFrom parsed time of the first change read when cdc starts without a position.
+	cdcStartFrom time.Time
+	// version of the connector.
+	version string
+	// metadata fields added to every record.
+	metadata map[string]string
+	// leader holds the lock of the pipeline, it's nil if instances don't coordinate.
+	leader *leader
+	// position the connector was opened with, a standby instance reads from it if the lock has no position.
+	position opencdc.Position
+	// pollBackoff paces reads without new records, it's nil if the pacing is left to Conduit.
+	pollBackoff *backoff.Backoff
 }
 
 // New initialises a new source.
@@ -39,6 +74,11 @@ func New() sdk.Source {
 	return &Source{}
 }
 
+// NewWithVersion initialises a new source of the connector version.
+func NewWithVersion(version string) sdk.Source {
+	return &Source{version: version}
+}
+
 // Parameters returns a map of named config.Parameters that describe how to configure the Destination.
 func (s *Source) Parameters() config.Parameters {
 	return s.config.Parameters()
@@ -50,42 +90,429 @@ func (s *Source) Configure(ctx context.Context, cfg config.Config) error {
 		return fmt.Errorf("parse config: %w", err)
 	}
 
+	if s.config.Collection.Name != "" {
+		if s.config.Table != "" || s.config.Schema != "" {
+			return ErrCollectionWithTable
+		}
+	} else if (s.config.Table == "") == (s.config.Schema == "") {
+		return ErrTableOrSchemaRequired
+	}
+
+	if s.config.Table != "" && s.config.OrderingColumn == "" {
+		return ErrOrderingColumnRequired
+	}
+
+	// the schema mode sets the read schema as the default one.
+	if s.config.Schema != "" && s.config.DefaultSchema != "" {
+		return ErrDefaultSchemaWithSchema
+	}
+
+	if s.config.History.Table != "" {
+		if s.config.Schema != "" {
+			return ErrHistoryWithSchema
+		}
+
+		if s.config.History.ValidFromColumn == "" || s.config.History.ValidToColumn == "" {
+			return ErrHistoryColumnsRequired
+		}
+	}
+
+	if s.config.SnapshotFunction != "" || s.config.SnapshotProcedure != "" {
+		if s.config.Schema != "" {
+			return ErrSnapshotSourceWithSchema
+		}
+
+		if s.config.SnapshotFunction != "" && s.config.SnapshotProcedure != "" {
+			return ErrSnapshotFunctionAndProcedure
+		}
+	}
+
+	// the result of the procedure is read with a single call, so it isn't bounded by the ordering column.
+	if s.config.SnapshotProcedure != "" && (s.config.SnapshotRangeStart != "" || s.config.SnapshotRangeEnd != "") {
+		return ErrRangeWithProcedure
+	}
+
+	// the result of the procedure is read with a single call, so it can't be resumed.
+	if s.config.SnapshotProcedure != "" && s.config.SnapshotMaxDuration > 0 &&
+		s.config.SnapshotOnMaxDuration == iterator.SnapshotResume {
+		return ErrResumeWithProcedure
+	}
+
+	// compacted updates would lose columns changed by the replaced rows.
+	if s.config.CDC.Compaction && s.config.CDC.ChangedColumnsOnly {
+		return ErrCompactionWithChangedColumns
+	}
+
+	// other pipelines keep reading the shared tracking table.
+	if s.config.CDC.DropOnTeardown && s.config.CDC.ConsumerName != "" {
+		return ErrDropOnTeardownWithConsumer
+	}
+
+	// running consumers refresh their offsets every few seconds, they must not expire in between.
+	if s.config.CDC.ConsumerTTL > 0 && s.config.CDC.ConsumerTTL < time.Minute {
+		return ErrConsumerTTLTooShort
+	}
+
+	// the remote subscription captures whole rows of all operations.
+	if s.config.CDCMode == iterator.CDCModeSDI && (s.config.CDC.ChangedColumnsOnly || s.config.CDC.TransactionID) {
+		return ErrTriggerOptionsWithSDI
+	}
+
+	if err := s.validateCDCOrder(); err != nil {
+		return err
+	}
+
+	for i, operation := range s.config.CDC.Operations {
+		s.config.CDC.Operations[i] = strings.ToLower(strings.TrimSpace(operation))
+
+		switch s.config.CDC.Operations[i] {
+		case opencdc.OperationCreate.String(), opencdc.OperationUpdate.String(), opencdc.OperationDelete.String():
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownOperation, operation)
+		}
+	}
+
+	if s.config.HA.LockName != "" && s.config.HA.LeaseDuration < time.Second {
+		return ErrLeaseTooShort
+	}
+
+	if s.config.PollBackoff.Min > 0 {
+		if s.config.PollBackoff.Min > s.config.PollBackoff.Max {
+			return ErrPollBackoffRange
+		}
+
+		s.pollBackoff = &backoff.Backoff{
+			Min:    s.config.PollBackoff.Min,
+			Max:    s.config.PollBackoff.Max,
+			Factor: 2,
+		}
+	}
+
 	if err := s.config.Auth.Validate(); err != nil {
 		return fmt.Errorf("validate auth config: %w", err)
 	}
 
-	// Column names and table are uppercase for Sap Hana database.
+	var err error
+
+	if s.config.Tables.IncludeRegex != "" {
+		s.includeRegex, err = regexp.Compile(s.config.Tables.IncludeRegex)
+		if err != nil {
+			return fmt.Errorf("compile tables include regex: %w", err)
+		}
+	}
+
+	if s.config.Tables.ExcludeRegex != "" {
+		s.excludeRegex, err = regexp.Compile(s.config.Tables.ExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("compile tables exclude regex: %w", err)
+		}
+	}
+
+	if len(s.config.Tables.Overrides) > 0 && s.config.Schema == "" {
+		return ErrOverridesWithoutSchema
+	}
+
+	s.overrides, err = parseTableOverrides(s.config.Tables.Overrides)
+	if err != nil {
+		return err
+	}
+
+	s.masking = columntypes.Masking{
+		Columns:    make(map[string]columntypes.MaskingStrategy, len(s.config.Masking.Columns)),
+		FixedValue: s.config.Masking.FixedValue,
+		HashSalt:   s.config.Masking.HashSalt,
+	}
+
+	for column, value := range s.config.Masking.Columns {
+		strategy := columntypes.MaskingStrategy(strings.ToLower(strings.TrimSpace(value)))
+		if !strategy.IsValid() {
+			return fmt.Errorf("%w: %s", ErrUnknownMaskingStrategy, value)
+		}
+
+		s.masking.Columns[strings.ToUpper(column)] = strategy
+	}
+
+	if len(s.config.Nulls.Defaults) > 0 && s.config.Nulls.Mode != iterator.NullsDefault {
+		return ErrNullDefaultsWithoutDefault
+	}
+
+	s.nullDefaults = make(map[string]string, len(s.config.Nulls.Defaults))
+	for column, value := range s.config.Nulls.Defaults {
+		s.nullDefaults[strings.ToUpper(column)] = value
+	}
+
+	// Column names, table and schema are uppercase for Sap Hana database.
 	s.config.OrderingColumn = strings.ToUpper(s.config.OrderingColumn)
+	s.config.SnapshotTieBreakerColumn = strings.ToUpper(s.config.SnapshotTieBreakerColumn)
 	s.config.Table = strings.ToUpper(s.config.Table)
+	s.config.Schema = strings.ToUpper(s.config.Schema)
+	s.config.Collection.Name = strings.ToUpper(s.config.Collection.Name)
+	s.config.History.Table = strings.ToUpper(s.config.History.Table)
+	s.config.History.ValidFromColumn = strings.ToUpper(s.config.History.ValidFromColumn)
+	s.config.History.ValidToColumn = strings.ToUpper(s.config.History.ValidToColumn)
+	s.config.CDC.ColumnPrefix = strings.ToUpper(s.config.CDC.ColumnPrefix)
+
+	// the service columns are created and read without quotes.
+	if !columnPrefixRegex.MatchString(s.config.CDC.ColumnPrefix) {
+		return fmt.Errorf("%w: %q", ErrInvalidColumnPrefix, s.config.CDC.ColumnPrefix)
+	}
+
+	for i, column := range s.config.BooleanColumns {
+		s.config.BooleanColumns[i] = strings.ToUpper(strings.TrimSpace(column))
+	}
+
+	return nil
+}
+
+// parseTableOverrides parses table overrides in the TABLE?option=value&option=value format.
+func parseTableOverrides(values []string) (map[string]iterator.TableOverrides, error) {
+	overrides := make(map[string]iterator.TableOverrides, len(values))
+
+	for _, value := range values {
+		table, query, _ := strings.Cut(strings.TrimSpace(value), "?")
+		if table == "" {
+			return nil, fmt.Errorf("%w: %q: no table", ErrInvalidTableOverride, value)
+		}
+
+		options, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidTableOverride, value, err)
+		}
+
+		// tables are uppercase for Sap Hana database.
+		table = strings.ToUpper(table)
+		override := overrides[table]
+
+		for option := range options {
+			var valid bool
+
+			switch option {
+			case "batchSize":
+				override.BatchSize, err = strconv.Atoi(options.Get(option))
+				valid = err == nil && override.BatchSize > 0 && override.BatchSize <= maxBatchSize
+			case "pollInterval":
+				override.PollInterval, err = time.ParseDuration(options.Get(option))
+				valid = err == nil && override.PollInterval >= 0
+			}
+
+			if !valid {
+				return nil, fmt.Errorf("%w: %q: option %s", ErrInvalidTableOverride, value, option)
+			}
+		}
+
+		overrides[table] = override
+	}
+
+	return overrides, nil
+}
+
+// validateCDCOrder validates and parses the options of the cdc order.
+func (s *Source) validateCDCOrder() error {
+	if s.config.CDC.OrderBy != iterator.CDCOrderTimestamp &&
+		(s.config.CDC.StartFrom != "" || s.config.CDC.Retention > 0) {
+		return ErrTimestampOrderRequired
+	}
+
+	// other pipelines keep reading the shared tracking table.
+	if s.config.CDC.Retention > 0 && s.config.CDC.ConsumerName != "" {
+		return ErrRetentionWithConsumer
+	}
+
+	if s.config.CDC.StartFrom != "" {
+		startFrom, err := time.Parse(time.RFC3339, s.config.CDC.StartFrom)
+		if err != nil {
+			return fmt.Errorf("parse cdc.startFrom: %w", err)
+		}
+
+		s.cdcStartFrom = startFrom.UTC()
+	}
 
 	return nil
 }
 
 // Open prepare the plugin to start sending records from the given position.
+// If the lock of the pipeline is held by another instance, the connector stands by and takes over in Read.
 func (s *Source) Open(ctx context.Context, rp opencdc.Position) error {
-	db, err := helper.ConnectToDB(s.config.Auth)
+	if s.config.HA.LockName == "" {
+		return s.open(ctx, rp)
+	}
+
+	s.position = rp
+
+	db, err := helper.ConnectToDB(s.config.Auth, helper.WithDefaultSchema(s.defaultSchema()))
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+
+	owner, err := helper.InstanceName()
+	if err != nil {
+		return fmt.Errorf("instance name: %w", err)
+	}
+
+	s.leader, err = newLeader(ctx, db, s.config.HA.LockName, owner, s.config.HA.LeaseDuration)
+	if err != nil {
+		db.Close() //nolint:errcheck // the lock error is more relevant
+
+		return fmt.Errorf("new leader: %w", err)
+	}
+
+	return s.takeOver(ctx)
+}
+
+// takeOver opens the iterator, if the instance acquires the lock of the pipeline. The iterator reads from
+// the position saved by the previous holder of the lock, or from the position the connector was opened with.
+func (s *Source) takeOver(ctx context.Context) error {
+	acquired, pos, err := s.leader.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire lock: %w", err)
+	}
+
+	if !acquired {
+		sdk.Logger(ctx).Info().Str("lock", s.leader.name).Msg("lock is held by another instance, stand by")
+
+		return nil
+	}
+
+	sdk.Logger(ctx).Info().Str("lock", s.leader.name).Msg("lock acquired, start reading")
+
+	if pos == nil {
+		pos = s.position
+	}
+
+	return s.open(ctx, pos)
+}
+
+// defaultSchema returns the schema of unqualified names, it's the read schema in the schema mode.
+func (s *Source) defaultSchema() string {
+	if s.config.Schema != "" {
+		return s.config.Schema
+	}
+
+	return s.config.DefaultSchema
+}
+
+// open opens the iterator from the given position.
+func (s *Source) open(ctx context.Context, rp opencdc.Position) error {
+	var fetchSize int
+	if s.config.SnapshotCursor {
+		fetchSize = s.config.BatchSize
+	}
+
+	defaultSchema := s.defaultSchema()
+
+	db, err := helper.ConnectToDB(s.config.Auth, helper.WithDefaultSchema(defaultSchema),
+		helper.WithFetchSize(fetchSize), helper.WithKeepalive(s.config.KeepaliveInterval))
 	if err != nil {
 		return fmt.Errorf("connect to db: %w", err)
 	}
 
 	if err = db.Ping(); err != nil {
+		db.Close() //nolint:errcheck // the ping error is more relevant
+
+		return fmt.Errorf("ping db: %w", helper.RedactError(err, s.config.Auth))
+	}
+
+	s.metadata, err = s.recordMetadata(ctx, db, defaultSchema)
+	if err != nil {
+		return fmt.Errorf("record metadata: %w", err)
+	}
+
+	if s.config.Collection.Name != "" {
+		s.iterator, err = iterator.NewCollectionIterator(ctx, iterator.CollectionParams{
+			DB:            db,
+			Collection:    s.config.Collection.Name,
+			OrderingField: s.config.Collection.OrderingField,
+			BatchSize:     s.config.BatchSize,
+			Snapshot:      s.config.Snapshot,
+			SdkPosition:   rp,
+		})
 		if err != nil {
-			return fmt.Errorf("ping db: %w", err)
-		}
-	}
-
-	s.iterator, err = iterator.NewCombinedIterator(
-		ctx,
-		iterator.CombinedParams{
-			DB:             db,
-			Table:          s.config.Table,
-			OrderingColumn: s.config.OrderingColumn,
-			CfgKeys:        s.config.PrimaryKeys,
-			BatchSize:      s.config.BatchSize,
-			Snapshot:       s.config.Snapshot,
-			SdkPosition:    rp,
-		},
-	)
+			return fmt.Errorf("new collection iterator: %w", err)
+		}
+
+		return nil
+	}
+
+	params := iterator.CombinedParams{
+		DB:                    db,
+		Auth:                  s.config.Auth,
+		Schema:                defaultSchema,
+		Table:                 s.config.Table,
+		OrderingColumn:        s.config.OrderingColumn,
+		CfgKeys:               s.config.PrimaryKeys,
+		BatchSize:             s.config.BatchSize,
+		Snapshot:              s.config.Snapshot,
+		SnapshotFunction:      s.config.SnapshotFunction,
+		SnapshotProcedure:     s.config.SnapshotProcedure,
+		SnapshotArguments:     s.config.SnapshotArguments,
+		SnapshotCursor:        s.config.SnapshotCursor,
+		SdkPosition:           rp,
+		CDCStopTimeout:        s.config.CDC.StopTimeout,
+		CDCChangedColumnsOnly: s.config.CDC.ChangedColumnsOnly,
+		CDCCompaction:         s.config.CDC.Compaction,
+		CDCConsumer:           s.config.CDC.ConsumerName,
+		CDCConsumerTTL:        s.config.CDC.ConsumerTTL,
+		CDCColumnPrefix:       s.config.CDC.ColumnPrefix,
+		CDCDropOnTeardown:     s.config.CDC.DropOnTeardown,
+		CDCExclusive:          s.config.CDC.Exclusive,
+		CDCGapTimeout:         s.config.CDC.GapTimeout,
+		CDCLagThreshold:       s.config.CDC.LagThreshold,
+		LagMetadata:           s.config.Metadata.Lag,
+		CDCTransactionID:      s.config.CDC.TransactionID,
+		CDCOrderBy:            s.config.CDC.OrderBy,
+		CDCStartFrom:          s.cdcStartFrom,
+		CDCRetention:          s.config.CDC.Retention,
+		CDCMode:               s.config.CDCMode,
+		DebugMetadata:         s.config.DebugMetadata,
+		ColumnTypesMetadata:   s.config.Metadata.ColumnTypes,
+		ChecksumMetadata:      s.config.Metadata.Checksum,
+		QuoteIdentifiers:      s.config.QuoteIdentifiers,
+		KeepaliveInterval:     s.config.KeepaliveInterval,
+		OnOrphanTrackingTable: s.config.OnOrphanTrackingTable,
+		OnTableRecreate:       s.config.OnTableRecreate,
+		QueryHints:            s.config.QueryHints,
+		KeyCase:               s.config.KeyCase,
+		BooleanColumns:        s.config.BooleanColumns,
+		Nulls:                 s.config.Nulls.Mode,
+		NullDefaults:          s.nullDefaults,
+		Operations:            s.config.CDC.Operations,
+		TimeFormat:            s.config.TimeFormat,
+		SchemaCheckInterval:   s.config.SchemaCheckInterval,
+		Masking:               s.masking,
+
+		HistoryTable:           s.config.History.Table,
+		HistoryValidFromColumn: s.config.History.ValidFromColumn,
+		HistoryValidToColumn:   s.config.History.ValidToColumn,
+
+		SnapshotIsolationLevel: s.config.SnapshotIsolation,
+
+		SnapshotMaxValueRefreshInterval: s.config.SnapshotMaxValueRefreshInterval,
+		SnapshotMaxDuration:             s.config.SnapshotMaxDuration,
+		SnapshotLimit:                   s.config.SnapshotLimit,
+		SnapshotOnMaxDuration:           s.config.SnapshotOnMaxDuration,
+		SnapshotReadReplicaHost:         s.config.SnapshotReadReplicaHost,
+		SnapshotRangeStart:              s.config.SnapshotRangeStart,
+		SnapshotRangeEnd:                s.config.SnapshotRangeEnd,
+		SnapshotTieBreakerColumn:        s.config.SnapshotTieBreakerColumn,
+
+		RetryMax:     s.config.Retry.Max,
+		RetryBackoff: s.config.Retry.Backoff,
+
+		Catalog: catalog.Shared(catalog.Key(s.config.Auth, defaultSchema)),
+	}
+
+	if s.config.Schema != "" {
+		s.iterator, err = iterator.NewMultiIterator(ctx, iterator.MultiParams{
+			CombinedParams:    params,
+			Schema:            s.config.Schema,
+			IncludeRegex:      s.includeRegex,
+			ExcludeRegex:      s.excludeRegex,
+			DiscoveryInterval: s.config.Tables.DiscoveryInterval,
+			Overrides:         s.overrides,
+		})
+	} else {
+		s.iterator, err = iterator.NewCombinedIterator(ctx, params)
+	}
 	if err != nil {
 		return fmt.Errorf("new iterator: %w", err)
 	}
@@ -94,14 +521,25 @@ func (s *Source) Open(ctx context.Context, rp opencdc.Position) error {
 }
 
 // Read gets the next object from the Sap Hana db.
+// A standby instance tries to take over the lock of the pipeline instead, the active one renews it.
 func (s *Source) Read(ctx context.Context) (opencdc.Record, error) {
+	if s.leader != nil && s.leader.due() {
+		if err := s.coordinate(ctx); err != nil {
+			return opencdc.Record{}, err
+		}
+	}
+
+	if s.iterator == nil {
+		return opencdc.Record{}, s.backoff(ctx)
+	}
+
 	hasNext, err := s.iterator.HasNext(ctx)
 	if err != nil {
 		return opencdc.Record{}, fmt.Errorf("source has next: %w", err)
 	}
 
 	if !hasNext {
-		return opencdc.Record{}, sdk.ErrBackoffRetry
+		return opencdc.Record{}, s.backoff(ctx)
 	}
 
 	r, err := s.iterator.Next(ctx)
@@ -109,10 +547,63 @@ func (s *Source) Read(ctx context.Context) (opencdc.Record, error) {
 		return opencdc.Record{}, fmt.Errorf("source next: %w", err)
 	}
 
+	if s.pollBackoff != nil {
+		s.pollBackoff.Reset()
+	}
+
+	if r.Metadata != nil {
+		r.Metadata = withPrefix(r.Metadata, s.config.Metadata.Prefix)
+	}
+
+	if len(s.metadata) > 0 {
+		if r.Metadata == nil {
+			r.Metadata = make(opencdc.Metadata, len(s.metadata))
+		}
+
+		for k, v := range s.metadata {
+			r.Metadata[k] = v
+		}
+	}
+
 	return r, nil
 }
 
+// backoff waits before the next read without new records, if the poll backoff is set, and returns
+// sdk.ErrBackoffRetry, so Conduit calls Read again after its own backoff. The wait ends early
+// when the lock of the pipeline is due, so the lease is renewed in time.
+func (s *Source) backoff(ctx context.Context) error {
+	if s.pollBackoff == nil {
+		return sdk.ErrBackoffRetry
+	}
+
+	delay := s.pollBackoff.Duration()
+	if s.leader != nil {
+		delay = min(delay, s.leader.untilDue())
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+
+	return sdk.ErrBackoffRetry
+}
+
+// coordinate renews the lock of the active instance, or tries to take it over by the standby one.
+func (s *Source) coordinate(ctx context.Context) error {
+	if s.iterator == nil {
+		return s.takeOver(ctx)
+	}
+
+	if err := s.leader.renew(ctx); err != nil {
+		return fmt.Errorf("renew lock: %w", err)
+	}
+
+	return nil
+}
+
 // Teardown gracefully shutdown connector.
+// The lock of the pipeline is released, so a standby instance takes over right away.
 func (s *Source) Teardown(ctx context.Context) error {
 	if s.iterator != nil {
 		err := s.iterator.Stop(ctx)
@@ -121,6 +612,14 @@ func (s *Source) Teardown(ctx context.Context) error {
 		}
 	}
 
+	if s.leader != nil {
+		defer s.leader.db.Close() //nolint:errcheck // the lock is released before
+
+		if err := s.leader.release(ctx); err != nil {
+			return fmt.Errorf("release lock: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -131,5 +630,9 @@ func (s *Source) Ack(ctx context.Context, p opencdc.Position) error {
 		return fmt.Errorf("iterator ack: %w", err)
 	}
 
+	if s.leader != nil {
+		s.leader.ack(p)
+	}
+
 	return nil
 }
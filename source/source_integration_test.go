@@ -553,6 +553,8 @@ func TestSource_CDC_Success(t *testing.T) {
 	}
 
 	is.Equal(opencdc.OperationDelete, r.Operation)
+	// the deleted row is the updated one.
+	is.Equal(wantedRecordBytes, r.Payload.Before.Bytes())
 
 	// check teardown.
 	err = s.Teardown(ctx)
@@ -561,6 +563,95 @@ func TestSource_CDC_Success(t *testing.T) {
 	}
 }
 
+func TestSource_Snapshot_SwitchToCDC(t *testing.T) {
+	t.Parallel()
+
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	tableName := randomIdentifier(t)
+
+	cfg, err := prepareConfigMap(tableName)
+	if err != nil {
+		t.Log(err)
+		t.Skip()
+	}
+
+	db, err := sqlx.Open(driverName, cfg[dsnKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(queryCreateTable, tableName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		er := clearData(ctx, db, tableName)
+		if er != nil {
+			t.Log(er)
+		}
+
+		db.Close()
+	})
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(queryInsertFirstRow, tableName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+
+	err = s.Configure(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Open(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the row is captured by cdc while the snapshot is read.
+	_, err = db.ExecContext(ctx, fmt.Sprintf(queryInsertSecondRow, tableName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := s.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is.Equal(opencdc.OperationSnapshot, r.Operation)
+
+	// the snapshot is done, the change is returned by the same read which switches to cdc.
+	r, err = s.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is.Equal(opencdc.OperationCreate, r.Operation)
+
+	wantedKeyBytes, err := json.Marshal(map[string]any{"ID": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is.Equal(r.Key.Bytes(), wantedKeyBytes)
+
+	err = s.Teardown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestSource_Snapshot_Off(t *testing.T) {
 	t.Parallel()
 
@@ -8,19 +8,91 @@ import (
 )
 
 const (
-	ConfigAuthClientCertFilePath = "auth.clientCertFilePath"
-	ConfigAuthClientKeyFilePath  = "auth.clientKeyFilePath"
-	ConfigAuthDsn                = "auth.dsn"
-	ConfigAuthHost               = "auth.host"
-	ConfigAuthMechanism          = "auth.mechanism"
-	ConfigAuthPassword           = "auth.password"
-	ConfigAuthToken              = "auth.token"
-	ConfigAuthUsername           = "auth.username"
-	ConfigBatchSize              = "batchSize"
-	ConfigOrderingColumn         = "orderingColumn"
-	ConfigPrimaryKeys            = "primaryKeys"
-	ConfigSnapshot               = "snapshot"
-	ConfigTable                  = "table"
+	ConfigAuthClientCertFilePath          = "auth.clientCertFilePath"
+	ConfigAuthClientKeyFilePath           = "auth.clientKeyFilePath"
+	ConfigAuthDatabaseName                = "auth.databaseName"
+	ConfigAuthDsn                         = "auth.dsn"
+	ConfigAuthHost                        = "auth.host"
+	ConfigAuthMechanism                   = "auth.mechanism"
+	ConfigAuthOptions                     = "auth.options.*"
+	ConfigAuthPassword                    = "auth.password"
+	ConfigAuthToken                       = "auth.token"
+	ConfigAuthUsername                    = "auth.username"
+	ConfigBatchSize                       = "batchSize"
+	ConfigBooleanColumns                  = "booleanColumns"
+	ConfigCdcChangedColumnsOnly           = "cdc.changedColumnsOnly"
+	ConfigCdcColumnPrefix                 = "cdc.columnPrefix"
+	ConfigCdcCompaction                   = "cdc.compaction"
+	ConfigCdcConsumerName                 = "cdc.consumerName"
+	ConfigCdcConsumerTTL                  = "cdc.consumerTTL"
+	ConfigCdcDropOnTeardown               = "cdc.dropOnTeardown"
+	ConfigCdcExclusive                    = "cdc.exclusive"
+	ConfigCdcGapTimeout                   = "cdc.gapTimeout"
+	ConfigCdcLagThreshold                 = "cdc.lagThreshold"
+	ConfigCdcOperations                   = "cdc.operations"
+	ConfigCdcOrderBy                      = "cdc.orderBy"
+	ConfigCdcRetention                    = "cdc.retention"
+	ConfigCdcStartFrom                    = "cdc.startFrom"
+	ConfigCdcStopTimeout                  = "cdc.stopTimeout"
+	ConfigCdcTransactionId                = "cdc.transactionId"
+	ConfigCdcMode                         = "cdcMode"
+	ConfigCollectionName                  = "collection.name"
+	ConfigCollectionOrderingField         = "collection.orderingField"
+	ConfigDebugMetadata                   = "debugMetadata"
+	ConfigDefaultSchema                   = "defaultSchema"
+	ConfigHaLeaseDuration                 = "ha.leaseDuration"
+	ConfigHaLockName                      = "ha.lockName"
+	ConfigHistoryTable                    = "history.table"
+	ConfigHistoryValidFromColumn          = "history.validFromColumn"
+	ConfigHistoryValidToColumn            = "history.validToColumn"
+	ConfigKeepaliveInterval               = "keepaliveInterval"
+	ConfigKeyCase                         = "keyCase"
+	ConfigMaskingColumns                  = "masking.columns.*"
+	ConfigMaskingFixedValue               = "masking.fixedValue"
+	ConfigMaskingHashSalt                 = "masking.hashSalt"
+	ConfigMetadataChecksum                = "metadata.checksum"
+	ConfigMetadataColumnTypes             = "metadata.columnTypes"
+	ConfigMetadataConnectorVersion        = "metadata.connectorVersion"
+	ConfigMetadataDatabase                = "metadata.database"
+	ConfigMetadataHost                    = "metadata.host"
+	ConfigMetadataLag                     = "metadata.lag"
+	ConfigMetadataPrefix                  = "metadata.prefix"
+	ConfigMetadataSchema                  = "metadata.schema"
+	ConfigMetadataStatic                  = "metadata.static.*"
+	ConfigNullsDefaults                   = "nulls.defaults.*"
+	ConfigNullsMode                       = "nulls.mode"
+	ConfigOnOrphanTrackingTable           = "onOrphanTrackingTable"
+	ConfigOnTableRecreate                 = "onTableRecreate"
+	ConfigOrderingColumn                  = "orderingColumn"
+	ConfigPollBackoffMax                  = "pollBackoff.max"
+	ConfigPollBackoffMin                  = "pollBackoff.min"
+	ConfigPrimaryKeys                     = "primaryKeys"
+	ConfigQueryHints                      = "queryHints"
+	ConfigQuoteIdentifiers                = "quoteIdentifiers"
+	ConfigRetryBackoff                    = "retry.backoff"
+	ConfigRetryMax                        = "retry.max"
+	ConfigSchema                          = "schema"
+	ConfigSchemaCheckInterval             = "schemaCheckInterval"
+	ConfigSnapshot                        = "snapshot"
+	ConfigSnapshotArguments               = "snapshot.arguments"
+	ConfigSnapshotCursor                  = "snapshot.cursor"
+	ConfigSnapshotFunction                = "snapshot.function"
+	ConfigSnapshotIsolationLevel          = "snapshot.isolationLevel"
+	ConfigSnapshotLimit                   = "snapshot.limit"
+	ConfigSnapshotMaxDuration             = "snapshot.maxDuration"
+	ConfigSnapshotMaxValueRefreshInterval = "snapshot.maxValueRefreshInterval"
+	ConfigSnapshotOnMaxDuration           = "snapshot.onMaxDuration"
+	ConfigSnapshotProcedure               = "snapshot.procedure"
+	ConfigSnapshotRangeEnd                = "snapshot.rangeEnd"
+	ConfigSnapshotRangeStart              = "snapshot.rangeStart"
+	ConfigSnapshotReadReplicaHost         = "snapshot.readReplicaHost"
+	ConfigSnapshotTieBreakerColumn        = "snapshot.tieBreakerColumn"
+	ConfigTable                           = "table"
+	ConfigTablesDiscoveryInterval         = "tables.discoveryInterval"
+	ConfigTablesExcludeRegex              = "tables.excludeRegex"
+	ConfigTablesIncludeRegex              = "tables.includeRegex"
+	ConfigTablesOverrides                 = "tables.overrides"
+	ConfigTimeFormat                      = "timeFormat"
 )
 
 func (Config) Parameters() map[string]config.Parameter {
@@ -37,6 +109,12 @@ func (Config) Parameters() map[string]config.Parameter {
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
+		ConfigAuthDatabaseName: {
+			Default:     "",
+			Description: "DatabaseName is a name of the tenant database of a multitenant system, the host is the system database\nthen. It overrides the databaseName of the DSN.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigAuthDsn: {
 			Default:     "",
 			Description: "DSN connection to SAP HANA database.",
@@ -57,6 +135,12 @@ func (Config) Parameters() map[string]config.Parameter {
 				config.ValidationInclusion{List: []string{"DSN", "Basic", "JWT", "X509"}},
 			},
 		},
+		ConfigAuthOptions: {
+			Default:     "",
+			Description: "Options are go-hdb DSN options for Basic, JWT and X509 auth, e.g. timeout, TLSServerName, defaultSchema.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigAuthPassword: {
 			Default:     "",
 			Description: "Password parameter for basic auth.",
@@ -84,32 +168,470 @@ func (Config) Parameters() map[string]config.Parameter {
 				config.ValidationLessThan{V: 10001},
 			},
 		},
-		ConfigOrderingColumn: {
+		ConfigBooleanColumns: {
 			Default:     "",
-			Description: "OrderingColumn is a name of a column that the connector will use for ordering rows.",
+			Description: "BooleanColumns is a list of integer columns, usually TINYINT, which hold logical booleans,\ntheir values are represented as true or false in records.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcChangedColumnsOnly: {
+			Default:     "false",
+			Description: "ChangedColumnsOnly makes update records contain only keys and changed columns,\nthe names of changed columns are in the saphana.changedColumns metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcColumnPrefix: {
+			Default:     "CONDUIT_",
+			Description: "ColumnPrefix is the prefix of the service columns of the tracking table, like CONDUIT_TRACKING_ID.\nIt's changed if the table has columns with the same names. The tracking table keeps the prefix\nit was created with, so a new prefix requires a new tracking table.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcCompaction: {
+			Default:     "false",
+			Description: "Compaction makes CDC return only the latest change of every key within a batch of tracking rows.\nIt can't be used with changedColumnsOnly.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcConsumerName: {
+			Default:     "",
+			Description: "ConsumerName is a unique name of the pipeline among the pipelines reading the same table. If it's set,\nthe pipelines share one tracking table, and its rows are removed when all of them acknowledge the rows.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcConsumerTTL: {
+			Default:     "168h",
+			Description: "ConsumerTTL is the time after which the offset of a consumer which isn't running is removed, so a deleted\npipeline doesn't keep rows in the shared tracking table forever. A consumer started after its offset\nwas removed fails, as changes it hasn't read can be deleted. Offsets never expire if it's zero.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcDropOnTeardown: {
+			Default:     "false",
+			Description: "DropOnTeardown makes the connector drop the tracking table and the triggers when it stops, for pipelines\nwhich stop permanently, like batch pipelines. Changes made while the connector is stopped aren't captured.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcExclusive: {
+			Default:     "false",
+			Description: "Exclusive makes the connector claim the tracking table, so only one instance reads it. An instance started\nlater with the same tracking table takes it over, the previous one fails before its next batch of changes\nand doesn't remove rows from the tracking table anymore.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcGapTimeout: {
+			Default:     "10s",
+			Description: "GapTimeout is the time after which a gap of tracking ids is skipped. Ids are taken when changes are made,\nbut changes become visible when transactions commit, so changes after a gap aren't read until the gap\nis filled or times out, the transaction was rolled back then. Zero disables waiting for gaps.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcLagThreshold: {
+			Default:     "0",
+			Description: "LagThreshold is the replication lag, the time between a change and its read by the connector, above which\na warning is logged for a batch of changes. The tracking table gets the CONDUIT_CHANGED_AT column with\nthe time of changes, if it's set. Zero disables warnings.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcOperations: {
+			Default:     "create,update,delete",
+			Description: "Operations is a list of operations captured by CDC: create, update, delete.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcOrderBy: {
+			Default:     "id",
+			Description: "OrderBy defines the order of changes. Valid values: id - tracking ids, timestamp - time of changes and\ntracking ids, the tracking table gets the CONDUIT_CHANGED_AT column then.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"id", "timestamp"}},
+			},
+		},
+		ConfigCdcRetention: {
+			Default:     "0",
+			Description: "Retention is the period acknowledged changes are kept in the tracking table, so CDC can be started from\na time within it. It requires the timestamp order. Changes are deleted once acknowledged if it's zero.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcStartFrom: {
+			Default:     "",
+			Description: "StartFrom is the time of the first change read when CDC starts without a position, in RFC 3339 format.\nIt requires the timestamp order.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcStopTimeout: {
+			Default:     "20s",
+			Description: "StopTimeout is the maximum time the connector waits for the tracking table cleanup on stop.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcTransactionId: {
+			Default:     "false",
+			Description: "TransactionID makes the triggers capture the id of the transaction of every change, records carry it\nin the saphana.transactionId metadata field, so changes can be applied atomically per transaction.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigCdcMode: {
+			Default:     "trigger",
+			Description: "CDCMode defines how changes are captured. Valid values: trigger - triggers on the table,\nsdi - the Smart Data Integration remote subscription on the table, which has to be a virtual table.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
-				config.ValidationRequired{},
+				config.ValidationInclusion{List: []string{"trigger", "sdi"}},
 			},
 		},
+		ConfigCollectionName: {
+			Default:     "",
+			Description: "Name is a name of the collection the connector should read from instead of the table or schema.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigCollectionOrderingField: {
+			Default:     "_id",
+			Description: "OrderingField is a name of a top-level document field that the connector will use for ordering documents.\nField names are case-sensitive.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigDebugMetadata: {
+			Default:     "false",
+			Description: "DebugMetadata adds the id, the operation and the capture time of the tracking row into the metadata\nof CDC records, so a record can be traced back to its tracking table entry.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigDefaultSchema: {
+			Default:     "",
+			Description: "DefaultSchema is a schema, which is set on every connection of the pool, so unqualified table names\nresolve in it instead of the default schema of the user. It takes precedence over the defaultSchema option.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHaLeaseDuration: {
+			Default:     "30s",
+			Description: "LeaseDuration is the time the lock is held without renewing it, a standby instance takes over after it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigHaLockName: {
+			Default:     "",
+			Description: "LockName is a name of the lock shared by the instances of the pipeline, the instance holding it reads\nrecords, and the other ones stand by until its lease expires. Instances don't coordinate if it's empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHistoryTable: {
+			Default:     "",
+			Description: "Table is a name of the history table of the system-versioned table.\nIf it's set, all versions from the history table are read before the snapshot.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHistoryValidFromColumn: {
+			Default:     "",
+			Description: "ValidFromColumn is a name of the column with the start of the version validity period.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigHistoryValidToColumn: {
+			Default:     "",
+			Description: "ValidToColumn is a name of the column with the end of the version validity period.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigKeepaliveInterval: {
+			Default:     "0",
+			Description: "KeepaliveInterval is the interval of pinging the database in the background, so idle connections aren't\ndropped by firewalls. Idle connections are checked before reuse and replaced if they are broken.\nZero disables it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigKeyCase: {
+			Default:     "preserve",
+			Description: "KeyCase defines the case of field names of keys and payloads.\nValid values: preserve - column names of the table, usually upper case, upper, lower.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"upper", "preserve", "lower"}},
+			},
+		},
+		ConfigMaskingColumns: {
+			Default:     "",
+			Description: "Columns is a map of column names to masking strategies: null, hash, fixed.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigMaskingFixedValue: {
+			Default:     "****",
+			Description: "FixedValue is the value of columns masked with the fixed strategy.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigMaskingHashSalt: {
+			Default:     "",
+			Description: "HashSalt is the key of HMAC-SHA256 for columns masked with the hash strategy, plain SHA-256 is used if it's empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataChecksum: {
+			Default:     "false",
+			Description: "Checksum adds the SHA-256 hash of the JSON of the payload with sorted field names into the saphana.checksum\nmetadata field, so reconciliation jobs can compare rows of the source and the target by their keys.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataColumnTypes: {
+			Default:     "false",
+			Description: "ColumnTypes adds the JSON object of column types of the table with their lengths, precisions and scales,\ne.g. DECIMAL(10,2), NVARCHAR(40), into the saphana.columnTypes metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataConnectorVersion: {
+			Default:     "false",
+			Description: "ConnectorVersion adds the version of the connector into the saphana.connectorVersion metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataDatabase: {
+			Default:     "false",
+			Description: "Database adds the name of the database, the tenant database of a multitenant system,\ninto the saphana.database metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataHost: {
+			Default:     "false",
+			Description: "Host adds the host and port of the database into the saphana.host metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataLag: {
+			Default:     "false",
+			Description: "Lag adds the replication lag of CDC records in milliseconds into the saphana.lag metadata field.\nThe tracking table gets the CONDUIT_CHANGED_AT column with the time of changes, if it's set.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataPrefix: {
+			Default:     "saphana.",
+			Description: "Prefix is the prefix of the metadata fields of the connector, e.g. erp. makes it erp.table instead of\nsaphana.table.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataSchema: {
+			Default:     "false",
+			Description: "Schema adds the name of the schema of the table into the saphana.schema metadata field.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigMetadataStatic: {
+			Default:     "",
+			Description: "Static is a map of metadata fields with constant values added to every record as they are,\ne.g. environment or the system ID, which downstream processors route records by.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigNullsDefaults: {
+			Default:     "",
+			Description: "Defaults is a map of column names to values replacing their nulls in the default mode. Values which are\nvalid JSON, like 0, false or \"\", are parsed, other values are strings. Columns without a default keep nulls.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigNullsMode: {
+			Default:     "include",
+			Description: "Mode defines how NULL column values are represented. Valid values: include - explicit nulls,\nomit - the fields are left out, default - the defaults of the columns replace nulls.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"include", "omit", "default"}},
+			},
+		},
+		ConfigOnOrphanTrackingTable: {
+			Default:     "adopt",
+			Description: "OnOrphanTrackingTable defines what to do with the tracking table left by the previous runs of the connector,\nwhen there is no position. Valid values: adopt - read changes from the existing tracking table,\nrecreate - drop it and create a new one, fail - stop with an error.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"adopt", "recreate", "fail"}},
+			},
+		},
+		ConfigOnTableRecreate: {
+			Default:     "fail",
+			Description: "OnTableRecreate defines what to do when the table was dropped and created again since the position was saved.\nValid values: fail - stop with an error, resnapshot - drop the tracking table and read the table\nfrom the beginning.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"fail", "resnapshot"}},
+			},
+		},
+		ConfigOrderingColumn: {
+			Default:     "",
+			Description: "OrderingColumn is a name of a column that the connector will use for ordering rows.\nIt's required for the single table. In the schema mode tables without this column are ordered\nby their single column primary key.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigPollBackoffMax: {
+			Default:     "30s",
+			Description: "Max is the maximum delay of reads without new records.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigPollBackoffMin: {
+			Default:     "0",
+			Description: "Min is the delay of the first read without new records, it's doubled for every next one up to Max,\nand reset by a new record. Zero leaves the pacing to Conduit.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
 		ConfigPrimaryKeys: {
 			Default:     "",
-			Description: "PrimaryKeys list of column names should use for their `Key` fields.",
+			Description: "PrimaryKeys list of column names should use for their `Key` fields. It's ignored in the schema mode.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigQueryHints: {
+			Default:     "",
+			Description: "QueryHints are hints added as WITH HINT(...) to the snapshot and CDC select queries,\ne.g. NO_USE_OLAP_PLAN, RESULT_LAG('hana_sr', 30).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigQuoteIdentifiers: {
+			Default:     "false",
+			Description: "QuoteIdentifiers quotes column names in the generated SQL: the tracking table DDL, the triggers and the queries\nof the source, and the statements of the destination. It's needed for columns named by reserved words,\ne.g. ORDER, or containing special characters, e.g. /BIC/ZAMOUNT.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigRetryBackoff: {
+			Default:     "1s",
+			Description: "Backoff is the delay before the first retry, it's doubled for every next retry.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigRetryMax: {
+			Default:     "3",
+			Description: "Max is the maximum number of retries of a failed read, zero disables retries.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		ConfigSchema: {
+			Default:     "",
+			Description: "Schema is a name of the schema, all tables of which the connector should read from instead of the single table.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
+		ConfigSchemaCheckInterval: {
+			Default:     "1m",
+			Description: "SchemaCheckInterval is the interval of checking the table columns for changes, 0 disables the check.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
 		ConfigSnapshot: {
 			Default:     "true",
 			Description: "Snapshot whether or not the plugin will take a snapshot of the entire table before starting cdc.",
 			Type:        config.ParameterTypeBool,
 			Validations: []config.Validation{},
 		},
+		ConfigSnapshotArguments: {
+			Default:     "",
+			Description: "SnapshotArguments is a list of values bound to the parameters of the snapshot function or procedure.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotCursor: {
+			Default:     "false",
+			Description: "SnapshotCursor makes the snapshot read the table through a single cursor, which is fetched in batches,\ninstead of a query per batch.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotFunction: {
+			Default:     "",
+			Description: "SnapshotFunction is a name of a table function the snapshot reads from instead of the table.\nThe function result is paginated by the ordering column, as the table is.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotIsolationLevel: {
+			Default:     "",
+			Description: "SnapshotIsolation is the isolation level of the transaction the snapshot is read in.\nValid values: readCommitted, repeatableRead, serializable. The snapshot isn't read in a transaction by default.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"readCommitted", "repeatableRead", "serializable"}},
+			},
+		},
+		ConfigSnapshotLimit: {
+			Default:     "0",
+			Description: "SnapshotLimit is the maximum number of records of the snapshot, after them the connector switches to CDC,\nthe rest of the snapshot isn't read. In the schema mode it limits the snapshot of every table. Zero disables it.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		ConfigSnapshotMaxDuration: {
+			Default:     "0",
+			Description: "SnapshotMaxDuration is the maximum time of reading the snapshot, after it the connector switches to CDC.\nZero disables it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotMaxValueRefreshInterval: {
+			Default:     "0",
+			Description: "SnapshotMaxValueRefreshInterval is the interval of refreshing the max value of the ordering column during\nthe snapshot, so rows inserted after the snapshot started are read by it. Zero disables it.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotOnMaxDuration: {
+			Default:     "resume",
+			Description: "SnapshotOnMaxDuration defines what happens to the rest of the snapshot after its max duration.\nValid values: resume - it's read in batches whenever CDC has no changes, abandon - it isn't read.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"resume", "abandon"}},
+			},
+		},
+		ConfigSnapshotProcedure: {
+			Default:     "",
+			Description: "SnapshotProcedure is a name of a procedure the snapshot reads the first result set of instead of the table.\nThe result is read with a single call, so the snapshot restarts from the beginning after a restart.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotRangeEnd: {
+			Default:     "",
+			Description: "SnapshotRangeEnd is the highest value of the ordering column the snapshot reads. It's inclusive.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotRangeStart: {
+			Default:     "",
+			Description: "SnapshotRangeStart is the lowest value of the ordering column the snapshot reads, e.g. 2024-01-01,\nso a part of the table is backfilled again without the full snapshot. It's inclusive.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotReadReplicaHost: {
+			Default:     "",
+			Description: "SnapshotReadReplicaHost is the host and port of the read-enabled secondary system of the system replication,\nthe snapshot is read from it, while CDC stays on the primary system.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigSnapshotTieBreakerColumn: {
+			Default:     "",
+			Description: "SnapshotTieBreakerColumn is a name of a unique column, which orders rows sharing a value of the ordering column,\ne.g. the key of a table ordered by a DATE or SECONDDATE column, so none of them are skipped on resume.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		ConfigTable: {
 			Default:     "",
 			Description: "Table is a name of the table that the connector should write to or read from.",
 			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigTablesDiscoveryInterval: {
+			Default:     "1m",
+			Description: "DiscoveryInterval is the interval of checking the schema for new tables.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		ConfigTablesExcludeRegex: {
+			Default:     "",
+			Description: "ExcludeRegex is a regular expression, tables matching it aren't read in the schema mode.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigTablesIncludeRegex: {
+			Default:     "",
+			Description: "IncludeRegex is a regular expression, only tables matching it are read in the schema mode.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigTablesOverrides: {
+			Default:     "",
+			Description: "Overrides is a list of options of single tables, which override the connector options in the schema mode,\ne.g. ORDERS?batchSize=500, CURRENCIES?pollInterval=1m. Options are separated by &.\nValid options: batchSize, pollInterval - the minimum interval of polling the table after it had no changes.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		ConfigTimeFormat: {
+			Default:     "rfc3339",
+			Description: "TimeFormat defines how time values are represented in records.\nValid values: rfc3339, unixMillis - epoch milliseconds, date - DATE columns without time, e.g. 2018-01-01.",
+			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
-				config.ValidationRequired{},
+				config.ValidationInclusion{List: []string{"rfc3339", "unixMillis", "date"}},
 			},
 		},
 	}
@@ -0,0 +1,276 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// row is a row of a table with the values of the key columns and the checksum of the compared columns.
+type row struct {
+	// key - normalized values of the key columns by their names, which are reported.
+	key map[string]any
+	// keys - values of the key columns in their order, which the rows are ordered by.
+	keys     []any
+	checksum [sha256.Size]byte
+}
+
+// rowReader reads rows of a table ordered by their keys.
+type rowReader interface {
+	// next returns the next row, and false if there are no more rows.
+	next() (row, bool, error)
+}
+
+// tableRows reads rows of a table query.
+type tableRows struct {
+	rows    *sqlx.Rows
+	keys    []string
+	columns []string
+}
+
+// next scans the next row of the query.
+func (t *tableRows) next() (row, bool, error) {
+	if !t.rows.Next() {
+		if err := t.rows.Err(); err != nil {
+			return row{}, false, fmt.Errorf("iterate rows: %w", err)
+		}
+
+		return row{}, false, nil
+	}
+
+	values := make([]any, len(t.keys)+len(t.columns))
+	dest := make([]any, len(values))
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	if err := t.rows.Scan(dest...); err != nil {
+		return row{}, false, fmt.Errorf("scan row: %w", err)
+	}
+
+	fields := make(map[string]any, len(t.columns))
+	for i, column := range t.columns {
+		fields[column] = values[len(t.keys)+i]
+	}
+
+	return newRow(t.keys, values[:len(t.keys)], fields)
+}
+
+// Close closes the query rows.
+func (t *tableRows) Close() error {
+	if err := t.rows.Close(); err != nil {
+		return fmt.Errorf("close rows: %w", err)
+	}
+
+	return nil
+}
+
+// newRow returns the row of the key values and the compared fields.
+// The checksum is the SHA-256 hash of the JSON of the fields with sorted names. Decimals are represented
+// as reduced fractions and time in UTC, so the same values of columns of different types have the same checksum.
+func newRow(keys []string, keyValues []any, fields map[string]any) (row, bool, error) {
+	r := row{key: make(map[string]any, len(keys)), keys: keyValues}
+
+	for i, key := range keys {
+		r.key[key] = normalize(keyValues[i])
+	}
+
+	normalized := make(map[string]any, len(fields))
+	for column, value := range fields {
+		normalized[column] = normalize(value)
+	}
+
+	// maps are encoded with sorted keys.
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return row{}, false, fmt.Errorf("marshal row: %w", err)
+	}
+
+	r.checksum = sha256.Sum256(data)
+
+	return r, true, nil
+}
+
+// normalize returns the value in the representation independent of the column type.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case *big.Rat:
+		return v.RatString()
+	case time.Time:
+		return v.UTC()
+	default:
+		return value
+	}
+}
+
+// compare reads the rows of both tables in the order of their keys, and counts the rows missing in one
+// of them and the rows with different checksums.
+func compare(source, target rowReader, maxSamples int) (Report, error) {
+	var report Report
+
+	drift := func(kind string, r row) {
+		if len(report.Samples) < maxSamples {
+			report.Samples = append(report.Samples, Drift{Kind: kind, Key: r.key})
+		}
+	}
+
+	sourceRow, sourceOK, err := source.next()
+	if err != nil {
+		return Report{}, fmt.Errorf("read source row: %w", err)
+	}
+
+	targetRow, targetOK, err := target.next()
+	if err != nil {
+		return Report{}, fmt.Errorf("read target row: %w", err)
+	}
+
+	for sourceOK || targetOK {
+		var order int
+
+		switch {
+		case !targetOK:
+			order = -1
+		case !sourceOK:
+			order = 1
+		default:
+			order = compareKeys(sourceRow.keys, targetRow.keys)
+		}
+
+		if order < 0 {
+			report.Missing++
+			drift(DriftMissing, sourceRow)
+		}
+
+		if order > 0 {
+			report.Extra++
+			drift(DriftExtra, targetRow)
+		}
+
+		if order == 0 && sourceRow.checksum != targetRow.checksum {
+			report.Mismatched++
+			drift(DriftMismatch, sourceRow)
+		}
+
+		if order <= 0 {
+			report.SourceRows++
+
+			if sourceRow, sourceOK, err = source.next(); err != nil {
+				return Report{}, fmt.Errorf("read source row: %w", err)
+			}
+		}
+
+		if order >= 0 {
+			report.TargetRows++
+
+			if targetRow, targetOK, err = target.next(); err != nil {
+				return Report{}, fmt.Errorf("read target row: %w", err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// compareKeys compares the key values in the order of the database, see compareValues.
+func compareKeys(a, b []any) int {
+	for i := range a {
+		if order := compareValues(a[i], b[i]); order != 0 {
+			return order
+		}
+	}
+
+	return 0
+}
+
+// compareValues compares the values like the database sorts them: nulls first, numbers by their values,
+// strings and binaries by their bytes, and time chronologically.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil || b == nil:
+		return cmp.Compare(boolOrder(a != nil), boolOrder(b != nil))
+	case isNumber(a) && isNumber(b):
+		return toRat(a).Cmp(toRat(b))
+	}
+
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return cmp.Compare(a, b)
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// boolOrder returns 1 for true and 0 for false.
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// isNumber returns true if the value is an integer, a float or a decimal.
+func isNumber(value any) bool {
+	if _, ok := value.(*big.Rat); ok {
+		return true
+	}
+
+	switch reflect.ValueOf(value).Kind() { //nolint:exhaustive // other kinds aren't numbers
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// toRat returns the number as a fraction, see isNumber.
+func toRat(value any) *big.Rat {
+	if rat, ok := value.(*big.Rat); ok {
+		return rat
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() { //nolint:exhaustive // other kinds aren't numbers
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetUint64(rv.Uint())
+	default:
+		return new(big.Rat).SetFloat64(rv.Float())
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import "errors"
+
+var (
+	// ErrNoKey occurs when the key columns aren't set and the source table has no primary key or unique index.
+	ErrNoKey = errors.New("no key columns")
+	// ErrUnknownColumn occurs when a key or a compared column doesn't exist in one of the tables.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrNotComparable occurs when a compared column is a large object or a vector.
+	ErrNotComparable = errors.New("column isn't comparable")
+)
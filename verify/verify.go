@@ -0,0 +1,195 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verify compares a source table with a target table, the tables a pipeline reads from and writes to,
+// by their row counts and the checksums of their rows, and reports the drift between them.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DriftMissing is the kind of the drift of a source row missing in the target table.
+	DriftMissing = "missing"
+	// DriftExtra is the kind of the drift of a target row missing in the source table.
+	DriftExtra = "extra"
+	// DriftMismatch is the kind of the drift of a row with different values in the tables.
+	DriftMismatch = "mismatch"
+)
+
+const queryRows = `SELECT %s FROM %s ORDER BY %s`
+
+// Params is an incoming params for the Run function.
+type Params struct {
+	Source *sqlx.DB
+	Target *sqlx.DB
+	// SourceTable, TargetTable - names of the compared tables, the target table has the name of the source one
+	// if it's empty.
+	SourceTable string
+	TargetTable string
+	// Keys - columns matching rows of the tables, the primary key or the first unique index of the source table
+	// is used if it's empty.
+	Keys []string
+	// Columns - columns compared by their values, the comparable columns of the source table, which exist
+	// in the target table, are compared if it's empty. Large objects and vectors aren't comparable.
+	Columns []string
+	// MaxSamples - max number of keys of drifted rows in the report.
+	MaxSamples int
+}
+
+// Report holds the result of the comparison of the tables.
+type Report struct {
+	SourceRows int64 `json:"sourceRows"`
+	TargetRows int64 `json:"targetRows"`
+	// Missing - number of source rows missing in the target table.
+	Missing int64 `json:"missing"`
+	// Extra - number of target rows missing in the source table.
+	Extra int64 `json:"extra"`
+	// Mismatched - number of rows with different values in the tables.
+	Mismatched int64 `json:"mismatched"`
+	// Samples - keys of the first drifted rows.
+	Samples []Drift `json:"samples,omitempty"`
+}
+
+// Drift is a row which differs in the tables.
+type Drift struct {
+	Kind string         `json:"kind"`
+	Key  map[string]any `json:"key"`
+}
+
+// HasDrift returns true if the tables differ.
+func (r Report) HasDrift() bool {
+	return r.Missing > 0 || r.Extra > 0 || r.Mismatched > 0
+}
+
+// Run reads rows of both tables ordered by the keys and compares them by their keys and checksums.
+// The rows are read in one pass, so the tables can be of any size, and rows changed during the comparison
+// can be reported as drifted.
+func Run(ctx context.Context, params Params) (Report, error) {
+	source, err := columntypes.GetTableInfo(ctx, params.Source, params.SourceTable)
+	if err != nil {
+		return Report{}, fmt.Errorf("get source table info: %w", err)
+	}
+
+	targetTable := params.TargetTable
+	if targetTable == "" {
+		targetTable = params.SourceTable
+	}
+
+	target, err := columntypes.GetTableInfo(ctx, params.Target, targetTable)
+	if err != nil {
+		return Report{}, fmt.Errorf("get target table info: %w", err)
+	}
+
+	keys, columns, err := comparedColumns(source, target, params.Keys, params.Columns)
+	if err != nil {
+		return Report{}, err
+	}
+
+	sourceRows, err := queryTable(ctx, params.Source, source, keys, columns)
+	if err != nil {
+		return Report{}, fmt.Errorf("query source table: %w", err)
+	}
+	defer sourceRows.Close()
+
+	targetRows, err := queryTable(ctx, params.Target, target, keys, columns)
+	if err != nil {
+		return Report{}, fmt.Errorf("query target table: %w", err)
+	}
+	defer targetRows.Close()
+
+	return compare(sourceRows, targetRows, params.MaxSamples)
+}
+
+// comparedColumns returns the upper-cased key columns and the compared columns, which are checked to exist
+// in both tables.
+func comparedColumns(
+	source, target columntypes.TableInfo, keys, columns []string,
+) ([]string, []string, error) {
+	keys = upper(keys)
+	if len(keys) == 0 {
+		keys = source.PrimaryKeys
+	}
+
+	if len(keys) == 0 {
+		keys = source.UniqueKeys
+	}
+
+	if len(keys) == 0 {
+		return nil, nil, ErrNoKey
+	}
+
+	columns = upper(columns)
+	if len(columns) == 0 {
+		for column, columnType := range source.ColumnTypes {
+			if _, ok := target.ColumnTypes[column]; ok && columntypes.IsComparable(columnType) {
+				columns = append(columns, column)
+			}
+		}
+
+		slices.Sort(columns)
+	}
+
+	for _, column := range append(slices.Clone(keys), columns...) {
+		for _, table := range []columntypes.TableInfo{source, target} {
+			columnType, ok := table.ColumnTypes[column]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: %s of %s", ErrUnknownColumn, column, table.QualifiedName())
+			}
+
+			if !columntypes.IsComparable(columnType) {
+				return nil, nil, fmt.Errorf("%w: %s of %s", ErrNotComparable, column, table.QualifiedName())
+			}
+		}
+	}
+
+	return keys, columns, nil
+}
+
+// queryTable returns the reader of the key and the compared columns of the table ordered by the keys.
+func queryTable(
+	ctx context.Context, db *sqlx.DB, table columntypes.TableInfo, keys, columns []string,
+) (*tableRows, error) {
+	selected := make([]string, 0, len(keys)+len(columns))
+	for _, column := range append(slices.Clone(keys), columns...) {
+		selected = append(selected, columntypes.QuoteIdentifier(column))
+	}
+
+	query := fmt.Sprintf(queryRows, strings.Join(selected, ", "), table.QualifiedName(),
+		strings.Join(selected[:len(keys)], ", "))
+
+	rows, err := db.QueryxContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("execute select query: %w", err)
+	}
+
+	return &tableRows{rows: rows, keys: keys, columns: columns}, nil
+}
+
+// upper returns the names in upper case, as column names are upper case in Sap Hana.
+func upper(names []string) []string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = strings.ToUpper(strings.TrimSpace(name))
+	}
+
+	return result
+}
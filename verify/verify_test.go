@@ -0,0 +1,168 @@
+// Copyright © 2023 Meroxa, Inc. & Yalantis
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conduitio-labs/conduit-connector-sap-hana/columntypes"
+)
+
+// sliceReader reads rows of the ID key and the NAME column.
+type sliceReader struct {
+	rows [][2]any
+}
+
+func (s *sliceReader) next() (row, bool, error) {
+	if len(s.rows) == 0 {
+		return row{}, false, nil
+	}
+
+	r := s.rows[0]
+	s.rows = s.rows[1:]
+
+	return newRow([]string{"ID"}, []any{r[0]}, map[string]any{"NAME": r[1]})
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	source := &sliceReader{rows: [][2]any{
+		{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}, {int64(5), "e"},
+	}}
+	target := &sliceReader{rows: [][2]any{
+		{int64(1), "a"}, {int64(3), "changed"}, {int64(4), "d"}, {int64(5), "e"}, {int64(6), "f"},
+	}}
+
+	got, err := compare(source, target, 2)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+
+	want := Report{
+		SourceRows: 4,
+		TargetRows: 5,
+		Missing:    1,
+		Extra:      2,
+		Mismatched: 1,
+		Samples: []Drift{
+			{Kind: DriftMissing, Key: map[string]any{"ID": int64(2)}},
+			{Kind: DriftMismatch, Key: map[string]any{"ID": int64(3)}},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compare() = %+v, want %+v", got, want)
+	}
+
+	if !got.HasDrift() {
+		t.Errorf("HasDrift() = false, want true")
+	}
+}
+
+func TestNewRow_Checksum(t *testing.T) {
+	t.Parallel()
+
+	// the same decimal and time values of columns of different types have the same checksum.
+	moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	a, _, err := newRow([]string{"ID"}, []any{int64(1)}, map[string]any{
+		"PRICE": big.NewRat(2209, 20), "UPDATED_AT": moment,
+	})
+	if err != nil {
+		t.Fatalf("new row: %v", err)
+	}
+
+	b, _, err := newRow([]string{"ID"}, []any{int64(1)}, map[string]any{
+		"UPDATED_AT": moment.In(time.FixedZone("CET", 3600)), "PRICE": big.NewRat(110450, 1000),
+	})
+	if err != nil {
+		t.Fatalf("new row: %v", err)
+	}
+
+	if a.checksum != b.checksum {
+		t.Errorf("checksums differ")
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b any
+		want int
+	}{
+		{name: "integers", a: int64(9), b: int64(10), want: -1},
+		{name: "integer and decimal", a: int32(2), b: big.NewRat(3, 2), want: 1},
+		{name: "equal decimal and float", a: big.NewRat(1, 4), b: 0.25, want: 0},
+		{name: "strings by bytes", a: "10", b: "9", want: -1},
+		{name: "binaries", a: []byte("b"), b: []byte("a"), want: 1},
+		{name: "time", a: time.Unix(1, 0), b: time.Unix(2, 0), want: -1},
+		{name: "null first", a: nil, b: int64(1), want: -1},
+		{name: "nulls", a: nil, b: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparedColumns(t *testing.T) {
+	t.Parallel()
+
+	source := columntypes.TableInfo{
+		Schema:      "SALES",
+		Name:        "ORDERS",
+		PrimaryKeys: []string{"ID"},
+		ColumnTypes: map[string]string{"ID": "INTEGER", "NAME": "NVARCHAR", "NOTE": "NCLOB", "SECRET": "VARCHAR"},
+	}
+	target := columntypes.TableInfo{
+		Schema:      "DWH",
+		Name:        "ORDERS",
+		ColumnTypes: map[string]string{"ID": "BIGINT", "NAME": "NVARCHAR", "NOTE": "NCLOB", "LOADED_AT": "TIMESTAMP"},
+	}
+
+	keys, columns, err := comparedColumns(source, target, nil, nil)
+	if err != nil {
+		t.Fatalf("compared columns: %v", err)
+	}
+
+	if !reflect.DeepEqual(keys, []string{"ID"}) || !reflect.DeepEqual(columns, []string{"ID", "NAME"}) {
+		t.Errorf("comparedColumns() = %v, %v, want [ID], [ID NAME]", keys, columns)
+	}
+
+	if _, _, err = comparedColumns(source, target, []string{"id"}, []string{"secret"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error = %v, want %v", err, ErrUnknownColumn)
+	}
+
+	if _, _, err = comparedColumns(source, target, nil, []string{"note"}); !errors.Is(err, ErrNotComparable) {
+		t.Errorf("error = %v, want %v", err, ErrNotComparable)
+	}
+
+	if _, _, err = comparedColumns(columntypes.TableInfo{}, target, nil, nil); !errors.Is(err, ErrNoKey) {
+		t.Errorf("error = %v, want %v", err, ErrNoKey)
+	}
+}